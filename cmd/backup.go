@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+
+	"gotssh/internal/config"
+	"gotssh/internal/ui"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// backupPassphraseEnv 备份密码的环境变量，用于脚本中创建和恢复备份
+const backupPassphraseEnv = config.EnvPrefix + "BACKUP_PASSPHRASE"
+
+// backupCmd 备份命令
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "创建和恢复加密备份",
+	Long: `将服务器、凭证、端口转发和设置备份到用密码加密的文件，用于迁移到新电脑。
+
+备份包含用户配置中的全部条目和设置，以及凭证、服务器和模板引用的私钥文件的内容；
+系统配置、项目配置、共享清单和动态清单中的条目不备份。
+备份用 scrypt 由密码派生密钥，以 AES-256-GCM 加密。
+
+密码从终端输入，也可以用 --passphrase-file 或环境变量 GOTSSH_BACKUP_PASSPHRASE 指定。`,
+}
+
+// backupCreateCmd 创建备份命令
+var backupCreateCmd = &cobra.Command{
+	Use:   "create <备份文件>",
+	Short: "创建加密备份",
+	Long: `创建加密备份，备份文件的权限为 0600。
+
+私钥文件无法读取时给出警告，备份中不包含该文件。
+
+示例：
+  gotssh backup create ~/gotssh.bak
+  GOTSSH_BACKUP_PASSPHRASE=... gotssh backup create /mnt/usb/gotssh.bak`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		if _, err := os.Stat(args[0]); err == nil && !force {
+			return fmt.Errorf("文件 %s 已存在，使用 --force 覆盖", args[0])
+		}
+		passphrase, err := backupPassphrase(cmd, true)
+		if err != nil {
+			return err
+		}
+
+		data, info, err := configManager.CreateBackup(passphrase)
+		if err != nil {
+			return fmt.Errorf("创建备份失败: %w", err)
+		}
+		if err := os.WriteFile(args[0], data, 0600); err != nil {
+			return fmt.Errorf("写入备份文件失败: %w", err)
+		}
+		for _, warning := range info.Warnings {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+		fmt.Print(ui.FormatBackupInfo(info))
+		fmt.Printf("✅ 已创建备份 %s\n", args[0])
+		return nil
+	},
+}
+
+// backupRestoreCmd 恢复备份命令
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <备份文件>",
+	Short: "从加密备份恢复",
+	Long: `从加密备份恢复，恢复前显示预览。
+
+默认与现有配置合并，ID相同或地址（user@host:port）相同的条目视为冲突：
+  --on-conflict skip        保留现有的条目（默认）
+  --on-conflict overwrite   用备份中的条目覆盖
+  --on-conflict rename      以新的ID恢复，分组、模板和动态清单以名称为键，仍然跳过
+别名已被其他条目使用时追加序号，引用这些条目的服务器、端口转发和转发组随之更新。
+
+--replace 先删除用户配置中的全部条目再恢复，设置与备份合并。
+--tag 只恢复带有指定标签的服务器，以及它们的跳板机、端口转发、分组和凭证，不恢复设置。
+
+私钥文件不存在时写入（权限 0600）；已存在且内容不同时不覆盖，恢复的凭证改为使用备份中的密钥内容。
+恢复前的用户配置保留在配置文件的轮换备份中。
+
+示例：
+  gotssh backup restore ~/gotssh.bak --dry-run
+  gotssh backup restore ~/gotssh.bak --replace
+  gotssh backup restore ~/gotssh.bak --tag prod --skip-credentials
+  gotssh backup restore ~/gotssh.bak --on-conflict rename -y`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		replace, _ := cmd.Flags().GetBool("replace")
+		conflict, _ := cmd.Flags().GetString("on-conflict")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		skipCredentials, _ := cmd.Flags().GetBool("skip-credentials")
+		skipSettings, _ := cmd.Flags().GetBool("skip-settings")
+		skipKeys, _ := cmd.Flags().GetBool("skip-keys")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("读取备份文件失败: %w", err)
+		}
+		passphrase, err := backupPassphrase(cmd, false)
+		if err != nil {
+			return err
+		}
+		backup, err := config.OpenBackup(data, passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Print(ui.FormatBackupInfo(&backup.BackupInfo))
+
+		plan, err := configManager.PlanRestore(backup, config.RestoreOptions{
+			Replace:         replace,
+			Conflict:        config.ConflictPolicy(conflict),
+			Tags:            tags,
+			SkipCredentials: skipCredentials,
+			SkipSettings:    skipSettings,
+			SkipKeys:        skipKeys,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Print(ui.FormatRestorePlan(plan))
+		if dryRun {
+			return nil
+		}
+
+		if !yes {
+			label := "确定恢复以上内容吗？"
+			if replace {
+				label = "替换模式将删除现有的服务器、凭证和端口转发，确定恢复吗？"
+			}
+			ok, err := confirm(label)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		result, err := configManager.ApplyRestore(plan)
+		if err != nil {
+			return fmt.Errorf("恢复备份失败: %w", err)
+		}
+		for _, path := range result.Keys {
+			fmt.Printf("🔑 已写入私钥文件 %s\n", path)
+		}
+		for _, warning := range result.Warnings {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+		fmt.Printf("✅ 已恢复 %d 个条目", result.Added+result.Overwritten)
+		if result.Overwritten > 0 {
+			fmt.Printf("（覆盖 %d 个）", result.Overwritten)
+		}
+		if result.Skipped > 0 {
+			fmt.Printf("，跳过 %d 个", result.Skipped)
+		}
+		if result.Removed > 0 {
+			fmt.Printf("，删除 %d 个", result.Removed)
+		}
+		if result.Settings > 0 {
+			fmt.Printf("，%d 项设置", result.Settings)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// backupPassphrase 读取备份密码：--passphrase-file 指定的文件、环境变量或终端输入，创建备份时终端输入需要确认
+func backupPassphrase(cmd *cobra.Command, confirmInput bool) ([]byte, error) {
+	if path, _ := cmd.Flags().GetString("passphrase-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取密码文件失败: %w", err)
+		}
+		line, _, _ := bytes.Cut(data, []byte("\n"))
+		passphrase := bytes.TrimRight(line, "\r")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("密码文件 %s 为空", path)
+		}
+		return passphrase, nil
+	}
+	if passphrase := os.Getenv(backupPassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return nil, fmt.Errorf("无法读取备份密码，请使用 --passphrase-file 或环境变量 %s", backupPassphraseEnv)
+	}
+
+	fmt.Print("请输入备份密码: ")
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("读取密码失败: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("备份密码不能为空")
+	}
+	if confirmInput {
+		fmt.Print("请再次输入备份密码: ")
+		again, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Println()
+		if err != nil {
+			return nil, fmt.Errorf("读取密码失败: %w", err)
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("两次输入的密码不一致")
+		}
+	}
+	return passphrase, nil
+}
+
+func init() {
+	backupCreateCmd.Flags().Bool("force", false, "覆盖已存在的备份文件")
+	backupRestoreCmd.Flags().Bool("replace", false, "先删除用户配置中的全部条目再恢复")
+	backupRestoreCmd.Flags().String("on-conflict", string(config.ConflictSkip), "合并时冲突的处理方式：skip、overwrite、rename")
+	backupRestoreCmd.Flags().StringSlice("tag", nil, "只恢复带有指定标签的服务器及其依赖，可多次指定")
+	backupRestoreCmd.Flags().Bool("skip-credentials", false, "不恢复凭证")
+	backupRestoreCmd.Flags().Bool("skip-settings", false, "不恢复设置")
+	backupRestoreCmd.Flags().Bool("skip-keys", false, "不写入私钥文件")
+	backupRestoreCmd.Flags().Bool("dry-run", false, "只显示预览，不恢复")
+	backupRestoreCmd.Flags().BoolP("yes", "y", false, "不询问直接恢复")
+	for _, c := range []*cobra.Command{backupCreateCmd, backupRestoreCmd} {
+		c.Flags().String("passphrase-file", "", "从文件读取备份密码（第一行）")
+		backupCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(backupCmd)
+}
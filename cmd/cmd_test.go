@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"log/slog"
 	"os"
 	"strings"
 	"testing"
@@ -323,6 +325,22 @@ func TestPreprocessCredentialFlag(t *testing.T) {
 		// 验证参数保持不变
 		assert.Contains(t, os.Args, "-o=mycred")
 	})
+
+	t.Run("不处理远程命令中的-o", func(t *testing.T) {
+		// 保存原始参数
+		originalArgs := os.Args
+		defer func() {
+			os.Args = originalArgs
+		}()
+
+		args := []string{"gotssh", "run", "web1", "--", "grep", "-o", "error", "app.log"}
+		os.Args = append([]string(nil), args...)
+
+		preprocessCredentialFlag()
+
+		// 验证参数保持不变
+		assert.Equal(t, args, os.Args)
+	})
 }
 
 // TestCommandFlags 测试命令标志
@@ -349,6 +367,17 @@ func TestCommandFlags(t *testing.T) {
 	})
 }
 
+// TestRecordFlags 测试会话录制参数只注册在启动交互式Shell的命令上
+func TestRecordFlags(t *testing.T) {
+	for _, cmd := range []*cobra.Command{rootCmd, connectCmd, connectWithCredentialCmd, recentCmd} {
+		assert.NotNil(t, cmd.Flags().Lookup("record"), cmd.Name())
+		assert.NotNil(t, cmd.Flags().Lookup("record-input"), cmd.Name())
+	}
+	assert.Nil(t, rootCmd.PersistentFlags().Lookup("record"))
+	assert.Nil(t, runCmd.Flags().Lookup("record"))
+	assert.Nil(t, backupCreateCmd.InheritedFlags().Lookup("record"))
+}
+
 // TestConfigManagerInitialization 测试配置管理器初始化
 func TestConfigManagerInitialization(t *testing.T) {
 	defer teardownTest()
@@ -433,6 +462,30 @@ func TestCommandUsage(t *testing.T) {
 	}
 }
 
+// TestNewLoggerFallback 测试配置中的日志设置无效时使用默认值并给出警告
+func TestNewLoggerFallback(t *testing.T) {
+	cmd := &cobra.Command{Use: "doctor"}
+	cmd.Flags().String("log-file", "", "")
+	cmd.Flags().String("log-format", "", "")
+	cmd.Flags().CountP("verbose", "v", "")
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+
+	settings := &config.Settings{LogLevel: "loud", LogFormat: "xml"}
+	logger, closer, err := newLogger(cmd, settings)
+	require.NoError(t, err)
+	defer closer.Close()
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelInfo))
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelDebug))
+	assert.Contains(t, stderr.String(), "loud")
+	assert.Contains(t, stderr.String(), "xml")
+
+	// 命令行指定的格式无效时仍然报错
+	require.NoError(t, cmd.Flags().Set("log-format", "xml"))
+	_, _, err = newLogger(cmd, &config.Settings{})
+	assert.Error(t, err)
+}
+
 // BenchmarkParseServerQuery 性能测试
 func BenchmarkParseServerQuery(b *testing.B) {
 	queries := []string{
@@ -447,3 +500,20 @@ func BenchmarkParseServerQuery(b *testing.B) {
 		parseServerQuery(query)
 	}
 }
+
+// TestFindRunServerMultipleMatches 测试 run 命令在非终端中匹配多个服务器时不弹出选择列表
+func TestFindRunServerMultipleMatches(t *testing.T) {
+	manager, err := config.NewManager(t.TempDir() + "/config.yaml")
+	require.NoError(t, err)
+	saved := configManager
+	configManager = manager
+	defer func() { configManager = saved }()
+
+	require.NoError(t, manager.AddServer(&config.ServerConfig{Host: "10.0.0.1", Port: 22, User: "root", AuthType: "password"}))
+	require.NoError(t, manager.AddServer(&config.ServerConfig{Host: "10.0.0.1", Port: 22, User: "admin", AuthType: "password"}))
+
+	// go test 运行时标准输入不是终端
+	_, err = findRunServer("10.0.0.1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "找到 2 个匹配")
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gotssh/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// configCmd 配置文件管理命令
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "查看、检查和修复配置文件",
+	Long: `查看、检查和修复配置文件。
+
+生效的配置按优先级从低到高合并以下各层：
+  1. 内置默认值
+  2. 系统配置 /etc/gotssh/config.yaml（只读）
+  3. 用户配置 ~/.config/gotssh/config.yaml
+  4. 项目配置：从当前目录逐级向上查找的 .gotssh.yaml
+  5. 环境变量 GOTSSH_<设置名>，如 GOTSSH_LOG_LEVEL、GOTSSH_DEFAULT_PORT
+服务器、凭证等条目按ID整体覆盖，设置按字段覆盖。修改过的条目写回其来源的配置文件，
+来自系统配置的条目修改后写入用户配置；新增的条目和修改的设置写入用户配置。
+使用 --config 或环境变量 GOTSSH_CONFIG 指定配置文件时只使用该文件。
+
+配置文件在加载和保存时都会校验，端口越界、未知的认证类型或代理类型等错误会拒绝加载，
+并指出所在的行和列；失效的凭证或服务器引用、重复的别名等问题只给出提示，
+可以使用 config doctor 查看和修复。`,
+}
+
+// configDoctorCmd 检查配置文件命令
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "检查配置文件中的问题并可自动修复",
+	Long: `检查配置文件中的问题，包括：
+- 端口越界、未知的认证类型、代理类型和转发类型
+- 引用了不存在的凭证、服务器或跳板机
+- 重复的服务器、端口转发和凭证别名
+- 不可读的密钥文件
+- 其他用户可以读取的配置文件和密钥文件
+
+使用 --fix 自动修复可以修复的问题（清除失效的引用、删除指向不存在服务器的端口转发、
+为重复的别名追加序号、将文件权限改为 0600）。
+
+示例：
+  gotssh config doctor
+  gotssh config doctor --fix`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fix, _ := cmd.Flags().GetBool("fix")
+
+		fmt.Printf("检查配置文件 %s\n", configManager.ConfigPath())
+		problems := configManager.Diagnose()
+		if len(problems) == 0 {
+			fmt.Println("✅ 没有发现问题")
+			return nil
+		}
+
+		errorCount, fixable := 0, 0
+		for _, p := range problems {
+			fmt.Println(formatProblem(p))
+			if p.Severity == config.SeverityError {
+				errorCount++
+			}
+			if p.Fixable() {
+				fixable++
+			}
+		}
+		fmt.Printf("\n共 %d 个错误，%d 个警告，其中 %d 个可以自动修复\n", errorCount, len(problems)-errorCount, fixable)
+
+		if !fix {
+			if fixable > 0 {
+				fmt.Println("运行 gotssh config doctor --fix 自动修复")
+			}
+			if errorCount > 0 {
+				return fmt.Errorf("配置文件存在 %d 个错误", errorCount)
+			}
+			return nil
+		}
+
+		fixed, err := configManager.Fix(problems)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ 已修复 %d 个问题\n", fixed)
+
+		remaining := config.Errors(configManager.Diagnose())
+		if len(remaining) > 0 {
+			return fmt.Errorf("仍有 %d 个错误需要手动修改配置文件", len(remaining))
+		}
+		return nil
+	},
+}
+
+// configShowCmd 查看生效配置命令
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "显示合并各配置层后生效的配置",
+	Long: `显示合并各配置层后生效的配置，密码等敏感字段默认隐藏。
+
+使用 --origin 在每个条目和设置后注明其来源（默认值、系统配置、用户配置、项目配置或环境变量）。
+
+示例：
+  gotssh config show
+  gotssh config show --origin
+  gotssh --config ./test.yaml config show`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		origin, _ := cmd.Flags().GetBool("origin")
+		secrets, _ := cmd.Flags().GetBool("show-secrets")
+
+		if origin {
+			fmt.Println("# 配置层（优先级从低到高）:")
+			for _, l := range configManager.Layers() {
+				status := ""
+				if l.Path != "" {
+					if _, err := os.Stat(l.Path); err != nil {
+						status = "，不存在"
+					}
+					if l.ReadOnly {
+						status += "，只读"
+					}
+				}
+				fmt.Printf("#   %s%s\n", l, status)
+			}
+		}
+
+		data, err := configManager.Show(config.ShowOptions{Origin: origin, Secrets: secrets})
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	},
+}
+
+// formatProblem 问题的显示形式，标出严重程度和修复方式
+func formatProblem(p config.Problem) string {
+	mark := "⚠"
+	if p.Severity == config.SeverityError {
+		mark = "✗"
+	}
+	line := fmt.Sprintf("%s %s", mark, p.String())
+	if p.File != "" && p.File != configManager.ConfigPath() {
+		line += fmt.Sprintf(" [%s]", p.File)
+	}
+	if p.Fixable() {
+		line += fmt.Sprintf(" (可修复: %s)", p.Fix)
+	}
+	return line
+}
+
+func init() {
+	// config 子命令使用不因校验错误拒绝加载的配置管理器，以便检查和修复有问题的配置
+	configCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return initManagers(cmd, config.NewLenientManager)
+	}
+
+	configDoctorCmd.Flags().Bool("fix", false, "自动修复可以修复的问题")
+
+	configShowCmd.Flags().Bool("origin", false, "注明每个条目和设置的来源")
+	configShowCmd.Flags().Bool("show-secrets", false, "显示密码等敏感字段")
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configDoctorCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -8,6 +8,7 @@ import (
 
 	"gotssh/internal/config"
 	"gotssh/internal/ssh"
+	"gotssh/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -70,11 +71,14 @@ var connectCmd = &cobra.Command{
 这个命令等同于使用 -a 参数。
 
 参数：
-  server    服务器IP地址或别名
+  server    服务器IP地址或别名，tag:标签 按标签查找
+
+匹配到多个服务器时显示可搜索的选择列表。
 
 示例：
   gotssh connect 192.168.1.100
   gotssh connect myserver
+  gotssh connect tag:prod
   gotssh -a 192.168.1.100
   gotssh -a myserver`,
 	Aliases: []string{"a"},
@@ -112,57 +116,22 @@ var connectCmd = &cobra.Command{
 				server = servers[0]
 			} else {
 				// 多个匹配结果，让用户选择
-				fmt.Printf("找到 %d 个匹配的服务器：\n", len(servers))
-				for i, s := range servers {
-					fmt.Printf("%d. ", i+1)
-					if s.Alias != "" {
-						fmt.Printf("[%s] ", s.Alias)
-					}
-					fmt.Printf("%s@%s:%d", s.User, s.Host, s.Port)
-					if s.Description != "" {
-						fmt.Printf(" - %s", s.Description)
-					}
-					fmt.Println()
-				}
-
-				var choice int
-				fmt.Print("请选择服务器 (1-" + fmt.Sprintf("%d", len(servers)) + "): ")
-				if _, err := fmt.Scanf("%d", &choice); err != nil {
-					return fmt.Errorf("读取选择失败: %w", err)
-				}
-
-				if choice < 1 || choice > len(servers) {
-					return fmt.Errorf("无效的选择")
+				server, err = pickServer(servers)
+				if err != nil {
+					return err
 				}
-
-				server = servers[choice-1]
 			}
 		}
 
-		// 显示连接信息
+		// 显示连接信息（应用分组默认值后）
+		server = configManager.ResolveServer(server)
 		fmt.Printf("正在连接到 %s@%s:%d", server.User, server.Host, server.Port)
 		if server.Alias != "" {
 			fmt.Printf(" [%s]", server.Alias)
 		}
 		fmt.Println()
 
-		// 创建SSH客户端
-		client := ssh.NewClient(server, configManager)
-
-		// 连接到服务器
-		if err := client.Connect(); err != nil {
-			return fmt.Errorf("连接失败: %w", err)
-		}
-		defer client.Close()
-
-		fmt.Println("✅ 连接成功！正在启动Shell...")
-
-		// 启动交互式Shell
-		if err := client.Shell(); err != nil {
-			return fmt.Errorf("Shell启动失败: %w", err)
-		}
-
-		return nil
+		return startShell(cmd, server)
 	},
 }
 
@@ -227,33 +196,14 @@ var connectWithCredentialCmd = &cobra.Command{
 				server = servers[0]
 			} else {
 				// 多个匹配结果，让用户选择
-				fmt.Printf("找到 %d 个匹配的服务器：\n", len(servers))
-				for i, s := range servers {
-					fmt.Printf("%d. ", i+1)
-					if s.Alias != "" {
-						fmt.Printf("[%s] ", s.Alias)
-					}
-					fmt.Printf("%s@%s:%d", s.User, s.Host, s.Port)
-					if s.Description != "" {
-						fmt.Printf(" - %s", s.Description)
-					}
-					fmt.Println()
-				}
-
-				var choice int
-				fmt.Print("请选择服务器 (1-" + fmt.Sprintf("%d", len(servers)) + "): ")
-				if _, err := fmt.Scanf("%d", &choice); err != nil {
-					return fmt.Errorf("读取选择失败: %w", err)
-				}
-
-				if choice < 1 || choice > len(servers) {
-					return fmt.Errorf("无效的选择")
+				server, err = pickServer(servers)
+				if err != nil {
+					return err
 				}
-
-				server = servers[choice-1]
 			}
 
 			// 修改服务器配置以使用指定的凭证
+			server = configManager.ResolveServer(server)
 			server.AuthType = config.AuthTypeCredential
 			server.CredentialID = credential.ID
 
@@ -269,27 +219,52 @@ var connectWithCredentialCmd = &cobra.Command{
 			fmt.Println()
 		}
 
-		// 创建SSH客户端
-		client := ssh.NewClient(server, configManager)
+		return startShell(cmd, server)
+	},
+}
 
-		// 连接到服务器
-		if err := client.Connect(); err != nil {
-			return fmt.Errorf("连接失败: %w", err)
-		}
-		defer client.Close()
+// pickServer 查询匹配多个服务器时让用户从按常用程度排序的列表中选择
+func pickServer(servers []*config.ServerConfig) (*config.ServerConfig, error) {
+	label := fmt.Sprintf("找到 %d 个匹配的服务器", len(servers))
+	return ui.PickServer(label, servers, ui.LastConnectedNote(configManager.LastConnected()))
+}
 
-		fmt.Println("✅ 连接成功！正在启动Shell...")
+// startShell 连接服务器并启动交互式Shell，会话结束后记录连接历史
+func startShell(cmd *cobra.Command, server *config.ServerConfig) error {
+	// 创建SSH客户端
+	client := ssh.NewClient(server, configManager)
+	client.SetRecording(recordFlags(cmd))
 
-		// 启动交互式Shell
-		if err := client.Shell(); err != nil {
-			return fmt.Errorf("Shell启动失败: %w", err)
-		}
+	// 连接到服务器
+	if err := client.Connect(); err != nil {
+		return fmt.Errorf("连接失败: %w", err)
+	}
+	defer client.Close()
 
-		return nil
-	},
+	fmt.Println("✅ 连接成功！正在启动Shell...")
+
+	// 启动交互式Shell
+	if err := client.Shell(); err != nil {
+		return fmt.Errorf("Shell启动失败: %w", err)
+	}
+
+	return nil
+}
+
+// recordFlags 读取会话录制参数
+func recordFlags(cmd *cobra.Command) (record, recordInput bool) {
+	record, _ = cmd.Flags().GetBool("record")
+	recordInput, _ = cmd.Flags().GetBool("record-input")
+	return record || recordInput, recordInput
 }
 
 func init() {
 	// 添加-a标志
 	rootCmd.Flags().StringP("connect", "a", "", "连接到服务器 (IP或别名)")
+
+	// 会话录制参数，只对启动交互式Shell的 -a、connect、connect-with-credential 和 recent 命令生效
+	for _, c := range []*cobra.Command{rootCmd, connectCmd, connectWithCredentialCmd, recentCmd} {
+		c.Flags().Bool("record", false, "录制本次交互式会话（asciicast 格式）")
+		c.Flags().Bool("record-input", false, "录制会话时同时记录键盘输入（可能包含密码等敏感内容）")
+	}
 }
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gotssh/internal/config"
+	"gotssh/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// importCmd 从其他工具导入服务器命令
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "从其他工具导入服务器",
+	Long: `从其他工具的清单或会话导入服务器，导入前显示预览。
+
+支持 Ansible 清单、PuTTY 注册表导出文件、MobaXterm 会话文件和 Termius 等客户端导出的 JSON。
+
+与已有服务器地址（user@host:port）相同的服务器会被跳过，别名已被使用时追加序号。
+私钥和身份复用已有的相同凭证，没有时自动创建凭证；跳板机匹配已保存或本次导入的服务器，
+找不到时自动创建；会话中的端口转发随服务器一起导入。`,
+}
+
+// importAnsibleCmd 导入 Ansible 清单命令
+var importAnsibleCmd = &cobra.Command{
+	Use:   "ansible <清单文件>",
+	Short: "导入 Ansible 清单",
+	Long: `导入 Ansible 清单中的主机，支持 INI 和 YAML 静态清单以及 ansible-inventory --list 的 JSON 输出。
+
+读取的主机变量：
+  ansible_host、ansible_port、ansible_user   地址、端口和用户名
+  ansible_ssh_private_key_file               私钥，创建或复用密钥凭证
+  ansible_ssh_common_args                    其中的 -J 或 -o ProxyJump=... 作为跳板机
+
+主机所属的分组（包括上级分组）作为标签，如 web-1 属于 prod:children 中的 web 分组，
+标签为 prod 和 web。
+
+示例：
+  gotssh import ansible hosts.ini --dry-run
+  gotssh import ansible inventory.yml --group prod --tag ansible
+  ansible-inventory -i aws_ec2.yml --list > hosts.json && gotssh import ansible hosts.json -y`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importFile(cmd, args[0], func(data []byte) ([]*config.ImportEntry, error) {
+			hosts, err := config.ParseAnsibleInventory(data)
+			if err != nil {
+				return nil, err
+			}
+			return config.AnsibleImportEntries(hosts), nil
+		})
+	},
+}
+
+// importPuTTYCmd 导入 PuTTY 会话命令
+var importPuTTYCmd = &cobra.Command{
+	Use:   "putty <reg文件>",
+	Short: "导入 PuTTY 会话",
+	Long: `导入 PuTTY 保存的会话，会话先在 Windows 上导出为注册表文件：
+  reg export HKCU\Software\SimonTatham\PuTTY\Sessions putty.reg
+
+读取主机、端口、用户名、私钥（PublicKeyFile）、代理和端口转发（L/R）：
+- SSH 代理作为跳板机，SOCKS5 和 HTTP 代理作为服务器的代理，其他代理被忽略
+- PuTTY 格式的私钥 (.ppk) 需要用 puttygen 转换为 OpenSSH 格式
+- 动态转发（D）和非 SSH 会话不导入
+
+示例：
+  gotssh import putty putty.reg --dry-run
+  gotssh import putty putty.reg --group putty`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importFile(cmd, args[0], config.ParsePuTTYSessions)
+	},
+}
+
+// importMobaXtermCmd 导入 MobaXterm 会话命令
+var importMobaXtermCmd = &cobra.Command{
+	Use:     "mobaxterm <mxtsessions文件>",
+	Aliases: []string{"moba"},
+	Short:   "导入 MobaXterm 会话",
+	Long: `导入 MobaXterm 导出的会话文件（在会话列表中右键 "Export all sessions to file"）。
+
+读取 SSH 会话的主机、端口、用户名、私钥和 SSH 网关，书签文件夹作为服务器分组，
+SSH 网关作为跳板机，私钥路径中的 _ProfileDir_ 替换为 ~。非 SSH 会话不导入。
+
+示例：
+  gotssh import mobaxterm sessions.mxtsessions --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importFile(cmd, args[0], config.ParseMobaXtermSessions)
+	},
+}
+
+// importTermiusCmd 导入 Termius 等客户端导出的 JSON 命令
+var importTermiusCmd = &cobra.Command{
+	Use:     "termius <json文件>",
+	Aliases: []string{"json"},
+	Short:   "导入 Termius 或其他客户端导出的 JSON",
+	Long: `导入 Termius 或其他客户端导出的 JSON，顶层为主机数组，或包含 hosts、identities、groups 的对象：
+  {
+    "identities": [{"label": "deploy", "username": "deploy", "key": "~/.ssh/deploy"}],
+    "hosts": [{
+      "label": "web-1", "address": "10.0.0.1", "port": 22, "identity": "deploy",
+      "group": "prod/web", "tags": ["web"], "jump_host": "bastion",
+      "port_forwarding": [{"type": "local", "bind_port": 8080, "host": "127.0.0.1", "port": 80}]
+    }]
+  }
+
+身份作为凭证导入，主机上的 password 作为密码认证，key 为私钥路径或内容。
+
+示例：
+  gotssh import termius termius.json --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importFile(cmd, args[0], config.ParseTermiusJSON)
+	},
+}
+
+// importFile 读取并解析文件后导入
+func importFile(cmd *cobra.Command, path string, parse func([]byte) ([]*config.ImportEntry, error)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	entries, err := parse(data)
+	if err != nil {
+		return err
+	}
+	return runImport(cmd, entries)
+}
+
+// runImport 预览、确认并导入服务器
+func runImport(cmd *cobra.Command, entries []*config.ImportEntry) error {
+	group, _ := cmd.Flags().GetString("group")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	if len(entries) == 0 {
+		fmt.Println("没有可以导入的服务器")
+		return nil
+	}
+	configManager.PreviewImport(entries, config.ImportOptions{Group: group, Tags: tags})
+	fmt.Print(ui.FormatImportPreview(entries))
+	if dryRun {
+		return nil
+	}
+
+	if !yes {
+		ok, err := confirm("确定导入以上服务器吗？")
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	result, err := configManager.Import(entries)
+	if err != nil {
+		return fmt.Errorf("导入服务器失败: %w", err)
+	}
+	for _, cred := range result.Credentials {
+		fmt.Printf("🔑 已创建密钥凭证 %s (%s)\n", cred.Alias, cred.KeyPath)
+	}
+	for _, server := range result.JumpHosts {
+		fmt.Printf("🔀 已创建跳板机 %s\n", ui.ServerLabel(server))
+	}
+	if len(result.Forwards) > 0 {
+		fmt.Printf("🔗 已导入 %d 个端口转发\n", len(result.Forwards))
+	}
+	fmt.Printf("✅ 已导入 %d 台服务器", len(result.Added))
+	if result.Skipped > 0 {
+		fmt.Printf("，跳过 %d 台", result.Skipped)
+	}
+	fmt.Println()
+	return nil
+}
+
+// addImportFlags 添加导入命令的通用参数
+func addImportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("group", "", "导入到的服务器分组，来源中的文件夹作为其下的子分组")
+	cmd.Flags().StringSlice("tag", nil, "附加到每台服务器的标签，可多次指定")
+	cmd.Flags().Bool("dry-run", false, "只显示预览，不导入")
+	cmd.Flags().BoolP("yes", "y", false, "不询问直接导入")
+}
+
+func init() {
+	for _, c := range []*cobra.Command{importAnsibleCmd, importPuTTYCmd, importMobaXtermCmd, importTermiusCmd} {
+		addImportFlags(c)
+		importCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(importCmd)
+}
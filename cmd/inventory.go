@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"gotssh/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// inventoryCmd 共享清单管理命令
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "管理团队共享清单和动态清单",
+	Long: `管理团队共享的服务器清单。
+
+共享清单是只读的配置文件（通常放在团队的 git 仓库中），其中的服务器、端口转发、
+服务器分组和模板与个人配置合并使用。清单中的服务器通过 credential 按别名引用凭证，
+凭证从个人配置中查找，每个成员使用自己的凭证；清单中的凭证和设置会被忽略。
+
+冲突规则：
+- 个人配置中相同ID的条目覆盖共享清单中的条目，修改共享清单中的条目时保存到个人配置
+- 共享清单中的条目不能删除
+- 多个共享清单定义了相同ID的条目时，后列出的清单优先，并给出警告
+
+共享清单在 settings.inventories 中列出，也可以使用 inventory add 添加。
+
+动态清单（inventory_providers）运行本地命令或读取文件，得到 Ansible 清单格式
+（ansible-inventory --list 的 JSON 输出，或 YAML、INI 静态清单）的主机列表，在启动时生成服务器：
+  inventory_providers:
+    aws:
+      command: ansible-inventory -i aws_ec2.yml --list
+      ttl: 300          # 命令输出缓存5分钟
+      credential: ops   # 按别名引用个人配置中的凭证
+动态清单中的服务器以 Ansible 分组为标签，ID 为 "清单名:主机名"，不保存到配置文件，不能编辑和删除。`,
+}
+
+// inventoryListCmd 列出共享清单命令
+var inventoryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出共享清单",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		layers := configManager.InventoryLayers()
+		dynamic := configManager.DynamicLayers()
+		if len(layers) == 0 && len(dynamic) == 0 {
+			fmt.Println("暂无共享清单，使用 gotssh inventory add <文件> 添加")
+			return nil
+		}
+
+		if len(dynamic) > 0 {
+			fmt.Println("\n=== 动态清单 ===")
+			printDynamicLayers(dynamic)
+		}
+		if len(layers) == 0 {
+			fmt.Println()
+			return nil
+		}
+
+		fmt.Println("\n=== 共享清单 ===")
+		for i, l := range layers {
+			servers, overridden := 0, 0
+			for _, server := range configManager.ListServers() {
+				if origin := configManager.Origin("servers", server.ID); origin == l {
+					servers++
+				} else if origin != nil && origin.Kind != config.LayerInventory && l.Defines("servers", server.ID) {
+					overridden++
+				}
+			}
+			fmt.Printf("%d. %s", i+1, l.Path)
+			if !l.Exists() {
+				fmt.Print(" [不存在]")
+			} else {
+				fmt.Printf(" (%d 台服务器", servers)
+				if overridden > 0 {
+					fmt.Printf("，%d 台被个人配置覆盖", overridden)
+				}
+				fmt.Print(")")
+			}
+			fmt.Println()
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// inventorySyncCmd 同步共享清单命令
+var inventorySyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "重新读取共享清单并报告变化",
+	Long: `重新读取所有共享清单，与上次同步时比较，列出新增、删除和地址变化的服务器。
+
+通常在拉取团队仓库的更新之后运行：
+  git -C ~/team-inventory pull && gotssh inventory sync`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return syncInventories()
+	},
+}
+
+// inventoryAddCmd 添加共享清单命令
+var inventoryAddCmd = &cobra.Command{
+	Use:   "add <文件>",
+	Short: "添加共享清单",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("解析路径失败: %w", err)
+		}
+		if err := configManager.AddInventory(path); err != nil {
+			return err
+		}
+		fmt.Printf("✅ 已添加共享清单 %s\n", path)
+		return syncInventories()
+	},
+}
+
+// inventoryRemoveCmd 移除共享清单命令
+var inventoryRemoveCmd = &cobra.Command{
+	Use:     "remove <文件>",
+	Aliases: []string{"rm"},
+	Short:   "移除共享清单（不删除文件）",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("解析路径失败: %w", err)
+		}
+		if err := configManager.RemoveInventory(path); err != nil {
+			return err
+		}
+		fmt.Printf("✅ 已移除共享清单 %s\n", path)
+		return nil
+	},
+}
+
+// inventoryRefreshCmd 刷新动态清单命令
+var inventoryRefreshCmd = &cobra.Command{
+	Use:   "refresh [名称...]",
+	Short: "忽略缓存重新获取动态清单",
+	Long: `忽略缓存，重新运行动态清单的命令或读取清单文件，不指定名称时刷新全部动态清单。
+命令失败时继续使用上次缓存的结果。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		layers, err := configManager.RefreshInventoryProviders(args...)
+		if err != nil {
+			return err
+		}
+		if len(layers) == 0 {
+			fmt.Println("暂无动态清单")
+			return nil
+		}
+		printDynamicLayers(layers)
+		return nil
+	},
+}
+
+// printDynamicLayers 输出动态清单的服务器数量、获取时间和获取失败的原因
+func printDynamicLayers(layers []*config.Layer) {
+	for i, l := range layers {
+		servers := 0
+		for _, server := range configManager.ListServers() {
+			if configManager.Origin("servers", server.ID) == l {
+				servers++
+			}
+		}
+		fmt.Printf("%d. %s (%d 台服务器", i+1, l.Name, servers)
+		if fetched := l.FetchedAt(); !fetched.IsZero() {
+			fmt.Printf("，获取于 %s", fetched.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Println(")")
+		for _, p := range l.Problems() {
+			fmt.Printf("   ⚠ %s\n", p.Message)
+		}
+	}
+}
+
+// syncInventories 同步共享清单并输出变化
+func syncInventories() error {
+	results, err := configManager.SyncInventories()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("暂无共享清单")
+		return nil
+	}
+
+	for _, result := range results {
+		if !result.Exists {
+			fmt.Printf("⚠ %s 不存在\n", result.Path)
+			continue
+		}
+		fmt.Printf("%s: %d 台服务器", result.Path, result.Servers)
+		if len(result.Added)+len(result.Removed)+len(result.Changed) == 0 {
+			fmt.Println("，没有变化")
+			continue
+		}
+		fmt.Printf("，新增 %d，删除 %d，变化 %d\n", len(result.Added), len(result.Removed), len(result.Changed))
+		for _, s := range result.Added {
+			fmt.Printf("  + %s\n", s)
+		}
+		for _, s := range result.Removed {
+			fmt.Printf("  - %s\n", s)
+		}
+		for _, s := range result.Changed {
+			fmt.Printf("  ~ %s\n", s)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	inventoryCmd.AddCommand(inventoryListCmd)
+	inventoryCmd.AddCommand(inventorySyncCmd)
+	inventoryCmd.AddCommand(inventoryAddCmd)
+	inventoryCmd.AddCommand(inventoryRemoveCmd)
+	inventoryCmd.AddCommand(inventoryRefreshCmd)
+	rootCmd.AddCommand(inventoryCmd)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gotssh/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// recentCmd 最近连接命令
+var recentCmd = &cobra.Command{
+	Use:   "recent [n]",
+	Short: "查看最近的连接并快速重连",
+	Long: `显示最近的交互式连接历史，包括连接时间、时长、退出码和使用的凭证。
+
+指定序号时直接重新连接该记录对应的服务器。
+
+参数：
+  n    连接历史中的序号
+
+示例：
+  gotssh recent            # 最近 20 次连接
+  gotssh recent -n 50      # 最近 50 次连接
+  gotssh recent 1          # 重新连接最近一次连接的服务器
+  gotssh recent --top      # 按常用程度列出服务器`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("lines")
+		top, _ := cmd.Flags().GetBool("top")
+
+		if top {
+			return showTopServers(limit)
+		}
+
+		entries, err := configManager.ConnectionHistory(0)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("暂无连接历史")
+			return nil
+		}
+
+		if len(args) == 1 {
+			index, err := strconv.Atoi(args[0])
+			if err != nil || index < 1 || index > len(entries) {
+				return fmt.Errorf("无效的序号: %s (共 %d 条连接历史)", args[0], len(entries))
+			}
+			return reconnect(cmd, entries[index-1])
+		}
+
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+
+		fmt.Println("\n=== 最近连接 ===")
+		now := time.Now()
+		for i, entry := range entries {
+			fmt.Printf("%d. [%s] %s", i+1, entry.Server, entry.Address())
+			fmt.Printf(" %s (%s)", entry.Start.Format("2006-01-02 15:04:05"), formatAgo(now.Sub(entry.Start)))
+			fmt.Printf(" 时长 %s", entry.Duration.Round(time.Second))
+			switch {
+			case entry.ExitStatus == -1:
+				fmt.Printf(" [异常: %s]", entry.Error)
+			case entry.ExitStatus != 0:
+				fmt.Printf(" [退出码: %d]", entry.ExitStatus)
+			}
+			if entry.Credential != "" {
+				fmt.Printf(" [凭证: %s]", entry.Credential)
+			}
+			fmt.Println()
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// showTopServers 按常用程度列出已保存的服务器
+func showTopServers(limit int) error {
+	servers := configManager.ListServers()
+	if len(servers) == 0 {
+		fmt.Println("暂无服务器配置")
+		return nil
+	}
+	configManager.SortServersByFrecency(servers)
+	lastConnected := configManager.LastConnected()
+
+	if limit > 0 && len(servers) > limit {
+		servers = servers[:limit]
+	}
+
+	fmt.Println("\n=== 常用服务器 ===")
+	now := time.Now()
+	for i, server := range servers {
+		server = configManager.ResolveServer(server)
+		fmt.Printf("%d. ", i+1)
+		if server.Alias != "" {
+			fmt.Printf("[%s] ", server.Alias)
+		}
+		fmt.Printf("%s@%s:%d", server.User, server.Host, server.Port)
+		if last, ok := lastConnected[server.ID]; ok {
+			fmt.Printf(" (最近: %s)", formatAgo(now.Sub(last)))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	return nil
+}
+
+// reconnect 根据连接历史重新连接服务器，沿用当时使用的凭证
+// 已删除的服务器按地址直接连接
+func reconnect(cmd *cobra.Command, entry config.HistoryEntry) error {
+	var server config.ServerConfig
+	if saved, err := configManager.GetServer(entry.ServerID); err == nil {
+		server = *configManager.ResolveServer(saved)
+	} else {
+		fmt.Printf("服务器配置已不存在，尝试直接连接 %s...\n", entry.Address())
+		server = config.ServerConfig{
+			ID:       entry.ServerID,
+			Host:     entry.Host,
+			Port:     entry.Port,
+			User:     entry.User,
+			AuthType: config.AuthTypeAsk,
+		}
+	}
+
+	if entry.Credential != "" {
+		credential, err := configManager.GetCredentialByAlias(entry.Credential)
+		if err != nil {
+			credential, err = configManager.GetCredential(entry.Credential)
+		}
+		if err == nil && credential.ID != server.CredentialID {
+			server.AuthType = config.AuthTypeCredential
+			server.CredentialID = credential.ID
+			if credential.Username != "" {
+				server.User = credential.Username
+			}
+		}
+	}
+
+	fmt.Printf("正在连接到 %s@%s:%d", server.User, server.Host, server.Port)
+	if server.Alias != "" {
+		fmt.Printf(" [%s]", server.Alias)
+	}
+	fmt.Println()
+
+	return startShell(cmd, &server)
+}
+
+// formatAgo 以“多久之前”的形式显示时间间隔
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "刚刚"
+	case d < time.Hour:
+		return fmt.Sprintf("%d分钟前", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d小时前", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d天前", int(d.Hours()/24))
+	}
+}
+
+func init() {
+	recentCmd.Flags().IntP("lines", "n", 20, "显示的记录条数，0表示全部")
+	recentCmd.Flags().Bool("top", false, "按常用程度列出服务器")
+	rootCmd.AddCommand(recentCmd)
+}
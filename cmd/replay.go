@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gotssh/internal/recording"
+
+	"github.com/spf13/cobra"
+)
+
+// replayCmd 回放会话录制命令
+var replayCmd = &cobra.Command{
+	Use:   "replay [file]",
+	Short: "回放录制的交互式会话",
+	Long: `按录制时的节奏回放 asciicast v2 格式的会话录制。
+
+参数：
+  file    录制文件路径、录制目录中的文件名，或 gotssh sessions ls 中的序号
+
+示例：
+  gotssh replay 1
+  gotssh replay web01-20240102-150405.cast --speed 2
+  gotssh replay ~/session.cast --idle-limit 1
+
+录制文件也可以使用 asciinema play 回放。按 Ctrl+C 停止回放。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		speed, _ := cmd.Flags().GetFloat64("speed")
+		idleLimit, _ := cmd.Flags().GetFloat64("idle-limit")
+		if speed <= 0 {
+			return fmt.Errorf("回放速度必须大于0")
+		}
+
+		path, err := recording.Resolve(configManager.ConfigDir(), args[0])
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("打开录制文件失败: %w", err)
+		}
+		defer f.Close()
+
+		reader, err := recording.NewReader(f)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = recording.Play(ctx, reader, os.Stdout, recording.PlayOptions{
+			Speed:     speed,
+			IdleLimit: time.Duration(idleLimit * float64(time.Second)),
+		})
+		// 恢复终端属性，录制内容可能切换了备用屏幕或隐藏了光标
+		fmt.Print("\x1b[0m\x1b[?25h")
+		if err == context.Canceled {
+			fmt.Println("\n回放已停止")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("回放失败: %w", err)
+		}
+		fmt.Println("\n回放结束")
+		return nil
+	},
+}
+
+func init() {
+	replayCmd.Flags().Float64P("speed", "s", 1, "回放速度倍数")
+	replayCmd.Flags().Float64P("idle-limit", "i", 0, "两次输出之间的最长等待秒数，0表示不限制")
+	rootCmd.AddCommand(replayCmd)
+}
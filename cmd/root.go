@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 
 	"gotssh/internal/config"
 	"gotssh/internal/forward"
+	"gotssh/internal/logging"
+	"gotssh/internal/ssh"
 
 	"github.com/spf13/cobra"
 )
@@ -13,13 +18,18 @@ import (
 var (
 	configManager  *config.Manager
 	forwardManager *forward.Manager
+	logCloser      io.Closer
 )
 
-// preprocessCredentialFlag 预处理 -o 参数，支持 -o value 和 -o=value 两种形式
+// preprocessCredentialFlag 预处理 -o 参数，支持 -o value 和 -o=value 两种形式。
+// -- 和 run 命令之后的参数属于远程命令，不做处理
 func preprocessCredentialFlag() {
 	// 检查命令行参数中是否有 -o 后跟着值的情况
 	args := os.Args[1:]
 	for i := 0; i < len(args)-1; i++ {
+		if args[i] == "--" || args[i] == runCmd.Name() {
+			break
+		}
 		if args[i] == "-o" && i+1 < len(args) && args[i+1] != "" && args[i+1][0] != '-' {
 			// 将 -o value 转换为 -o=value
 			os.Args[i+1] = "-o=" + args[i+1]
@@ -50,18 +60,16 @@ var rootCmd = &cobra.Command{
   gotssh -a server1 -o mycred  # 使用指定凭证连接到服务器
   gotssh -a 192.168.1.100 -o mycred  # 使用凭证直接连接IP地址
   gotssh -t                    # 管理端口转发
-  gotssh --at tunnel1          # 启动别名为tunnel1的端口转发`,
+  gotssh --at tunnel1          # 启动别名为tunnel1的端口转发
+  gotssh --at @devstack        # 启动名为devstack的转发组
+  gotssh -vv -a server1        # 输出SSH握手跟踪日志（类似 ssh -vvv）`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// 初始化配置管理器
-		var err error
-		configManager, err = config.NewManager("")
-		if err != nil {
-			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		return initManagers(cmd, config.NewManager)
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if logCloser != nil {
+			return logCloser.Close()
 		}
-
-		// 初始化端口转发管理器
-		forwardManager = forward.NewManager(configManager)
-
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -111,13 +119,104 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// initManagers 初始化配置管理器、日志和端口转发管理器
+func initManagers(cmd *cobra.Command, newConfigManager func(string) (*config.Manager, error)) error {
+	// 初始化配置管理器，未指定配置文件时合并系统、用户、项目配置和环境变量
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath == "" {
+		configPath = os.Getenv(config.EnvPrefix + "CONFIG")
+	}
+	var err error
+	configManager, err = newConfigManager(configPath)
+	if err != nil {
+		return fmt.Errorf("初始化配置管理器失败: %w", err)
+	}
+
+	// 初始化日志，配置管理器创建的SSH客户端和端口转发管理器沿用该日志记录器
+	logger, closer, err := newLogger(cmd, configManager.GetConfig().Settings)
+	if err != nil {
+		return fmt.Errorf("初始化日志失败: %w", err)
+	}
+	logCloser = closer
+	slog.SetDefault(logger)
+	configManager.SetLogger(logger)
+
+	if from, backupPath := configManager.Migrated(); from != 0 {
+		logger.Info(fmt.Sprintf("配置文件已从版本 %d 升级到 %d，原配置备份在 %s", from, config.CurrentConfigVersion, backupPath))
+	}
+
+	// 加载时发现的引用失效等问题不影响使用，只提示运行 config doctor
+	if problems := configManager.Problems(); len(problems) > 0 && !isConfigCommand(cmd) {
+		logger.Warn(fmt.Sprintf("配置存在 %d 个问题，运行 gotssh config doctor 查看和修复", len(problems)))
+		for _, p := range problems {
+			logger.Debug("配置问题", "problem", p.String())
+		}
+	}
+
+	// 初始化端口转发管理器
+	forwardManager = forward.NewManager(configManager)
+
+	return nil
+}
+
+// isConfigCommand 是否为 config 的子命令，这些命令自行报告配置问题
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "config" && c.HasParent() && !c.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+// newLogger 根据配置和命令行参数创建日志记录器，命令行参数优先
+func newLogger(cmd *cobra.Command, settings *config.Settings) (*slog.Logger, io.Closer, error) {
+	var opts logging.Options
+	if settings != nil {
+		opts = logging.Options{
+			Level:  settings.LogLevel,
+			Format: settings.LogFormat,
+			File:   settings.LogFile,
+		}
+	}
+
+	// 配置中的日志级别和格式无效时使用默认值，以免所有命令（包括 config doctor）都无法运行
+	if _, err := logging.ParseLevel(opts.Level); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  %v，使用 info，运行 gotssh config doctor 修复\n", err)
+		opts.Level = ""
+	}
+	if _, err := logging.ParseFormat(opts.Format); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  %v，使用 text，运行 gotssh config doctor 修复\n", err)
+		opts.Format = ""
+	}
+
+	if cmd.Flags().Changed("log-file") {
+		opts.File, _ = cmd.Flags().GetString("log-file")
+	}
+	if cmd.Flags().Changed("log-format") {
+		opts.Format, _ = cmd.Flags().GetString("log-format")
+	}
+	opts.Verbose, _ = cmd.Flags().GetCount("verbose")
+
+	return logging.New(opts)
+}
+
 // Execute 执行根命令
 func Execute() {
 	// 预处理参数：将 -o value 转换为 -o=value 以支持两种语法
 	preprocessCredentialFlag()
 
 	if err := rootCmd.Execute(); err != nil {
+		// run 命令以远程命令的退出码退出
+		var exitErr *ssh.CommandExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Status)
+		}
 		fmt.Fprintf(os.Stderr, "执行命令失败: %v\n", err)
+		var runErr *runError
+		if errors.As(err, &runErr) {
+			os.Exit(runErrorStatus)
+		}
 		os.Exit(1)
 	}
 }
@@ -130,4 +229,10 @@ func init() {
 	rootCmd.AddCommand(tunnelCmd)
 	rootCmd.AddCommand(tunnelConnectCmd)
 	rootCmd.AddCommand(credentialCmd)
+
+	// 配置文件和日志参数对所有子命令生效
+	rootCmd.PersistentFlags().String("config", "", "只使用指定的配置文件（默认合并系统、用户、项目配置，也可用环境变量 GOTSSH_CONFIG 指定）")
+	rootCmd.PersistentFlags().CountP("verbose", "v", "输出更详细的日志（-v 调试，-vv 包含SSH握手跟踪）")
+	rootCmd.PersistentFlags().String("log-file", "", "将日志写入指定文件而不是标准错误")
+	rootCmd.PersistentFlags().String("log-format", "", "日志格式: text 或 json")
 }
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gotssh/internal/config"
+	"gotssh/internal/ssh"
+	"gotssh/internal/ui"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// runCmd 在服务器上执行命令
+var runCmd = &cobra.Command{
+	Use:   "run <服务器> [--] <命令>...",
+	Short: "在服务器上执行命令",
+	Long: `在服务器上执行命令，标准输入、输出和错误分别连接到本地，远程命令的退出码作为 gotssh 的退出码。
+
+服务器为已保存服务器的IP地址或别名，找不到时按 [user@]host[:port] 直接连接。
+服务器之后的参数都作为远程命令，以空格连接后交给远程 Shell 执行；命令中的参数可以用 -- 与 gotssh 的参数分开。
+
+- 远程命令被信号终止时退出码为 128 加信号值，连接失败等本地错误的退出码为 255
+- Ctrl-C、SIGTERM、SIGHUP 转发到远程命令（需要服务器支持，OpenSSH 7.9 及以上），
+  服务器不响应时连续按三次 Ctrl-C 断开
+- -t 分配伪终端，用于 top、vim 等交互式程序，此时远程的标准错误合并到标准输出
+- -e 通过 SSH 设置环境变量，服务器需要在 sshd_config 的 AcceptEnv 中允许
+
+示例：
+  gotssh run web1 -- uptime
+  gotssh run web1 -- tail -f /var/log/syslog | grep error
+  tar czf - ./dist | gotssh run web1 -- tar xzf - -C /srv/app
+  gotssh run -t web1 -- htop
+  gotssh run -e LANG=C.UTF-8 -e DEPLOY_ENV=prod web1 -- ./deploy.sh`,
+	Args:          cobra.MinimumNArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pty, _ := cmd.Flags().GetBool("tty")
+		env, _ := cmd.Flags().GetStringArray("env")
+
+		command := args[1:]
+		if command[0] == "--" {
+			command = command[1:]
+		}
+		if len(command) == 0 {
+			return &runError{fmt.Errorf("未指定要执行的命令")}
+		}
+
+		server, err := findRunServer(args[0])
+		if err != nil {
+			return &runError{err}
+		}
+		client := ssh.NewClient(server, configManager)
+		if err := client.Connect(); err != nil {
+			return &runError{fmt.Errorf("连接失败: %w", err)}
+		}
+		defer client.Close()
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(signals)
+
+		err = client.Exec(strings.Join(command, " "), ssh.ExecOptions{
+			Stdin:   os.Stdin,
+			Stdout:  os.Stdout,
+			Stderr:  os.Stderr,
+			Env:     env,
+			PTY:     pty,
+			Signals: signals,
+		})
+		if _, ok := err.(*ssh.CommandExitError); err != nil && !ok {
+			return &runError{err}
+		}
+		return err
+	},
+}
+
+// runError run 命令在远程命令开始前后的本地错误，退出码为 255，与 ssh 一致
+type runError struct {
+	err error
+}
+
+func (e *runError) Error() string { return e.err.Error() }
+
+func (e *runError) Unwrap() error { return e.err }
+
+// runErrorStatus run 命令本地错误的退出码
+const runErrorStatus = 255
+
+// findRunServer 按IP地址或别名查找已保存的服务器，找不到时按 [user@]host[:port] 直接连接。
+// 提示信息和选择列表输出到标准错误，不影响远程命令的输出；匹配多个服务器且不在终端中运行时返回错误
+func findRunServer(query string) (*config.ServerConfig, error) {
+	servers, err := configManager.FindServer(query)
+	if err != nil {
+		user, host, port, parseErr := parseServerQuery(query)
+		if parseErr != nil {
+			return nil, fmt.Errorf("解析服务器地址失败: %w", parseErr)
+		}
+		fmt.Fprintf(os.Stderr, "未找到保存的服务器 '%s'，使用交互式认证连接到 %s@%s:%d\n", query, user, host, port)
+		return &config.ServerConfig{
+			ID:       "temp-" + fmt.Sprintf("%d", time.Now().Unix()),
+			Host:     host,
+			Port:     port,
+			User:     user,
+			AuthType: config.AuthTypeAsk,
+		}, nil
+	}
+	if len(servers) == 1 {
+		return servers[0], nil
+	}
+	// 输入或输出重定向时无法交互选择，选择列表也不能混入命令输出
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("找到 %d 个匹配 '%s' 的服务器，请使用别名或完整的IP地址指定一个", len(servers), query)
+	}
+	label := fmt.Sprintf("找到 %d 个匹配的服务器", len(servers))
+	return ui.PickServerTo(os.Stderr, label, servers, ui.LastConnectedNote(configManager.LastConnected()))
+}
+
+func init() {
+	runCmd.Flags().SetInterspersed(false)
+	runCmd.Flags().BoolP("tty", "t", false, "分配伪终端，用于交互式程序")
+	runCmd.Flags().StringArrayP("env", "e", nil, "设置远程命令的环境变量 KEY=VALUE，可多次指定")
+	rootCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+
+	"gotssh/internal/ui"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+// serverCmd 服务器批量管理命令
+var serverCmd = &cobra.Command{
+	Use:   "server",
+	Short: "按模板批量管理服务器",
+	Long: `按服务器模板批量添加和删除服务器。
+
+服务器模板定义在配置文件的 server_templates 中，主机模式可以包含范围，
+如 node-[01-40].internal，或使用 {n} 并在添加时通过 --range 指定范围。`,
+}
+
+// serverAddCmd 按模板批量添加服务器命令
+var serverAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "按模板批量添加服务器",
+	Long: `展开服务器模板并批量添加服务器，添加前显示预览。
+
+与已有服务器重复的主机会被跳过。
+
+示例：
+  gotssh server add --template k8s --range 01-40
+  gotssh server add --template k8s --range 1-3,7 --dry-run
+  gotssh server add --template k8s --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("template")
+		rangeSpec, _ := cmd.Flags().GetString("range")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		previews, err := configManager.PreviewServerTemplate(name, rangeSpec)
+		if err != nil {
+			return err
+		}
+		fmt.Print(ui.FormatTemplatePreview(previews))
+		if dryRun {
+			return nil
+		}
+
+		if !yes {
+			ok, err := confirm("确定添加以上服务器吗？")
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		added, skipped, err := configManager.AddServersFromTemplate(name, rangeSpec)
+		if err != nil {
+			return fmt.Errorf("批量添加服务器失败: %w", err)
+		}
+		fmt.Printf("✅ 已添加 %d 台服务器", added)
+		if skipped > 0 {
+			fmt.Printf("，跳过 %d 台", skipped)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// serverDeleteCmd 按模板批量删除服务器命令
+var serverDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "删除由模板创建的所有服务器",
+	Long: `删除由指定模板创建的所有服务器及其端口转发，模板本身保留。
+
+示例：
+  gotssh server delete --template k8s`,
+	Aliases: []string{"rm"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("template")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if _, err := configManager.GetServerTemplate(name); err != nil {
+			return err
+		}
+		servers := configManager.ServersByTemplate(name)
+		if len(servers) == 0 {
+			fmt.Printf("没有由模板 %s 创建的服务器\n", name)
+			return nil
+		}
+
+		fmt.Printf("\n=== 将删除 %d 台服务器 ===\n", len(servers))
+		for _, server := range servers {
+			fmt.Printf("  - %s\n", ui.ServerLabel(server))
+		}
+		if !yes {
+			ok, err := confirm("确定删除以上服务器及其端口转发吗？")
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		deleted, err := configManager.DeleteServersByTemplate(name)
+		if err != nil {
+			return fmt.Errorf("批量删除服务器失败: %w", err)
+		}
+		fmt.Printf("✅ 已删除 %d 台服务器\n", deleted)
+		return nil
+	},
+}
+
+// serverTemplatesCmd 列出服务器模板命令
+var serverTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "列出服务器模板",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates := configManager.ListServerTemplates()
+		if len(templates) == 0 {
+			fmt.Println("暂无服务器模板，请在配置文件的 server_templates 中添加")
+			return nil
+		}
+
+		fmt.Println("\n=== 服务器模板 ===")
+		for i, t := range templates {
+			fmt.Printf("%d. %s (已创建 %d 台)\n", i+1, ui.TemplateLabel(t), len(configManager.ServersByTemplate(t.Name)))
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// confirm 询问用户是否继续
+func confirm(label string) (bool, error) {
+	prompt := promptui.Select{
+		Label: label,
+		Items: []string{"是", "否"},
+	}
+	_, result, err := prompt.Run()
+	if err != nil {
+		return false, err
+	}
+	return result == "是", nil
+}
+
+func init() {
+	serverAddCmd.Flags().String("template", "", "服务器模板名称")
+	serverAddCmd.Flags().String("range", "", "范围，如 01-40 或 1-3,7（默认使用模板主机模式中的范围）")
+	serverAddCmd.Flags().Bool("dry-run", false, "只显示预览，不添加")
+	serverAddCmd.Flags().BoolP("yes", "y", false, "不询问直接添加")
+	serverAddCmd.MarkFlagRequired("template")
+
+	serverDeleteCmd.Flags().String("template", "", "服务器模板名称")
+	serverDeleteCmd.Flags().BoolP("yes", "y", false, "不询问直接删除")
+	serverDeleteCmd.MarkFlagRequired("template")
+
+	serverCmd.AddCommand(serverAddCmd)
+	serverCmd.AddCommand(serverDeleteCmd)
+	serverCmd.AddCommand(serverTemplatesCmd)
+	rootCmd.AddCommand(serverCmd)
+}
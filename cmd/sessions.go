@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gotssh/internal/recording"
+
+	"github.com/spf13/cobra"
+)
+
+// sessionsCmd 会话录制管理命令
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "管理交互式会话录制",
+	Long: `管理交互式会话录制。
+
+在服务器配置中启用会话录制，或连接时使用 --record 参数，
+交互式会话会以 asciicast v2 格式保存在配置目录的 recordings 子目录中。`,
+}
+
+// sessionsLsCmd 列出会话录制命令
+var sessionsLsCmd = &cobra.Command{
+	Use:   "ls [server]",
+	Short: "列出会话录制",
+	Long: `列出会话录制及对应的服务器，按时间从新到旧排列。
+
+参数：
+  server    只显示指定服务器（别名、主机或服务器ID）的录制
+
+示例：
+  gotssh sessions ls
+  gotssh sessions ls web01
+  gotssh replay 1          # 回放列表中的第1个录制`,
+	Aliases: []string{"list"},
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		infos, err := recording.List(configManager.ConfigDir())
+		if err != nil {
+			return err
+		}
+		if len(infos) == 0 {
+			fmt.Println("暂无会话录制")
+			return nil
+		}
+
+		fmt.Println("\n=== 会话录制 ===")
+		shown := 0
+		for i, info := range infos {
+			if len(args) > 0 && !matchRecording(info, args[0]) {
+				continue
+			}
+			shown++
+
+			// 序号与 replay 使用的序号一致
+			fmt.Printf("%d. [%s] %s@%s:%d", i+1, info.Server, info.User, info.Host, info.Port)
+			fmt.Printf(" %s", info.Start.Format("2006-01-02 15:04:05"))
+			if info.Missing {
+				fmt.Print(" [文件已删除]")
+			} else {
+				fmt.Printf(" 时长 %s, %s", info.Duration.Round(time.Second), formatSize(info.Size))
+			}
+			if info.RecordInput {
+				fmt.Print(" [含键盘输入]")
+			}
+			fmt.Printf("\n   %s\n", info.Path)
+		}
+		if shown == 0 {
+			fmt.Printf("没有服务器 '%s' 的会话录制\n", args[0])
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// matchRecording 检查录制是否属于指定的服务器
+func matchRecording(info *recording.Info, query string) bool {
+	return info.ServerID == query || strings.EqualFold(info.Server, query) || info.Host == query
+}
+
+// formatSize 格式化文件大小
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/1024/1024)
+	case size >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+func init() {
+	sessionsCmd.AddCommand(sessionsLsCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
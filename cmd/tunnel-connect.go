@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -15,11 +16,12 @@ var tunnelConnectCmd = &cobra.Command{
 这个命令等同于使用 -at 参数。
 
 参数：
-  alias    端口转发配置的别名
+  alias    端口转发配置的别名，以 @ 开头时表示转发组名称
 
 示例：
   gotssh tunnel-connect mysql-tunnel
   gotssh -at mysql-tunnel
+  gotssh --at @devstack
 
 端口转发将在前台运行，使用 Ctrl+C 停止。`,
 	Aliases: []string{"at"},
@@ -27,6 +29,11 @@ var tunnelConnectCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		alias := args[0]
 
+		// 以 @ 开头表示启动转发组
+		if strings.HasPrefix(alias, "@") {
+			return runForwardGroup(strings.TrimPrefix(alias, "@"))
+		}
+
 		// 根据别名获取端口转发配置
 		pf, err := configManager.GetPortForwardByAlias(alias)
 		if err != nil {
@@ -38,6 +45,7 @@ var tunnelConnectCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("获取服务器配置失败: %w", err)
 		}
+		server = configManager.ResolveServer(server)
 
 		// 显示端口转发信息
 		fmt.Printf("🚀 正在启动端口转发: [%s]\n", alias)
@@ -47,8 +55,7 @@ var tunnelConnectCmd = &cobra.Command{
 		}
 		fmt.Println()
 
-		fmt.Printf("转发配置: %s:%d -> %s:%d (%s)\n",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort, pf.Type)
+		fmt.Printf("转发配置: %s (%s)\n", pf.Summary(), pf.Type)
 
 		if pf.Description != "" {
 			fmt.Printf("描述: %s\n", pf.Description)
@@ -68,5 +75,5 @@ var tunnelConnectCmd = &cobra.Command{
 
 func init() {
 	// 添加--at标志（不能使用短标志，因为at是两个字符）
-	rootCmd.Flags().String("at", "", "根据别名快速启动端口转发")
+	rootCmd.Flags().String("at", "", "根据别名快速启动端口转发（@组名 启动转发组）")
 }
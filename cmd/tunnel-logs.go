@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gotssh/internal/forward"
+
+	"github.com/spf13/cobra"
+)
+
+// tunnelLogsCmd 查看端口转发访问日志命令
+var tunnelLogsCmd = &cobra.Command{
+	Use:   "logs [alias]",
+	Short: "查看端口转发的访问日志",
+	Long: `查看端口转发的访问日志，每条记录包含连接时间、来源地址、目标地址、
+持续时间、传输字节数和关闭原因。
+
+访问日志需要在端口转发配置中启用（access_log: true），
+日志以JSON行格式保存在配置目录的 logs 子目录中，超过10MB时自动轮转。
+
+参数：
+  alias    端口转发配置的别名
+
+示例：
+  gotssh tunnel logs mysql-tunnel
+  gotssh tunnel logs mysql-tunnel --follow
+  gotssh tunnel logs mysql-tunnel -n 100 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		follow, _ := cmd.Flags().GetBool("follow")
+		lines, _ := cmd.Flags().GetInt("lines")
+		raw, _ := cmd.Flags().GetBool("json")
+
+		pf, err := configManager.GetPortForwardByAlias(args[0])
+		if err != nil {
+			return fmt.Errorf("获取端口转发配置失败: %w", err)
+		}
+		if !pf.AccessLog {
+			fmt.Printf("⚠️  端口转发 '%s' 未启用访问日志\n", args[0])
+		}
+
+		path := forward.AccessLogPath(configManager.ConfigDir(), pf.ID)
+		show := func(entry forward.AccessLogEntry) {
+			if raw {
+				data, _ := json.Marshal(entry)
+				fmt.Println(string(data))
+				return
+			}
+			fmt.Println(entry)
+		}
+
+		entries, offset, err := forward.ReadAccessLog(path, lines)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("读取访问日志失败: %w", err)
+		}
+		if os.IsNotExist(err) && !follow {
+			fmt.Println("暂无访问日志")
+			return nil
+		}
+		for _, entry := range entries {
+			show(entry)
+		}
+
+		if !follow {
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return forward.FollowAccessLog(ctx, path, offset, 500*time.Millisecond, show)
+	},
+}
+
+func init() {
+	tunnelLogsCmd.Flags().BoolP("follow", "f", false, "持续输出新的访问记录")
+	tunnelLogsCmd.Flags().IntP("lines", "n", 20, "显示最后的记录条数，0表示全部")
+	tunnelLogsCmd.Flags().Bool("json", false, "以原始JSON行格式输出")
+	tunnelCmd.AddCommand(tunnelLogsCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// tunnelUpCmd 启动转发组命令
+var tunnelUpCmd = &cobra.Command{
+	Use:   "up [group]",
+	Short: "启动转发组中的所有端口转发",
+	Long: `根据名称启动转发组，组内的端口转发会并发启动。
+
+如果转发组配置了依赖关系，会先启动被依赖的端口转发，
+待其运行后再启动依赖它们的端口转发。
+
+参数：
+  group    转发组名称
+
+示例：
+  gotssh tunnel up devstack
+  gotssh --at @devstack
+
+转发组将在前台运行，使用 Ctrl+C 停止组内所有端口转发。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runForwardGroup(args[0])
+	},
+}
+
+// runForwardGroup 显示转发组信息并在前台启动
+func runForwardGroup(name string) error {
+	group, err := configManager.GetForwardGroupByName(name)
+	if err != nil {
+		return fmt.Errorf("获取转发组配置失败: %w", err)
+	}
+
+	fmt.Printf("🚀 正在启动转发组: [%s] (%d 个端口转发)\n", group.Name, len(group.Forwards))
+	if group.Description != "" {
+		fmt.Printf("描述: %s\n", group.Description)
+	}
+	fmt.Println("使用 Ctrl+C 停止所有端口转发")
+	fmt.Println("----------------------------------------")
+
+	if err := forwardManager.StartForwardGroupWithSignalHandler(group.Name); err != nil {
+		return fmt.Errorf("启动转发组失败: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	tunnelCmd.AddCommand(tunnelUpCmd)
+}
@@ -0,0 +1,529 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ansible 清单中的特殊分组
+const (
+	ansibleAll       = "all"       // 包含所有主机的分组
+	ansibleUngrouped = "ungrouped" // 不属于其他分组的主机
+	ansibleMeta      = "_meta"     // ansible-inventory --list 输出中的主机变量
+)
+
+// AnsibleHost Ansible 清单中的一台主机
+type AnsibleHost struct {
+	Name   string                 // 清单中的主机名（inventory_hostname）
+	Vars   map[string]interface{} // 合并所属分组变量后的主机变量，主机变量优先
+	Groups []string               // 所属分组（含上级分组），不含 all 和 ungrouped
+}
+
+// ansibleGroup 解析过程中的一个分组
+type ansibleGroup struct {
+	hosts    map[string]map[string]interface{} // 直接属于该分组的主机及其在分组中定义的变量
+	vars     map[string]interface{}
+	children []string
+}
+
+// ansibleInventory 解析过程中的清单
+type ansibleInventory struct {
+	groups   map[string]*ansibleGroup
+	hostvars map[string]map[string]interface{} // _meta.hostvars
+}
+
+// ParseAnsibleInventory 解析 Ansible 清单，支持 ansible-inventory --list 和动态清单脚本的 JSON 输出、
+// YAML 静态清单（all: {hosts: ..., children: ...}），不是 YAML/JSON 时按 INI 格式解析
+func ParseAnsibleInventory(data []byte) ([]*AnsibleHost, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		hosts, iniErr := parseAnsibleINI(data)
+		if iniErr != nil {
+			return nil, fmt.Errorf("解析 Ansible 清单失败，既不是有效的 YAML/JSON（%v），也不是有效的 INI 清单（%v）", err, iniErr)
+		}
+		return hosts, nil
+	}
+
+	inv := newAnsibleInventory()
+	for name, value := range doc {
+		if name == ansibleMeta {
+			meta, _ := value.(map[string]interface{})
+			hostvars, _ := meta["hostvars"].(map[string]interface{})
+			for host, vars := range hostvars {
+				inv.hostvars[host] = ansibleVars(vars)
+			}
+			continue
+		}
+		if err := inv.addGroup(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return inv.hosts(), nil
+}
+
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{
+		groups:   make(map[string]*ansibleGroup),
+		hostvars: make(map[string]map[string]interface{}),
+	}
+}
+
+// parseAnsibleINI 解析 INI 格式的 Ansible 清单：
+// [分组] 下每行一台主机及其变量，[分组:vars] 为分组变量，[分组:children] 为下级分组，
+// 主机名可以包含范围，如 web[01:20].example.com，第一个分组之前的主机属于 ungrouped
+func parseAnsibleINI(data []byte) ([]*AnsibleHost, error) {
+	inv := newAnsibleInventory()
+	group, kind := ansibleUngrouped, "hosts"
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && !strings.Contains(line, " ") {
+			name := line[1 : len(line)-1]
+			group, kind = name, "hosts"
+			if base, suffix, ok := strings.Cut(name, ":"); ok {
+				if suffix != "vars" && suffix != "children" {
+					return nil, fmt.Errorf("第 %d 行: 未知的分组类型 '%s'（可选 vars、children）", i+1, suffix)
+				}
+				group, kind = base, suffix
+			}
+			inv.group(group)
+			continue
+		}
+
+		switch kind {
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("第 %d 行: 分组变量应为 key=value 的形式", i+1)
+			}
+			inv.group(group).vars[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		case "children":
+			inv.group(group).children = append(inv.group(group).children, line)
+			inv.group(line)
+		default:
+			fields := splitShellArgs(line)
+			vars := make(map[string]interface{})
+			for _, field := range fields[1:] {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					return nil, fmt.Errorf("第 %d 行: 无效的主机变量 '%s'，应为 key=value 的形式", i+1, field)
+				}
+				vars[key] = value
+			}
+			pattern := fields[0]
+			if host, port, ok := strings.Cut(pattern, ":"); ok && !strings.Contains(port, ":") {
+				if _, err := strconv.Atoi(port); err == nil {
+					pattern = host
+					vars["ansible_port"] = port
+				}
+			}
+			names, err := expandAnsibleHosts(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 行: %w", i+1, err)
+			}
+			for _, name := range names {
+				inv.group(group).addHost(name, vars)
+			}
+		}
+	}
+	return inv.hosts(), nil
+}
+
+// expandAnsibleHosts 展开主机名中的范围，如 web[01:03] -> web01 web02 web03，
+// 也支持字母范围 [a:c] 和步长 [1:10:2]
+func expandAnsibleHosts(pattern string) ([]string, error) {
+	start := strings.Index(pattern, "[")
+	if start < 0 {
+		return []string{pattern}, nil
+	}
+	end := strings.Index(pattern[start:], "]")
+	if end < 0 {
+		return nil, fmt.Errorf("主机范围 '%s' 缺少 ]", pattern)
+	}
+	end += start
+	prefix, spec, rest := pattern[:start], pattern[start+1:end], pattern[end+1:]
+
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("无效的主机范围 '[%s]'", spec)
+	}
+	step := 1
+	if len(parts) == 3 {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("无效的主机范围步长 '%s'", parts[2])
+		}
+		step = n
+	}
+
+	var values []string
+	from, err1 := strconv.Atoi(parts[0])
+	to, err2 := strconv.Atoi(parts[1])
+	switch {
+	case err1 == nil && err2 == nil:
+		if from > to || (to-from)/step >= maxTemplateServers {
+			return nil, fmt.Errorf("无效的主机范围 '[%s]'", spec)
+		}
+		width := 0
+		if len(parts[0]) > 1 && strings.HasPrefix(parts[0], "0") {
+			width = len(parts[0])
+		}
+		for n := from; n <= to; n += step {
+			values = append(values, fmt.Sprintf("%0*d", width, n))
+		}
+	case len(parts[0]) == 1 && len(parts[1]) == 1 && parts[0] <= parts[1]:
+		for c := parts[0][0]; c <= parts[1][0]; c += byte(step) {
+			values = append(values, string(c))
+			if int(c)+step > 255 {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("无效的主机范围 '[%s]'", spec)
+	}
+
+	var hosts []string
+	for _, value := range values {
+		expanded, err := expandAnsibleHosts(prefix + value + rest)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, expanded...)
+	}
+	return hosts, nil
+}
+
+// addGroup 记录分组定义，value 可以是主机名列表，或包含 hosts、vars、children 的映射
+func (inv *ansibleInventory) addGroup(name string, value interface{}) error {
+	group := inv.group(name)
+	switch def := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, host := range def {
+			group.addHost(fmt.Sprint(host), nil)
+		}
+		return nil
+	case map[string]interface{}:
+		switch hosts := def["hosts"].(type) {
+		case []interface{}:
+			for _, host := range hosts {
+				group.addHost(fmt.Sprint(host), nil)
+			}
+		case map[string]interface{}:
+			for host, vars := range hosts {
+				group.addHost(host, ansibleVars(vars))
+			}
+		}
+		for key, value := range ansibleVars(def["vars"]) {
+			group.vars[key] = value
+		}
+		switch children := def["children"].(type) {
+		case []interface{}:
+			for _, child := range children {
+				group.children = append(group.children, fmt.Sprint(child))
+				inv.group(fmt.Sprint(child))
+			}
+		case map[string]interface{}:
+			for child, childDef := range children {
+				group.children = append(group.children, child)
+				if err := inv.addGroup(child, childDef); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("Ansible 清单中分组 '%s' 的格式无效", name)
+}
+
+// group 获取或创建分组
+func (inv *ansibleInventory) group(name string) *ansibleGroup {
+	group := inv.groups[name]
+	if group == nil {
+		group = &ansibleGroup{
+			hosts: make(map[string]map[string]interface{}),
+			vars:  make(map[string]interface{}),
+		}
+		inv.groups[name] = group
+	}
+	return group
+}
+
+// addHost 将主机加入分组，同一主机在多处定义的变量合并
+func (g *ansibleGroup) addHost(name string, vars map[string]interface{}) {
+	merged := g.hosts[name]
+	if merged == nil {
+		merged = make(map[string]interface{})
+		g.hosts[name] = merged
+	}
+	for key, value := range vars {
+		merged[key] = value
+	}
+}
+
+// hosts 展开分组层级，得到每台主机所属的分组和合并后的变量
+// 变量优先级从低到高：all 的变量、上级分组、下级分组、分组中定义的主机变量、_meta.hostvars
+func (inv *ansibleInventory) hosts() []*AnsibleHost {
+	parents := make(map[string][]string)
+	for name, group := range inv.groups {
+		for _, child := range group.children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+	// 分组的层级，all 最低，用于按层级应用分组变量；循环引用的分组按 0 处理
+	depths := make(map[string]int)
+	var depth func(name string) int
+	depth = func(name string) int {
+		if name == ansibleAll {
+			return -1
+		}
+		if d, ok := depths[name]; ok {
+			return d
+		}
+		depths[name] = 0
+		d := 0
+		for _, parent := range parents[name] {
+			if pd := depth(parent) + 1; pd > d {
+				d = pd
+			}
+		}
+		depths[name] = d
+		return d
+	}
+
+	memberships := make(map[string]map[string]bool)
+	var join func(host, group string)
+	join = func(host, group string) {
+		if memberships[host][group] {
+			return
+		}
+		memberships[host][group] = true
+		for _, parent := range parents[group] {
+			join(host, parent)
+		}
+	}
+	for name := range inv.hostvars {
+		memberships[name] = make(map[string]bool)
+	}
+	for name, group := range inv.groups {
+		for host := range group.hosts {
+			if memberships[host] == nil {
+				memberships[host] = make(map[string]bool)
+			}
+			join(host, name)
+		}
+	}
+
+	hosts := make([]*AnsibleHost, 0, len(memberships))
+	for _, name := range sortedKeys(memberships) {
+		groups := sortedKeys(memberships[name])
+		if !memberships[name][ansibleAll] {
+			groups = append(groups, ansibleAll)
+		}
+		sort.SliceStable(groups, func(i, j int) bool {
+			return depth(groups[i]) < depth(groups[j])
+		})
+
+		host := &AnsibleHost{Name: name, Vars: make(map[string]interface{})}
+		for _, group := range groups {
+			if g := inv.groups[group]; g != nil {
+				for key, value := range g.vars {
+					host.Vars[key] = value
+				}
+			}
+			if group != ansibleAll && group != ansibleUngrouped {
+				host.Groups = append(host.Groups, group)
+			}
+		}
+		for _, group := range groups {
+			if g := inv.groups[group]; g != nil {
+				for key, value := range g.hosts[name] {
+					host.Vars[key] = value
+				}
+			}
+		}
+		for key, value := range inv.hostvars[name] {
+			host.Vars[key] = value
+		}
+		sort.Strings(host.Groups)
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// ansibleVars 将变量映射转换为 map[string]interface{}，其他类型返回空映射
+func ansibleVars(value interface{}) map[string]interface{} {
+	vars, _ := value.(map[string]interface{})
+	if vars == nil {
+		return map[string]interface{}{}
+	}
+	return vars
+}
+
+// Var 第一个已设置的变量的字符串值，如 Var("ansible_host", "ansible_ssh_host")
+func (h *AnsibleHost) Var(names ...string) string {
+	for _, name := range names {
+		if value, ok := h.Vars[name]; ok && value != nil {
+			return strings.TrimSpace(fmt.Sprint(value))
+		}
+	}
+	return ""
+}
+
+// ProxyJump ansible_ssh_common_args 或 ansible_ssh_extra_args 中指定的跳板机，
+// 识别 -o ProxyJump=...、-J ... 和 -o ProxyCommand="ssh -W %h:%p ..."，返回如 ops@bastion:2222
+func (h *AnsibleHost) ProxyJump() string {
+	for _, name := range []string{"ansible_ssh_common_args", "ansible_ssh_extra_args"} {
+		args := splitShellArgs(h.Var(name))
+		for i := 0; i < len(args); i++ {
+			var option string
+			switch arg := args[i]; {
+			case (arg == "-J" || arg == "-o") && i+1 < len(args):
+				i++
+				if arg == "-J" {
+					return args[i]
+				}
+				option = args[i]
+			case strings.HasPrefix(arg, "-J"):
+				return arg[2:]
+			case strings.HasPrefix(arg, "-o"):
+				option = arg[2:]
+			default:
+				continue
+			}
+
+			key, value, ok := strings.Cut(option, "=")
+			if !ok {
+				key, value, _ = strings.Cut(option, " ")
+			}
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "proxyjump":
+				return strings.TrimSpace(value)
+			case "proxycommand":
+				if jump := proxyCommandJump(value); jump != "" {
+					return jump
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// proxyCommandJump 从 ssh -W %h:%p [-p 端口] [-l 用户] [用户@]主机 形式的 ProxyCommand 中取出跳板机
+func proxyCommandJump(command string) string {
+	args := splitShellArgs(command)
+	if len(args) == 0 || filepath.Base(args[0]) != "ssh" || !slices.Contains(args, "-W") {
+		return ""
+	}
+	var user, port, host string
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "-") && len(arg) == 2 && strings.Contains("WpliFoJ", arg[1:]) && i+1 < len(args):
+			i++
+			switch arg {
+			case "-p":
+				port = args[i]
+			case "-l":
+				user = args[i]
+			}
+		case strings.HasPrefix(arg, "-"):
+		case host == "":
+			host = arg
+		}
+	}
+	if host == "" {
+		return ""
+	}
+	if user != "" && !strings.Contains(host, "@") {
+		host = user + "@" + host
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	return host
+}
+
+// splitShellArgs 按 shell 规则拆分参数，处理单引号、双引号和反斜杠转义
+func splitShellArgs(s string) []string {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// unquote 去掉值两端成对的引号
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// Server 由主机变量生成服务器配置（不含ID），识别 ansible_host、ansible_port、
+// ansible_user 和 ansible_ssh_private_key_file 及其 ansible_ssh_* 旧名称，分组作为标签
+func (h *AnsibleHost) Server() (*ServerConfig, error) {
+	server := &ServerConfig{
+		Alias:   h.Name,
+		Host:    h.Var("ansible_host", "ansible_ssh_host"),
+		User:    h.Var("ansible_user", "ansible_ssh_user"),
+		KeyPath: h.Var("ansible_ssh_private_key_file", "ansible_private_key_file"),
+		Tags:    append([]string(nil), h.Groups...),
+	}
+	if server.Host == "" {
+		server.Host = h.Name
+	}
+	if port := h.Var("ansible_port", "ansible_ssh_port"); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("主机 '%s' 的端口 '%s' 无效", h.Name, port)
+		}
+		server.Port = n
+	}
+	if server.KeyPath != "" {
+		server.AuthType = AuthTypeKey
+	}
+	return server, nil
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ansibleListOutput ansible-inventory --list 的输出
+const ansibleListOutput = `{
+  "_meta": {
+    "hostvars": {
+      "web-1": {"ansible_host": "10.0.0.1", "ansible_user": "ubuntu"},
+      "web-2": {"ansible_host": "10.0.0.2", "ansible_port": 2222},
+      "db-1": {"ansible_host": "10.0.1.1", "ansible_ssh_private_key_file": "~/.ssh/db"}
+    }
+  },
+  "all": {"children": ["ungrouped", "prod"]},
+  "prod": {"children": ["web", "db"], "vars": {"ansible_user": "deploy"}},
+  "web": {"hosts": ["web-1", "web-2"]},
+  "db": {"hosts": ["db-1"]},
+  "ungrouped": {"hosts": ["bastion"]}
+}`
+
+// ansibleStaticYAML YAML 静态清单
+const ansibleStaticYAML = `all:
+  vars:
+    ansible_user: admin
+  hosts:
+    bastion:
+      ansible_host: 192.168.1.1
+  children:
+    web:
+      vars:
+        ansible_port: 2200
+      hosts:
+        web-1:
+          ansible_host: 10.0.0.1
+        web-2:
+          ansible_port: 22
+`
+
+// TestParseAnsibleList 测试解析 ansible-inventory --list 的输出
+func TestParseAnsibleList(t *testing.T) {
+	hosts, err := ParseAnsibleInventory([]byte(ansibleListOutput))
+	require.NoError(t, err)
+	require.Len(t, hosts, 4)
+
+	byName := make(map[string]*AnsibleHost)
+	for _, h := range hosts {
+		byName[h.Name] = h
+	}
+	assert.Equal(t, []string{"db", "prod"}, byName["db-1"].Groups)
+	assert.Empty(t, byName["bastion"].Groups, "all 和 ungrouped 不作为分组")
+
+	// 主机变量覆盖上级分组的变量
+	server, err := byName["web-1"].Server()
+	require.NoError(t, err)
+	assert.Equal(t, "web-1", server.Alias)
+	assert.Equal(t, "10.0.0.1", server.Host)
+	assert.Equal(t, "ubuntu", server.User)
+	assert.Equal(t, 0, server.Port, "未设置端口时继承分组和全局默认值")
+	assert.Equal(t, []string{"prod", "web"}, server.Tags)
+
+	server, err = byName["web-2"].Server()
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", server.User)
+	assert.Equal(t, 2222, server.Port)
+
+	server, err = byName["db-1"].Server()
+	require.NoError(t, err)
+	assert.Equal(t, AuthTypeKey, server.AuthType)
+	assert.Equal(t, "~/.ssh/db", server.KeyPath)
+
+	server, err = byName["bastion"].Server()
+	require.NoError(t, err)
+	assert.Equal(t, "bastion", server.Host)
+}
+
+// TestParseAnsibleStaticYAML 测试解析 YAML 静态清单
+func TestParseAnsibleStaticYAML(t *testing.T) {
+	hosts, err := ParseAnsibleInventory([]byte(ansibleStaticYAML))
+	require.NoError(t, err)
+	require.Len(t, hosts, 3)
+
+	servers := make(map[string]*ServerConfig)
+	for _, h := range hosts {
+		server, err := h.Server()
+		require.NoError(t, err)
+		servers[h.Name] = server
+	}
+	assert.Equal(t, "192.168.1.1", servers["bastion"].Host)
+	assert.Equal(t, "admin", servers["bastion"].User)
+	assert.Equal(t, 0, servers["bastion"].Port)
+	assert.Equal(t, 2200, servers["web-1"].Port, "分组变量覆盖 all 的变量")
+	assert.Equal(t, 22, servers["web-2"].Port, "主机变量覆盖分组变量")
+	assert.Equal(t, []string{"web"}, servers["web-2"].Tags)
+}
+
+// TestParseAnsibleInvalid 测试无效的清单和主机变量
+func TestParseAnsibleInvalid(t *testing.T) {
+	_, err := ParseAnsibleInventory([]byte("web: 42\n"))
+	assert.Error(t, err)
+	_, err = ParseAnsibleInventory([]byte("{not json"))
+	assert.Error(t, err)
+
+	hosts, err := ParseAnsibleInventory([]byte(`{"web": ["a"], "_meta": {"hostvars": {"a": {"ansible_port": "ssh"}}}}`))
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	_, err = hosts[0].Server()
+	assert.Error(t, err)
+}
+
+// ansibleINI INI 静态清单
+const ansibleINI = `# 生产环境
+bastion.example.com ansible_user=ops
+
+[web]
+web[01:03].example.com ansible_user=deploy
+web-legacy:2222 ansible_host=10.0.0.9
+
+[db]
+db-1 ansible_host=10.0.1.1 ansible_ssh_private_key_file="~/.ssh/db key"
+
+[prod:children]
+web
+db
+
+[prod:vars]
+ansible_ssh_common_args='-o ProxyJump=ops@bastion.example.com:2222'
+`
+
+// TestParseAnsibleINI 测试解析 INI 静态清单
+func TestParseAnsibleINI(t *testing.T) {
+	hosts, err := ParseAnsibleInventory([]byte(ansibleINI))
+	require.NoError(t, err)
+	require.Len(t, hosts, 6)
+
+	byName := make(map[string]*AnsibleHost)
+	for _, h := range hosts {
+		byName[h.Name] = h
+	}
+	require.Contains(t, byName, "web02.example.com")
+	assert.Equal(t, []string{"prod", "web"}, byName["web02.example.com"].Groups)
+	assert.Empty(t, byName["bastion.example.com"].Groups)
+	assert.Empty(t, byName["bastion.example.com"].ProxyJump())
+
+	server, err := byName["web-legacy"].Server()
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.9", server.Host)
+	assert.Equal(t, 2222, server.Port, "主机名后的端口作为 ansible_port")
+
+	server, err = byName["db-1"].Server()
+	require.NoError(t, err)
+	assert.Equal(t, "~/.ssh/db key", server.KeyPath)
+	assert.Equal(t, "ops@bastion.example.com:2222", byName["db-1"].ProxyJump(), "跳板机继承自上级分组的变量")
+
+	_, err = ParseAnsibleInventory([]byte("[web:hosts]\na\n"))
+	assert.Error(t, err)
+	_, err = ParseAnsibleInventory([]byte("[web\na\n"))
+	assert.Error(t, err)
+}
+
+// TestExpandAnsibleHosts 测试展开主机名中的范围
+func TestExpandAnsibleHosts(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"web", []string{"web"}},
+		{"web[1:3]", []string{"web1", "web2", "web3"}},
+		{"web[08:10].example.com", []string{"web08.example.com", "web09.example.com", "web10.example.com"}},
+		{"db-[a:c]", []string{"db-a", "db-b", "db-c"}},
+		{"n[0:6:3]", []string{"n0", "n3", "n6"}},
+		{"r[1:2]-[a:b]", []string{"r1-a", "r1-b", "r2-a", "r2-b"}},
+	}
+	for _, tt := range tests {
+		got, err := expandAnsibleHosts(tt.pattern)
+		require.NoError(t, err, tt.pattern)
+		assert.Equal(t, tt.want, got, tt.pattern)
+	}
+
+	for _, pattern := range []string{"web[3:1]", "web[1:x]", "web[1:3"} {
+		_, err := expandAnsibleHosts(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
+
+// TestAnsibleProxyJump 测试从 SSH 参数中识别跳板机
+func TestAnsibleProxyJump(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{"-o ProxyJump=ops@bastion", "ops@bastion"},
+		{"-oProxyJump=bastion:2222 -o StrictHostKeyChecking=no", "bastion:2222"},
+		{"-J a,b", "a,b"},
+		{"-Jbastion", "bastion"},
+		{`-o ProxyCommand="ssh -W %h:%p -q ops@bastion -p 2222"`, "ops@bastion:2222"},
+		{`-o ProxyCommand="ssh -l ops -W %h:%p bastion"`, "ops@bastion"},
+		{"-o StrictHostKeyChecking=no", ""},
+	}
+	for _, tt := range tests {
+		h := &AnsibleHost{Name: "web", Vars: map[string]interface{}{"ansible_ssh_common_args": tt.args}}
+		assert.Equal(t, tt.want, h.ProxyJump(), tt.args)
+	}
+
+	h := &AnsibleHost{Name: "web", Vars: map[string]interface{}{"ansible_ssh_extra_args": "-J bastion"}}
+	assert.Equal(t, "bastion", h.ProxyJump())
+}
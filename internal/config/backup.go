@@ -0,0 +1,745 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// 备份文件格式：魔数、格式版本、scrypt 参数（log2 N、r、p）、盐、随机数，之后是 AES-256-GCM 加密的内容，
+// 密文之前的部分作为附加数据一起认证
+const (
+	backupMagic     = "GOTSSH-BACKUP"
+	backupFormat    = 1
+	backupSaltSize  = 16
+	backupScryptLog = 15 // N = 2^15
+	backupScryptR   = 8
+	backupScryptP   = 1
+	backupKeySize   = 32
+)
+
+// ErrBackupPassphrase 密码错误或备份文件被修改
+var ErrBackupPassphrase = errors.New("密码错误或备份文件已损坏")
+
+// backupDocument 备份中加密的内容
+type backupDocument struct {
+	CreatedAt time.Time    `yaml:"created_at"`
+	Hostname  string       `yaml:"hostname"`
+	Config    string       `yaml:"config"`         // 用户配置文件的内容
+	Keys      []*BackupKey `yaml:"keys,omitempty"` // 配置中引用的私钥文件
+}
+
+// BackupKey 备份的私钥文件
+type BackupKey struct {
+	Path    string `yaml:"path"` // 配置中引用的路径，如 ~/.ssh/id_rsa
+	Content string `yaml:"content"`
+}
+
+// BackupInfo 备份的概况
+type BackupInfo struct {
+	CreatedAt time.Time
+	Hostname  string
+	Counts    map[string]int // 段名 -> 条目数
+	Settings  int            // 设置了的设置字段数，不含配置目录
+	Keys      int            // 包含的私钥文件数
+	Warnings  []string       // 创建备份时的警告，如私钥文件无法读取
+}
+
+// Backup 解密后的备份
+type Backup struct {
+	BackupInfo
+	config []byte // 升级到当前版本的用户配置
+	keys   []*BackupKey
+}
+
+// CreateBackup 创建加密备份：用户配置中的条目和设置，以及凭证、服务器和模板引用的私钥文件的内容。
+// 系统配置、项目配置、共享清单和动态清单中的条目不备份
+func (m *Manager) CreateBackup(passphrase []byte) ([]byte, *BackupInfo, error) {
+	config, err := marshalLayer(m.primary.layerState)
+	if err != nil {
+		return nil, nil, err
+	}
+	hostname, _ := os.Hostname()
+	doc := backupDocument{CreatedAt: time.Now(), Hostname: hostname, Config: string(config)}
+
+	info := backupInfo(m.primary.layerState)
+	info.CreatedAt, info.Hostname = doc.CreatedAt, doc.Hostname
+	for _, path := range referencedKeys(m.primary.config) {
+		content, err := os.ReadFile(expandHome(path))
+		if err != nil {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("读取私钥文件 %s 失败，未包含在备份中: %v", path, err))
+			continue
+		}
+		doc.Keys = append(doc.Keys, &BackupKey{Path: path, Content: string(content)})
+	}
+	info.Keys = len(doc.Keys)
+
+	plaintext, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("序列化备份失败: %w", err)
+	}
+	data, err := encryptBackup(plaintext, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, info, nil
+}
+
+// OpenBackup 解密备份，备份中的配置按需升级到当前版本，由更新版本的程序创建的备份返回 ErrNewerConfigVersion
+func OpenBackup(data, passphrase []byte) (*Backup, error) {
+	plaintext, err := decryptBackup(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var doc backupDocument
+	if err := yaml.Unmarshal(plaintext, &doc); err != nil {
+		return nil, fmt.Errorf("解析备份内容失败: %w", err)
+	}
+
+	config, _, err := migrateData([]byte(doc.Config))
+	if err != nil {
+		return nil, err
+	}
+	state, err := parseLayer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backup{BackupInfo: *backupInfo(state), config: config, keys: doc.Keys}
+	b.CreatedAt, b.Hostname, b.Keys = doc.CreatedAt, doc.Hostname, len(doc.Keys)
+	return b, nil
+}
+
+// backupInfo 统计配置层中各段的条目数和设置字段数
+func backupInfo(state layerState) *BackupInfo {
+	info := &BackupInfo{Counts: make(map[string]int)}
+	for key := range state.settings {
+		if key != "config_dir" {
+			info.Settings++
+		}
+	}
+	for _, s := range configSections {
+		if n := len(s.entries(state.config).keys()); n > 0 {
+			info.Counts[s.name] = n
+		}
+	}
+	return info
+}
+
+// referencedKeys 凭证、服务器和模板引用的私钥文件，已保存密钥内容的凭证除外
+func referencedKeys(c *Config) []string {
+	var paths []string
+	add := func(path string) {
+		if path != "" && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	for _, id := range sortedKeys(c.Credentials) {
+		if cred := c.Credentials[id]; cred != nil && cred.Type == CredentialTypeKey && cred.KeyContent == "" {
+			add(cred.KeyPath)
+		}
+	}
+	for _, id := range sortedKeys(c.Servers) {
+		if server := c.Servers[id]; server != nil {
+			add(server.KeyPath)
+		}
+	}
+	for _, name := range sortedKeys(c.ServerTemplates) {
+		if t := c.ServerTemplates[name]; t != nil {
+			add(t.KeyPath)
+		}
+	}
+	return paths
+}
+
+// encryptBackup 用由密码派生的密钥加密备份内容
+func encryptBackup(plaintext, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("备份密码不能为空")
+	}
+	header := []byte(backupMagic)
+	header = append(header, backupFormat, backupScryptLog, backupScryptR, backupScryptP)
+	salt := make([]byte, backupSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+	header = append(header, salt...)
+
+	aead, err := backupCipher(passphrase, salt, backupScryptLog, backupScryptR, backupScryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+	header = append(header, nonce...)
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// decryptBackup 解密备份内容，密码错误或内容被修改时返回 ErrBackupPassphrase
+func decryptBackup(data, passphrase []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(backupMagic)) {
+		return nil, fmt.Errorf("不是 gotssh 备份文件")
+	}
+	rest := data[len(backupMagic):]
+	if len(rest) < 4 {
+		return nil, fmt.Errorf("备份文件不完整")
+	}
+	if rest[0] != backupFormat {
+		return nil, fmt.Errorf("不支持的备份格式版本 %d，请升级 gotssh", rest[0])
+	}
+	logN, r, p := rest[1], rest[2], rest[3]
+	if logN < 10 || logN > 22 || r == 0 || p == 0 {
+		return nil, fmt.Errorf("备份文件的密钥参数无效")
+	}
+	rest = rest[4:]
+	if len(rest) < backupSaltSize {
+		return nil, fmt.Errorf("备份文件不完整")
+	}
+	salt := rest[:backupSaltSize]
+
+	aead, err := backupCipher(passphrase, salt, logN, r, p)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(backupMagic) + 4 + backupSaltSize + aead.NonceSize()
+	if len(data) < headerSize+aead.Overhead() {
+		return nil, fmt.Errorf("备份文件不完整")
+	}
+	header := data[:headerSize]
+	nonce := header[headerSize-aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize:], header)
+	if err != nil {
+		return nil, ErrBackupPassphrase
+	}
+	return plaintext, nil
+}
+
+// backupCipher 由密码和盐派生 AES-256-GCM 密钥
+func backupCipher(passphrase, salt []byte, logN, r, p byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<logN, int(r), int(p), backupKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ConflictPolicy 恢复时与现有条目冲突（ID相同或服务器地址相同，内容不同）的处理方式
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // 保留现有的条目
+	ConflictOverwrite ConflictPolicy = "overwrite" // 用备份中的条目覆盖
+	ConflictRename    ConflictPolicy = "rename"    // 以新的ID和别名恢复，以名称为键的分组、模板和动态清单仍然跳过
+)
+
+// RestoreOptions 恢复选项
+type RestoreOptions struct {
+	Replace         bool           // 先删除用户配置中的条目再恢复，否则与现有配置合并
+	Conflict        ConflictPolicy // 合并时冲突的处理方式，默认跳过
+	Tags            []string       // 只恢复带有其中任一标签的服务器及其跳板机、端口转发、分组和凭证，不恢复设置
+	SkipCredentials bool           // 不恢复凭证
+	SkipSettings    bool           // 不恢复设置
+	SkipKeys        bool           // 不写入私钥文件
+}
+
+// RestoreAction 恢复计划中对一个条目的处理
+type RestoreAction string
+
+const (
+	RestoreAdd       RestoreAction = "add"       // 新增
+	RestoreOverwrite RestoreAction = "overwrite" // 覆盖现有的条目
+	RestoreRename    RestoreAction = "rename"    // 以新的ID或别名新增
+	RestoreSkip      RestoreAction = "skip"      // 跳过
+)
+
+// RestoreItem 恢复计划中的一个条目
+type RestoreItem struct {
+	Section string // servers、credentials 等
+	Key     string // 恢复后的条目ID或名称
+	Label   string // 显示名称
+	Action  RestoreAction
+	Note    string // 跳过的原因或别名的变化
+
+	value interface{}
+}
+
+// RestorePlan 恢复计划，由 PlanRestore 生成，ApplyRestore 执行
+type RestorePlan struct {
+	Items    []*RestoreItem
+	Removed  int                    // 替换模式下删除的现有条目数
+	Settings map[string]interface{} // 恢复的设置字段
+	Keys     []*BackupKey           // 将写入的私钥文件（文件不存在）
+	Notes    []string               // 其他说明，如私钥文件已存在且内容不同
+
+	removals []entryRef
+}
+
+// restorePlanner 生成恢复计划时的状态
+type restorePlanner struct {
+	m       *Manager
+	policy  ConflictPolicy
+	work    *Config                      // 现有配置加上已计划恢复的条目
+	ids     map[string]map[string]string // 段名 -> 备份中的ID -> 恢复后的ID
+	aliases map[string]map[string]string // 段名 -> 备份中的别名 -> 恢复后的别名
+	plan    *RestorePlan
+}
+
+// PlanRestore 生成恢复计划，不修改配置
+func (m *Manager) PlanRestore(b *Backup, opts RestoreOptions) (*RestorePlan, error) {
+	policy := opts.Conflict
+	if policy == "" {
+		policy = ConflictSkip
+	}
+	switch policy {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("未知的冲突处理方式 '%s'（可选 skip、overwrite、rename）", policy)
+	}
+	if opts.Replace && len(opts.Tags) > 0 {
+		return nil, fmt.Errorf("替换模式会删除现有条目，不能只恢复部分服务器")
+	}
+
+	// 每次重新解析，计划中修改的是备份条目的副本
+	state, err := parseLayer(b.config)
+	if err != nil {
+		return nil, err
+	}
+	src := state.config
+	if opts.SkipCredentials {
+		src.Credentials = make(map[string]*CredentialConfig)
+	}
+	if len(opts.Tags) > 0 {
+		src = selectByTags(src, opts.Tags)
+	}
+
+	p := &restorePlanner{
+		m:       m,
+		policy:  policy,
+		work:    NewConfig(),
+		ids:     make(map[string]map[string]string),
+		aliases: make(map[string]map[string]string),
+		plan:    &RestorePlan{},
+	}
+	for _, s := range configSections {
+		current, work := s.entries(m.config), s.entries(p.work)
+		for _, key := range current.keys() {
+			value, _ := current.get(key)
+			if opts.Replace && m.Origin(s.name, key) == m.primary && (s.name != "credentials" || !opts.SkipCredentials) {
+				p.plan.removals = append(p.plan.removals, entryRef{Section: s.name, Key: key})
+				continue
+			}
+			work.set(key, value)
+		}
+	}
+	p.plan.Removed = len(p.plan.removals)
+
+	planEntries(p, restoreSpec[*CredentialConfig]{
+		section: "credentials",
+		label:   func(c *CredentialConfig) string { return c.Alias },
+		alias:   func(c *CredentialConfig) *string { return &c.Alias },
+		setID:   func(c *CredentialConfig, id string) { c.ID = id },
+	}, src.Credentials, p.work.Credentials)
+	planEntries(p, restoreSpec[*ServerGroup]{
+		section: "server_groups",
+		label:   func(g *ServerGroup) string { return g.Path },
+	}, src.ServerGroups, p.work.ServerGroups)
+	planEntries(p, restoreSpec[*ServerTemplate]{
+		section: "server_templates",
+		label:   func(t *ServerTemplate) string { return t.Name },
+	}, src.ServerTemplates, p.work.ServerTemplates)
+	planEntries(p, restoreSpec[*ServerConfig]{
+		section: "servers",
+		label: func(s *ServerConfig) string {
+			if s.Alias != "" {
+				return s.Alias
+			}
+			return s.Host
+		},
+		alias: func(s *ServerConfig) *string { return &s.Alias },
+		setID: func(s *ServerConfig, id string) { s.ID = id },
+		match: p.matchServer,
+	}, src.Servers, p.work.Servers)
+	planEntries(p, restoreSpec[*PortForwardConfig]{
+		section: "port_forwards",
+		label:   func(pf *PortForwardConfig) string { return pf.Alias },
+		alias:   func(pf *PortForwardConfig) *string { return &pf.Alias },
+		setID:   func(pf *PortForwardConfig, id string) { pf.ID = id },
+	}, src.PortForwards, p.work.PortForwards)
+	planEntries(p, restoreSpec[*ForwardGroup]{
+		section: "forward_groups",
+		label:   func(g *ForwardGroup) string { return g.Name },
+		alias:   func(g *ForwardGroup) *string { return &g.Name },
+		setID:   func(g *ForwardGroup, id string) { g.ID = id },
+	}, src.ForwardGroups, p.work.ForwardGroups)
+	planEntries(p, restoreSpec[*InventoryProvider]{
+		section: providersSection,
+		label:   func(ip *InventoryProvider) string { return ip.Name },
+	}, src.InventoryProviders, p.work.InventoryProviders)
+	p.fixReferences()
+
+	if !opts.SkipSettings && len(opts.Tags) == 0 {
+		p.plan.Settings = make(map[string]interface{})
+		for key, value := range state.settings {
+			// 配置目录与机器有关，保存时自动设置
+			if key != "config_dir" {
+				p.plan.Settings[key] = value
+			}
+		}
+	}
+	if !opts.SkipKeys {
+		p.planKeys(b.keys)
+	}
+	return p.plan, nil
+}
+
+// restoreSpec 一段条目的恢复规则
+type restoreSpec[V any] struct {
+	section string
+	label   func(V) string
+	alias   func(V) *string        // 需要唯一的别名或名称，nil 表示没有
+	setID   func(V, string)        // 修改条目ID，nil 表示条目以名称为键，冲突时不能换新ID
+	match   func(V) (string, bool) // 按ID以外的条件查找重复的现有条目，如地址相同的服务器
+}
+
+// planEntries 为一段条目生成恢复计划，计划恢复的条目加入 current
+func planEntries[V any](p *restorePlanner, spec restoreSpec[V], backup, current map[string]V) {
+	for _, key := range sortedKeys(backup) {
+		value := backup[key]
+		item := &RestoreItem{Section: spec.section, Key: key, Label: spec.label(value), Action: RestoreAdd, value: value}
+		p.plan.Items = append(p.plan.Items, item)
+
+		existingKey := ""
+		if _, ok := current[key]; ok {
+			existingKey = key
+		} else if spec.match != nil {
+			if matched, ok := spec.match(value); ok {
+				existingKey = matched
+			}
+		}
+		if existingKey != "" {
+			if existingKey != key {
+				// 引用该条目的其他条目改为引用现有的条目
+				p.mapKey(p.ids, spec.section, key, existingKey)
+			}
+			owner := p.m.Origin(spec.section, existingKey)
+			switch {
+			case sameYAML(current[existingKey], value):
+				item.Action, item.Note = RestoreSkip, "与现有条目相同"
+			case owner != nil && owner.Kind == LayerDynamic:
+				item.Action, item.Note = RestoreSkip, fmt.Sprintf("现有条目来自%s", owner)
+			case p.policy == ConflictOverwrite && (existingKey == key || spec.setID != nil):
+				if existingKey != key {
+					spec.setID(value, existingKey)
+					item.Key = existingKey
+				}
+				item.Action = RestoreOverwrite
+			case p.policy == ConflictRename && existingKey == key && spec.setID != nil:
+				newKey := generateID()
+				for _, exists := current[newKey]; exists; _, exists = current[newKey] {
+					newKey = generateID()
+				}
+				spec.setID(value, newKey)
+				p.mapKey(p.ids, spec.section, key, newKey)
+				item.Key, item.Action = newKey, RestoreRename
+			case existingKey != key:
+				item.Action, item.Note = RestoreSkip, fmt.Sprintf("与现有条目 %s 重复", existingKey)
+			default:
+				item.Action, item.Note = RestoreSkip, "已存在且内容不同"
+			}
+		}
+		if item.Action == RestoreSkip {
+			continue
+		}
+
+		if spec.alias != nil {
+			alias := spec.alias(value)
+			taken := func(candidate string) bool {
+				for k, v := range current {
+					if k != item.Key && *spec.alias(v) == candidate {
+						return true
+					}
+				}
+				return false
+			}
+			if *alias != "" && taken(*alias) {
+				renamed := uniqueAlias(*alias, taken)
+				p.mapKey(p.aliases, spec.section, *alias, renamed)
+				item.Note = fmt.Sprintf("别名 '%s' 已被使用，改为 '%s'", *alias, renamed)
+				*alias = renamed
+				if item.Action == RestoreAdd {
+					item.Action = RestoreRename
+				}
+			}
+		}
+		current[item.Key] = value
+	}
+}
+
+// matchServer 查找地址（user@host:port）相同的现有服务器
+func (p *restorePlanner) matchServer(server *ServerConfig) (string, bool) {
+	candidate := p.m.ResolveServer(server)
+	for _, id := range sortedKeys(p.work.Servers) {
+		existing := p.m.ResolveServer(p.work.Servers[id])
+		if existing.Host == candidate.Host && existing.Port == candidate.Port && existing.User == candidate.User {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// mapKey 记录备份中的ID或别名恢复后的值
+func (p *restorePlanner) mapKey(m map[string]map[string]string, section, from, to string) {
+	if m[section] == nil {
+		m[section] = make(map[string]string)
+	}
+	m[section][from] = to
+}
+
+// ref 按恢复后的ID和别名更新对某段条目的引用（ID或别名）
+func (p *restorePlanner) ref(section, value string) string {
+	if to, ok := p.ids[section][value]; ok {
+		return to
+	}
+	if to, ok := p.aliases[section][value]; ok {
+		return to
+	}
+	return value
+}
+
+// fixReferences 恢复的条目中对凭证、服务器和端口转发的引用改为恢复后的ID和别名
+func (p *restorePlanner) fixReferences() {
+	for _, item := range p.plan.Items {
+		if item.Action == RestoreSkip {
+			continue
+		}
+		switch v := item.value.(type) {
+		case *ServerConfig:
+			v.CredentialID = p.ref("credentials", v.CredentialID)
+			v.Credential = p.ref("credentials", v.Credential)
+			v.JumpHost = p.ref("servers", v.JumpHost)
+		case *ServerGroup:
+			v.CredentialID = p.ref("credentials", v.CredentialID)
+			v.Credential = p.ref("credentials", v.Credential)
+			v.JumpHost = p.ref("servers", v.JumpHost)
+		case *ServerTemplate:
+			v.CredentialID = p.ref("credentials", v.CredentialID)
+			v.JumpHost = p.ref("servers", v.JumpHost)
+		case *PortForwardConfig:
+			v.ServerID = p.ref("servers", v.ServerID)
+		case *ForwardGroup:
+			for i, alias := range v.Forwards {
+				v.Forwards[i] = p.ref("port_forwards", alias)
+			}
+			if len(v.Dependencies) > 0 {
+				dependencies := make(map[string][]string, len(v.Dependencies))
+				for alias, deps := range v.Dependencies {
+					fixed := make([]string, len(deps))
+					for i, dep := range deps {
+						fixed[i] = p.ref("port_forwards", dep)
+					}
+					dependencies[p.ref("port_forwards", alias)] = fixed
+				}
+				v.Dependencies = dependencies
+			}
+		case *InventoryProvider:
+			v.Credential = p.ref("credentials", v.Credential)
+		}
+	}
+}
+
+// planKeys 计划写入恢复的条目引用的私钥文件：文件不存在时写入，已存在且内容不同时不覆盖，
+// 引用该文件的恢复的凭证改为使用备份中的密钥内容
+func (p *restorePlanner) planKeys(keys []*BackupKey) {
+	var used []string
+	for _, item := range p.plan.Items {
+		if item.Action == RestoreSkip {
+			continue
+		}
+		switch v := item.value.(type) {
+		case *CredentialConfig:
+			used = append(used, v.KeyPath)
+		case *ServerConfig:
+			used = append(used, v.KeyPath)
+		case *ServerTemplate:
+			used = append(used, v.KeyPath)
+		}
+	}
+
+	for _, key := range keys {
+		if !slices.Contains(used, key.Path) {
+			continue
+		}
+		existing, err := os.ReadFile(expandHome(key.Path))
+		switch {
+		case os.IsNotExist(err):
+			p.plan.Keys = append(p.plan.Keys, key)
+		case err != nil:
+			p.plan.Notes = append(p.plan.Notes, fmt.Sprintf("读取私钥文件 %s 失败: %v", key.Path, err))
+		case string(existing) != key.Content:
+			note := fmt.Sprintf("私钥文件 %s 已存在且内容不同，不覆盖", key.Path)
+			for _, item := range p.plan.Items {
+				if cred, ok := item.value.(*CredentialConfig); ok && item.Action != RestoreSkip && cred.KeyPath == key.Path {
+					cred.KeyContent = key.Content
+					note += fmt.Sprintf("，凭证 %s 使用备份中的密钥内容", cred.Alias)
+				}
+			}
+			p.plan.Notes = append(p.plan.Notes, note)
+		}
+	}
+}
+
+// selectByTags 只保留带有其中任一标签的服务器，以及它们的跳板机、端口转发、转发组、上级分组和引用的凭证
+func selectByTags(c *Config, tags []string) *Config {
+	selected := NewConfig()
+	findServer := func(ref string) *ServerConfig {
+		if server := c.Servers[ref]; server != nil {
+			return server
+		}
+		for _, server := range c.Servers {
+			if server.Alias == ref {
+				return server
+			}
+		}
+		return nil
+	}
+
+	var queue []*ServerConfig
+	for _, id := range sortedKeys(c.Servers) {
+		server := c.Servers[id]
+		if slices.ContainsFunc(server.Tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+			queue = append(queue, server)
+		}
+	}
+	for len(queue) > 0 {
+		server := queue[0]
+		queue = queue[1:]
+		if selected.Servers[server.ID] != nil {
+			continue
+		}
+		selected.Servers[server.ID] = server
+		jumps := []string{server.JumpHost}
+		for _, path := range GroupAncestors(server.Group) {
+			if group := c.ServerGroups[path]; group != nil {
+				selected.ServerGroups[path] = group
+				jumps = append(jumps, group.JumpHost)
+			}
+		}
+		for _, ref := range jumps {
+			if jump := findServer(ref); ref != "" && jump != nil {
+				queue = append(queue, jump)
+			}
+		}
+	}
+
+	var aliases []string
+	for id, pf := range c.PortForwards {
+		if selected.Servers[pf.ServerID] != nil {
+			selected.PortForwards[id] = pf
+			aliases = append(aliases, pf.Alias)
+		}
+	}
+	for id, group := range c.ForwardGroups {
+		if len(group.Forwards) > 0 && !slices.ContainsFunc(group.Forwards, func(alias string) bool { return !slices.Contains(aliases, alias) }) {
+			selected.ForwardGroups[id] = group
+		}
+	}
+
+	credentials := make(map[string]bool)
+	for _, server := range selected.Servers {
+		credentials[server.CredentialID] = true
+		credentials[server.Credential] = true
+	}
+	for _, group := range selected.ServerGroups {
+		credentials[group.CredentialID] = true
+		credentials[group.Credential] = true
+	}
+	for id, cred := range c.Credentials {
+		if credentials[id] || (cred.Alias != "" && credentials[cred.Alias]) {
+			selected.Credentials[id] = cred
+		}
+	}
+	return selected
+}
+
+// RestoreResult 恢复的结果
+type RestoreResult struct {
+	Added       int
+	Overwritten int
+	Skipped     int
+	Removed     int
+	Settings    int      // 恢复的设置字段数
+	Keys        []string // 写入的私钥文件
+	Warnings    []string // 写入私钥文件失败等
+}
+
+// ApplyRestore 执行恢复计划并保存配置，之后写入私钥文件。恢复前的用户配置保留在轮换备份中
+func (m *Manager) ApplyRestore(plan *RestorePlan) (*RestoreResult, error) {
+	result := &RestoreResult{Removed: len(plan.removals)}
+	for _, ref := range plan.removals {
+		findSection(ref.Section).entries(m.config).remove(ref.Key)
+	}
+	for _, item := range plan.Items {
+		switch item.Action {
+		case RestoreSkip:
+			result.Skipped++
+			continue
+		case RestoreOverwrite:
+			result.Overwritten++
+		default:
+			result.Added++
+		}
+		findSection(item.Section).entries(m.config).set(item.Key, item.value)
+	}
+	if len(plan.Settings) > 0 {
+		values := settingsMap(m.config.Settings)
+		for key, value := range plan.Settings {
+			values[key] = value
+		}
+		settings, err := applySettings(values)
+		if err != nil {
+			m.rebuild()
+			return nil, err
+		}
+		settings.ConfigDir = m.config.Settings.ConfigDir
+		*m.config.Settings = *settings
+		result.Settings = len(plan.Settings)
+	}
+	if err := m.Save(); err != nil {
+		m.rebuild()
+		return nil, err
+	}
+
+	for _, key := range plan.Keys {
+		path := expandHome(key.Path)
+		if _, err := os.Stat(path); err == nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("私钥文件 %s 已存在，不覆盖", key.Path))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("写入私钥文件 %s 失败: %v", key.Path, err))
+			continue
+		}
+		if err := os.WriteFile(path, []byte(key.Content), 0600); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("写入私钥文件 %s 失败: %v", key.Path, err))
+			continue
+		}
+		result.Keys = append(result.Keys, key.Path)
+	}
+	return result, nil
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backupFixture 创建包含密钥凭证、服务器、跳板机、端口转发、转发组和设置的配置
+func backupFixture(t *testing.T) (*Manager, string) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "keys", "deploy")
+	require.NoError(t, os.MkdirAll(filepath.Dir(keyPath), 0700))
+	require.NoError(t, os.WriteFile(keyPath, []byte("PRIVATE KEY"), 0600))
+
+	cred := NewCredentialConfig()
+	cred.Alias = "deploy"
+	cred.Username = "deploy"
+	cred.Type = CredentialTypeKey
+	cred.KeyPath = keyPath
+	require.NoError(t, manager.AddCredential(cred))
+
+	bastion := NewServerConfig("10.0.0.254")
+	bastion.Alias = "bastion"
+	bastion.User = "ops"
+	require.NoError(t, manager.AddServer(bastion))
+	web := NewServerConfig("10.0.0.1")
+	web.Alias = "web"
+	web.User = "deploy"
+	web.AuthType = AuthTypeCredential
+	web.CredentialID = cred.ID
+	web.JumpHost = "bastion"
+	web.Tags = []string{"prod"}
+	require.NoError(t, manager.AddServer(web))
+	dev := NewServerConfig("10.1.0.1")
+	dev.Alias = "dev"
+	dev.User = "root"
+	dev.Tags = []string{"dev"}
+	require.NoError(t, manager.AddServer(dev))
+
+	pf := NewPortForwardConfig(web.ID)
+	pf.Alias = "web-http"
+	pf.LocalPort = 8080
+	pf.RemoteHost = "localhost"
+	pf.RemotePort = 80
+	require.NoError(t, manager.AddPortForward(pf))
+	group := NewForwardGroup("web")
+	group.Forwards = []string{"web-http"}
+	require.NoError(t, manager.AddForwardGroup(group))
+
+	manager.GetConfig().Settings.DefaultUser = "admin"
+	require.NoError(t, manager.Save())
+	return manager, keyPath
+}
+
+// TestBackupEncryption 测试备份的加密、密码错误和内容被修改
+func TestBackupEncryption(t *testing.T) {
+	manager, keyPath := backupFixture(t)
+	data, info, err := manager.CreateBackup([]byte("secret"))
+	require.NoError(t, err)
+	assert.Empty(t, info.Warnings)
+	assert.Equal(t, 1, info.Keys)
+	assert.Equal(t, 3, info.Counts["servers"])
+	assert.NotContains(t, string(data), "PRIVATE KEY")
+	assert.NotContains(t, string(data), keyPath)
+
+	_, err = OpenBackup(data, []byte("wrong"))
+	assert.ErrorIs(t, err, ErrBackupPassphrase)
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = OpenBackup(tampered, []byte("secret"))
+	assert.ErrorIs(t, err, ErrBackupPassphrase)
+	_, err = OpenBackup([]byte("servers: {}"), []byte("secret"))
+	assert.Error(t, err)
+
+	backup, err := OpenBackup(data, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, info.Counts, backup.Counts)
+	assert.Equal(t, 1, backup.Settings)
+	assert.Equal(t, 1, backup.Keys)
+
+	// 无法读取的私钥文件给出警告
+	require.NoError(t, os.Remove(keyPath))
+	_, info, err = manager.CreateBackup([]byte("secret"))
+	require.NoError(t, err)
+	assert.Len(t, info.Warnings, 1)
+	assert.Equal(t, 0, info.Keys)
+}
+
+// TestRestoreToNewMachine 测试恢复到空配置：条目、设置和私钥文件
+func TestRestoreToNewMachine(t *testing.T) {
+	source, keyPath := backupFixture(t)
+	data, _, err := source.CreateBackup([]byte("secret"))
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(filepath.Dir(keyPath)))
+
+	path := createTempConfigFile(t)
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	backup, err := OpenBackup(data, []byte("secret"))
+	require.NoError(t, err)
+	plan, err := manager.PlanRestore(backup, RestoreOptions{})
+	require.NoError(t, err)
+	assert.Len(t, plan.Items, 6)
+	assert.Len(t, plan.Keys, 1)
+	assert.Equal(t, map[string]interface{}{"default_user": "admin"}, plan.Settings)
+
+	result, err := manager.ApplyRestore(plan)
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.Added)
+	assert.Equal(t, []string{keyPath}, result.Keys)
+	content, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, "PRIVATE KEY", string(content))
+	if info, err := os.Stat(keyPath); assert.NoError(t, err) && filepath.Separator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	reloaded, err := NewManager(path)
+	require.NoError(t, err)
+	assert.Len(t, reloaded.ListServers(), 3)
+	assert.Equal(t, "admin", reloaded.GetConfig().Settings.DefaultUser)
+	web, err := reloaded.GetServerByAlias("web")
+	require.NoError(t, err)
+	cred, err := reloaded.GetCredential(web.CredentialID)
+	require.NoError(t, err)
+	assert.Equal(t, keyPath, cred.KeyPath)
+	group, err := reloaded.GetForwardGroupByName("web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-http"}, group.Forwards)
+}
+
+// TestRestoreConflicts 测试合并时的冲突处理和引用更新
+func TestRestoreConflicts(t *testing.T) {
+	source, _ := backupFixture(t)
+	data, _, err := source.CreateBackup([]byte("secret"))
+	require.NoError(t, err)
+	backup, err := OpenBackup(data, []byte("secret"))
+	require.NoError(t, err)
+	sourceWeb, err := source.GetServerByAlias("web")
+	require.NoError(t, err)
+
+	newTarget := func(t *testing.T) *Manager {
+		manager, err := NewManager(createTempConfigFile(t))
+		require.NoError(t, err)
+		// 与备份中的 web 的ID相同但内容不同
+		web := *sourceWeb
+		web.Host = "10.0.0.100"
+		web.CredentialID = ""
+		web.AuthType = AuthTypeAsk
+		require.NoError(t, manager.AddServer(&web))
+		// 与备份中的 dev 地址相同
+		dev := NewServerConfig("10.1.0.1")
+		dev.User = "root"
+		dev.Alias = "dev-box"
+		require.NoError(t, manager.AddServer(dev))
+		// 别名与备份中的跳板机相同的其他服务器
+		other := NewServerConfig("10.2.0.1")
+		other.Alias = "bastion"
+		require.NoError(t, manager.AddServer(other))
+		return manager
+	}
+	items := func(plan *RestorePlan, section string) map[string]*RestoreItem {
+		byLabel := make(map[string]*RestoreItem)
+		for _, item := range plan.Items {
+			if item.Section == section {
+				byLabel[item.Label] = item
+			}
+		}
+		return byLabel
+	}
+
+	t.Run("跳过", func(t *testing.T) {
+		manager := newTarget(t)
+		plan, err := manager.PlanRestore(backup, RestoreOptions{})
+		require.NoError(t, err)
+		servers := items(plan, "servers")
+		assert.Equal(t, RestoreSkip, servers["web"].Action)
+		assert.Equal(t, RestoreSkip, servers["dev"].Action)
+		assert.Contains(t, servers["dev"].Note, "重复")
+		assert.Equal(t, RestoreRename, servers["bastion"].Action)
+		assert.Equal(t, "别名 'bastion' 已被使用，改为 'bastion-2'", servers["bastion"].Note)
+
+		_, err = manager.ApplyRestore(plan)
+		require.NoError(t, err)
+		web, err := manager.GetServer(sourceWeb.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.100", web.Host)
+		assert.Len(t, manager.ListServers(), 4)
+	})
+
+	t.Run("覆盖", func(t *testing.T) {
+		manager := newTarget(t)
+		plan, err := manager.PlanRestore(backup, RestoreOptions{Conflict: ConflictOverwrite})
+		require.NoError(t, err)
+		servers := items(plan, "servers")
+		assert.Equal(t, RestoreOverwrite, servers["web"].Action)
+		assert.Equal(t, RestoreOverwrite, servers["dev"].Action)
+
+		_, err = manager.ApplyRestore(plan)
+		require.NoError(t, err)
+		web, err := manager.GetServer(sourceWeb.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.1", web.Host)
+		assert.Equal(t, "bastion-2", web.JumpHost, "跳板机引用改为恢复后的别名")
+		dev, err := manager.GetServer(servers["dev"].Key)
+		require.NoError(t, err)
+		assert.Equal(t, "dev", dev.Alias)
+		assert.Len(t, manager.ListServers(), 4)
+	})
+
+	t.Run("重命名", func(t *testing.T) {
+		manager := newTarget(t)
+		plan, err := manager.PlanRestore(backup, RestoreOptions{Conflict: ConflictRename})
+		require.NoError(t, err)
+		web := items(plan, "servers")["web"]
+		assert.Equal(t, RestoreRename, web.Action)
+		assert.NotEqual(t, sourceWeb.ID, web.Key)
+		assert.Equal(t, "别名 'web' 已被使用，改为 'web-2'", web.Note)
+		assert.Equal(t, RestoreSkip, items(plan, "servers")["dev"].Action, "地址相同的服务器不能重命名")
+
+		_, err = manager.ApplyRestore(plan)
+		require.NoError(t, err)
+		pf, err := manager.GetPortForwardByAlias("web-http")
+		require.NoError(t, err)
+		assert.Equal(t, web.Key, pf.ServerID, "端口转发关联到重命名后的服务器")
+		_, err = manager.GetServer(sourceWeb.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("未知的冲突处理方式", func(t *testing.T) {
+		_, err := newTarget(t).PlanRestore(backup, RestoreOptions{Conflict: "merge"})
+		assert.Error(t, err)
+	})
+}
+
+// TestRestoreSelective 测试按标签恢复、跳过凭证和替换模式
+func TestRestoreSelective(t *testing.T) {
+	source, _ := backupFixture(t)
+	data, _, err := source.CreateBackup([]byte("secret"))
+	require.NoError(t, err)
+	backup, err := OpenBackup(data, []byte("secret"))
+	require.NoError(t, err)
+
+	t.Run("按标签", func(t *testing.T) {
+		manager, err := NewManager(createTempConfigFile(t))
+		require.NoError(t, err)
+		plan, err := manager.PlanRestore(backup, RestoreOptions{Tags: []string{"prod"}, SkipCredentials: true})
+		require.NoError(t, err)
+		assert.Nil(t, plan.Settings)
+		var labels []string
+		for _, item := range plan.Items {
+			labels = append(labels, item.Section+"/"+item.Label)
+		}
+		assert.ElementsMatch(t, []string{"servers/bastion", "servers/web", "port_forwards/web-http", "forward_groups/web"}, labels)
+		assert.Empty(t, plan.Keys, "未恢复的凭证引用的私钥文件不写入")
+	})
+
+	t.Run("替换", func(t *testing.T) {
+		manager, err := NewManager(createTempConfigFile(t))
+		require.NoError(t, err)
+		old := NewServerConfig("192.168.1.1")
+		old.Alias = "old"
+		require.NoError(t, manager.AddServer(old))
+
+		_, err = manager.PlanRestore(backup, RestoreOptions{Replace: true, Tags: []string{"prod"}})
+		assert.Error(t, err)
+		plan, err := manager.PlanRestore(backup, RestoreOptions{Replace: true, SkipSettings: true})
+		require.NoError(t, err)
+		assert.Equal(t, 1, plan.Removed)
+		result, err := manager.ApplyRestore(plan)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Removed)
+		_, err = manager.GetServerByAlias("old")
+		assert.Error(t, err)
+		assert.Len(t, manager.ListServers(), 3)
+		assert.Equal(t, "root", manager.GetConfig().Settings.DefaultUser)
+	})
+}
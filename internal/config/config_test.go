@@ -22,7 +22,7 @@ func TestNewConfig(t *testing.T) {
 		config := NewConfig()
 
 		assert.NotNil(t, config)
-		assert.Equal(t, 1, config.ConfigVersion)
+		assert.Equal(t, CurrentConfigVersion, config.ConfigVersion)
 		assert.NotNil(t, config.Servers)
 		assert.NotNil(t, config.PortForwards)
 		assert.NotNil(t, config.Credentials)
@@ -107,6 +107,19 @@ func TestManagerNew(t *testing.T) {
 	})
 }
 
+// TestManagerConfigDir 测试配置目录中的 ~ 展开为用户主目录
+func TestManagerConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	path := writeConfig(t, "config_version: 2\nsettings:\n  config_dir: ~/.config/gotssh\n")
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "gotssh"), manager.ConfigDir())
+	assert.Equal(t, filepath.Join(home, ".config", "gotssh", historyFile), manager.historyPath())
+}
+
 // TestManagerSaveAndLoad 测试配置保存和加载
 func TestManagerSaveAndLoad(t *testing.T) {
 	t.Run("保存和加载配置", func(t *testing.T) {
@@ -350,6 +363,331 @@ func TestManagerPortForwardOperations(t *testing.T) {
 	})
 }
 
+// TestPortForwardEndpoints 测试端口转发端点
+func TestPortForwardEndpoints(t *testing.T) {
+	t.Run("TCP端点", func(t *testing.T) {
+		pf := NewPortForwardConfig("server")
+		pf.LocalPort = 8080
+		pf.RemoteHost = "::1"
+		pf.RemotePort = 80
+
+		assert.Equal(t, "tcp", pf.LocalNetwork())
+		assert.Equal(t, "127.0.0.1:8080", pf.LocalAddress())
+		assert.Equal(t, "tcp", pf.RemoteNetwork())
+		assert.Equal(t, "[::1]:80", pf.RemoteAddress())
+	})
+
+	t.Run("Unix套接字端点", func(t *testing.T) {
+		pf := NewPortForwardConfig("server")
+		pf.LocalSocket = "/tmp/docker.sock"
+		pf.RemoteSocket = "/var/run/docker.sock"
+
+		assert.Equal(t, "unix", pf.LocalNetwork())
+		assert.Equal(t, "/tmp/docker.sock", pf.LocalAddress())
+		assert.Equal(t, "unix", pf.RemoteNetwork())
+		assert.Equal(t, "/var/run/docker.sock", pf.RemoteAddress())
+	})
+
+	t.Run("套接字权限", func(t *testing.T) {
+		pf := NewPortForwardConfig("server")
+
+		mode, err := pf.SocketFileMode()
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultSocketMode, mode)
+
+		pf.SocketMode = "0660"
+		mode, err = pf.SocketFileMode()
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0660), mode)
+
+		pf.SocketMode = "rw"
+		_, err = pf.SocketFileMode()
+		assert.Error(t, err)
+
+		pf.SocketMode = "1777"
+		_, err = pf.SocketFileMode()
+		assert.Error(t, err)
+	})
+}
+
+// TestLocalPortConflicts 测试本地端口重叠检测
+func TestLocalPortConflicts(t *testing.T) {
+	t.Run("监听地址重叠", func(t *testing.T) {
+		assert.True(t, LocalHostsOverlap("127.0.0.1", "127.0.0.1"))
+		assert.True(t, LocalHostsOverlap("localhost", "127.0.0.1"))
+		assert.True(t, LocalHostsOverlap("0.0.0.0", "127.0.0.1"))
+		assert.True(t, LocalHostsOverlap("", "192.168.1.10"))
+		assert.False(t, LocalHostsOverlap("127.0.0.1", "192.168.1.10"))
+	})
+
+	t.Run("查找已保存的冲突", func(t *testing.T) {
+		manager, err := NewManager(createTempConfigFile(t))
+		require.NoError(t, err)
+		server := NewServerConfig("192.168.1.100")
+		require.NoError(t, manager.AddServer(server))
+
+		web := NewPortForwardConfig(server.ID)
+		web.Alias = "web"
+		web.LocalPort = 8080
+		require.NoError(t, manager.AddPortForward(web))
+
+		remote := NewPortForwardConfig(server.ID)
+		remote.Type = ForwardTypeRemote
+		remote.LocalPort = 8080
+		require.NoError(t, manager.AddPortForward(remote))
+
+		auto := NewPortForwardConfig(server.ID)
+		auto.LocalPort = 0
+		require.NoError(t, manager.AddPortForward(auto))
+
+		candidate := NewPortForwardConfig(server.ID)
+		candidate.LocalHost = "0.0.0.0"
+		candidate.LocalPort = 8080
+		conflicts := manager.FindLocalPortConflicts(candidate)
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, "web", conflicts[0].Alias)
+
+		// 自身不算冲突，自动分配端口不冲突
+		assert.Empty(t, manager.FindLocalPortConflicts(web))
+		assert.Empty(t, manager.FindLocalPortConflicts(auto))
+		assert.True(t, auto.AutoLocalPort())
+	})
+}
+
+// TestPortForwardAccessControl 测试访问控制配置解析
+func TestPortForwardAccessControl(t *testing.T) {
+	pf := NewPortForwardConfig("server")
+	assert.False(t, pf.HasAccessControl())
+
+	pf.AllowedCIDRs = []string{"127.0.0.1", "10.0.0.0/8", "::1"}
+	assert.True(t, pf.HasAccessControl())
+
+	networks, err := pf.AllowedNetworks()
+	require.NoError(t, err)
+	require.Len(t, networks, 3)
+	assert.Equal(t, "127.0.0.1/32", networks[0].String())
+	assert.Equal(t, "10.0.0.0/8", networks[1].String())
+	assert.Equal(t, "::1/128", networks[2].String())
+
+	pf.AllowedCIDRs = []string{"10.0.0.0/33"}
+	_, err = pf.AllowedNetworks()
+	assert.Error(t, err)
+
+	_, err = ParseCIDR("not-an-ip")
+	assert.Error(t, err)
+}
+
+// TestForwardTypes 测试转发类型和显示形式
+func TestForwardTypes(t *testing.T) {
+	assert.True(t, ForwardTypeLocal.Valid())
+	assert.True(t, ForwardTypeRemoteDynamic.Valid())
+	assert.False(t, ForwardType("dynamic-ish").Valid())
+	assert.False(t, ForwardTypeLocal.ListensRemote())
+	assert.True(t, ForwardTypeRemote.ListensRemote())
+	assert.True(t, ForwardTypeRemoteDynamic.ListensRemote())
+
+	pf := NewPortForwardConfig("server")
+	pf.LocalPort = 8080
+	pf.RemotePort = 80
+	assert.Equal(t, "127.0.0.1:8080 -> 127.0.0.1:80", pf.Summary())
+
+	pf.Type = ForwardTypeRemoteDynamic
+	pf.RemotePort = 1080
+	assert.Equal(t, "127.0.0.1:1080 -> SOCKS5(本机)", pf.Summary())
+	assert.False(t, pf.AutoRemotePort())
+	pf.RemotePort = 0
+	assert.True(t, pf.AutoRemotePort())
+
+	// HTTP代理在本机监听，参与本地端口冲突检测
+	proxy := NewPortForwardConfig("server")
+	proxy.Type = ForwardTypeHTTPProxy
+	proxy.LocalPort = 3128
+	assert.True(t, ForwardTypeHTTPProxy.Valid())
+	assert.True(t, proxy.BindsLocalPort())
+	assert.Equal(t, "127.0.0.1:3128 -> HTTP代理(经服务器)", proxy.Summary())
+
+	local := NewPortForwardConfig("server")
+	local.LocalPort = 3128
+	assert.True(t, proxy.LocalPortOverlaps(local))
+}
+
+// TestForwardGroupStages 测试转发组启动阶段划分
+func TestForwardGroupStages(t *testing.T) {
+	t.Run("无依赖时同一阶段启动", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+		group.Forwards = []string{"db", "redis", "kafka"}
+
+		stages, err := group.Stages()
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"db", "redis", "kafka"}}, stages)
+	})
+
+	t.Run("按依赖分阶段", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+		group.Forwards = []string{"app", "db", "redis", "kafka"}
+		group.Dependencies = map[string][]string{
+			"app":   {"db", "kafka"},
+			"kafka": {"redis"},
+		}
+
+		stages, err := group.Stages()
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"db", "redis"}, {"kafka"}, {"app"}}, stages)
+	})
+
+	t.Run("循环依赖", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+		group.Forwards = []string{"a", "b"}
+		group.Dependencies = map[string][]string{"a": {"b"}, "b": {"a"}}
+
+		_, err := group.Stages()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "循环")
+	})
+
+	t.Run("没有成员", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+
+		_, err := group.Stages()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "没有成员")
+	})
+
+	t.Run("依赖非成员", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+		group.Forwards = []string{"a"}
+		group.Dependencies = map[string][]string{"a": {"b"}}
+
+		_, err := group.Stages()
+		assert.Error(t, err)
+	})
+
+	t.Run("重复成员", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+		group.Forwards = []string{"a", "a"}
+
+		_, err := group.Stages()
+		assert.Error(t, err)
+	})
+}
+
+// TestManagerForwardGroupOperations 测试转发组操作
+func TestManagerForwardGroupOperations(t *testing.T) {
+	configPath := createTempConfigFile(t)
+	manager, err := NewManager(configPath)
+	require.NoError(t, err)
+
+	server := NewServerConfig("192.168.1.100")
+	require.NoError(t, manager.AddServer(server))
+
+	for i, alias := range []string{"db", "redis", "kafka"} {
+		pf := NewPortForwardConfig(server.ID)
+		pf.Alias = alias
+		pf.LocalPort = 10000 + i
+		pf.RemotePort = 20000 + i
+		require.NoError(t, manager.AddPortForward(pf))
+	}
+
+	t.Run("添加转发组", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+		group.Forwards = []string{"db", "redis", "kafka"}
+		group.Dependencies = map[string][]string{"kafka": {"redis"}}
+
+		err := manager.AddForwardGroup(group)
+		assert.NoError(t, err)
+		assert.Len(t, manager.ListForwardGroups(), 1)
+	})
+
+	t.Run("名称重复", func(t *testing.T) {
+		group := NewForwardGroup("devstack")
+		group.Forwards = []string{"db"}
+
+		err := manager.AddForwardGroup(group)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "已存在")
+	})
+
+	t.Run("成员不存在", func(t *testing.T) {
+		group := NewForwardGroup("other")
+		group.Forwards = []string{"missing"}
+
+		err := manager.AddForwardGroup(group)
+		assert.Error(t, err)
+	})
+
+	t.Run("空成员", func(t *testing.T) {
+		group := NewForwardGroup("empty")
+
+		err := manager.AddForwardGroup(group)
+		assert.Error(t, err)
+	})
+
+	t.Run("重新加载后保持转发组", func(t *testing.T) {
+		reloaded, err := NewManager(configPath)
+		require.NoError(t, err)
+
+		group, err := reloaded.GetForwardGroupByName("devstack")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"db", "redis", "kafka"}, group.Forwards)
+		assert.Equal(t, []string{"redis"}, group.Dependencies["kafka"])
+	})
+
+	t.Run("重命名端口转发同步更新转发组", func(t *testing.T) {
+		pf, err := manager.GetPortForwardByAlias("redis")
+		require.NoError(t, err)
+
+		renamed := *pf
+		renamed.Alias = "cache"
+		require.NoError(t, manager.UpdatePortForward(pf.ID, &renamed))
+
+		group, err := manager.GetForwardGroupByName("devstack")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db", "cache", "kafka"}, group.Forwards)
+		assert.Equal(t, []string{"cache"}, group.Dependencies["kafka"])
+	})
+
+	t.Run("删除端口转发同步移除成员", func(t *testing.T) {
+		pf, err := manager.GetPortForwardByAlias("cache")
+		require.NoError(t, err)
+		require.NoError(t, manager.DeletePortForward(pf.ID))
+
+		group, err := manager.GetForwardGroupByName("devstack")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db", "kafka"}, group.Forwards)
+		assert.Empty(t, group.Dependencies["kafka"])
+	})
+
+	t.Run("删除全部成员后提示删除转发组", func(t *testing.T) {
+		for _, alias := range []string{"db", "kafka"} {
+			pf, err := manager.GetPortForwardByAlias(alias)
+			require.NoError(t, err)
+			require.NoError(t, manager.DeletePortForward(pf.ID))
+		}
+
+		group, err := manager.GetForwardGroupByName("devstack")
+		require.NoError(t, err)
+		assert.Empty(t, group.Forwards)
+		_, err = group.Stages()
+		assert.Error(t, err)
+
+		reloaded, err := NewManager(configPath)
+		require.NoError(t, err)
+		assert.Contains(t, problemMessages(reloaded.Problems()), "转发组 'devstack' 没有成员")
+	})
+
+	t.Run("删除转发组", func(t *testing.T) {
+		group, err := manager.GetForwardGroupByName("devstack")
+		require.NoError(t, err)
+
+		err = manager.DeleteForwardGroup(group.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, manager.ListForwardGroups())
+
+		err = manager.DeleteForwardGroup(group.ID)
+		assert.Error(t, err)
+	})
+}
+
 // TestAuthType 测试认证类型
 func TestAuthType(t *testing.T) {
 	t.Run("认证类型常量", func(t *testing.T) {
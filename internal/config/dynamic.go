@@ -0,0 +1,331 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 动态清单参数
+const (
+	defaultProviderTTL     = 300               // 命令输出默认缓存时间（秒）
+	defaultProviderTimeout = 30                // 命令默认超时时间（秒）
+	providerCacheDir       = "inventory-cache" // 配置目录下缓存命令输出的目录
+	providersSection       = "inventory_providers"
+)
+
+// InventoryProvider 动态清单，运行本地命令或读取文件，得到 Ansible 清单格式（JSON、YAML 或 INI）的主机列表。
+// 主机在启动时生成为服务器，合并到服务器列表中，不保存到配置文件，也不能编辑和删除
+type InventoryProvider struct {
+	Name        string   `yaml:"name"`                  // 名称
+	Command     string   `yaml:"command,omitempty"`     // 输出清单的命令，通过 shell 执行，如 ansible-inventory -i aws_ec2.yml --list
+	File        string   `yaml:"file,omitempty"`        // 清单文件，相对路径相对于声明它的配置文件所在目录
+	TTL         int      `yaml:"ttl,omitempty"`         // 命令输出的缓存时间（秒），0 为默认的300秒，负数表示不缓存
+	Timeout     int      `yaml:"timeout,omitempty"`     // 命令超时时间（秒），0 为默认的30秒
+	User        string   `yaml:"user,omitempty"`        // 主机未设置 ansible_user 时使用的用户名
+	Group       string   `yaml:"group,omitempty"`       // 服务器所属分组，继承分组的默认设置
+	Credential  string   `yaml:"credential,omitempty"`  // 引用的凭证别名，主机未设置密钥时使用
+	Tags        []string `yaml:"tags,omitempty"`        // 附加到每台服务器的标签，Ansible 分组也作为标签
+	Description string   `yaml:"description,omitempty"` // 描述
+}
+
+// providerCache 缓存的命令输出
+type providerCache struct {
+	Command   string    `yaml:"command"` // 命令变化后缓存失效
+	FetchedAt time.Time `yaml:"fetched_at"`
+	Output    string    `yaml:"output"`
+}
+
+// ttl 命令输出的缓存时间
+func (p *InventoryProvider) ttl() time.Duration {
+	if p.TTL == 0 {
+		return defaultProviderTTL * time.Second
+	}
+	return time.Duration(p.TTL) * time.Second
+}
+
+// timeout 命令超时时间
+func (p *InventoryProvider) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return defaultProviderTimeout * time.Second
+	}
+	return time.Duration(p.Timeout) * time.Second
+}
+
+// DynamicServerID 动态清单中主机对应的服务器ID，如 aws:web-1
+func DynamicServerID(provider, host string) string {
+	return provider + ":" + host
+}
+
+// ListInventoryProviders 列出所有动态清单，按名称排序
+func (m *Manager) ListInventoryProviders() []*InventoryProvider {
+	providers := make([]*InventoryProvider, 0, len(m.config.InventoryProviders))
+	for _, name := range sortedKeys(m.config.InventoryProviders) {
+		p := m.config.InventoryProviders[name]
+		if p == nil {
+			continue
+		}
+		if p.Name == "" {
+			p.Name = name
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+// DynamicLayers 已加载的动态清单
+func (m *Manager) DynamicLayers() []*Layer {
+	var layers []*Layer
+	for _, l := range m.layers {
+		if l.Kind == LayerDynamic {
+			layers = append(layers, l)
+		}
+	}
+	return layers
+}
+
+// IsDynamic 服务器是否来自动态清单，动态清单中的服务器不能编辑和删除
+func (m *Manager) IsDynamic(serverID string) bool {
+	l := m.Origin("servers", serverID)
+	return l != nil && l.Kind == LayerDynamic
+}
+
+// updateDynamicLayers 按配置中的动态清单更新配置层，动态清单位于共享清单和用户配置之前。
+// 返回动态清单是否有增减
+func (m *Manager) updateDynamicLayers() bool {
+	existing := make(map[string]*Layer)
+	for _, l := range m.DynamicLayers() {
+		existing[l.Name] = l
+	}
+
+	var dynamic []*Layer
+	changed := false
+	for _, p := range m.ListInventoryProviders() {
+		l := existing[p.Name]
+		if l == nil {
+			l = &Layer{Kind: LayerDynamic, Name: p.Name, ReadOnly: true}
+			changed = true
+		}
+		delete(existing, p.Name)
+		dynamic = append(dynamic, l)
+	}
+	if len(existing) > 0 {
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+
+	layers := make([]*Layer, 0, len(m.layers)+len(dynamic))
+	for _, l := range m.layers {
+		if l.Kind == LayerDynamic {
+			continue
+		}
+		if dynamic != nil && (l.Kind == LayerInventory || l == m.primary) {
+			layers = append(layers, dynamic...)
+			dynamic = nil
+		}
+		layers = append(layers, l)
+	}
+	m.layers = layers
+	return true
+}
+
+// loadDynamicLayers 按配置更新并读取所有动态清单
+func (m *Manager) loadDynamicLayers() error {
+	m.updateDynamicLayers()
+	for _, l := range m.DynamicLayers() {
+		m.loadDynamic(l, false)
+	}
+	return m.rebuild()
+}
+
+// RefreshInventoryProviders 忽略缓存重新获取动态清单，names 为空时刷新全部，返回刷新的动态清单
+func (m *Manager) RefreshInventoryProviders(names ...string) ([]*Layer, error) {
+	m.updateDynamicLayers()
+	for _, name := range names {
+		if m.config.InventoryProviders[name] == nil {
+			return nil, fmt.Errorf("动态清单 '%s' 不存在", name)
+		}
+	}
+
+	var refreshed []*Layer
+	for _, l := range m.DynamicLayers() {
+		if len(names) > 0 && !slices.Contains(names, l.Name) {
+			continue
+		}
+		m.loadDynamic(l, true)
+		refreshed = append(refreshed, l)
+	}
+	return refreshed, m.rebuild()
+}
+
+// loadDynamic 获取动态清单并生成服务器。获取或解析失败不影响其他配置，记录为警告
+func (m *Manager) loadDynamic(l *Layer, refresh bool) {
+	l.layerState = layerState{config: NewConfig(), settings: make(map[string]interface{})}
+	l.problems = nil
+	l.fetchedAt = time.Time{}
+	p := m.config.InventoryProviders[l.Name]
+	if p == nil {
+		return
+	}
+
+	path := []string{providersSection, l.Name}
+	warn := func(format string, args ...interface{}) {
+		l.problems = append(l.problems, Problem{
+			Severity: SeverityWarning,
+			Path:     path,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	data, fetchedAt, err := m.fetchProvider(l.Name, p, refresh)
+	if err != nil {
+		if data == nil {
+			warn("动态清单 '%s' 获取失败: %v", l.Name, err)
+			return
+		}
+		warn("动态清单 '%s' 获取失败，使用 %s 的缓存: %v", l.Name, fetchedAt.Format("2006-01-02 15:04"), err)
+	}
+	hosts, err := ParseAnsibleInventory(data)
+	if err != nil {
+		warn("动态清单 '%s': %v", l.Name, err)
+		return
+	}
+
+	for _, host := range hosts {
+		server, err := host.Server()
+		if err != nil {
+			warn("动态清单 '%s' 中%v，已跳过", l.Name, err)
+			continue
+		}
+		server.ID = DynamicServerID(l.Name, host.Name)
+		if server.User == "" {
+			server.User = p.User
+		}
+		if server.KeyPath == "" {
+			server.Credential = p.Credential
+		}
+		server.Group = NormalizeGroupPath(p.Group)
+		if server.AuthType == "" && server.Credential == "" && server.Group == "" {
+			// 没有可继承的认证方式时使用默认认证类型
+			server.AuthType = AuthType(m.config.Settings.DefaultAuthType)
+		}
+		server.Description = p.Description
+		for _, tag := range p.Tags {
+			if !slices.Contains(server.Tags, tag) {
+				server.Tags = append(server.Tags, tag)
+			}
+		}
+		l.config.Servers[server.ID] = server
+	}
+	l.fetchedAt = fetchedAt
+}
+
+// fetchProvider 获取动态清单的内容：文件每次重新读取；命令的输出在缓存有效期内直接使用缓存，
+// 命令失败时返回过期的缓存和错误
+func (m *Manager) fetchProvider(name string, p *InventoryProvider, refresh bool) ([]byte, time.Time, error) {
+	dir := filepath.Dir(m.configPath)
+	if l := m.Origin(providersSection, name); l != nil && l.Path != "" {
+		dir = filepath.Dir(l.Path)
+	}
+
+	switch {
+	case p.Command != "" && p.File != "":
+		return nil, time.Time{}, fmt.Errorf("不能同时设置 command 和 file")
+	case p.File != "":
+		path := expandHome(p.File)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("读取清单文件失败: %w", err)
+		}
+		return data, time.Now(), nil
+	case p.Command == "":
+		return nil, time.Time{}, fmt.Errorf("需要设置 command 或 file")
+	}
+
+	cachePath := filepath.Join(m.ConfigDir(), providerCacheDir, name+".yaml")
+	var cache providerCache
+	cached := false
+	if data, err := os.ReadFile(cachePath); err == nil && yaml.Unmarshal(data, &cache) == nil {
+		cached = cache.Command == p.Command
+	}
+	if cached && !refresh && p.TTL >= 0 && time.Since(cache.FetchedAt) < p.ttl() {
+		return []byte(cache.Output), cache.FetchedAt, nil
+	}
+
+	output, err := runInventoryCommand(p.Command, dir, p.timeout())
+	if err != nil {
+		if cached {
+			return []byte(cache.Output), cache.FetchedAt, err
+		}
+		return nil, time.Time{}, err
+	}
+	if _, err := ParseAnsibleInventory(output); err != nil {
+		// 输出无效时不覆盖缓存
+		if cached {
+			return []byte(cache.Output), cache.FetchedAt, err
+		}
+		return nil, time.Time{}, err
+	}
+
+	cache = providerCache{Command: p.Command, FetchedAt: time.Now(), Output: string(output)}
+	if data, err := yaml.Marshal(&cache); err == nil {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err == nil {
+			if err := writeFileAtomic(cachePath, data, 0600); err != nil {
+				m.logger.Warn("保存动态清单缓存失败", "provider", name, "error", err)
+			}
+		}
+	}
+	return output, cache.FetchedAt, nil
+}
+
+// runInventoryCommand 在 dir 中通过 shell 运行命令，返回标准输出
+func runInventoryCommand(command, dir string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("命令超时（%s）", timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("命令执行失败: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("命令执行失败: %w", err)
+	}
+	return output, nil
+}
+
+// FetchedAt 动态清单的获取时间，未获取到时为零值
+func (l *Layer) FetchedAt() time.Time {
+	return l.fetchedAt
+}
+
+// Problems 读取配置层时发现的问题，如共享清单不存在、动态清单获取失败
+func (l *Layer) Problems() []Problem {
+	return l.problems
+}
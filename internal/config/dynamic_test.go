@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDynamicManager 创建定义了动态清单的配置管理器，个人配置中有别名为 ops 的凭证
+func newDynamicManager(t *testing.T, providers map[string]*InventoryProvider) (*Manager, string) {
+	manager, path := newInventoryManager(t)
+	manager.GetConfig().InventoryProviders = providers
+	require.NoError(t, manager.Save())
+
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	return manager, path
+}
+
+// TestDynamicInventoryFile 测试从文件读取的动态清单
+func TestDynamicInventoryFile(t *testing.T) {
+	path := createTempConfigFile(t)
+	writeInventory(t, filepath.Dir(path), "hosts.json", ansibleListOutput)
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	manager.GetConfig().InventoryProviders["aws"] = &InventoryProvider{
+		File: "hosts.json", Group: "cloud", User: "ec2-user", Credential: "missing", Tags: []string{"aws"},
+	}
+	require.NoError(t, manager.Save())
+
+	manager, err = NewManager(path)
+	require.NoError(t, err)
+	assert.Len(t, manager.ListServers(), 4)
+	require.Len(t, manager.DynamicLayers(), 1)
+	assert.Equal(t, "动态清单 (aws)", manager.DynamicLayers()[0].String())
+
+	servers, err := manager.FindServer("web-2")
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	web2 := servers[0]
+	assert.Equal(t, DynamicServerID("aws", "web-2"), web2.ID)
+	assert.True(t, manager.IsDynamic(web2.ID))
+	assert.Equal(t, "cloud", web2.Group)
+	assert.Equal(t, []string{"prod", "web", "aws"}, web2.Tags)
+	assert.Equal(t, "deploy", web2.User)
+	bastion, err := manager.GetServerByAlias("bastion")
+	require.NoError(t, err)
+	assert.Equal(t, "ec2-user", bastion.User, "主机未设置用户名时使用动态清单的用户名")
+	assert.Contains(t, problemMessages(manager.Problems()), "凭证别名 'missing' 不存在")
+
+	// 动态清单中的服务器不能修改和删除
+	edited := *web2
+	edited.Description = "mine"
+	err = manager.UpdateServer(web2.ID, &edited)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "不能修改")
+	err = manager.DeleteServer(web2.ID)
+	require.Error(t, err)
+	server, err := manager.GetServer(web2.ID)
+	require.NoError(t, err)
+	assert.Empty(t, server.Description)
+
+	// 动态清单中的服务器不写入配置文件
+	require.NoError(t, manager.AddServer(NewServerConfig("10.9.9.9")))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "10.0.0.2")
+	assert.Contains(t, string(data), "hosts.json")
+	assert.Len(t, manager.ListServers(), 5)
+}
+
+// TestDynamicInventoryCommand 测试运行命令的动态清单及其缓存
+func TestDynamicInventoryCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试命令使用 sh")
+	}
+	dir := t.TempDir()
+	writeInventory(t, dir, "hosts.json", ansibleListOutput)
+	counter := filepath.Join(dir, "runs")
+	command := "echo run >> " + counter + " && cat " + filepath.Join(dir, "hosts.json")
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "run")
+	}
+
+	manager, path := newDynamicManager(t, map[string]*InventoryProvider{"aws": {Command: command}})
+	assert.Len(t, manager.ListServers(), 4)
+	assert.False(t, manager.DynamicLayers()[0].FetchedAt().IsZero())
+	assert.Equal(t, 1, runs())
+
+	// 缓存有效期内不再运行命令
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	assert.Len(t, manager.ListServers(), 4)
+	assert.Equal(t, 1, runs())
+
+	// 强制刷新
+	refreshed, err := manager.RefreshInventoryProviders("aws")
+	require.NoError(t, err)
+	require.Len(t, refreshed, 1)
+	assert.Equal(t, 2, runs())
+	_, err = manager.RefreshInventoryProviders("gcp")
+	assert.Error(t, err)
+
+	// 命令失败时使用过期的缓存
+	require.NoError(t, os.Remove(filepath.Join(dir, "hosts.json")))
+	refreshed, err = manager.RefreshInventoryProviders()
+	require.NoError(t, err)
+	assert.Len(t, manager.ListServers(), 4)
+	require.Len(t, refreshed[0].Problems(), 1)
+	assert.Contains(t, refreshed[0].Problems()[0].Message, "使用")
+}
+
+// TestDynamicInventoryFailure 测试动态清单获取失败不影响加载
+func TestDynamicInventoryFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeInventory(t, dir, "bad.json", `{"web": {"hosts": ["a", "b"]}, "_meta": {"hostvars": {"b": {"ansible_port": 70000}}}}`)
+	manager, path := newDynamicManager(t, map[string]*InventoryProvider{
+		"missing": {File: filepath.Join(dir, "missing.json")},
+		"bad":     {File: filepath.Join(dir, "bad.json")},
+	})
+
+	require.Len(t, manager.ListServers(), 1)
+	assert.Equal(t, DynamicServerID("bad", "a"), manager.ListServers()[0].ID)
+	messages := problemMessages(manager.Problems())
+	assert.Contains(t, messages, "动态清单 'missing' 获取失败")
+	assert.Contains(t, messages, "端口 '70000' 无效，已跳过")
+	for _, p := range manager.Problems() {
+		if strings.Contains(p.Message, "missing") {
+			assert.Equal(t, path, p.File, "问题定位到声明动态清单的配置文件")
+		}
+	}
+
+	// 动态清单需要设置命令或文件
+	manager.GetConfig().InventoryProviders["empty"] = &InventoryProvider{}
+	err := manager.Save()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command 或 file")
+}
+
+// TestInventoryIgnoresProviders 测试共享清单中的动态清单被忽略
+func TestInventoryIgnoresProviders(t *testing.T) {
+	dir := t.TempDir()
+	team := writeInventory(t, dir, "team.yaml", "inventory_providers:\n  cloud:\n    command: ./list-hosts.sh\n")
+	manager, _ := newInventoryManager(t, team)
+
+	assert.Empty(t, manager.ListInventoryProviders())
+	assert.Empty(t, manager.DynamicLayers())
+	assert.Contains(t, problemMessages(manager.Problems()), "1 个动态清单被忽略")
+}
+
+// TestProjectProvidersNotExecuted 测试项目配置和系统配置中运行命令的动态清单不会被执行
+func TestProjectProvidersNotExecuted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试命令使用 sh")
+	}
+	system, _, project, layers := newTestLayers(t)
+	dir := t.TempDir()
+	writeInventory(t, dir, "hosts.json", ansibleListOutput)
+	marker := filepath.Join(dir, "pwned")
+	provider := "inventory_providers:\n  evil:\n    command: \"touch " + marker + "; echo '{}'\"\n"
+	require.NoError(t, os.WriteFile(project, []byte(projectLayerConfig+provider+
+		"  hosts:\n    file: "+filepath.Join(dir, "hosts.json")+"\n"), 0644))
+	require.NoError(t, os.WriteFile(system, []byte(systemLayerConfig+
+		"inventory_providers:\n  corp:\n    command: \"touch "+marker+"\"\n"), 0644))
+
+	manager, err := newLayeredManager(layers, false)
+	require.NoError(t, err)
+	_, err = manager.RefreshInventoryProviders()
+	require.NoError(t, err)
+	assert.NoFileExists(t, marker)
+
+	// 读取文件的动态清单仍然生效
+	var names []string
+	for _, p := range manager.ListInventoryProviders() {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"hosts"}, names)
+	messages := problemMessages(manager.Problems())
+	assert.Contains(t, messages, "项目配置中的动态清单 'evil' 被忽略")
+	assert.Contains(t, messages, "系统配置中的动态清单 'corp' 被忽略")
+
+	// 修改项目配置时保留被忽略的定义
+	app, err := manager.GetServerByAlias("app")
+	require.NoError(t, err)
+	edited := *app
+	edited.Description = "edited"
+	require.NoError(t, manager.UpdateServer(app.ID, &edited))
+	data, err := os.ReadFile(project)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "edited")
+	assert.Contains(t, string(data), "evil")
+	assert.NoFileExists(t, marker)
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// groupSeparator 分组路径分隔符
+const groupSeparator = "/"
+
+// NormalizeGroupPath 规范化分组路径：去掉多余的空白和分隔符，如 " prod//db/ " -> "prod/db"
+func NormalizeGroupPath(path string) string {
+	var parts []string
+	for _, part := range strings.Split(path, groupSeparator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, groupSeparator)
+}
+
+// GroupAncestors 分组路径及其所有上级路径，从最上级到自身，如 prod/db -> [prod prod/db]
+func GroupAncestors(path string) []string {
+	path = NormalizeGroupPath(path)
+	if path == "" {
+		return nil
+	}
+	parts := strings.Split(path, groupSeparator)
+	ancestors := make([]string, len(parts))
+	for i := range parts {
+		ancestors[i] = strings.Join(parts[:i+1], groupSeparator)
+	}
+	return ancestors
+}
+
+// InGroup 服务器所在分组是否为 path 或其下级分组
+func InGroup(server *ServerConfig, path string) bool {
+	path = NormalizeGroupPath(path)
+	group := NormalizeGroupPath(server.Group)
+	return group == path || strings.HasPrefix(group, path+groupSeparator)
+}
+
+// NewServerGroup 创建新的服务器分组
+func NewServerGroup(path string) *ServerGroup {
+	now := time.Now()
+	return &ServerGroup{
+		Path:      NormalizeGroupPath(path),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// AddServerGroup 添加服务器分组
+func (m *Manager) AddServerGroup(group *ServerGroup) error {
+	group.Path = NormalizeGroupPath(group.Path)
+	if group.Path == "" {
+		return fmt.Errorf("分组路径不能为空")
+	}
+	if _, exists := m.config.ServerGroups[group.Path]; exists {
+		return fmt.Errorf("分组 '%s' 已存在", group.Path)
+	}
+
+	now := time.Now()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	m.config.ServerGroups[group.Path] = group
+	return m.Save()
+}
+
+// UpdateServerGroup 更新服务器分组，路径改变时组内服务器随之移动
+func (m *Manager) UpdateServerGroup(path string, group *ServerGroup) error {
+	path = NormalizeGroupPath(path)
+	if _, exists := m.config.ServerGroups[path]; !exists {
+		return fmt.Errorf("分组 '%s' 不存在", path)
+	}
+
+	group.Path = NormalizeGroupPath(group.Path)
+	if group.Path == "" {
+		return fmt.Errorf("分组路径不能为空")
+	}
+	if group.Path != path {
+		if _, exists := m.config.ServerGroups[group.Path]; exists {
+			return fmt.Errorf("分组 '%s' 已存在", group.Path)
+		}
+		for _, server := range m.config.Servers {
+			if NormalizeGroupPath(server.Group) == path {
+				server.Group = group.Path
+			}
+		}
+		delete(m.config.ServerGroups, path)
+	}
+
+	group.UpdatedAt = time.Now()
+	m.config.ServerGroups[group.Path] = group
+	return m.Save()
+}
+
+// DeleteServerGroup 删除服务器分组的默认设置，组内服务器保留分组路径
+func (m *Manager) DeleteServerGroup(path string) error {
+	path = NormalizeGroupPath(path)
+	if _, exists := m.config.ServerGroups[path]; !exists {
+		return fmt.Errorf("分组 '%s' 不存在", path)
+	}
+
+	delete(m.config.ServerGroups, path)
+	return m.Save()
+}
+
+// GetServerGroup 获取服务器分组
+func (m *Manager) GetServerGroup(path string) (*ServerGroup, error) {
+	group, exists := m.config.ServerGroups[NormalizeGroupPath(path)]
+	if !exists {
+		return nil, fmt.Errorf("分组 '%s' 不存在", path)
+	}
+	return group, nil
+}
+
+// ListServerGroups 列出所有设置了默认值的服务器分组，按路径排序
+func (m *Manager) ListServerGroups() []*ServerGroup {
+	groups := make([]*ServerGroup, 0, len(m.config.ServerGroups))
+	for _, group := range m.config.ServerGroups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Path < groups[j].Path
+	})
+	return groups
+}
+
+// GroupPaths 列出所有分组路径（包括只被服务器引用的分组及其上级），按路径排序
+func (m *Manager) GroupPaths() []string {
+	seen := make(map[string]bool)
+	for path := range m.config.ServerGroups {
+		for _, p := range GroupAncestors(path) {
+			seen[p] = true
+		}
+	}
+	for _, server := range m.config.Servers {
+		for _, p := range GroupAncestors(server.Group) {
+			seen[p] = true
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// HasGroup 是否存在该分组（设置了默认值或有服务器属于该分组及其下级）
+func (m *Manager) HasGroup(path string) bool {
+	path = NormalizeGroupPath(path)
+	if path == "" {
+		return false
+	}
+	for _, p := range m.GroupPaths() {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupMembers 列出分组及其下级分组中的服务器
+func (m *Manager) GroupMembers(path string) []*ServerConfig {
+	var servers []*ServerConfig
+	for _, server := range m.ListServers() {
+		if InGroup(server, path) {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// ResolveServer 返回应用了分组默认值的服务器配置
+// 服务器自身设置的字段优先，其次是最近的分组，再次是上级分组；
+// 用户名和端口最后回退到全局设置。没有需要继承的字段时返回原配置
+func (m *Manager) ResolveServer(server *ServerConfig) *ServerConfig {
+	if m == nil || server == nil {
+		return server
+	}
+	if server.Group == "" && server.User != "" && server.Port != 0 && server.Credential == "" {
+		return server
+	}
+
+	resolved := *server
+	if resolved.CredentialID == "" && resolved.Credential != "" {
+		m.resolveCredentialAlias(&resolved, resolved.Credential)
+	}
+	ancestors := GroupAncestors(server.Group)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		group, exists := m.config.ServerGroups[ancestors[i]]
+		if !exists {
+			continue
+		}
+		if resolved.User == "" {
+			resolved.User = group.User
+		}
+		if resolved.Port == 0 {
+			resolved.Port = group.Port
+		}
+		if resolved.AuthType == "" && group.CredentialID != "" {
+			resolved.AuthType = AuthTypeCredential
+			resolved.CredentialID = group.CredentialID
+		}
+		if resolved.AuthType == "" && group.Credential != "" {
+			m.resolveCredentialAlias(&resolved, group.Credential)
+		}
+		if resolved.Proxy == nil && group.Proxy != nil {
+			proxy := *group.Proxy
+			resolved.Proxy = &proxy
+		}
+		if resolved.JumpHost == "" {
+			resolved.JumpHost = group.JumpHost
+		}
+		if resolved.StartupScript == "" {
+			resolved.StartupScript = group.StartupScript
+		}
+	}
+
+	// 回退到全局默认值
+	settings := m.config.Settings
+	if resolved.User == "" {
+		resolved.User = "root"
+		if settings != nil && settings.DefaultUser != "" {
+			resolved.User = settings.DefaultUser
+		}
+	}
+	if resolved.Port == 0 {
+		resolved.Port = 22
+		if settings != nil && settings.DefaultPort != 0 {
+			resolved.Port = settings.DefaultPort
+		}
+	}
+	return &resolved
+}
+
+// resolveCredentialAlias 按别名引用个人配置中的凭证，未找到时保持不变
+func (m *Manager) resolveCredentialAlias(server *ServerConfig, alias string) {
+	cred, err := m.GetCredentialByAlias(alias)
+	if err != nil {
+		return
+	}
+	server.CredentialID = cred.ID
+	if server.AuthType == "" {
+		server.AuthType = AuthTypeCredential
+	}
+}
+
+// FindServerRef 根据别名或ID查找服务器，用于跳板机等引用
+func (m *Manager) FindServerRef(ref string) (*ServerConfig, error) {
+	if server, err := m.GetServerByAlias(ref); err == nil {
+		return server, nil
+	}
+	if server, err := m.GetServer(ref); err == nil {
+		return server, nil
+	}
+	return nil, fmt.Errorf("服务器 '%s' 不存在", ref)
+}
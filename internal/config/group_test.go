@@ -0,0 +1,186 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGroupPath 测试分组路径规范化和上级路径
+func TestGroupPath(t *testing.T) {
+	assert.Equal(t, "prod/db", NormalizeGroupPath(" /prod// db/ "))
+	assert.Equal(t, "", NormalizeGroupPath(" / "))
+	assert.Equal(t, []string{"prod", "prod/db", "prod/db/primary"}, GroupAncestors("prod/db/primary"))
+	assert.Nil(t, GroupAncestors(""))
+
+	server := &ServerConfig{Group: "prod/db"}
+	assert.True(t, InGroup(server, "prod"))
+	assert.True(t, InGroup(server, "prod/db/"))
+	assert.False(t, InGroup(server, "pro"))
+	assert.False(t, InGroup(server, "prod/db/primary"))
+}
+
+// TestResolveServer 测试服务器继承分组默认值
+func TestResolveServer(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+
+	prod := NewServerGroup("prod")
+	prod.User = "deploy"
+	prod.Port = 2222
+	prod.CredentialID = "cred-prod"
+	prod.Proxy = &ProxyConfig{Type: "socks5", Host: "proxy", Port: 1080}
+	prod.StartupScript = "cd /srv"
+	require.NoError(t, manager.AddServerGroup(prod))
+
+	db := NewServerGroup("prod/db")
+	db.User = "postgres"
+	db.JumpHost = "bastion"
+	require.NoError(t, manager.AddServerGroup(db))
+
+	// 未设置的字段继承最近的分组，再继承上级分组
+	server := &ServerConfig{ID: "s1", Host: "10.0.0.1", Group: "prod/db"}
+	resolved := manager.ResolveServer(server)
+	assert.Equal(t, "postgres", resolved.User)
+	assert.Equal(t, 2222, resolved.Port)
+	assert.Equal(t, AuthTypeCredential, resolved.AuthType)
+	assert.Equal(t, "cred-prod", resolved.CredentialID)
+	assert.Equal(t, "socks5", resolved.Proxy.Type)
+	assert.Equal(t, "bastion", resolved.JumpHost)
+	assert.Equal(t, "cd /srv", resolved.StartupScript)
+	// 原配置不被修改
+	assert.Empty(t, server.User)
+	assert.Nil(t, server.Proxy)
+
+	// 服务器自身的设置优先
+	server = &ServerConfig{Host: "10.0.0.2", Group: "prod/db", User: "admin", Port: 22, AuthType: AuthTypePassword, JumpHost: "other"}
+	resolved = manager.ResolveServer(server)
+	assert.Equal(t, "admin", resolved.User)
+	assert.Equal(t, 22, resolved.Port)
+	assert.Equal(t, AuthTypePassword, resolved.AuthType)
+	assert.Empty(t, resolved.CredentialID)
+	assert.Equal(t, "other", resolved.JumpHost)
+
+	// 没有分组默认值时回退到全局设置
+	resolved = manager.ResolveServer(&ServerConfig{Host: "10.0.0.3", Group: "staging"})
+	assert.Equal(t, "root", resolved.User)
+	assert.Equal(t, 22, resolved.Port)
+	assert.Empty(t, resolved.AuthType)
+
+	// 不需要继承时返回原配置
+	plain := NewServerConfig("10.0.0.4")
+	assert.Same(t, plain, manager.ResolveServer(plain))
+}
+
+// TestServerGroupOperations 测试服务器分组的增删改查
+func TestServerGroupOperations(t *testing.T) {
+	configPath := createTempConfigFile(t)
+	manager, err := NewManager(configPath)
+	require.NoError(t, err)
+
+	group := NewServerGroup("prod/web")
+	group.User = "www"
+	require.NoError(t, manager.AddServerGroup(group))
+	assert.Error(t, manager.AddServerGroup(NewServerGroup(" prod/web/ ")))
+	assert.Error(t, manager.AddServerGroup(NewServerGroup("/")))
+
+	for i, alias := range []string{"web-1", "web-2"} {
+		server := NewServerConfig("10.0.1.1")
+		server.Alias = alias
+		server.Port = 22 + i
+		server.User = ""
+		server.Group = "prod/web"
+		require.NoError(t, manager.AddServer(server))
+	}
+	other := NewServerConfig("10.0.2.1")
+	other.Alias = "db-1"
+	other.Group = "prod/db/"
+	require.NoError(t, manager.AddServer(other))
+	assert.Equal(t, "prod/db", other.Group)
+
+	// 继承后的地址相同的服务器视为重复
+	dup := NewServerConfig("10.0.1.1")
+	dup.User = "www"
+	assert.Error(t, manager.AddServer(dup))
+
+	assert.Equal(t, []string{"prod", "prod/db", "prod/web"}, manager.GroupPaths())
+	assert.True(t, manager.HasGroup("prod"))
+	assert.False(t, manager.HasGroup("staging"))
+	assert.Equal(t, []string{"db-1", "web-1", "web-2"}, serverAliases(manager.GroupMembers("prod")))
+
+	// 重命名分组，组内服务器随之移动
+	renamed := *group
+	renamed.Path = "prod/frontend"
+	require.NoError(t, manager.UpdateServerGroup("prod/web", &renamed))
+	_, err = manager.GetServerGroup("prod/web")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"web-1", "web-2"}, serverAliases(manager.GroupMembers("prod/frontend")))
+
+	// 重新加载后保持不变
+	reloaded, err := NewManager(configPath)
+	require.NoError(t, err)
+	loaded, err := reloaded.GetServerGroup("prod/frontend")
+	require.NoError(t, err)
+	assert.Equal(t, "www", loaded.User)
+	assert.Len(t, reloaded.ListServerGroups(), 1)
+
+	// 删除分组只删除默认设置
+	require.NoError(t, manager.DeleteServerGroup("prod/frontend"))
+	assert.Error(t, manager.DeleteServerGroup("prod/frontend"))
+	assert.Len(t, manager.GroupMembers("prod/frontend"), 2)
+}
+
+// TestFindServerByGroup 测试按分组查找服务器
+func TestFindServerByGroup(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+
+	for i, item := range []struct{ alias, group string }{
+		{"web-1", "prod/web"},
+		{"db-1", "prod/db"},
+		{"web-2", "staging/web"},
+	} {
+		server := NewServerConfig("10.0.0.1")
+		server.Alias = item.alias
+		server.Port = 22 + i
+		server.Group = item.group
+		require.NoError(t, manager.AddServer(server))
+	}
+
+	servers, err := manager.FindServer("prod")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db-1", "web-1"}, serverAliases(servers))
+
+	servers, err = manager.FindServer("staging/web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-2"}, serverAliases(servers))
+
+	servers, err = manager.FindServer("group:prod web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-1"}, serverAliases(servers))
+
+	// 分组名也参与模糊搜索
+	assert.Len(t, FilterServers(manager.ListServers(), "staging"), 1)
+}
+
+// TestFindServerRef 测试按别名或ID引用服务器
+func TestFindServerRef(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+
+	server := NewServerConfig("bastion.example.com")
+	server.Alias = "bastion"
+	require.NoError(t, manager.AddServer(server))
+
+	found, err := manager.FindServerRef("bastion")
+	require.NoError(t, err)
+	assert.Equal(t, server.ID, found.ID)
+
+	found, err = manager.FindServerRef(server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, server.ID, found.ID)
+
+	_, err = manager.FindServerRef("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// 连接历史参数
+const (
+	historyFile       = "history.jsonl" // 连接历史文件名（JSON行）
+	historyMaxEntries = 1000            // 最多保留的历史记录数
+)
+
+// HistoryEntry 一次连接的历史记录
+type HistoryEntry struct {
+	ServerID   string        `json:"server_id"`            // 服务器ID，直接连接时为临时ID
+	Server     string        `json:"server"`               // 服务器别名，未设置别名时为主机
+	User       string        `json:"user"`                 // 用户名
+	Host       string        `json:"host"`                 // 主机地址
+	Port       int           `json:"port"`                 // SSH端口
+	Credential string        `json:"credential,omitempty"` // 使用的凭证别名
+	Start      time.Time     `json:"start"`                // 连接开始时间
+	Duration   time.Duration `json:"duration"`             // 会话时长
+	ExitStatus int           `json:"exit_status"`          // Shell退出码，-1 表示异常结束
+	Error      string        `json:"error,omitempty"`      // 错误详情
+}
+
+// Address 连接地址的显示形式
+func (e HistoryEntry) Address() string {
+	return fmt.Sprintf("%s@%s:%d", e.User, e.Host, e.Port)
+}
+
+// historyPath 连接历史文件路径
+func (m *Manager) historyPath() string {
+	return filepath.Join(m.ConfigDir(), historyFile)
+}
+
+// RecordConnection 追加一条连接历史，超过保留数时丢弃最旧的记录
+func (m *Manager) RecordConnection(entry HistoryEntry) error {
+	entries, err := m.readHistory()
+	if err != nil {
+		return err
+	}
+
+	path := m.historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+
+	if len(entries) < historyMaxEntries {
+		return appendHistory(path, entry)
+	}
+
+	// 超出保留数，重写历史文件
+	entries = append(entries[len(entries)-historyMaxEntries+1:], entry)
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("写入连接历史失败: %w", err)
+	}
+	writer := bufio.NewWriter(f)
+	encoder := json.NewEncoder(writer)
+	for _, e := range entries {
+		if err := encoder.Encode(e); err != nil {
+			f.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("写入连接历史失败: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入连接历史失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入连接历史失败: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// appendHistory 以追加方式写入一条历史记录
+func appendHistory(path string, entry HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("打开连接历史失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入连接历史失败: %w", err)
+	}
+	return nil
+}
+
+// readHistory 按写入顺序读取全部历史记录，无法解析的行被忽略
+func (m *Manager) readHistory() ([]HistoryEntry, error) {
+	f, err := os.Open(m.historyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开连接历史失败: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取连接历史失败: %w", err)
+	}
+	return entries, nil
+}
+
+// ConnectionHistory 获取最近的连接历史，从新到旧排列，limit<=0 表示全部
+func (m *Manager) ConnectionHistory(limit int) ([]HistoryEntry, error) {
+	entries, err := m.readHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.After(entries[j].Start)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// frecencyWeight 根据连接距今的时间计算权重，越近权重越高
+func frecencyWeight(age time.Duration) float64 {
+	switch {
+	case age < 4*time.Hour:
+		return 100
+	case age < 24*time.Hour:
+		return 80
+	case age < 7*24*time.Hour:
+		return 60
+	case age < 30*24*time.Hour:
+		return 40
+	case age < 90*24*time.Hour:
+		return 20
+	default:
+		return 10
+	}
+}
+
+// Frecency 根据连接历史计算每个服务器的频率和新近度综合得分
+func Frecency(entries []HistoryEntry, now time.Time) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, entry := range entries {
+		if entry.ServerID == "" {
+			continue
+		}
+		scores[entry.ServerID] += frecencyWeight(now.Sub(entry.Start))
+	}
+	return scores
+}
+
+// SortServersByFrecency 按常用程度对服务器排序，得分相同时按别名和主机排序以保持稳定
+func (m *Manager) SortServersByFrecency(servers []*ServerConfig) {
+	entries, err := m.readHistory()
+	if err != nil {
+		m.Logger().Debug("读取连接历史失败", "error", err)
+	}
+	scores := Frecency(entries, time.Now())
+
+	sort.SliceStable(servers, func(i, j int) bool {
+		si, sj := scores[servers[i].ID], scores[servers[j].ID]
+		if si != sj {
+			return si > sj
+		}
+		return serverSortKey(servers[i]) < serverSortKey(servers[j])
+	})
+}
+
+// serverSortKey 服务器的默认排序键
+func serverSortKey(server *ServerConfig) string {
+	name := server.Alias
+	if name == "" {
+		name = server.Host
+	}
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%s", name, server.Host, server.Port, server.ID)
+}
+
+// LastConnected 获取每个服务器最近一次连接的时间
+func (m *Manager) LastConnected() map[string]time.Time {
+	entries, err := m.readHistory()
+	if err != nil {
+		m.Logger().Debug("读取连接历史失败", "error", err)
+	}
+
+	last := make(map[string]time.Time)
+	for _, entry := range entries {
+		if entry.Start.After(last[entry.ServerID]) {
+			last[entry.ServerID] = entry.Start
+		}
+	}
+	return last
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConnectionHistory 测试连接历史的记录和读取
+func TestConnectionHistory(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+
+	entries, err := manager.ConnectionHistory(0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	now := time.Now()
+	require.NoError(t, manager.RecordConnection(HistoryEntry{ServerID: "a", Server: "web", User: "root", Host: "10.0.0.1", Port: 22, Start: now.Add(-time.Hour), Duration: time.Minute}))
+	require.NoError(t, manager.RecordConnection(HistoryEntry{ServerID: "b", Server: "db", User: "admin", Host: "10.0.0.2", Port: 2222, Start: now, ExitStatus: 130, Credential: "ops"}))
+
+	entries, err = manager.ConnectionHistory(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "b", entries[0].ServerID)
+	assert.Equal(t, 130, entries[0].ExitStatus)
+	assert.Equal(t, "ops", entries[0].Credential)
+	assert.Equal(t, "admin@10.0.0.2:2222", entries[0].Address())
+	assert.Equal(t, time.Minute, entries[1].Duration)
+
+	entries, err = manager.ConnectionHistory(1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	info, err := os.Stat(manager.historyPath())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+// TestConnectionHistoryLimit 测试超出保留数时丢弃最旧的记录
+func TestConnectionHistoryLimit(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+
+	f, err := os.Create(manager.historyPath())
+	require.NoError(t, err)
+	encoder := json.NewEncoder(f)
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < historyMaxEntries; i++ {
+		require.NoError(t, encoder.Encode(HistoryEntry{ServerID: "old", Start: start.Add(time.Duration(i) * time.Second)}))
+	}
+	require.NoError(t, f.Close())
+
+	require.NoError(t, manager.RecordConnection(HistoryEntry{ServerID: "new", Start: time.Now()}))
+
+	entries, err := manager.ConnectionHistory(0)
+	require.NoError(t, err)
+	assert.Len(t, entries, historyMaxEntries)
+	assert.Equal(t, "new", entries[0].ServerID)
+	// 最旧的一条被丢弃
+	assert.Equal(t, start.Add(time.Second).Unix(), entries[len(entries)-1].Start.Unix())
+}
+
+// TestFrecency 测试频率和新近度得分
+func TestFrecency(t *testing.T) {
+	now := time.Now()
+	entries := []HistoryEntry{
+		// 很久以前频繁连接
+		{ServerID: "old", Start: now.Add(-200 * 24 * time.Hour)},
+		{ServerID: "old", Start: now.Add(-201 * 24 * time.Hour)},
+		{ServerID: "old", Start: now.Add(-202 * 24 * time.Hour)},
+		// 最近连接过一次
+		{ServerID: "recent", Start: now.Add(-time.Hour)},
+		// 直接连接的临时服务器不计分
+		{ServerID: "", Start: now},
+	}
+
+	scores := Frecency(entries, now)
+	assert.Equal(t, float64(30), scores["old"])
+	assert.Equal(t, float64(100), scores["recent"])
+	assert.NotContains(t, scores, "")
+}
+
+// TestSortServersByFrecency 测试按常用程度排序服务器和模糊查找
+func TestSortServersByFrecency(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+
+	var ids []string
+	for i, alias := range []string{"web-c", "web-a", "web-b"} {
+		server := NewServerConfig("10.0.0.1")
+		server.Alias = alias
+		server.Port = 2200 + i
+		require.NoError(t, manager.AddServer(server))
+		ids = append(ids, server.ID)
+	}
+
+	// 没有历史时按别名排序
+	servers, err := manager.FindServer("web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-a", "web-b", "web-c"}, serverAliases(servers))
+
+	// web-c 最常用，web-b 连接过一次
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, manager.RecordConnection(HistoryEntry{ServerID: ids[0], Start: now.Add(-time.Duration(i) * time.Hour)}))
+	}
+	require.NoError(t, manager.RecordConnection(HistoryEntry{ServerID: ids[2], Start: now}))
+
+	servers, err = manager.FindServer("web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-c", "web-b", "web-a"}, serverAliases(servers))
+
+	// 精确匹配主机的多个结果同样排序
+	servers, err = manager.FindServer("10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-c", "web-b", "web-a"}, serverAliases(servers))
+
+	last := manager.LastConnected()
+	assert.Equal(t, now.Unix(), last[ids[0]].Unix())
+	assert.NotContains(t, last, ids[1])
+}
+
+// serverAliases 提取服务器别名列表
+func serverAliases(servers []*ServerConfig) []string {
+	var aliases []string
+	for _, server := range servers {
+		aliases = append(aliases, server.Alias)
+	}
+	return aliases
+}
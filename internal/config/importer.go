@@ -0,0 +1,331 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportEntry 从其他工具导入的一台服务器
+type ImportEntry struct {
+	Name       string               // 在来源中的名称，如 Ansible 的主机名
+	Server     *ServerConfig        // 导入后的服务器配置，ID在导入时生成
+	KeyPath    string               // 私钥文件，导入时复用或创建密钥凭证
+	Credential *CredentialConfig    // 来源中保存的登录凭证，导入时复用相同的凭证或新建
+	Forwards   []*PortForwardConfig // 服务器的端口转发，导入时关联到服务器
+	JumpHost   string               // 跳板机，如 ops@bastion:2222，多级跳板机用逗号分隔
+	Conflict   string               // 跳过的原因，为空表示可以导入
+	Notes      []string             // 附加说明，如别名已存在时的新别名、不支持而被忽略的设置
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Group string   // 导入到的服务器分组
+	Tags  []string // 附加到每台服务器的标签
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Added       []*ServerConfig
+	Skipped     int
+	Credentials []*CredentialConfig  // 新建的凭证
+	JumpHosts   []*ServerConfig      // 为找不到的跳板机新建的服务器
+	Forwards    []*PortForwardConfig // 导入的端口转发
+}
+
+// AnsibleImportEntries 将 Ansible 清单中的主机转换为待导入的服务器，分组作为标签
+func AnsibleImportEntries(hosts []*AnsibleHost) []*ImportEntry {
+	entries := make([]*ImportEntry, 0, len(hosts))
+	for _, h := range hosts {
+		entry := &ImportEntry{Name: h.Name, JumpHost: h.ProxyJump()}
+		server, err := h.Server()
+		if err != nil {
+			server = &ServerConfig{Alias: h.Name, Host: h.Name}
+			entry.Conflict = err.Error()
+		}
+		entry.KeyPath = server.KeyPath
+		server.KeyPath = ""
+		server.AuthType = ""
+		entry.Server = server
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// PreviewImport 应用导入选项并检查冲突：与已有服务器或本次导入的其他服务器地址（user@host:port）相同的跳过，
+// 别名已被使用时追加序号
+func (m *Manager) PreviewImport(entries []*ImportEntry, opts ImportOptions) {
+	seen := make(map[string]bool)
+	aliases := make(map[string]bool)
+	aliasUsed := func(alias string) bool {
+		return aliases[alias] || m.aliasExists(alias)
+	}
+
+	for _, entry := range entries {
+		if entry.Conflict != "" {
+			continue
+		}
+		server := entry.Server
+		if opts.Group != "" {
+			// 来源中的文件夹作为导入分组下的子分组
+			server.Group = NormalizeGroupPath(opts.Group + groupSeparator + server.Group)
+		}
+		for _, tag := range opts.Tags {
+			if !slices.Contains(server.Tags, tag) {
+				server.Tags = append(server.Tags, tag)
+			}
+		}
+		resolved := m.ResolveServer(server)
+		if server.Group == "" {
+			// 不在分组中的服务器写明用户名和端口，与手动添加的服务器一致
+			server.User, server.Port = resolved.User, resolved.Port
+		}
+		if resolved.AuthType == "" && entry.KeyPath == "" && entry.Credential == nil {
+			// 没有可继承的认证方式时使用默认认证类型
+			server.AuthType = AuthType(m.config.Settings.DefaultAuthType)
+		}
+
+		key := fmt.Sprintf("%s@%s:%d", resolved.User, resolved.Host, resolved.Port)
+		switch {
+		case seen[key]:
+			entry.Conflict = "与本次导入的其他服务器重复"
+		case m.serverExists(resolved):
+			entry.Conflict = "服务器已存在"
+		}
+		seen[key] = true
+		if entry.Conflict != "" {
+			continue
+		}
+
+		if server.Alias != "" && aliasUsed(server.Alias) {
+			alias := uniqueAlias(server.Alias, aliasUsed)
+			entry.Notes = append(entry.Notes, fmt.Sprintf("别名 '%s' 已存在，改为 '%s'", server.Alias, alias))
+			server.Alias = alias
+		}
+		aliases[server.Alias] = true
+	}
+}
+
+// Import 导入 PreviewImport 检查过的服务器及其端口转发，跳过有冲突的：
+// 私钥和凭证复用已有的相同凭证或新建，跳板机匹配已保存或本次导入的服务器，找不到时新建
+func (m *Manager) Import(entries []*ImportEntry) (*ImportResult, error) {
+	result := &ImportResult{}
+	var importing []*ImportEntry
+	for _, entry := range entries {
+		if entry.Conflict != "" {
+			result.Skipped++
+			continue
+		}
+		importing = append(importing, entry)
+	}
+	if len(importing) == 0 {
+		return result, nil
+	}
+
+	// 凭证和服务器都加入配置后一次保存，只生成一个轮换备份
+	credentials := make(map[*ImportEntry]*CredentialConfig)
+	for _, entry := range importing {
+		want := entry.Credential
+		if want == nil && entry.KeyPath != "" {
+			want = keyCredential(entry.KeyPath)
+		}
+		if want == nil {
+			continue
+		}
+		cred, created, err := m.importCredential(want)
+		if err != nil {
+			m.discardImport(result)
+			return nil, err
+		}
+		credentials[entry] = cred
+		if created {
+			result.Credentials = append(result.Credentials, cred)
+		}
+	}
+
+	now := time.Now()
+	byName := make(map[string]*ServerConfig) // 本次导入的服务器，按来源中的名称和主机地址
+	for _, entry := range importing {
+		server := entry.Server
+		if cred := credentials[entry]; cred != nil {
+			server.AuthType = AuthTypeCredential
+			server.CredentialID = cred.ID
+		}
+		if server.ID == "" {
+			server.ID = generateID()
+		}
+		for m.config.Servers[server.ID] != nil {
+			server.ID = generateID()
+		}
+		server.Group = NormalizeGroupPath(server.Group)
+		server.CreatedAt = now
+		server.UpdatedAt = now
+		m.config.Servers[server.ID] = server
+		byName[entry.Name] = server
+		if byName[server.Host] == nil {
+			byName[server.Host] = server
+		}
+		result.Added = append(result.Added, server)
+
+		for _, pf := range entry.Forwards {
+			pf.ServerID = server.ID
+			if pf.ID == "" {
+				pf.ID = generateID()
+			}
+			for m.config.PortForwards[pf.ID] != nil {
+				pf.ID = generateID()
+			}
+			if _, err := m.GetPortForwardByAlias(pf.Alias); pf.Alias != "" && err == nil {
+				pf.Alias = uniqueAlias(pf.Alias, func(alias string) bool {
+					_, err := m.GetPortForwardByAlias(alias)
+					return err == nil
+				})
+			}
+			pf.CreatedAt = now
+			pf.UpdatedAt = now
+			m.config.PortForwards[pf.ID] = pf
+			result.Forwards = append(result.Forwards, pf)
+		}
+	}
+
+	for _, entry := range importing {
+		if entry.JumpHost != "" {
+			entry.Server.JumpHost = m.importJumpHost(entry.JumpHost, byName, result)
+		}
+	}
+	if err := m.Save(); err != nil {
+		m.discardImport(result)
+		return nil, err
+	}
+	return result, nil
+}
+
+// discardImport 保存失败时从内存中撤销本次导入新建的条目，导入只新建条目，不修改已有条目
+func (m *Manager) discardImport(result *ImportResult) {
+	for _, cred := range result.Credentials {
+		delete(m.config.Credentials, cred.ID)
+	}
+	for _, server := range append(result.Added, result.JumpHosts...) {
+		delete(m.config.Servers, server.ID)
+	}
+	for _, pf := range result.Forwards {
+		delete(m.config.PortForwards, pf.ID)
+	}
+}
+
+// keyCredential 私钥文件对应的密钥凭证，以文件名为别名
+func keyCredential(keyPath string) *CredentialConfig {
+	cred := NewCredentialConfig()
+	cred.Alias = strings.TrimSuffix(filepath.Base(keyPath), filepath.Ext(keyPath))
+	cred.Type = CredentialTypeKey
+	cred.KeyPath = keyPath
+	return cred
+}
+
+// importCredential 复用类型、用户名、密码和密钥都相同的凭证，没有时新建（只加入配置，由 Import 统一保存），
+// 别名已被使用时追加序号。用户名不同的凭证不能复用，因为凭证的用户名会覆盖服务器的用户名
+func (m *Manager) importCredential(want *CredentialConfig) (*CredentialConfig, bool, error) {
+	for _, cred := range m.ListCredentials() {
+		if cred.Type == want.Type && cred.Username == want.Username && cred.Password == want.Password &&
+			expandHome(cred.KeyPath) == expandHome(want.KeyPath) && cred.KeyContent == want.KeyContent {
+			return cred, false, nil
+		}
+	}
+
+	cred := *want
+	if cred.Alias == "" {
+		cred.Alias = cred.Username
+	}
+	if cred.Alias == "" {
+		cred.Alias = "imported"
+	}
+	exists := func(alias string) bool {
+		_, err := m.GetCredentialByAlias(alias)
+		return err == nil
+	}
+	if exists(cred.Alias) {
+		cred.Alias = uniqueAlias(cred.Alias, exists)
+	}
+	if cred.Description == "" {
+		cred.Description = "导入服务器时创建"
+	}
+	if err := m.addCredential(&cred); err != nil {
+		return nil, false, fmt.Errorf("创建凭证 '%s' 失败: %w", cred.Alias, err)
+	}
+	return &cred, true, nil
+}
+
+// importJumpHost 将跳板机解析为服务器引用（别名或ID）。多级跳板机逐级解析，
+// 新建的跳板机经上一级跳板机连接
+func (m *Manager) importJumpHost(spec string, byName map[string]*ServerConfig, result *ImportResult) string {
+	ref := ""
+	for _, hop := range strings.Split(spec, ",") {
+		user, host, port := parseJumpSpec(hop)
+		if host == "" {
+			continue
+		}
+		server := byName[host]
+		if server == nil {
+			server = m.findJumpServer(user, host, port)
+		}
+		if server == nil {
+			server = NewServerConfig(host)
+			server.User = m.config.Settings.DefaultUser
+			if user != "" {
+				server.User = user
+			}
+			server.Port = m.config.Settings.DefaultPort
+			if port != 0 {
+				server.Port = port
+			}
+			server.AuthType = AuthType(m.config.Settings.DefaultAuthType)
+			server.Alias = host
+			if m.aliasExists(host) {
+				server.Alias = m.uniqueServerAlias(host)
+			}
+			server.JumpHost = ref
+			server.Description = "导入服务器时创建的跳板机"
+			m.config.Servers[server.ID] = server
+			byName[host] = server
+			result.JumpHosts = append(result.JumpHosts, server)
+		}
+		ref = server.ID
+		if server.Alias != "" {
+			ref = server.Alias
+		}
+	}
+	return ref
+}
+
+// findJumpServer 按别名或地址查找已保存的服务器，未指定的用户名和端口不参与比较
+func (m *Manager) findJumpServer(user, host string, port int) *ServerConfig {
+	if server, err := m.GetServerByAlias(host); err == nil {
+		return server
+	}
+	for _, server := range m.ListServers() {
+		resolved := m.ResolveServer(server)
+		if resolved.Host == host && (user == "" || resolved.User == user) && (port == 0 || resolved.Port == port) {
+			return server
+		}
+	}
+	return nil
+}
+
+// parseJumpSpec 解析 [user@]host[:port] 形式的跳板机
+func parseJumpSpec(spec string) (user, host string, port int) {
+	spec = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(spec), "ssh://"))
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		user, spec = spec[:i], spec[i+1:]
+	}
+	host = spec
+	if h, p, err := net.SplitHostPort(spec); err == nil {
+		if n, err := strconv.Atoi(p); err == nil {
+			host, port = h, n
+		}
+	}
+	return user, host, port
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreviewImport 测试导入预览的去重和别名冲突
+func TestPreviewImport(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+	existing := NewServerConfig("10.0.0.1")
+	existing.Alias = "web-1"
+	existing.User = "ubuntu"
+	require.NoError(t, manager.AddServer(existing))
+	other := NewServerConfig("10.0.0.5")
+	other.Alias = "web-2"
+	require.NoError(t, manager.AddServer(other))
+
+	hosts, err := ParseAnsibleInventory([]byte(ansibleListOutput))
+	require.NoError(t, err)
+	entries := AnsibleImportEntries(hosts)
+	manager.PreviewImport(entries, ImportOptions{Tags: []string{"ansible", "web"}})
+
+	byName := make(map[string]*ImportEntry)
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+	assert.Equal(t, "服务器已存在", byName["web-1"].Conflict)
+	assert.Empty(t, byName["web-2"].Conflict)
+	assert.Equal(t, "web-2-2", byName["web-2"].Server.Alias)
+	assert.Equal(t, []string{"别名 'web-2' 已存在，改为 'web-2-2'"}, byName["web-2"].Notes)
+	assert.Equal(t, []string{"prod", "web", "ansible"}, byName["web-2"].Server.Tags)
+
+	// 未设置的用户名、端口和认证方式使用默认值，有私钥的服务器导入时使用凭证
+	bastion := byName["bastion"].Server
+	assert.Equal(t, "root", bastion.User)
+	assert.Equal(t, 22, bastion.Port)
+	assert.Equal(t, AuthTypeAsk, bastion.AuthType)
+	assert.Equal(t, "~/.ssh/db", byName["db-1"].KeyPath)
+	assert.Empty(t, byName["db-1"].Server.AuthType)
+	assert.Empty(t, byName["db-1"].Server.KeyPath)
+
+	// 本次导入中重复的服务器
+	duplicate := []*ImportEntry{
+		{Name: "a", Server: &ServerConfig{Alias: "a", Host: "10.9.0.1"}},
+		{Name: "b", Server: &ServerConfig{Alias: "b", Host: "10.9.0.1"}},
+	}
+	manager.PreviewImport(duplicate, ImportOptions{Group: "/imported/"})
+	assert.Empty(t, duplicate[0].Conflict)
+	assert.Equal(t, "imported", duplicate[0].Server.Group)
+	assert.Equal(t, 0, duplicate[0].Server.Port, "分组中的服务器继承分组的默认设置")
+	assert.Contains(t, duplicate[1].Conflict, "重复")
+}
+
+// TestImport 测试导入服务器时创建密钥凭证和跳板机
+func TestImport(t *testing.T) {
+	path := createTempConfigFile(t)
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	cred := NewCredentialConfig()
+	cred.Alias = "deploy"
+	cred.Type = CredentialTypeKey
+	cred.KeyPath = "~/.ssh/deploy.pem"
+	require.NoError(t, manager.AddCredential(cred))
+	taken := NewCredentialConfig()
+	taken.Alias = "db"
+	taken.Password = "secret"
+	require.NoError(t, manager.AddCredential(taken))
+	jump := NewServerConfig("10.0.0.254")
+	jump.Alias = "gateway"
+	require.NoError(t, manager.AddServer(jump))
+
+	hosts, err := ParseAnsibleInventory([]byte(`[web]
+web-1 ansible_host=10.0.0.1 ansible_ssh_private_key_file=~/.ssh/deploy.pem ansible_ssh_common_args="-J gateway"
+web-2 ansible_host=10.0.0.2 ansible_ssh_private_key_file=~/.ssh/db.key ansible_ssh_common_args="-J bastion"
+
+[db]
+db-1 ansible_host=10.0.1.1 ansible_ssh_private_key_file=~/.ssh/db.key ansible_ssh_common_args="-J ops@jump.example.com:2222,10.0.9.9"
+bastion ansible_host=192.168.1.1
+`))
+	require.NoError(t, err)
+	entries := AnsibleImportEntries(hosts)
+	manager.PreviewImport(entries, ImportOptions{})
+	result, err := manager.Import(entries)
+	require.NoError(t, err)
+	assert.Len(t, result.Added, 4)
+	assert.Zero(t, result.Skipped)
+
+	// 复用密钥文件相同的凭证，新建的凭证别名不与已有凭证冲突
+	require.Len(t, result.Credentials, 1)
+	assert.Equal(t, "db-2", result.Credentials[0].Alias)
+	assert.Equal(t, CredentialTypeKey, result.Credentials[0].Type)
+	web1, err := manager.GetServerByAlias("web-1")
+	require.NoError(t, err)
+	assert.Equal(t, AuthTypeCredential, web1.AuthType)
+	assert.Equal(t, cred.ID, web1.CredentialID)
+	assert.Equal(t, []string{"web"}, web1.Tags)
+
+	// 跳板机匹配已保存和本次导入的服务器，找不到时新建并经上一级跳板机连接
+	assert.Equal(t, "gateway", web1.JumpHost)
+	web2, err := manager.GetServerByAlias("web-2")
+	require.NoError(t, err)
+	assert.Equal(t, "bastion", web2.JumpHost)
+	require.Len(t, result.JumpHosts, 2)
+	outer, inner := result.JumpHosts[0], result.JumpHosts[1]
+	assert.Equal(t, "jump.example.com", outer.Alias)
+	assert.Equal(t, "ops", outer.User)
+	assert.Equal(t, 2222, outer.Port)
+	assert.Empty(t, outer.JumpHost)
+	assert.Equal(t, "10.0.9.9", inner.Alias)
+	assert.Equal(t, "jump.example.com", inner.JumpHost)
+	db1, err := manager.GetServerByAlias("db-1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.9.9", db1.JumpHost)
+
+	// 重新加载后跳板机引用有效
+	manager, err = NewManager(path)
+	require.NoError(t, err)
+	assert.Empty(t, manager.Problems())
+	assert.Len(t, manager.ListServers(), 7)
+	assert.Len(t, manager.ListCredentials(), 3)
+}
+
+// TestImportCredentialsAndForwards 测试导入会话中的凭证和端口转发
+func TestImportCredentialsAndForwards(t *testing.T) {
+	path := createTempConfigFile(t)
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	admin := NewCredentialConfig()
+	admin.Alias = "admin"
+	admin.Username = "admin"
+	admin.Password = "secret"
+	require.NoError(t, manager.AddCredential(admin))
+	server := NewServerConfig("10.8.0.1")
+	require.NoError(t, manager.AddServer(server))
+	pf := NewPortForwardConfig(server.ID)
+	pf.Alias = "web-1-8080"
+	pf.LocalPort = 18080
+	pf.RemotePort = 80
+	require.NoError(t, manager.AddPortForward(pf))
+
+	entries, err := ParseTermiusJSON([]byte(termiusJSON))
+	require.NoError(t, err)
+	manager.PreviewImport(entries, ImportOptions{Group: "termius"})
+	assert.Equal(t, "termius/prod/web", entries[0].Server.Group)
+	result, err := manager.Import(entries)
+	require.NoError(t, err)
+	assert.Len(t, result.Added, 3)
+	assert.Equal(t, 1, result.Skipped)
+
+	// 用户名和密码相同的凭证复用，新的身份创建凭证
+	require.Len(t, result.Credentials, 1)
+	assert.Equal(t, "deploy", result.Credentials[0].Alias)
+	db, err := manager.GetServerByAlias("db")
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, db.CredentialID)
+	bastion, err := manager.GetServerByAlias("bastion")
+	require.NoError(t, err)
+	assert.Equal(t, AuthTypePassword, bastion.AuthType)
+
+	// 端口转发关联到导入的服务器，别名冲突时追加序号
+	web, err := manager.GetServerByAlias("web-1")
+	require.NoError(t, err)
+	assert.Equal(t, "bastion", web.JumpHost)
+	require.Len(t, result.Forwards, 2)
+	assert.Equal(t, web.ID, result.Forwards[0].ServerID)
+	assert.Equal(t, "web-1-8080-2", result.Forwards[0].Alias)
+	assert.Len(t, manager.ListPortForwards(), 3)
+
+	manager, err = NewManager(path)
+	require.NoError(t, err)
+	assert.Empty(t, manager.Problems())
+}
+
+// TestImportSavesOnce 测试一次导入只保存一次，保存失败时撤销全部导入的条目
+func TestImportSavesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	hosts, err := ParseAnsibleInventory([]byte(`web-1 ansible_host=10.0.0.1 ansible_ssh_private_key_file=~/.ssh/web.pem
+db-1 ansible_host=10.0.1.1 ansible_ssh_private_key_file=~/.ssh/db.pem ansible_ssh_common_args="-J bastion.example.com"
+`))
+	require.NoError(t, err)
+	entries := AnsibleImportEntries(hosts)
+	manager.PreviewImport(entries, ImportOptions{})
+	result, err := manager.Import(entries)
+	require.NoError(t, err)
+	require.Len(t, result.Credentials, 2)
+
+	// 只生成一个轮换备份，内容为导入前的配置
+	backup, err := os.ReadFile(rotatedBackupPath(path, 1))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(backup))
+	assert.NoFileExists(t, rotatedBackupPath(path, 2))
+
+	t.Run("保存失败时撤销", func(t *testing.T) {
+		saved, err := os.ReadFile(path)
+		require.NoError(t, err)
+		servers, credentials := len(manager.ListServers()), len(manager.ListCredentials())
+
+		hosts, err := ParseAnsibleInventory([]byte(`app-1 ansible_host=10.0.2.1 ansible_ssh_private_key_file=~/.ssh/app.pem
+`))
+		require.NoError(t, err)
+		entries := AnsibleImportEntries(hosts)
+		manager.PreviewImport(entries, ImportOptions{})
+		entries[0].Server.Port = 70000
+		_, err = manager.Import(entries)
+		require.Error(t, err)
+
+		assert.Len(t, manager.ListServers(), servers)
+		assert.Len(t, manager.ListCredentials(), credentials)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(saved), string(data))
+		assert.NoFileExists(t, rotatedBackupPath(path, 2))
+	})
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inventoryStateFile 上次同步时各共享清单中的服务器，用于 inventory sync 报告变化
+const inventoryStateFile = "inventory-state.yaml"
+
+// InventorySync 同步一个共享清单的结果
+type InventorySync struct {
+	Path    string
+	Exists  bool
+	Servers int      // 清单中的服务器数量
+	Added   []string // 新增的服务器，如 "web (deploy@10.0.0.1:22)"
+	Removed []string // 删除的服务器
+	Changed []string // 别名或地址变化的服务器（变化后的形式）
+}
+
+// InventoryPaths 设置中的共享清单文件路径
+func (m *Manager) InventoryPaths() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, path := range m.config.Settings.Inventories {
+		path = m.inventoryPath(path)
+		if path != "" && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// inventoryPath 共享清单的绝对路径，相对路径相对于声明 inventories 的配置文件所在目录
+func (m *Manager) inventoryPath(path string) string {
+	path = expandHome(strings.TrimSpace(path))
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		base := filepath.Dir(m.configPath)
+		if l := m.Origin(settingsSection, "inventories"); l != nil && l.Path != "" {
+			base = filepath.Dir(l.Path)
+		}
+		path = filepath.Join(base, path)
+	}
+	return filepath.Clean(path)
+}
+
+// AddInventory 添加共享清单（绝对路径）并保存
+func (m *Manager) AddInventory(path string) error {
+	path = filepath.Clean(path)
+	for _, existing := range m.InventoryPaths() {
+		if existing == path {
+			return fmt.Errorf("共享清单 %s 已存在", path)
+		}
+	}
+	m.config.Settings.Inventories = append(m.config.Settings.Inventories, path)
+	return m.Save()
+}
+
+// RemoveInventory 移除共享清单（不删除文件）并保存
+func (m *Manager) RemoveInventory(path string) error {
+	path = filepath.Clean(path)
+	var kept []string
+	for _, entry := range m.config.Settings.Inventories {
+		if m.inventoryPath(entry) != path {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(m.config.Settings.Inventories) {
+		return fmt.Errorf("共享清单 %s 不存在", path)
+	}
+	m.config.Settings.Inventories = kept
+	return m.Save()
+}
+
+// InventoryLayers 已加载的共享清单
+func (m *Manager) InventoryLayers() []*Layer {
+	var layers []*Layer
+	for _, l := range m.layers {
+		if l.Kind == LayerInventory {
+			layers = append(layers, l)
+		}
+	}
+	return layers
+}
+
+// updateInventoryLayers 按设置中的共享清单更新配置层，共享清单位于用户配置之前，
+// 个人配置中相同ID的条目覆盖共享清单中的条目。返回共享清单是否有增减
+func (m *Manager) updateInventoryLayers() bool {
+	existing := make(map[string]*Layer)
+	for _, l := range m.InventoryLayers() {
+		existing[l.Path] = l
+	}
+
+	var inventories []*Layer
+	changed := false
+	for _, path := range m.InventoryPaths() {
+		l := existing[path]
+		if l == nil {
+			l = &Layer{Kind: LayerInventory, Path: path, ReadOnly: true}
+			changed = true
+		}
+		delete(existing, path)
+		inventories = append(inventories, l)
+	}
+	if len(existing) > 0 {
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+
+	layers := make([]*Layer, 0, len(m.layers)+len(inventories))
+	for _, l := range m.layers {
+		if l.Kind == LayerInventory {
+			continue
+		}
+		if l == m.primary {
+			layers = append(layers, inventories...)
+		}
+		layers = append(layers, l)
+	}
+	m.layers = layers
+	return true
+}
+
+// Exists 配置层文件是否存在
+func (l *Layer) Exists() bool {
+	return l.data != nil
+}
+
+// Defines 配置层中是否定义了某个条目
+func (l *Layer) Defines(section, key string) bool {
+	s := findSection(section)
+	if s == nil || l.config == nil {
+		return false
+	}
+	_, ok := s.entries(l.config).get(key)
+	return ok
+}
+
+// loadInventories 按设置更新并重新读取所有共享清单
+func (m *Manager) loadInventories() error {
+	m.updateInventoryLayers()
+	for _, l := range m.InventoryLayers() {
+		if err := m.loadLayer(l); err != nil {
+			return err
+		}
+	}
+	return m.rebuild()
+}
+
+// checkInventory 共享清单只提供服务器、端口转发等条目：
+// 凭证应保存在个人配置中，设置由个人配置决定，动态清单会运行本地命令，只能在个人配置中定义
+func (l *Layer) checkInventory(state *layerState) {
+	if l.data == nil {
+		l.problems = append(l.problems, Problem{
+			Severity: SeverityWarning,
+			File:     l.Path,
+			Message:  fmt.Sprintf("共享清单 %s 不存在", l.Path),
+		})
+		return
+	}
+	if n := len(state.config.Credentials); n > 0 {
+		l.problems = append(l.problems, Problem{
+			Severity: SeverityWarning,
+			Path:     []string{"credentials"},
+			File:     l.Path,
+			Message:  fmt.Sprintf("共享清单中的 %d 个凭证被忽略，凭证应保存在个人配置中，由服务器的 credential 按别名引用", n),
+		})
+	}
+	if n := len(state.config.InventoryProviders); n > 0 {
+		l.problems = append(l.problems, Problem{
+			Severity: SeverityWarning,
+			Path:     []string{providersSection},
+			File:     l.Path,
+			Message:  fmt.Sprintf("共享清单中的 %d 个动态清单被忽略，动态清单会运行本地命令，应在个人配置中定义", n),
+		})
+	}
+	stripInventory(state)
+}
+
+// checkProviders 系统配置和项目配置中运行命令的动态清单不生效：项目配置在当前目录向上查找，
+// 进入他人的仓库时其中的命令会在每次运行 gotssh 时执行。读取文件的动态清单不受影响。
+// 这些定义保留在配置层中（修改项目配置时不会被删除），合并时跳过
+func (l *Layer) checkProviders(state layerState) {
+	for _, name := range sortedKeys(state.config.InventoryProviders) {
+		if !runsCommand(state.config.InventoryProviders[name]) {
+			continue
+		}
+		l.problems = append(l.problems, Problem{
+			Severity: SeverityWarning,
+			Path:     []string{providersSection, name},
+			File:     l.Path,
+			Message:  fmt.Sprintf("%s中的动态清单 '%s' 被忽略，运行命令的动态清单只能在个人配置中定义", l.Kind, name),
+		})
+	}
+}
+
+// runsCommand 条目是否为运行本地命令的动态清单
+func runsCommand(value interface{}) bool {
+	p, ok := value.(*InventoryProvider)
+	return ok && p != nil && p.Command != ""
+}
+
+// stripInventory 去掉共享清单中的凭证、动态清单和设置
+func stripInventory(state *layerState) {
+	state.config.Credentials = make(map[string]*CredentialConfig)
+	state.config.InventoryProviders = make(map[string]*InventoryProvider)
+	state.settings = make(map[string]interface{})
+}
+
+// inventoryProblems 读取系统配置、项目配置、共享清单和动态清单时发现的问题，以及多个共享清单定义了相同条目的冲突
+func (m *Manager) inventoryProblems() []Problem {
+	var problems []Problem
+	for _, l := range m.layers {
+		if l.Kind == LayerDynamic || l.Kind == LayerSystem || l.Kind == LayerProject {
+			problems = append(problems, l.problems...)
+		}
+	}
+	defined := make(map[entryRef]*Layer)
+	for _, l := range m.InventoryLayers() {
+		problems = append(problems, l.problems...)
+		for _, s := range configSections {
+			for _, key := range s.entries(l.config).keys() {
+				ref := entryRef{Section: s.name, Key: key}
+				if previous := defined[ref]; previous != nil {
+					problems = append(problems, Problem{
+						Severity: SeverityWarning,
+						Path:     []string{s.name, key},
+						File:     l.Path,
+						Message:  fmt.Sprintf("同时定义在共享清单 %s 和 %s 中，使用后者", previous.Path, l.Path),
+					})
+				}
+				defined[ref] = l
+			}
+		}
+	}
+	return problems
+}
+
+// SyncInventories 重新读取共享清单，与上次同步时比较，报告新增、删除和变化的服务器
+func (m *Manager) SyncInventories() ([]InventorySync, error) {
+	if err := m.loadInventories(); err != nil {
+		return nil, err
+	}
+
+	statePath := filepath.Join(m.ConfigDir(), inventoryStateFile)
+	previous := make(map[string]map[string]string)
+	if data, err := os.ReadFile(statePath); err == nil {
+		if err := yaml.Unmarshal(data, &previous); err != nil {
+			return nil, fmt.Errorf("读取共享清单同步状态失败: %w", err)
+		}
+	}
+
+	state := make(map[string]map[string]string)
+	var results []InventorySync
+	for _, l := range m.InventoryLayers() {
+		current := make(map[string]string, len(l.config.Servers))
+		for id, server := range l.config.Servers {
+			current[id] = m.inventorySummary(server)
+		}
+		state[l.Path] = current
+
+		result := InventorySync{Path: l.Path, Exists: l.data != nil, Servers: len(current)}
+		before := previous[l.Path]
+		for _, id := range sortedKeys(current) {
+			summary, existed := before[id]
+			switch {
+			case !existed:
+				result.Added = append(result.Added, current[id])
+			case summary != current[id]:
+				result.Changed = append(result.Changed, current[id])
+			}
+		}
+		for _, id := range sortedKeys(before) {
+			if _, exists := current[id]; !exists {
+				result.Removed = append(result.Removed, before[id])
+			}
+		}
+		sort.Strings(result.Added)
+		sort.Strings(result.Removed)
+		sort.Strings(result.Changed)
+		results = append(results, result)
+	}
+
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("序列化共享清单同步状态失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		return nil, fmt.Errorf("创建配置目录失败: %w", err)
+	}
+	if err := writeFileAtomic(statePath, data, 0600); err != nil {
+		return nil, fmt.Errorf("保存共享清单同步状态失败: %w", err)
+	}
+	return results, nil
+}
+
+// inventorySummary 共享清单中服务器的显示形式，如 "web (deploy@10.0.0.1:22)"
+func (m *Manager) inventorySummary(server *ServerConfig) string {
+	resolved := m.ResolveServer(server)
+	address := resolved.User + "@" + resolved.Host + ":" + strconv.Itoa(resolved.Port)
+	if server.Alias == "" {
+		return address
+	}
+	return server.Alias + " (" + address + ")"
+}
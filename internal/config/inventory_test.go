@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const teamInventory = `servers:
+  web1:
+    alias: web1
+    host: 10.1.0.1
+    user: deploy
+    credential: ops
+  web2:
+    alias: web2
+    host: 10.1.0.2
+    port: 2222
+credentials:
+  leaked:
+    alias: ops
+    type: password
+    password: team-secret
+settings:
+  default_user: team
+`
+
+// newInventoryManager 创建引用共享清单的配置管理器，个人配置中有别名为 ops 的凭证
+func newInventoryManager(t *testing.T, inventories ...string) (*Manager, string) {
+	path := createTempConfigFile(t)
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+
+	cred := NewCredentialConfig()
+	cred.Alias = "ops"
+	cred.Type = CredentialTypePassword
+	cred.Password = "mine"
+	require.NoError(t, manager.AddCredential(cred))
+	manager.GetConfig().Settings.Inventories = inventories
+	require.NoError(t, manager.Save())
+
+	manager, err = NewManager(path)
+	require.NoError(t, err)
+	return manager, path
+}
+
+// writeInventory 写入共享清单文件
+func writeInventory(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// TestInventoryOverlay 测试共享清单与个人配置的合并
+func TestInventoryOverlay(t *testing.T) {
+	dir := t.TempDir()
+	team := writeInventory(t, dir, "team.yaml", teamInventory)
+	manager, path := newInventoryManager(t, team)
+
+	require.Len(t, manager.InventoryLayers(), 1)
+	assert.Len(t, manager.ListServers(), 2)
+	assert.Equal(t, LayerInventory, manager.Origin("servers", "web1").Kind)
+
+	// 清单中的凭证和设置被忽略，凭证别名从个人配置中解析
+	require.Len(t, manager.ListCredentials(), 1)
+	assert.Equal(t, "root", manager.GetConfig().Settings.DefaultUser)
+	web1, err := manager.GetServer("web1")
+	require.NoError(t, err)
+	resolved := manager.ResolveServer(web1)
+	assert.Equal(t, AuthTypeCredential, resolved.AuthType)
+	assert.Equal(t, manager.ListCredentials()[0].ID, resolved.CredentialID)
+	assert.Empty(t, web1.CredentialID, "解析不修改原配置")
+	assert.Contains(t, problemMessages(manager.Problems()), "共享清单中的 1 个凭证被忽略")
+
+	// 修改清单中的服务器保存到个人配置，清单文件不变
+	edited := *web1
+	edited.Description = "mine"
+	require.NoError(t, manager.UpdateServer("web1", &edited))
+	assert.Equal(t, LayerUser, manager.Origin("servers", "web1").Kind)
+	data, err := os.ReadFile(team)
+	require.NoError(t, err)
+	assert.Equal(t, teamInventory, string(data))
+
+	// 清单中的服务器不能删除
+	err = manager.DeleteServer("web2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "只读")
+
+	reloaded, err := NewManager(path)
+	require.NoError(t, err)
+	server, err := reloaded.GetServer("web1")
+	require.NoError(t, err)
+	assert.Equal(t, "mine", server.Description)
+	assert.Len(t, reloaded.ListServers(), 2)
+}
+
+// TestInventoryConflicts 测试多个共享清单定义相同条目和缺失的清单
+func TestInventoryConflicts(t *testing.T) {
+	dir := t.TempDir()
+	first := writeInventory(t, dir, "a.yaml", "servers:\n  web1:\n    host: 10.1.0.1\n")
+	second := writeInventory(t, dir, "b.yaml", "servers:\n  web1:\n    host: 10.2.0.1\n    credential: missing\n")
+	manager, _ := newInventoryManager(t, first, second, filepath.Join(dir, "missing.yaml"))
+
+	server, err := manager.GetServer("web1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.2.0.1", server.Host)
+	assert.Equal(t, second, manager.Origin("servers", "web1").Path)
+
+	messages := problemMessages(manager.Problems())
+	assert.Contains(t, messages, "同时定义在共享清单")
+	assert.Contains(t, messages, "missing.yaml 不存在")
+	assert.Contains(t, messages, "凭证别名 'missing' 不存在")
+	for _, p := range manager.Problems() {
+		if strings.Contains(p.Message, "同时定义") {
+			assert.Equal(t, second, p.File)
+			assert.Positive(t, p.Line)
+		}
+	}
+}
+
+// TestInventoryRelativePath 测试共享清单的相对路径相对于配置文件所在目录
+func TestInventoryRelativePath(t *testing.T) {
+	manager, path := newInventoryManager(t)
+	writeInventory(t, filepath.Dir(path), "team.yaml", teamInventory)
+
+	require.NoError(t, manager.AddInventory(filepath.Join(filepath.Dir(path), "team.yaml")))
+	assert.Error(t, manager.AddInventory(filepath.Join(filepath.Dir(path), "team.yaml")))
+
+	manager.GetConfig().Settings.Inventories = []string{"team.yaml"}
+	require.NoError(t, manager.Save())
+	reloaded, err := NewManager(path)
+	require.NoError(t, err)
+	assert.Len(t, reloaded.ListServers(), 2)
+
+	require.NoError(t, reloaded.RemoveInventory(filepath.Join(filepath.Dir(path), "team.yaml")))
+	assert.Empty(t, reloaded.GetConfig().Settings.Inventories)
+	_, err = reloaded.SyncInventories()
+	require.NoError(t, err)
+	assert.Empty(t, reloaded.ListServers())
+}
+
+// TestSyncInventories 测试同步时报告新增、删除和变化的服务器
+func TestSyncInventories(t *testing.T) {
+	dir := t.TempDir()
+	team := writeInventory(t, dir, "team.yaml", teamInventory)
+	manager, _ := newInventoryManager(t, team)
+
+	results, err := manager.SyncInventories()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Exists)
+	assert.Equal(t, 2, results[0].Servers)
+	assert.Equal(t, []string{"web1 (deploy@10.1.0.1:22)", "web2 (root@10.1.0.2:2222)"}, results[0].Added)
+
+	// 没有变化
+	results, err = manager.SyncInventories()
+	require.NoError(t, err)
+	assert.Empty(t, results[0].Added)
+	assert.Empty(t, results[0].Removed)
+
+	writeInventory(t, dir, "team.yaml", `servers:
+  web1:
+    alias: web1
+    host: 10.1.0.10
+    user: deploy
+  web3:
+    alias: web3
+    host: 10.1.0.3
+`)
+	results, err = manager.SyncInventories()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web3 (root@10.1.0.3:22)"}, results[0].Added)
+	assert.Equal(t, []string{"web2 (root@10.1.0.2:2222)"}, results[0].Removed)
+	assert.Equal(t, []string{"web1 (deploy@10.1.0.10:22)"}, results[0].Changed)
+	_, err = manager.GetServer("web3")
+	assert.NoError(t, err)
+	_, err = manager.GetServer("web2")
+	assert.Error(t, err)
+}
+
+// problemMessages 所有问题的描述，用于断言包含某个问题
+func problemMessages(problems []Problem) string {
+	var messages []string
+	for _, p := range problems {
+		messages = append(messages, p.Message)
+	}
+	return strings.Join(messages, "\n")
+}
@@ -0,0 +1,581 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LayerKind 配置层的种类
+type LayerKind string
+
+const (
+	LayerDefault   LayerKind = "default"   // 内置默认值
+	LayerSystem    LayerKind = "system"    // 系统配置，只读
+	LayerDynamic   LayerKind = "dynamic"   // 动态清单生成的服务器，只读，不保存
+	LayerInventory LayerKind = "inventory" // 团队共享清单，只读，只提供服务器、端口转发等条目
+	LayerUser      LayerKind = "user"      // 用户配置，新增的条目和修改的设置写入这一层
+	LayerProject   LayerKind = "project"   // 项目配置，从当前目录向上查找的 .gotssh.yaml
+	LayerEnv       LayerKind = "env"       // GOTSSH_* 环境变量，只覆盖设置
+)
+
+// String 配置层种类的显示名称
+func (k LayerKind) String() string {
+	switch k {
+	case LayerDefault:
+		return "默认值"
+	case LayerSystem:
+		return "系统配置"
+	case LayerDynamic:
+		return "动态清单"
+	case LayerInventory:
+		return "共享清单"
+	case LayerUser:
+		return "用户配置"
+	case LayerProject:
+		return "项目配置"
+	case LayerEnv:
+		return "环境变量"
+	}
+	return string(k)
+}
+
+// ProjectConfigName 项目配置文件名，从当前目录开始逐级向上查找
+const ProjectConfigName = ".gotssh.yaml"
+
+// EnvPrefix 覆盖设置的环境变量前缀，如 GOTSSH_LOG_LEVEL 覆盖 settings.log_level
+const EnvPrefix = "GOTSSH_"
+
+// settingsSection 设置在配置中的段名，用于记录设置字段的来源
+const settingsSection = "settings"
+
+// Layer 一个配置层。多个配置层按优先级从低到高合并：
+// 默认值 < 系统配置 < 动态清单 < 共享清单 < 用户配置 < 项目配置 < 环境变量，
+// 条目（服务器、端口转发、凭证等）按ID整体覆盖，设置按字段覆盖
+type Layer struct {
+	Kind     LayerKind
+	Path     string // 配置文件路径，默认值、动态清单和环境变量层为空
+	Name     string // 动态清单的名称
+	ReadOnly bool   // 修改过的条目写入用户配置，不能删除其中的条目
+
+	data      []byte    // 本进程最近一次读取或写入的文件内容，文件不存在时为 nil
+	problems  []Problem // 读取时发现的问题，如共享清单不存在
+	fetchedAt time.Time // 动态清单的获取时间
+	layerState
+}
+
+// layerState 配置层的内容
+type layerState struct {
+	config   *Config                // 该层的条目，不使用其中的 Settings
+	settings map[string]interface{} // 该层设置了的设置字段
+}
+
+// String 配置层的显示形式，如 "用户配置 (/home/me/.config/gotssh/config.yaml)"
+func (l *Layer) String() string {
+	switch {
+	case l.Name != "":
+		return fmt.Sprintf("%s (%s)", l.Kind, l.Name)
+	case l.Path == "":
+		return l.Kind.String()
+	}
+	return fmt.Sprintf("%s (%s)", l.Kind, l.Path)
+}
+
+// SystemConfigPath 系统配置文件路径
+func SystemConfigPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "gotssh", "config.yaml")
+	}
+	return "/etc/gotssh/config.yaml"
+}
+
+// DefaultConfigPath 用户配置文件路径
+func DefaultConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "gotssh", "config.yaml"), nil
+}
+
+// FindProjectConfig 从 dir 开始逐级向上查找项目配置文件，未找到时返回空字符串
+func FindProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// defaultLayers 未指定配置文件时的配置层：系统、用户、项目配置和环境变量
+func defaultLayers(userPath string) []*Layer {
+	layers := []*Layer{
+		{Kind: LayerDefault},
+		{Kind: LayerSystem, Path: SystemConfigPath(), ReadOnly: true},
+		{Kind: LayerUser, Path: userPath},
+	}
+	if dir, err := os.Getwd(); err == nil {
+		if path := FindProjectConfig(dir); path != "" {
+			layers = append(layers, &Layer{Kind: LayerProject, Path: path})
+		}
+	}
+	return append(layers, &Layer{Kind: LayerEnv})
+}
+
+// singleFileLayers 指定配置文件时只使用该文件，环境变量仍然覆盖设置
+func singleFileLayers(path string) []*Layer {
+	return []*Layer{
+		{Kind: LayerDefault},
+		{Kind: LayerUser, Path: path},
+		{Kind: LayerEnv},
+	}
+}
+
+// parseLayer 解析配置层文件的内容，data 为 nil 表示文件不存在
+func parseLayer(data []byte) (layerState, error) {
+	state := layerState{config: NewConfig(), settings: make(map[string]interface{})}
+	if data == nil {
+		return state, nil
+	}
+
+	config, err := parseConfig(data)
+	if err != nil {
+		return state, err
+	}
+	var raw struct {
+		Settings map[string]interface{} `yaml:"settings"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return state, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	state.config = config
+	for key, value := range raw.Settings {
+		state.settings[key] = value
+	}
+	return state, nil
+}
+
+// parse 解析配置层文件的内容，共享清单只保留条目
+func (l *Layer) parse(data []byte) (layerState, error) {
+	state, err := parseLayer(data)
+	if err == nil && l.Kind == LayerInventory {
+		stripInventory(&state)
+	}
+	return state, err
+}
+
+// marshalLayer 序列化配置层，settings 中只写入该层设置了的字段
+func marshalLayer(state layerState) ([]byte, error) {
+	config := *state.config
+	config.ConfigVersion = CurrentConfigVersion
+	config.Settings = nil
+
+	var doc yaml.Node
+	if err := doc.Encode(&config); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != settingsSection {
+			continue
+		}
+		if len(state.settings) == 0 {
+			doc.Content = append(doc.Content[:i], doc.Content[i+2:]...)
+			break
+		}
+		if err := doc.Content[i+1].Encode(state.settings); err != nil {
+			return nil, fmt.Errorf("序列化配置失败: %w", err)
+		}
+		break
+	}
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	return data, nil
+}
+
+// settingsMap 将设置转换为字段名到值的映射
+func settingsMap(settings *Settings) map[string]interface{} {
+	values := make(map[string]interface{})
+	if data, err := yaml.Marshal(settings); err == nil {
+		yaml.Unmarshal(data, &values)
+	}
+	return values
+}
+
+// applySettings 由字段名到值的映射生成设置
+func applySettings(values map[string]interface{}) (*Settings, error) {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("序列化设置失败: %w", err)
+	}
+	settings := &Settings{}
+	if err := yaml.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("解析设置失败: %w", err)
+	}
+	return settings, nil
+}
+
+// envSettings 读取覆盖设置的环境变量，变量名为前缀加大写的字段名，如 GOTSSH_DEFAULT_PORT
+func envSettings() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	t := reflect.TypeOf(Settings{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		name := EnvPrefix + strings.ToUpper(key)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Slice:
+			values[key] = filepath.SplitList(raw)
+		case reflect.Int:
+			n, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("环境变量 %s 的值 '%s' 不是有效的整数", name, raw)
+			}
+			values[key] = n
+		default:
+			values[key] = raw
+		}
+	}
+	return values, nil
+}
+
+// entryRef 配置中的一个条目或设置字段
+type entryRef struct {
+	Section string
+	Key     string
+}
+
+// entryMap 一段配置条目，统一处理类型不同的各段
+type entryMap interface {
+	keys() []string
+	get(key string) (interface{}, bool)
+	set(key string, value interface{})
+	remove(key string)
+}
+
+// typedEntries 将某一段的条目映射适配为 entryMap，与原映射共享数据
+type typedEntries[V any] map[string]V
+
+func (e typedEntries[V]) keys() []string {
+	return sortedKeys(e)
+}
+
+func (e typedEntries[V]) get(key string) (interface{}, bool) {
+	value, ok := e[key]
+	return value, ok
+}
+
+func (e typedEntries[V]) set(key string, value interface{}) {
+	e[key] = value.(V)
+}
+
+func (e typedEntries[V]) remove(key string) {
+	delete(e, key)
+}
+
+// configSection 配置中按条目合并的一段
+type configSection struct {
+	name    string
+	entries func(c *Config) entryMap
+}
+
+// configSections 按条目合并的各段配置
+var configSections = []configSection{
+	{"servers", func(c *Config) entryMap { return typedEntries[*ServerConfig](c.Servers) }},
+	{"server_groups", func(c *Config) entryMap { return typedEntries[*ServerGroup](c.ServerGroups) }},
+	{"server_templates", func(c *Config) entryMap { return typedEntries[*ServerTemplate](c.ServerTemplates) }},
+	{"port_forwards", func(c *Config) entryMap { return typedEntries[*PortForwardConfig](c.PortForwards) }},
+	{"forward_groups", func(c *Config) entryMap { return typedEntries[*ForwardGroup](c.ForwardGroups) }},
+	{"credentials", func(c *Config) entryMap { return typedEntries[*CredentialConfig](c.Credentials) }},
+	{"inventory_providers", func(c *Config) entryMap { return typedEntries[*InventoryProvider](c.InventoryProviders) }},
+}
+
+// findSection 按段名查找配置段
+func findSection(name string) *configSection {
+	for i := range configSections {
+		if configSections[i].name == name {
+			return &configSections[i]
+		}
+	}
+	return nil
+}
+
+// Layers 按优先级从低到高排列的配置层
+func (m *Manager) Layers() []*Layer {
+	return m.layers
+}
+
+// Origin 配置条目或设置字段来自哪个配置层，section 为 servers、credentials、settings 等，
+// 不存在时返回 nil
+func (m *Manager) Origin(section, key string) *Layer {
+	return m.origins[entryRef{Section: section, Key: key}]
+}
+
+// loadLayer 读取配置层的内容，用户配置需要升级时备份并写回
+func (m *Manager) loadLayer(l *Layer) error {
+	switch l.Kind {
+	case LayerDefault:
+		l.layerState = layerState{config: NewConfig(), settings: settingsMap(NewConfig().Settings)}
+		return nil
+	case LayerEnv:
+		settings, err := envSettings()
+		if err != nil {
+			return err
+		}
+		l.layerState = layerState{config: NewConfig(), settings: settings}
+		return nil
+	case LayerDynamic:
+		m.loadDynamic(l, false)
+		return nil
+	}
+
+	data, err := os.ReadFile(l.Path)
+	if os.IsNotExist(err) {
+		data, err = nil, nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取%s失败: %w", l, err)
+	}
+	state, err := parseLayer(data)
+	if err != nil {
+		if l != m.primary {
+			return fmt.Errorf("%s: %w", l, err)
+		}
+		return err
+	}
+	l.data = data
+	l.problems = nil
+	if l.Kind == LayerInventory {
+		l.checkInventory(&state)
+	} else if l != m.primary {
+		l.checkProviders(state)
+	}
+	l.layerState = state
+	return nil
+}
+
+// rebuild 按优先级合并各配置层，得到生效的配置并记录每个条目和设置字段的来源
+// 生效配置中的映射和设置原地更新，已获取的配置对象保持有效
+func (m *Manager) rebuild() error {
+	values := make(map[string]interface{})
+	origins := make(map[entryRef]*Layer)
+	for _, s := range configSections {
+		effective := s.entries(m.config)
+		for _, key := range effective.keys() {
+			effective.remove(key)
+		}
+	}
+
+	for _, l := range m.layers {
+		for _, s := range configSections {
+			entries, effective := s.entries(l.config), s.entries(m.config)
+			for _, key := range entries.keys() {
+				value, _ := entries.get(key)
+				if s.name == providersSection && l != m.primary && runsCommand(value) {
+					// 运行命令的动态清单只使用用户配置中的定义，见 checkProviders
+					continue
+				}
+				effective.set(key, value)
+				origins[entryRef{Section: s.name, Key: key}] = l
+			}
+		}
+		for key, value := range l.settings {
+			values[key] = value
+			origins[entryRef{Section: settingsSection, Key: key}] = l
+		}
+	}
+
+	settings, err := applySettings(values)
+	if err != nil {
+		return err
+	}
+	if m.config.Settings == nil {
+		m.config.Settings = settings
+	} else {
+		*m.config.Settings = *settings
+	}
+	m.config.ConfigVersion = CurrentConfigVersion
+	m.origins = origins
+	m.settingsBase = settingsMap(m.config.Settings)
+	return nil
+}
+
+// split 将生效的配置拆分回各配置层：未修改的条目留在原来的层，
+// 修改过的条目写回其来源层（来源层只读时写入用户配置），新增的条目和修改的设置写入用户配置。
+// 返回各层拆分后的内容和上次读写时的内容
+func (m *Manager) split() (next, bases []layerState, err error) {
+	next = make([]layerState, len(m.layers))
+	bases = make([]layerState, len(m.layers))
+	index := make(map[*Layer]int, len(m.layers))
+	primary := 0
+	for i, l := range m.layers {
+		index[l] = i
+		if l == m.primary {
+			primary = i
+		}
+		if l.Path == "" {
+			next[i], bases[i] = l.layerState, l.layerState
+			continue
+		}
+		if bases[i], err = l.parse(l.data); err != nil {
+			return nil, nil, err
+		}
+		if next[i], err = l.parse(l.data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, s := range configSections {
+		effective := s.entries(m.config)
+		for _, key := range effective.keys() {
+			value, _ := effective.get(key)
+			target := primary
+			if owner := m.origins[entryRef{Section: s.name, Key: key}]; owner != nil {
+				i := index[owner]
+				if base, ok := s.entries(bases[i].config).get(key); ok && sameYAML(value, base) {
+					// 未修改的条目保留同一个对象
+					s.entries(next[i].config).set(key, value)
+					continue
+				}
+				if owner.Kind == LayerDynamic {
+					return nil, nil, fmt.Errorf("%s.%s 来自%s，不能修改", s.name, key, owner)
+				}
+				if !owner.ReadOnly {
+					target = i
+				}
+			}
+			s.entries(next[target].config).set(key, value)
+		}
+	}
+
+	for ref, owner := range m.origins {
+		s := findSection(ref.Section)
+		if s == nil {
+			continue
+		}
+		if _, ok := s.entries(m.config).get(ref.Key); ok {
+			continue
+		}
+		if owner.ReadOnly {
+			return nil, nil, fmt.Errorf("%s.%s 来自只读的%s，无法删除", ref.Section, ref.Key, owner)
+		}
+		s.entries(next[index[owner]].config).remove(ref.Key)
+	}
+
+	current := settingsMap(m.config.Settings)
+	for _, key := range changedSettings(m.settingsBase, current) {
+		value, ok := current[key]
+		switch {
+		case ok:
+			next[primary].settings[key] = value
+		case m.Origin(settingsSection, key) == m.primary:
+			delete(next[primary].settings, key)
+		default:
+			// 清除其他层设置的值
+			next[primary].settings[key] = nil
+		}
+	}
+	return next, bases, nil
+}
+
+// changedSettings 两份设置中取值不同的字段
+func changedSettings(before, after map[string]interface{}) []string {
+	var changed []string
+	for key, value := range after {
+		if !sameYAML(value, before[key]) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// ShowOptions config show 的输出选项
+type ShowOptions struct {
+	Origin  bool // 在每个条目和设置字段后注明来源
+	Secrets bool // 显示密码等敏感字段
+}
+
+// secretFields 默认隐藏取值的字段
+var secretFields = map[string]bool{
+	"password":       true,
+	"key_content":    true,
+	"key_passphrase": true,
+	"proxy_password": true,
+}
+
+// Show 生效配置的 YAML 形式
+func (m *Manager) Show(opts ShowOptions) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(m.config); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	if !opts.Secrets {
+		maskSecrets(&doc)
+	}
+
+	if opts.Origin {
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			section, entries := doc.Content[i].Value, doc.Content[i+1]
+			if entries.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(entries.Content); j += 2 {
+				if l := m.Origin(section, entries.Content[j].Value); l != nil {
+					entries.Content[j].LineComment = l.String()
+				}
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	return data, nil
+}
+
+// maskSecrets 将非空的敏感字段替换为星号
+func maskSecrets(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if secretFields[key.Value] && value.Kind == yaml.ScalarNode && value.Value != "" {
+				value.Value = "********"
+				value.Tag = "!!str"
+				value.Style = 0
+				continue
+			}
+			maskSecrets(value)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		maskSecrets(child)
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const systemLayerConfig = `config_version: 2
+servers:
+  shared:
+    id: shared
+    alias: bastion
+    host: 10.0.0.1
+    port: 22
+    user: ops
+    auth_type: ask
+settings:
+  connect_timeout: 60
+  default_user: ops
+`
+
+const projectLayerConfig = `config_version: 2
+servers:
+  app:
+    id: app
+    alias: app
+    host: 10.0.1.1
+    port: 22
+    user: deploy
+    auth_type: password
+    password: secret
+settings:
+  default_port: 2222
+`
+
+// newTestLayers 创建系统、用户、项目配置和环境变量四层配置
+func newTestLayers(t *testing.T) (system, user, project string, layers []*Layer) {
+	dir := t.TempDir()
+	system = filepath.Join(dir, "etc", "config.yaml")
+	user = filepath.Join(dir, "home", "config.yaml")
+	project = filepath.Join(dir, "project", ProjectConfigName)
+	for path, content := range map[string]string{system: systemLayerConfig, project: projectLayerConfig} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	layers = []*Layer{
+		{Kind: LayerDefault},
+		{Kind: LayerSystem, Path: system, ReadOnly: true},
+		{Kind: LayerUser, Path: user},
+		{Kind: LayerProject, Path: project},
+		{Kind: LayerEnv},
+	}
+	return system, user, project, layers
+}
+
+// TestLayeredConfig 测试多层配置的合并和来源
+func TestLayeredConfig(t *testing.T) {
+	t.Setenv("GOTSSH_LOG_LEVEL", "debug")
+	_, user, project, layers := newTestLayers(t)
+
+	manager, err := newLayeredManager(layers, false)
+	require.NoError(t, err)
+
+	settings := manager.GetConfig().Settings
+	assert.Equal(t, "debug", settings.LogLevel)
+	assert.Equal(t, 60, settings.ConnectTimeout)
+	assert.Equal(t, "ops", settings.DefaultUser)
+	assert.Equal(t, 2222, settings.DefaultPort)
+	assert.Equal(t, "ask", settings.DefaultAuthType)
+	assert.Len(t, manager.ListServers(), 2)
+
+	assert.Equal(t, LayerEnv, manager.Origin("settings", "log_level").Kind)
+	assert.Equal(t, LayerSystem, manager.Origin("settings", "connect_timeout").Kind)
+	assert.Equal(t, LayerProject, manager.Origin("settings", "default_port").Kind)
+	assert.Equal(t, LayerDefault, manager.Origin("settings", "default_auth_type").Kind)
+	assert.Equal(t, LayerSystem, manager.Origin("servers", "shared").Kind)
+	assert.Equal(t, project, manager.Origin("servers", "app").Path)
+	assert.Nil(t, manager.Origin("servers", "missing"))
+
+	// 用户配置只写入用户自己的设置，不写入其他层和环境变量的值
+	data, err := os.ReadFile(user)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "log_level")
+	assert.NotContains(t, string(data), "10.0.0.1")
+	assert.Equal(t, user, manager.ConfigPath())
+}
+
+// TestLayeredWrites 测试修改写回条目的来源层
+func TestLayeredWrites(t *testing.T) {
+	t.Setenv("GOTSSH_LOG_LEVEL", "debug")
+	system, user, project, layers := newTestLayers(t)
+	manager, err := newLayeredManager(layers, false)
+	require.NoError(t, err)
+
+	// 新增的服务器写入用户配置
+	require.NoError(t, manager.AddServer(NewServerConfig("10.0.2.1")))
+	userData, err := os.ReadFile(user)
+	require.NoError(t, err)
+	assert.Contains(t, string(userData), "10.0.2.1")
+
+	// 来自项目配置的服务器修改后写回项目配置，保留文件权限
+	app, err := manager.GetServerByAlias("app")
+	require.NoError(t, err)
+	edited := *app
+	edited.Description = "edited"
+	require.NoError(t, manager.UpdateServer("app", &edited))
+	projectData, err := os.ReadFile(project)
+	require.NoError(t, err)
+	assert.Contains(t, string(projectData), "edited")
+	assert.Contains(t, string(projectData), "default_port: 2222")
+	assert.NotContains(t, string(projectData), "10.0.2.1")
+	info, err := os.Stat(project)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// 来自只读系统配置的服务器修改后写入用户配置覆盖
+	shared, err := manager.GetServerByAlias("bastion")
+	require.NoError(t, err)
+	override := *shared
+	override.Port = 2200
+	require.NoError(t, manager.UpdateServer("shared", &override))
+	assert.Equal(t, LayerUser, manager.Origin("servers", "shared").Kind)
+	systemData, err := os.ReadFile(system)
+	require.NoError(t, err)
+	assert.Equal(t, systemLayerConfig, string(systemData))
+
+	// 修改的设置写入用户配置，环境变量的值不写入
+	manager.GetConfig().Settings.DefaultUser = "admin"
+	require.NoError(t, manager.Save())
+	userData, err = os.ReadFile(user)
+	require.NoError(t, err)
+	assert.Contains(t, string(userData), "default_user: admin")
+	assert.NotContains(t, string(userData), "log_level")
+
+	reloaded, err := newLayeredManager(newLayersLike(layers), false)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", reloaded.GetConfig().Settings.DefaultUser)
+	assert.Equal(t, 2222, reloaded.GetConfig().Settings.DefaultPort)
+	server, err := reloaded.GetServer("shared")
+	require.NoError(t, err)
+	assert.Equal(t, 2200, server.Port)
+	server, err = reloaded.GetServer("app")
+	require.NoError(t, err)
+	assert.Equal(t, "edited", server.Description)
+}
+
+// TestLayeredReadOnlyDelete 测试不能删除只读配置中的条目
+func TestLayeredReadOnlyDelete(t *testing.T) {
+	_, _, _, layers := newTestLayers(t)
+	manager, err := newLayeredManager(layers, false)
+	require.NoError(t, err)
+
+	err = manager.DeleteServer("shared")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "只读")
+	_, err = manager.GetServer("shared")
+	assert.NoError(t, err, "无法保存的删除应从内存中撤销")
+
+	// 项目配置中的条目可以删除
+	require.NoError(t, manager.DeleteServer("app"))
+	reloaded, err := newLayeredManager(newLayersLike(layers), false)
+	require.NoError(t, err)
+	_, err = reloaded.GetServer("app")
+	assert.Error(t, err)
+}
+
+// TestEnvSettings 测试环境变量覆盖设置
+func TestEnvSettings(t *testing.T) {
+	t.Setenv("GOTSSH_DEFAULT_PORT", "2022")
+	t.Setenv("GOTSSH_DEFAULT_USER", "env-user")
+	path := createTempConfigFile(t)
+
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2022, manager.GetConfig().Settings.DefaultPort)
+	assert.Equal(t, "env-user", manager.GetConfig().Settings.DefaultUser)
+	assert.Equal(t, LayerEnv, manager.Origin("settings", "default_port").Kind)
+
+	t.Setenv("GOTSSH_DEFAULT_PORT", "abc")
+	_, err = NewManager(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GOTSSH_DEFAULT_PORT")
+}
+
+// TestFindProjectConfig 测试从当前目录向上查找项目配置
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b", "c")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+	assert.Empty(t, FindProjectConfig(nested))
+
+	path := filepath.Join(root, "a", ProjectConfigName)
+	require.NoError(t, os.WriteFile(path, []byte("servers: {}\n"), 0644))
+	assert.Equal(t, path, FindProjectConfig(nested))
+	assert.Equal(t, path, FindProjectConfig(filepath.Join(root, "a")))
+	assert.Empty(t, FindProjectConfig(root))
+}
+
+// TestShow 测试显示生效配置
+func TestShow(t *testing.T) {
+	_, _, project, layers := newTestLayers(t)
+	manager, err := newLayeredManager(layers, false)
+	require.NoError(t, err)
+
+	data, err := manager.Show(ShowOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.NotContains(t, string(data), "# ")
+
+	data, err = manager.Show(ShowOptions{Origin: true, Secrets: true})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "secret")
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, "app:") {
+			assert.Contains(t, line, project)
+		}
+		if strings.Contains(line, "connect_timeout:") {
+			assert.Contains(t, line, LayerSystem.String())
+		}
+	}
+}
+
+// newLayersLike 创建与已有配置层路径相同的新配置层，模拟重新启动的进程
+func newLayersLike(layers []*Layer) []*Layer {
+	fresh := make([]*Layer, 0, len(layers))
+	for _, l := range layers {
+		fresh = append(fresh, &Layer{Kind: l.Kind, Path: l.Path, ReadOnly: l.ReadOnly})
+	}
+	return fresh
+}
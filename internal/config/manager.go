@@ -1,42 +1,86 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
-	"gopkg.in/yaml.v3"
+	"gotssh/internal/logging"
 )
 
 // Manager 配置管理器
+// 生效的配置由多个配置层合并而成（见 Layer），修改后按条目来源写回对应的配置文件
 type Manager struct {
-	configPath string
+	configPath string // 用户配置文件，新增的条目和修改的设置写入该文件
 	config     *Config
+	logger     *slog.Logger
+	problems   []Problem // 最近一次加载时发现的问题
+	lenient    bool      // 加载和保存时不因校验错误失败，用于 config doctor 修复配置
+
+	layers       []*Layer               // 按优先级从低到高排列的配置层
+	primary      *Layer                 // 用户配置层
+	origins      map[entryRef]*Layer    // 每个条目和设置字段的来源层
+	settingsBase map[string]interface{} // 最近一次合并各层得到的设置，用于找出本进程修改的设置
+	migratedFrom int                    // 加载时从哪个版本升级，0表示未升级
+	backupPath   string                 // 升级前的备份文件
 }
 
 // NewManager 创建新的配置管理器
+// configPath 为空时合并系统配置、用户配置、项目配置和环境变量；
+// 指定 configPath 时只使用该文件，环境变量仍然覆盖设置
 func NewManager(configPath string) (*Manager, error) {
-	if configPath == "" {
-		homeDir, err := os.UserHomeDir()
+	return newManager(configPath, false)
+}
+
+// NewLenientManager 创建不因校验错误拒绝加载和保存的配置管理器，用于检查和修复有问题的配置
+func NewLenientManager(configPath string) (*Manager, error) {
+	return newManager(configPath, true)
+}
+
+func newManager(configPath string, lenient bool) (*Manager, error) {
+	var layers []*Layer
+	if configPath != "" {
+		layers = singleFileLayers(configPath)
+	} else {
+		userPath, err := DefaultConfigPath()
 		if err != nil {
-			return nil, fmt.Errorf("获取用户主目录失败: %w", err)
+			return nil, err
 		}
-		configPath = filepath.Join(homeDir, ".config", "gotssh", "config.yaml")
+		layers = defaultLayers(userPath)
 	}
+	return newLayeredManager(layers, lenient)
+}
 
+// newLayeredManager 由配置层创建配置管理器，其中必须有且只有一个用户配置层
+func newLayeredManager(layers []*Layer, lenient bool) (*Manager, error) {
 	manager := &Manager{
-		configPath: configPath,
-		config:     NewConfig(),
+		config:  NewConfig(),
+		logger:  logging.Nop(),
+		lenient: lenient,
+		layers:  layers,
+	}
+	for _, l := range layers {
+		if l.Kind == LayerUser {
+			manager.primary = l
+			manager.configPath = l.Path
+		}
+	}
+	if manager.primary == nil {
+		return nil, fmt.Errorf("缺少用户配置层")
 	}
 
-	// 尝试加载现有配置
+	_, statErr := os.Stat(manager.configPath)
 	if err := manager.Load(); err != nil {
-		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("加载配置失败: %w", err)
-		}
-		// 配置文件不存在，创建默认配置
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	// 用户配置文件不存在，创建默认配置
+	if os.IsNotExist(statErr) {
 		if err := manager.Save(); err != nil {
 			return nil, fmt.Errorf("保存默认配置失败: %w", err)
 		}
@@ -45,23 +89,89 @@ func NewManager(configPath string) (*Manager, error) {
 	return manager, nil
 }
 
-// Load 从文件加载配置
+// Load 从各配置层加载配置
 func (m *Manager) Load() error {
-	data, err := os.ReadFile(m.configPath)
+	for _, l := range m.layers {
+		if err := m.loadLayer(l); err != nil {
+			return err
+		}
+	}
+	if err := m.rebuild(); err != nil {
+		return err
+	}
+	if m.updateInventoryLayers() {
+		if err := m.loadInventories(); err != nil {
+			return err
+		}
+	}
+	if m.updateDynamicLayers() {
+		if err := m.loadDynamicLayers(); err != nil {
+			return err
+		}
+	}
+
+	problems := append(Validate(m.config), m.inventoryProblems()...)
+	m.locate(problems)
+	m.problems = problems
+	errs := Errors(problems)
+	if len(errs) > 0 && !m.lenient {
+		return &ValidationError{File: m.configPath, Problems: errs}
+	}
+	if len(errs) == 0 {
+		if err := m.writeMigration(); err != nil {
+			return err
+		}
+	}
+
+	m.logger.Debug("已加载配置", "path", m.configPath, "layers", len(m.layers),
+		"servers", len(m.config.Servers), "port_forwards", len(m.config.PortForwards), "credentials", len(m.config.Credentials))
+	return nil
+}
+
+// writeMigration 用户配置是旧版本时备份原文件并写入升级后的配置
+// 旧版本的配置在解析时已在内存中升级，只在升级后的配置通过校验后写回，保留原文件的权限
+func (m *Manager) writeMigration() error {
+	original := m.primary.data
+	if original == nil {
+		return nil
+	}
+	migrated, version, err := migrateData(original)
+	if err != nil || version == CurrentConfigVersion {
+		return err
+	}
+
+	lock, err := m.lockConfig()
 	if err != nil {
 		return err
 	}
+	defer lock.Unlock()
+	// 其他进程已修改配置文件时不覆盖，下次加载时再升级
+	if current, err := os.ReadFile(m.configPath); err != nil || !bytes.Equal(current, original) {
+		return nil
+	}
 
-	config := NewConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return fmt.Errorf("解析配置文件失败: %w", err)
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(m.configPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+	backupPath, err := backupBeforeMigration(m.configPath, version, original)
+	if err != nil {
+		return err
 	}
+	if err := writeFileAtomic(m.configPath, migrated, perm); err != nil {
+		return fmt.Errorf("写入升级后的配置失败: %w（原配置已备份到 %s）", err, backupPath)
+	}
+	m.primary.data = migrated
 
-	m.config = config
+	m.migratedFrom = version
+	m.backupPath = backupPath
+	m.logger.Info("已升级配置文件", "path", m.configPath, "from", version, "to", CurrentConfigVersion, "backup", backupPath)
 	return nil
 }
 
 // Save 保存配置到文件
+// 按条目来源拆分到各配置层，在文件锁内先合并其他进程的修改，再原子替换有变化的配置文件，
+// 并轮换备份上一次的用户配置
 func (m *Manager) Save() error {
 	// 确保配置目录存在
 	configDir := filepath.Dir(m.configPath)
@@ -74,18 +184,124 @@ func (m *Manager) Save() error {
 		m.config.Settings.ConfigDir = configDir
 	}
 
-	data, err := yaml.Marshal(m.config)
+	next, bases, err := m.split()
 	if err != nil {
-		return fmt.Errorf("序列化配置失败: %w", err)
+		// 无法保存的修改（如删除只读配置中的条目）从内存中撤销
+		m.rebuild()
+		return err
 	}
 
-	if err := os.WriteFile(m.configPath, data, 0600); err != nil {
-		return fmt.Errorf("写入配置文件失败: %w", err)
+	// 锁定需要写入的配置文件，用户配置总是锁定
+	for i, l := range m.layers {
+		if l.Path == "" || l.ReadOnly {
+			continue
+		}
+		if l != m.primary {
+			data, err := marshalLayer(next[i])
+			if err != nil {
+				return err
+			}
+			if bytes.Equal(data, l.data) {
+				continue
+			}
+		}
+		lock, err := lockFile(l.Path)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
 	}
 
+	if _, err := m.mergeFromDisk(next, bases); err != nil {
+		return err
+	}
+	m.applyLayers(next)
+	if err := m.rebuild(); err != nil {
+		return err
+	}
+
+	if errs := Errors(Validate(m.config)); len(errs) > 0 && !m.lenient {
+		return &ValidationError{Problems: errs}
+	}
+
+	for _, l := range m.layers {
+		if l.Path == "" || l.ReadOnly {
+			continue
+		}
+		if err := m.writeLayer(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLayer 内容有变化时写入配置层文件
+func (m *Manager) writeLayer(l *Layer) error {
+	data, err := marshalLayer(l.layerState)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, l.data) {
+		return nil
+	}
+
+	perm := os.FileMode(0600)
+	if l != m.primary {
+		// 项目配置可能纳入版本管理，保留原有权限，不生成轮换备份
+		if info, err := os.Stat(l.Path); err == nil {
+			perm = info.Mode().Perm()
+		}
+	} else if err := rotateBackups(l.Path); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(l.Path, data, perm); err != nil {
+		return fmt.Errorf("写入%s失败: %w", l, err)
+	}
+	l.data = data
+
+	m.logger.Debug("已保存配置", "path", l.Path, "bytes", len(data))
 	return nil
 }
 
+// Problems 最近一次加载配置时发现的问题
+func (m *Manager) Problems() []Problem {
+	return m.problems
+}
+
+// Migrated 加载时是否升级了配置，返回升级前的版本和备份文件路径
+func (m *Manager) Migrated() (from int, backupPath string) {
+	return m.migratedFrom, m.backupPath
+}
+
+// ConfigPath 获取配置文件路径
+func (m *Manager) ConfigPath() string {
+	return m.configPath
+}
+
+// SetLogger 设置日志记录器，由配置管理器创建的SSH客户端和端口转发管理器沿用
+func (m *Manager) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = logging.Nop()
+	}
+	m.logger = logger
+}
+
+// Logger 获取日志记录器
+func (m *Manager) Logger() *slog.Logger {
+	if m == nil || m.logger == nil {
+		return logging.Nop()
+	}
+	return m.logger
+}
+
+// ConfigDir 获取配置目录，未设置 Settings.ConfigDir 时使用配置文件所在目录，~ 展开为用户主目录
+func (m *Manager) ConfigDir() string {
+	if m.config != nil && m.config.Settings.ConfigDir != "" {
+		return expandHome(m.config.Settings.ConfigDir)
+	}
+	return filepath.Dir(m.configPath)
+}
+
 // GetConfig 获取配置
 func (m *Manager) GetConfig() *Config {
 	return m.config
@@ -97,20 +313,16 @@ func (m *Manager) AddServer(server *ServerConfig) error {
 		server.ID = generateID()
 	}
 
-	// 检查是否已存在相同的主机+端口+用户组合
-	for _, existing := range m.config.Servers {
-		if existing.Host == server.Host && existing.Port == server.Port && existing.User == server.User {
-			return fmt.Errorf("服务器 %s@%s:%d 已存在", server.User, server.Host, server.Port)
-		}
+	server.Group = NormalizeGroupPath(server.Group)
+
+	// 检查是否已存在相同的主机+端口+用户组合（按继承分组默认值后的设置比较）
+	if candidate := m.ResolveServer(server); m.serverExists(candidate) {
+		return fmt.Errorf("服务器 %s@%s:%d 已存在", candidate.User, candidate.Host, candidate.Port)
 	}
 
 	// 检查别名是否唯一
-	if server.Alias != "" {
-		for _, existing := range m.config.Servers {
-			if existing.Alias == server.Alias {
-				return fmt.Errorf("别名 '%s' 已存在", server.Alias)
-			}
-		}
+	if server.Alias != "" && m.aliasExists(server.Alias) {
+		return fmt.Errorf("别名 '%s' 已存在", server.Alias)
 	}
 
 	now := time.Now()
@@ -137,6 +349,7 @@ func (m *Manager) UpdateServer(serverID string, server *ServerConfig) error {
 	}
 
 	server.ID = serverID
+	server.Group = NormalizeGroupPath(server.Group)
 	server.UpdatedAt = time.Now()
 
 	m.config.Servers[serverID] = server
@@ -149,15 +362,18 @@ func (m *Manager) DeleteServer(serverID string) error {
 		return fmt.Errorf("服务器 %s 不存在", serverID)
 	}
 
-	// 删除相关的端口转发配置
+	m.removeServer(serverID)
+	return m.Save()
+}
+
+// removeServer 删除服务器及相关的端口转发配置（不保存）
+func (m *Manager) removeServer(serverID string) {
 	for id, pf := range m.config.PortForwards {
 		if pf.ServerID == serverID {
-			delete(m.config.PortForwards, id)
+			m.removePortForward(id)
 		}
 	}
-
 	delete(m.config.Servers, serverID)
-	return m.Save()
 }
 
 // GetServer 获取服务器配置
@@ -192,10 +408,12 @@ func (m *Manager) GetServerByHost(host string) ([]*ServerConfig, error) {
 		return nil, fmt.Errorf("主机 '%s' 不存在", host)
 	}
 
+	SortServers(servers)
 	return servers, nil
 }
 
-// FindServer 查找服务器配置（支持IP、别名、模糊匹配）
+// FindServer 查找服务器配置（支持IP、别名、分组路径、模糊匹配）
+// 多个匹配结果按常用程度排序，最常连接的服务器排在最前
 func (m *Manager) FindServer(query string) ([]*ServerConfig, error) {
 	var servers []*ServerConfig
 
@@ -206,12 +424,30 @@ func (m *Manager) FindServer(query string) ([]*ServerConfig, error) {
 
 	// 精确匹配主机
 	if results, err := m.GetServerByHost(query); err == nil {
+		m.SortServersByFrecency(results)
 		return results, nil
 	}
 
+	// 分组路径，列出组内（含下级分组）的服务器
+	if m.HasGroup(query) {
+		servers = m.GroupMembers(query)
+		m.SortServersByFrecency(servers)
+		return servers, nil
+	}
+
+	// 包含标签或分组过滤（tag:xxx、group:xxx）时按搜索条件匹配
+	if ParseSearchQuery(query).HasFacets() {
+		servers = FilterServers(m.ListServers(), query)
+		if len(servers) == 0 {
+			return nil, fmt.Errorf("未找到匹配的服务器: %s", query)
+		}
+		m.SortServersByFrecency(servers)
+		return servers, nil
+	}
+
 	// 模糊匹配
 	query = strings.ToLower(query)
-	for _, server := range m.config.Servers {
+	for _, server := range m.ListServers() {
 		if strings.Contains(strings.ToLower(server.Host), query) ||
 			strings.Contains(strings.ToLower(server.Alias), query) ||
 			strings.Contains(strings.ToLower(server.Description), query) {
@@ -223,15 +459,17 @@ func (m *Manager) FindServer(query string) ([]*ServerConfig, error) {
 		return nil, fmt.Errorf("未找到匹配的服务器: %s", query)
 	}
 
+	m.SortServersByFrecency(servers)
 	return servers, nil
 }
 
-// ListServers 列出所有服务器
+// ListServers 列出所有服务器，按别名和主机排序
 func (m *Manager) ListServers() []*ServerConfig {
 	servers := make([]*ServerConfig, 0)
 	for _, server := range m.config.Servers {
 		servers = append(servers, server)
 	}
+	SortServers(servers)
 	return servers
 }
 
@@ -278,6 +516,11 @@ func (m *Manager) UpdatePortForward(pfID string, pf *PortForwardConfig) error {
 		}
 	}
 
+	// 别名变更时同步更新转发组中的引用
+	if old := m.config.PortForwards[pfID]; old.Alias != "" && old.Alias != pf.Alias {
+		m.renameGroupMember(old.Alias, pf.Alias)
+	}
+
 	pf.ID = pfID
 	pf.UpdatedAt = time.Now()
 
@@ -291,10 +534,22 @@ func (m *Manager) DeletePortForward(pfID string) error {
 		return fmt.Errorf("端口转发 %s 不存在", pfID)
 	}
 
-	delete(m.config.PortForwards, pfID)
+	m.removePortForward(pfID)
 	return m.Save()
 }
 
+// removePortForward 删除端口转发并从转发组中移除（不保存）
+func (m *Manager) removePortForward(pfID string) {
+	pf, exists := m.config.PortForwards[pfID]
+	if !exists {
+		return
+	}
+	if pf.Alias != "" {
+		m.renameGroupMember(pf.Alias, "")
+	}
+	delete(m.config.PortForwards, pfID)
+}
+
 // GetPortForward 获取端口转发配置
 func (m *Manager) GetPortForward(pfID string) (*PortForwardConfig, error) {
 	pf, exists := m.config.PortForwards[pfID]
@@ -334,8 +589,167 @@ func (m *Manager) ListPortForwardsByServer(serverID string) []*PortForwardConfig
 	return pfs
 }
 
+// FindLocalPortConflicts 查找与指定端口转发监听同一本地端口的已保存端口转发
+func (m *Manager) FindLocalPortConflicts(pf *PortForwardConfig) []*PortForwardConfig {
+	var conflicts []*PortForwardConfig
+	for id, existing := range m.config.PortForwards {
+		if id != pf.ID && pf.LocalPortOverlaps(existing) {
+			conflicts = append(conflicts, existing)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].CreatedAt.Before(conflicts[j].CreatedAt)
+	})
+	return conflicts
+}
+
+// AddForwardGroup 添加端口转发组
+func (m *Manager) AddForwardGroup(group *ForwardGroup) error {
+	if group.ID == "" {
+		group.ID = generateID()
+	}
+
+	// 检查名称是否唯一
+	for _, existing := range m.config.ForwardGroups {
+		if existing.Name == group.Name {
+			return fmt.Errorf("转发组名称 '%s' 已存在", group.Name)
+		}
+	}
+
+	if err := m.validateForwardGroup(group); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	m.config.ForwardGroups[group.ID] = group
+	return m.Save()
+}
+
+// UpdateForwardGroup 更新端口转发组
+func (m *Manager) UpdateForwardGroup(groupID string, group *ForwardGroup) error {
+	if _, exists := m.config.ForwardGroups[groupID]; !exists {
+		return fmt.Errorf("转发组 %s 不存在", groupID)
+	}
+
+	// 检查名称是否唯一（排除当前转发组）
+	for id, existing := range m.config.ForwardGroups {
+		if id != groupID && existing.Name == group.Name {
+			return fmt.Errorf("转发组名称 '%s' 已存在", group.Name)
+		}
+	}
+
+	if err := m.validateForwardGroup(group); err != nil {
+		return err
+	}
+
+	group.ID = groupID
+	group.UpdatedAt = time.Now()
+
+	m.config.ForwardGroups[groupID] = group
+	return m.Save()
+}
+
+// DeleteForwardGroup 删除端口转发组
+func (m *Manager) DeleteForwardGroup(groupID string) error {
+	if _, exists := m.config.ForwardGroups[groupID]; !exists {
+		return fmt.Errorf("转发组 %s 不存在", groupID)
+	}
+
+	delete(m.config.ForwardGroups, groupID)
+	return m.Save()
+}
+
+// GetForwardGroup 获取端口转发组
+func (m *Manager) GetForwardGroup(groupID string) (*ForwardGroup, error) {
+	group, exists := m.config.ForwardGroups[groupID]
+	if !exists {
+		return nil, fmt.Errorf("转发组 %s 不存在", groupID)
+	}
+	return group, nil
+}
+
+// GetForwardGroupByName 根据名称获取端口转发组
+func (m *Manager) GetForwardGroupByName(name string) (*ForwardGroup, error) {
+	for _, group := range m.config.ForwardGroups {
+		if group.Name == name {
+			return group, nil
+		}
+	}
+	return nil, fmt.Errorf("转发组 '%s' 不存在", name)
+}
+
+// ListForwardGroups 列出所有端口转发组
+func (m *Manager) ListForwardGroups() []*ForwardGroup {
+	groups := make([]*ForwardGroup, 0)
+	for _, group := range m.config.ForwardGroups {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// validateForwardGroup 校验转发组名称、成员和依赖关系
+func (m *Manager) validateForwardGroup(group *ForwardGroup) error {
+	if strings.TrimSpace(group.Name) == "" {
+		return fmt.Errorf("转发组名称不能为空")
+	}
+	if len(group.Forwards) == 0 {
+		return fmt.Errorf("转发组 '%s' 至少需要一个端口转发", group.Name)
+	}
+
+	for _, alias := range group.Forwards {
+		if _, err := m.GetPortForwardByAlias(alias); err != nil {
+			return err
+		}
+	}
+
+	_, err := group.Stages()
+	return err
+}
+
+// renameGroupMember 在所有转发组中重命名成员别名，newAlias 为空时移除该成员
+func (m *Manager) renameGroupMember(oldAlias, newAlias string) {
+	rename := func(aliases []string) []string {
+		result := make([]string, 0, len(aliases))
+		for _, alias := range aliases {
+			if alias != oldAlias {
+				result = append(result, alias)
+			} else if newAlias != "" {
+				result = append(result, newAlias)
+			}
+		}
+		return result
+	}
+
+	for _, group := range m.config.ForwardGroups {
+		group.Forwards = rename(group.Forwards)
+
+		deps := make(map[string][]string, len(group.Dependencies))
+		for alias, aliasDeps := range group.Dependencies {
+			if alias == oldAlias {
+				if newAlias == "" {
+					continue
+				}
+				alias = newAlias
+			}
+			deps[alias] = rename(aliasDeps)
+		}
+		group.Dependencies = deps
+	}
+}
+
 // AddCredential 添加凭证配置
 func (m *Manager) AddCredential(cred *CredentialConfig) error {
+	if err := m.addCredential(cred); err != nil {
+		return err
+	}
+	return m.Save()
+}
+
+// addCredential 将凭证加入配置但不保存，用于需要一次保存多个条目的场景（如导入）
+func (m *Manager) addCredential(cred *CredentialConfig) error {
 	if cred.ID == "" {
 		cred.ID = generateID()
 	}
@@ -354,7 +768,7 @@ func (m *Manager) AddCredential(cred *CredentialConfig) error {
 	cred.UpdatedAt = now
 
 	m.config.Credentials[cred.ID] = cred
-	return m.Save()
+	return nil
 }
 
 // UpdateCredential 更新凭证配置
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentConfigVersion 当前程序使用的配置版本，新增需要转换旧配置的字段时递增并添加迁移步骤
+const CurrentConfigVersion = 2
+
+// ErrNewerConfigVersion 配置文件由更新版本的程序写入
+var ErrNewerConfigVersion = errors.New("配置文件版本高于当前程序支持的版本")
+
+// migration 将配置从 From 版本升级到 From+1 版本的一步
+// 迁移直接修改 YAML 节点，写回时保留原文件的字段顺序和注释
+type migration struct {
+	From        int
+	Description string
+	Apply       func(root *yaml.Node) error
+}
+
+// migrations 按版本排列的迁移步骤，每个版本有且只有一步
+var migrations = []migration{
+	{From: 1, Description: "补全缺失的ID、模板名称和转发类型，规范化分组路径", Apply: migrateV1},
+}
+
+// ConfigVersionOf 获取配置根节点中的版本号，没有版本号的配置视为版本1
+func ConfigVersionOf(root *yaml.Node) int {
+	value := mappingValue(root, "config_version")
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return 1
+	}
+	switch value.ShortTag() {
+	case "!!int":
+		if v, err := strconv.Atoi(value.Value); err == nil && v > 0 {
+			return v
+		}
+	case "!!float":
+		if v, err := strconv.ParseFloat(value.Value, 64); err == nil && v >= 1 {
+			return int(v)
+		}
+	}
+	return 1
+}
+
+// Migrate 将配置根节点依次升级到当前版本，返回升级前的版本
+// 配置版本高于当前版本时返回 ErrNewerConfigVersion
+func Migrate(root *yaml.Node) (int, error) {
+	from := ConfigVersionOf(root)
+	if from > CurrentConfigVersion {
+		return from, fmt.Errorf("%w: 配置版本为 %d，当前程序只支持到版本 %d，请升级 gotssh", ErrNewerConfigVersion, from, CurrentConfigVersion)
+	}
+
+	for _, step := range migrations {
+		if step.From < from {
+			continue
+		}
+		if err := step.Apply(root); err != nil {
+			return from, fmt.Errorf("将配置从版本 %d 升级到 %d 失败: %w", step.From, step.From+1, err)
+		}
+		setConfigVersion(root, step.From+1)
+	}
+	return from, nil
+}
+
+// migrateData 将配置文件内容升级到当前版本，返回升级后的内容和升级前的版本，
+// 已是当前版本或不是映射的内容原样返回
+func migrateData(data []byte) ([]byte, int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, CurrentConfigVersion, nil
+	}
+	root := doc.Content[0]
+	if ConfigVersionOf(root) == CurrentConfigVersion {
+		return data, CurrentConfigVersion, nil
+	}
+
+	from, err := Migrate(root)
+	if err != nil {
+		return nil, from, err
+	}
+	migrated, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, from, fmt.Errorf("序列化升级后的配置失败: %w", err)
+	}
+	return migrated, from, nil
+}
+
+// backupBeforeMigration 升级前备份原配置文件，如 config.yaml.v1.bak，已存在时追加时间
+func backupBeforeMigration(configPath string, version int, data []byte) (string, error) {
+	backupPath := fmt.Sprintf("%s.v%d.bak", configPath, version)
+	if _, err := os.Stat(backupPath); err == nil {
+		backupPath = fmt.Sprintf("%s.v%d.%s.bak", configPath, version, time.Now().Format("20060102-150405"))
+	}
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("备份配置文件失败: %w", err)
+	}
+	return backupPath, nil
+}
+
+// migrateV1 版本1到2：手工编写或早期版本写入的配置可能缺少ID和转发类型，
+// 分组路径可能带有多余的分隔符
+func migrateV1(root *yaml.Node) error {
+	for _, section := range []string{"servers", "port_forwards", "forward_groups", "credentials"} {
+		for _, entry := range sectionEntries(root, section) {
+			if stringValue(entry.Node, "id") == "" {
+				setString(entry.Node, "id", entry.Key)
+			}
+		}
+	}
+
+	for _, entry := range sectionEntries(root, "server_templates") {
+		if stringValue(entry.Node, "name") == "" {
+			setString(entry.Node, "name", entry.Key)
+		}
+	}
+
+	for _, entry := range sectionEntries(root, "port_forwards") {
+		if stringValue(entry.Node, "type") == "" {
+			setString(entry.Node, "type", string(ForwardTypeLocal))
+		}
+	}
+
+	for _, entry := range sectionEntries(root, "servers") {
+		if group := mappingValue(entry.Node, "group"); group != nil && group.ShortTag() == "!!str" {
+			group.Value = NormalizeGroupPath(group.Value)
+		}
+	}
+	return nil
+}
+
+// sectionEntry 配置某一段中的一个条目
+type sectionEntry struct {
+	Key  string
+	Node *yaml.Node
+}
+
+// sectionEntries 获取配置中某一段（如 servers）下值为映射的条目，按文件中的顺序排列
+func sectionEntries(root *yaml.Node, section string) []sectionEntry {
+	var entries []sectionEntry
+	node := mappingValue(root, section)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return entries
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if value := node.Content[i+1]; value.Kind == yaml.MappingNode {
+			entries = append(entries, sectionEntry{Key: node.Content[i].Value, Node: value})
+		}
+	}
+	return entries
+}
+
+// mappingValue 获取映射节点中字段的值节点，字段不存在时返回 nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// stringValue 获取映射节点中字符串字段的值，字段不存在或不是字符串时返回空
+func stringValue(node *yaml.Node, key string) string {
+	value := mappingValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode || value.ShortTag() != "!!str" {
+		return ""
+	}
+	return value.Value
+}
+
+// setString 设置映射节点中字段的字符串值，字段不存在时追加到末尾
+func setString(node *yaml.Node, key, value string) {
+	setScalar(node, key, "!!str", value)
+}
+
+// setScalar 设置映射节点中字段的标量值，保留原值节点上的注释
+func setScalar(node *yaml.Node, key, tag, value string) {
+	if existing := mappingValue(node, key); existing != nil {
+		existing.Kind, existing.Tag, existing.Value, existing.Style = yaml.ScalarNode, tag, value, 0
+		existing.Content = nil
+		return
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+}
+
+// setConfigVersion 设置配置的版本号，没有版本号时插入到最前面，与程序写入的配置一致
+func setConfigVersion(root *yaml.Node, version int) {
+	value := strconv.Itoa(version)
+	if mappingValue(root, "config_version") != nil {
+		setScalar(root, "config_version", "!!int", value)
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "config_version"}
+	if len(root.Content) > 0 {
+		// 文件开头的注释留在最前面
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{key, {Kind: yaml.ScalarNode, Tag: "!!int", Value: value}}, root.Content...)
+}
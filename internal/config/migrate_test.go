@@ -0,0 +1,227 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// v1Config 早期版本写入的配置，缺少ID和转发类型
+const v1Config = `config_version: 1
+servers:
+  s1:
+    alias: web
+    host: 10.0.0.1
+    port: 22
+    user: root
+    auth_type: ask
+    group: " prod//db/ "
+port_forwards:
+  f1:
+    alias: t1
+    server_id: s1
+    local_port: 8080
+    remote_host: 127.0.0.1
+    remote_port: 80
+server_templates:
+  k8s:
+    host: node-[1-3]
+`
+
+// TestMigrationSteps 测试迁移步骤覆盖从版本1到当前版本的每个版本
+func TestMigrationSteps(t *testing.T) {
+	require.Len(t, migrations, CurrentConfigVersion-1)
+	for i, step := range migrations {
+		assert.Equal(t, i+1, step.From)
+		assert.NotEmpty(t, step.Description)
+	}
+}
+
+// TestMigrate 测试原始配置的升级
+func TestMigrate(t *testing.T) {
+	t.Run("从版本1升级", func(t *testing.T) {
+		data, from, err := migrateData([]byte(v1Config))
+		require.NoError(t, err)
+		assert.Equal(t, 1, from)
+
+		doc := rawConfig(t, data)
+		assert.Equal(t, CurrentConfigVersion, doc["config_version"])
+		server := rawEntry(doc, "servers", "s1")
+		assert.Equal(t, "s1", server["id"])
+		assert.Equal(t, "prod/db", server["group"])
+		pf := rawEntry(doc, "port_forwards", "f1")
+		assert.Equal(t, "f1", pf["id"])
+		assert.Equal(t, "local", pf["type"])
+		assert.Equal(t, "k8s", rawEntry(doc, "server_templates", "k8s")["name"])
+	})
+
+	t.Run("没有版本号视为版本1", func(t *testing.T) {
+		data, from, err := migrateData([]byte("port_forwards:\n  f1:\n    type: remote\n"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, from)
+		doc := rawConfig(t, data)
+		assert.Equal(t, "remote", rawEntry(doc, "port_forwards", "f1")["type"])
+		assert.Equal(t, CurrentConfigVersion, doc["config_version"])
+	})
+
+	t.Run("当前版本原样返回", func(t *testing.T) {
+		content := "config_version: 2\nservers: {}\n"
+		data, from, err := migrateData([]byte(content))
+		require.NoError(t, err)
+		assert.Equal(t, CurrentConfigVersion, from)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("拒绝更新版本的配置", func(t *testing.T) {
+		var doc yaml.Node
+		require.NoError(t, yaml.Unmarshal([]byte("config_version: 99\n"), &doc))
+		_, err := Migrate(doc.Content[0])
+		assert.True(t, errors.Is(err, ErrNewerConfigVersion))
+	})
+
+	t.Run("保留注释和字段顺序", func(t *testing.T) {
+		content := `# 手工维护的配置
+servers:
+  # 生产环境
+  s1:
+    host: 10.0.0.1 # 主库
+    alias: web
+    group: prod//db
+settings:
+  log_level: debug
+`
+		data, _, err := migrateData([]byte(content))
+		require.NoError(t, err)
+		migrated := string(data)
+		for _, comment := range []string{"# 手工维护的配置", "# 生产环境", "# 主库"} {
+			assert.Contains(t, migrated, comment)
+		}
+		assert.True(t, strings.HasPrefix(migrated, "# 手工维护的配置\nconfig_version: 2\n"), migrated)
+		order := []string{"servers:", "host:", "alias:", "group: prod/db", "id: s1", "settings:"}
+		for i := 1; i < len(order); i++ {
+			assert.Less(t, strings.Index(migrated, order[i-1]), strings.Index(migrated, order[i]), order[i])
+		}
+	})
+}
+
+// rawConfig 将配置内容解析为原始映射
+func rawConfig(t *testing.T, data []byte) map[string]interface{} {
+	var doc map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &doc))
+	return doc
+}
+
+// rawEntry 获取原始配置中某一段下的条目
+func rawEntry(doc map[string]interface{}, section, key string) map[string]interface{} {
+	entries, _ := doc[section].(map[string]interface{})
+	entry, _ := entries[key].(map[string]interface{})
+	return entry
+}
+
+// TestLoadMigration 测试加载旧版本配置时备份并升级
+func TestLoadMigration(t *testing.T) {
+	path := writeConfig(t, v1Config)
+
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+
+	from, backupPath := manager.Migrated()
+	assert.Equal(t, 1, from)
+	assert.Equal(t, path+".v1.bak", backupPath)
+	backup, err := os.ReadFile(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, v1Config, string(backup))
+
+	server, err := manager.GetServerByAlias("web")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", server.ID)
+	assert.Equal(t, "prod/db", server.Group)
+	pf, err := manager.GetPortForwardByAlias("t1")
+	require.NoError(t, err)
+	assert.Equal(t, ForwardTypeLocal, pf.Type)
+
+	// 升级后的配置已写入，再次加载不再升级
+	reloaded, err := NewManager(path)
+	require.NoError(t, err)
+	from, _ = reloaded.Migrated()
+	assert.Zero(t, from)
+	assert.Equal(t, CurrentConfigVersion, reloaded.GetConfig().ConfigVersion)
+
+	// 再次升级时不覆盖已有的备份
+	require.NoError(t, os.WriteFile(path, []byte(v1Config), 0600))
+	again, err := NewManager(path)
+	require.NoError(t, err)
+	_, secondBackup := again.Migrated()
+	assert.NotEqual(t, backupPath, secondBackup)
+	assert.FileExists(t, secondBackup)
+}
+
+// TestLoadNewerVersion 测试拒绝打开更新版本的配置
+func TestLoadNewerVersion(t *testing.T) {
+	content := "config_version: 99\nservers: {}\n"
+	path := writeConfig(t, content)
+
+	_, err := NewManager(path)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNewerConfigVersion))
+	assert.Contains(t, err.Error(), "请升级 gotssh")
+
+	// 配置文件保持不变
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+// TestLoadMigrationKeepsFile 测试升级写回的配置保留注释、字段顺序和文件权限
+func TestLoadMigrationKeepsFile(t *testing.T) {
+	content := "# 团队共享的配置\n" + v1Config
+	path := writeConfig(t, content)
+	require.NoError(t, os.Chmod(path, 0644))
+
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	from, _ := manager.Migrated()
+	assert.Equal(t, 1, from)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	migrated := string(data)
+	assert.Contains(t, migrated, "# 团队共享的配置")
+	assert.Less(t, strings.Index(migrated, "host: 10.0.0.1"), strings.Index(migrated, "user: root"))
+	assert.Less(t, strings.Index(migrated, "servers:"), strings.Index(migrated, "port_forwards:"))
+
+	// 保留原有权限，config doctor 仍能发现不安全的权限
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+// TestLoadMigrationInvalid 测试升级后的配置校验失败时不写回也不备份
+func TestLoadMigrationInvalid(t *testing.T) {
+	content := strings.Replace(v1Config, "auth_type: ask", "auth_type: bogus", 1)
+	for name, open := range map[string]func(string) (*Manager, error){"严格": NewManager, "宽松": NewLenientManager} {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, content)
+
+			manager, err := open(path)
+			if err == nil {
+				from, _ := manager.Migrated()
+				assert.Zero(t, from)
+				assert.NotEmpty(t, Errors(manager.Problems()))
+			}
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+			backups, err := filepath.Glob(path + ".v1*.bak")
+			require.NoError(t, err)
+			assert.Empty(t, backups)
+		})
+	}
+}
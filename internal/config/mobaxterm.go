@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MobaXterm 会话参数中 SSH 会话的字段位置
+const (
+	mobaTypeSSH     = "0"
+	mobaHost        = 1
+	mobaPort        = 2
+	mobaUser        = 3
+	mobaGatewayHost = 8
+	mobaGatewayPort = 9
+	mobaGatewayUser = 10
+	mobaKey         = 14
+)
+
+// mobaEscapes MobaXterm 会话中转义的字符
+var mobaEscapes = strings.NewReplacer("__PIPE__", "|", "__PERCENT__", "%", "__DIEZE__", "#", "__DBLQUO__", `"`)
+
+// ParseMobaXtermSessions 解析 MobaXterm 导出的会话文件（.mxtsessions），
+// 书签文件夹（SubRep）作为服务器分组，非 SSH 会话标为跳过
+func ParseMobaXtermSessions(data []byte) ([]*ImportEntry, error) {
+	text := string(bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}))
+
+	var entries []*ImportEntry
+	folder := ""
+	inBookmarks := false
+	sawSection := false
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section := line[1 : len(line)-1]
+			inBookmarks = section == "Bookmarks" || strings.HasPrefix(section, "Bookmarks_")
+			sawSection = sawSection || inBookmarks
+			folder = ""
+			continue
+		}
+		if !inBookmarks {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "SubRep":
+			folder = strings.ReplaceAll(value, `\`, groupSeparator)
+			continue
+		case "ImgNum":
+			continue
+		}
+		if !strings.HasPrefix(value, "#") {
+			continue
+		}
+		entries = append(entries, mobaEntry(name, folder, value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取会话文件失败: %w", err)
+	}
+	if !sawSection {
+		return nil, fmt.Errorf("不是有效的 MobaXterm 会话文件，缺少 [Bookmarks] 部分")
+	}
+	return entries, nil
+}
+
+// mobaEntry 将一个 MobaXterm 会话转换为待导入的服务器，会话格式为 #图标#参数1%参数2%...#终端设置#...
+func mobaEntry(name, folder, value string) *ImportEntry {
+	server := &ServerConfig{Alias: name, Group: NormalizeGroupPath(folder), Tags: []string{}}
+	entry := &ImportEntry{Name: name, Server: server}
+
+	parts := strings.Split(value, "#")
+	var params []string
+	if len(parts) > 2 {
+		params = strings.Split(parts[2], "%")
+	}
+	param := func(i int) string {
+		if i < len(params) {
+			return strings.TrimSpace(mobaEscapes.Replace(params[i]))
+		}
+		return ""
+	}
+
+	server.Host = param(mobaHost)
+	server.User = param(mobaUser)
+	server.Port, _ = strconv.Atoi(param(mobaPort))
+	if param(0) != mobaTypeSSH {
+		entry.Conflict = "不是 SSH 会话"
+		return entry
+	}
+	if server.Host == "" {
+		entry.Conflict = "未设置主机"
+		return entry
+	}
+
+	if key := param(mobaKey); key != "" {
+		entry.KeyPath = mobaPath(key)
+	}
+
+	// 多个网关用 | 分隔，对应多级跳板机
+	var hops []string
+	hosts := strings.Split(param(mobaGatewayHost), "|")
+	ports := strings.Split(param(mobaGatewayPort), "|")
+	users := strings.Split(param(mobaGatewayUser), "|")
+	for i, host := range hosts {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		if i < len(users) && strings.TrimSpace(users[i]) != "" {
+			host = strings.TrimSpace(users[i]) + "@" + host
+		}
+		if i < len(ports) && strings.TrimSpace(ports[i]) != "" && strings.TrimSpace(ports[i]) != "22" {
+			host += ":" + strings.TrimSpace(ports[i])
+		}
+		hops = append(hops, host)
+	}
+	entry.JumpHost = strings.Join(hops, ",")
+	return entry
+}
+
+// mobaPath 将 MobaXterm 路径中的 _ProfileDir_ 替换为 ~，其他变量保持不变
+func mobaPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "_ProfileDir_"); ok {
+		return "~" + strings.ReplaceAll(rest, `\`, "/")
+	}
+	return path
+}
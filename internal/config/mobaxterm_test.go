@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mobaSessions MobaXterm 导出的会话文件
+const mobaSessions = `[Bookmarks]
+SubRep=
+ImgNum=42
+web=#109#0%10.0.0.1%22%deploy%%-1%-1%%%22%%0%0%0%_ProfileDir_\.ssh\id_rsa%%-1%0%0%0%%1080%%0%0%1#MobaFont%10%0%0%-1%15#0# #-1
+rdp=#91#4%10.0.0.9%3389%admin%0%-1%-1%-1#MobaFont%10#0# #-1
+
+[Bookmarks_1]
+SubRep=Production\Database
+ImgNum=41
+db=#109#0%10.0.1.1%2222%%%-1%-1%%gw1.example.com__PIPE__gw2%22__PIPE__2200%ops__PIPE__%0%0%0%%%-1%0%0%0%%1080%%0%0%1#MobaFont#0# #-1
+`
+
+// TestParseMobaXtermSessions 测试解析 MobaXterm 会话
+func TestParseMobaXtermSessions(t *testing.T) {
+	entries, err := ParseMobaXtermSessions([]byte(mobaSessions))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	web := entries[0]
+	assert.Equal(t, "web", web.Server.Alias)
+	assert.Equal(t, "deploy", web.Server.User)
+	assert.Equal(t, "10.0.0.1", web.Server.Host)
+	assert.Equal(t, 22, web.Server.Port)
+	assert.Equal(t, "~/.ssh/id_rsa", web.KeyPath)
+	assert.Empty(t, web.Server.Group)
+	assert.Empty(t, web.JumpHost)
+
+	assert.Equal(t, "不是 SSH 会话", entries[1].Conflict)
+
+	db := entries[2]
+	assert.Equal(t, "Production/Database", db.Server.Group)
+	assert.Empty(t, db.Server.User)
+	assert.Equal(t, 2222, db.Server.Port)
+	assert.Equal(t, "ops@gw1.example.com,gw2:2200", db.JumpHost)
+
+	_, err = ParseMobaXtermSessions([]byte("[Misc]\na=b\n"))
+	assert.Error(t, err)
+}
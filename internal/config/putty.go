@@ -0,0 +1,295 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PuTTY 会话在注册表中的位置和默认设置会话的名称
+const (
+	puttySessionsKey    = `\Software\SimonTatham\PuTTY\Sessions\`
+	puttyDefaultSession = "Default Settings"
+)
+
+// puttyProxyMethods PuTTY 的 ProxyMethod 取值
+var puttyProxyMethods = map[int]string{
+	1: "SOCKS4",
+	2: "SOCKS5",
+	3: "HTTP",
+	4: "Telnet",
+	5: "本地命令",
+	6: "SSH",
+}
+
+// ParsePuTTYSessions 解析 reg export 导出的 PuTTY 会话（HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions），
+// 文件可以是 reg export 默认的 UTF-16 编码或 UTF-8 编码，按会话在文件中的顺序返回
+func ParsePuTTYSessions(data []byte) ([]*ImportEntry, error) {
+	text := decodeRegText(data)
+	if !strings.HasPrefix(strings.TrimSpace(text), "Windows Registry Editor") && !strings.HasPrefix(strings.TrimSpace(text), "REGEDIT4") {
+		return nil, fmt.Errorf("不是有效的注册表导出文件")
+	}
+
+	var names []string
+	sessions := make(map[string]map[string]string)
+	var current map[string]string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var pending string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// 二进制值可以用行尾的反斜杠续行
+		if strings.HasSuffix(line, `\`) && !strings.HasPrefix(line, "[") {
+			pending += strings.TrimSuffix(line, `\`)
+			continue
+		}
+		line, pending = pending+line, ""
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = nil
+			key := line[1 : len(line)-1]
+			i := strings.Index(strings.ToLower(key), strings.ToLower(puttySessionsKey))
+			if strings.HasPrefix(key, "-") || i < 0 {
+				continue
+			}
+			name, err := url.PathUnescape(key[i+len(puttySessionsKey):])
+			if err != nil {
+				name = key[i+len(puttySessionsKey):]
+			}
+			if name == "" || strings.Contains(name, `\`) || name == puttyDefaultSession {
+				continue
+			}
+			current = make(map[string]string)
+			sessions[name] = current
+			names = append(names, name)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if name, value, ok := parseRegValue(line); ok {
+			current[name] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取注册表导出文件失败: %w", err)
+	}
+
+	entries := make([]*ImportEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, puttyEntry(name, sessions[name]))
+	}
+	return entries, nil
+}
+
+// puttyEntry 将 PuTTY 会话的设置转换为待导入的服务器
+func puttyEntry(name string, values map[string]string) *ImportEntry {
+	server := &ServerConfig{Alias: name, Tags: []string{}}
+	entry := &ImportEntry{Name: name, Server: server}
+
+	host := values["HostName"]
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		server.User, host = host[:i], host[i+1:]
+	}
+	server.Host = strings.TrimSpace(host)
+	if server.User == "" {
+		server.User = values["UserName"]
+	}
+	server.Port, _ = strconv.Atoi(values["PortNumber"])
+	if protocol := values["Protocol"]; protocol != "" && protocol != "ssh" {
+		entry.Conflict = fmt.Sprintf("不是 SSH 会话（%s）", protocol)
+		return entry
+	}
+	if server.Host == "" {
+		entry.Conflict = "未设置主机"
+		return entry
+	}
+
+	if key := values["PublicKeyFile"]; key != "" {
+		entry.KeyPath = key
+		if strings.HasSuffix(strings.ToLower(key), ".ppk") {
+			entry.Notes = append(entry.Notes, "PuTTY 格式的私钥 (.ppk) 需要用 puttygen 转换为 OpenSSH 格式后修改凭证的密钥路径")
+		}
+	}
+
+	method, _ := strconv.Atoi(values["ProxyMethod"])
+	proxyPort, _ := strconv.Atoi(values["ProxyPort"])
+	switch method {
+	case 0:
+	case 2, 3:
+		proxyType := "socks5"
+		if method == 3 {
+			proxyType = "http"
+		}
+		server.Proxy = &ProxyConfig{
+			Type:     proxyType,
+			Host:     values["ProxyHost"],
+			Port:     proxyPort,
+			Username: values["ProxyUsername"],
+			Password: values["ProxyPassword"],
+		}
+	case 6:
+		entry.JumpHost = values["ProxyHost"]
+		if user := values["ProxyUsername"]; user != "" {
+			entry.JumpHost = user + "@" + entry.JumpHost
+		}
+		if proxyPort != 0 && proxyPort != 22 {
+			entry.JumpHost += ":" + strconv.Itoa(proxyPort)
+		}
+	case 5:
+		command := strings.NewReplacer(
+			"%proxyhost", values["ProxyHost"],
+			"%proxyport", values["ProxyPort"],
+			"%user", values["ProxyUsername"],
+		).Replace(values["ProxyTelnetCommand"])
+		if jump := proxyCommandJump(command); jump != "" {
+			entry.JumpHost = jump
+			break
+		}
+		fallthrough
+	default:
+		entry.Notes = append(entry.Notes, fmt.Sprintf("不支持 %s 代理，已忽略", puttyProxyMethods[method]))
+	}
+
+	forwards, notes := puttyForwards(name, values["PortForwardings"])
+	entry.Forwards = forwards
+	entry.Notes = append(entry.Notes, notes...)
+	return entry
+}
+
+// puttyForwards 解析 PuTTY 的 PortForwardings，如 L8080=localhost:80,4R127.0.0.1:9000=localhost:9000,D1080
+func puttyForwards(name, spec string) ([]*PortForwardConfig, []string) {
+	var forwards []*PortForwardConfig
+	var notes []string
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimLeft(strings.TrimSpace(item), "46")
+		if item == "" {
+			continue
+		}
+		kind, rest := item[0], item[1:]
+		listen, target, _ := strings.Cut(strings.Replace(rest, "\t", "=", 1), "=")
+		if kind == 'D' {
+			notes = append(notes, fmt.Sprintf("不支持本地动态转发（SOCKS）%s，已忽略", listen))
+			continue
+		}
+
+		listenHost, listenPort, err := splitListenAddress(listen)
+		targetHost, targetPortText, err2 := net.SplitHostPort(target)
+		targetPort, err3 := strconv.Atoi(targetPortText)
+		if (kind != 'L' && kind != 'R') || err != nil || err2 != nil || err3 != nil {
+			notes = append(notes, fmt.Sprintf("无法识别的端口转发 '%s'，已忽略", item))
+			continue
+		}
+
+		pf := NewPortForwardConfig("")
+		pf.Alias = fmt.Sprintf("%s-%d", name, listenPort)
+		pf.Description = "从 PuTTY 导入"
+		if kind == 'L' {
+			if listenHost != "" {
+				pf.LocalHost = listenHost
+			}
+			pf.LocalPort = listenPort
+			pf.RemoteHost, pf.RemotePort = targetHost, targetPort
+		} else {
+			pf.Type = ForwardTypeRemote
+			if listenHost != "" {
+				pf.RemoteHost = listenHost
+			}
+			pf.RemotePort = listenPort
+			pf.LocalHost, pf.LocalPort = targetHost, targetPort
+		}
+		forwards = append(forwards, pf)
+	}
+	return forwards, notes
+}
+
+// splitListenAddress 解析 [地址:]端口 形式的监听地址，未指定地址时返回空地址
+func splitListenAddress(spec string) (string, int, error) {
+	host := ""
+	portText := spec
+	if strings.Contains(spec, ":") {
+		var err error
+		if host, portText, err = net.SplitHostPort(spec); err != nil {
+			return "", 0, err
+		}
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port < 0 || port > 65535 {
+		return "", 0, fmt.Errorf("无效的端口 '%s'", portText)
+	}
+	return host, port, nil
+}
+
+// parseRegValue 解析注册表导出文件中的一个值，支持字符串和 dword，dword 转换为十进制字符串
+func parseRegValue(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, `"`) {
+		return "", "", false
+	}
+	name, rest, ok := cutRegString(line)
+	if !ok || !strings.HasPrefix(rest, "=") {
+		return "", "", false
+	}
+	rest = strings.TrimSpace(rest[1:])
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		value, _, ok := cutRegString(rest)
+		return name, value, ok
+	case strings.HasPrefix(strings.ToLower(rest), "dword:"):
+		n, err := strconv.ParseUint(strings.TrimSpace(rest[len("dword:"):]), 16, 32)
+		if err != nil {
+			return "", "", false
+		}
+		return name, strconv.FormatUint(n, 10), true
+	}
+	return "", "", false
+}
+
+// cutRegString 取出开头的带引号字符串并处理 \\ 和 \" 转义，返回字符串和剩余部分
+func cutRegString(s string) (string, string, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
+
+// decodeRegText 将注册表导出文件转换为字符串，识别 UTF-16 字节顺序标记
+func decodeRegText(data []byte) string {
+	var bigEndian bool
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		bigEndian = true
+	default:
+		return string(bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}))
+	}
+
+	data = data[2:]
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// puttyReg reg export 导出的 PuTTY 会话
+const puttyReg = `Windows Registry Editor Version 5.00
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions]
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\Default%20Settings]
+"HostName"=""
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\prod%20web]
+"HostName"="deploy@10.0.0.1"
+"PortNumber"=dword:00000016
+"Protocol"="ssh"
+"PublicKeyFile"="C:\\Users\\me\\keys\\deploy.ppk"
+"ProxyMethod"=dword:00000006
+"ProxyHost"="bastion.example.com"
+"ProxyPort"=dword:000008ae
+"ProxyUsername"="ops"
+"PortForwardings"="L8080=localhost:80,4R127.0.0.1:9000=localhost:9000,D1080,Lbad"
+"Colour0"=hex:bb,bb,bb,\
+  00
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\db]
+"HostName"="10.0.1.1"
+"UserName"="postgres"
+"PortNumber"=dword:00000d3d
+"ProxyMethod"=dword:00000002
+"ProxyHost"="proxy.example.com"
+"ProxyPort"=dword:00000438
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\router]
+"HostName"="192.168.1.1"
+"Protocol"="telnet"
+`
+
+// TestParsePuTTYSessions 测试解析 PuTTY 会话
+func TestParsePuTTYSessions(t *testing.T) {
+	entries, err := ParsePuTTYSessions([]byte(puttyReg))
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "不导入默认设置")
+
+	web := entries[0]
+	assert.Equal(t, "prod web", web.Name)
+	assert.Equal(t, "deploy", web.Server.User)
+	assert.Equal(t, "10.0.0.1", web.Server.Host)
+	assert.Equal(t, 22, web.Server.Port)
+	assert.Equal(t, `C:\Users\me\keys\deploy.ppk`, web.KeyPath)
+	assert.Equal(t, "ops@bastion.example.com:2222", web.JumpHost)
+	require.Len(t, web.Forwards, 2)
+	assert.Equal(t, "127.0.0.1:8080 -> localhost:80", web.Forwards[0].Summary())
+	assert.Equal(t, "prod web-8080", web.Forwards[0].Alias)
+	assert.Equal(t, ForwardTypeRemote, web.Forwards[1].Type)
+	assert.Equal(t, "127.0.0.1:9000", web.Forwards[1].RemoteAddress())
+	assert.Equal(t, "localhost:9000", web.Forwards[1].LocalAddress())
+	assert.Len(t, web.Notes, 3, "ppk 私钥、动态转发和无法识别的转发")
+
+	db := entries[1]
+	assert.Equal(t, "postgres", db.Server.User)
+	assert.Equal(t, 3389, db.Server.Port)
+	require.NotNil(t, db.Server.Proxy)
+	assert.Equal(t, ProxyConfig{Type: "socks5", Host: "proxy.example.com", Port: 1080}, *db.Server.Proxy)
+
+	assert.Contains(t, entries[2].Conflict, "telnet")
+
+	// reg export 默认导出 UTF-16 编码
+	units := utf16.Encode([]rune(puttyReg))
+	data := []byte{0xFF, 0xFE}
+	for _, u := range units {
+		data = append(data, byte(u), byte(u>>8))
+	}
+	utf16Entries, err := ParsePuTTYSessions(data)
+	require.NoError(t, err)
+	assert.Len(t, utf16Entries, 3)
+
+	_, err = ParsePuTTYSessions([]byte("HostName=web"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 搜索过滤条件的前缀
+const (
+	tagFacet   = "tag:"   // 按标签过滤
+	groupFacet = "group:" // 按分组过滤（包括下级分组）
+)
+
+// SearchQuery 解析后的服务器搜索条件
+type SearchQuery struct {
+	Terms  []string // 模糊匹配关键字，全部都要匹配
+	Tags   []string // 要求具有的标签（tag:xxx），全部都要具有
+	Groups []string // 要求所在的分组（group:xxx），全部都要满足
+}
+
+// ParseSearchQuery 解析搜索字符串，空白分隔，tag:xxx 按标签过滤，group:xxx 按分组过滤，
+// 关键字不区分大小写
+func ParseSearchQuery(query string) SearchQuery {
+	var q SearchQuery
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if tag, ok := strings.CutPrefix(field, tagFacet); ok {
+			if tag != "" {
+				q.Tags = append(q.Tags, tag)
+			}
+			continue
+		}
+		if group, ok := strings.CutPrefix(field, groupFacet); ok {
+			if group = NormalizeGroupPath(group); group != "" {
+				q.Groups = append(q.Groups, group)
+			}
+			continue
+		}
+		q.Terms = append(q.Terms, field)
+	}
+	return q
+}
+
+// Empty 是否没有任何搜索条件
+func (q SearchQuery) Empty() bool {
+	return len(q.Terms) == 0 && len(q.Tags) == 0 && len(q.Groups) == 0
+}
+
+// HasFacets 是否包含标签或分组过滤条件
+func (q SearchQuery) HasFacets() bool {
+	return len(q.Tags) > 0 || len(q.Groups) > 0
+}
+
+// Match 计算服务器与搜索条件的匹配得分，不匹配时返回 false
+func (q SearchQuery) Match(server *ServerConfig) (int, bool) {
+	for _, tag := range q.Tags {
+		if !hasTag(server, tag) {
+			return 0, false
+		}
+	}
+	for _, group := range q.Groups {
+		if !inGroupFold(server, group) {
+			return 0, false
+		}
+	}
+
+	// 字段按重要程度加权：别名 > 主机 > 用户名 > 分组、标签 > 描述
+	fields := []struct {
+		text   string
+		weight int
+	}{
+		{server.Alias, 4},
+		{server.Host, 3},
+		{server.User, 2},
+		{server.Group, 2},
+		{strings.Join(server.Tags, " "), 2},
+		{server.Description, 1},
+	}
+
+	total := 0
+	for _, term := range q.Terms {
+		best := 0
+		for _, field := range fields {
+			if score, ok := fuzzyScore(term, strings.ToLower(field.text)); ok && score*field.weight > best {
+				best = score * field.weight
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// MatchServer 判断服务器是否匹配搜索字符串，返回匹配得分
+func MatchServer(server *ServerConfig, query string) (int, bool) {
+	return ParseSearchQuery(query).Match(server)
+}
+
+// FilterServers 按搜索字符串过滤服务器，匹配度高的排在前面，得分相同时保持原有顺序
+func FilterServers(servers []*ServerConfig, query string) []*ServerConfig {
+	q := ParseSearchQuery(query)
+	if q.Empty() {
+		return append([]*ServerConfig(nil), servers...)
+	}
+
+	type match struct {
+		server *ServerConfig
+		score  int
+	}
+	var matches []match
+	for _, server := range servers {
+		if score, ok := q.Match(server); ok {
+			matches = append(matches, match{server, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]*ServerConfig, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.server)
+	}
+	return result
+}
+
+// SortServers 按别名（无别名时按主机）、主机、端口排序，使列表顺序稳定
+func SortServers(servers []*ServerConfig) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		return serverSortKey(servers[i]) < serverSortKey(servers[j])
+	})
+}
+
+// hasTag 服务器是否具有指定标签（不区分大小写）
+func hasTag(server *ServerConfig, tag string) bool {
+	for _, t := range server.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// inGroupFold 服务器是否在分组或其下级分组中（不区分大小写）
+func inGroupFold(server *ServerConfig, path string) bool {
+	group := strings.ToLower(NormalizeGroupPath(server.Group))
+	return group == path || strings.HasPrefix(group, path+groupSeparator)
+}
+
+// fuzzyScore 按子序列匹配 pattern 和 text（均为小写），
+// 连续匹配、单词开头匹配和整段包含得分更高
+func fuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, false
+	}
+
+	p := []rune(pattern)
+	score := 0
+	matched := 0
+	prevMatch := -2
+	var prev rune
+	for i, r := range []rune(text) {
+		if matched < len(p) && r == p[matched] {
+			score++
+			if i == prevMatch+1 {
+				score += 4
+			}
+			if i == 0 || isWordSeparator(prev) {
+				score += 3
+			}
+			prevMatch = i
+			matched++
+		}
+		prev = r
+	}
+	if matched < len(p) {
+		return 0, false
+	}
+
+	if strings.HasPrefix(text, pattern) {
+		score += 20
+	} else if strings.Contains(text, pattern) {
+		score += 10
+	}
+	return score, true
+}
+
+// isWordSeparator 是否为单词分隔字符
+func isWordSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("-_.@/:", r)
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSearchQuery 测试搜索字符串解析
+func TestParseSearchQuery(t *testing.T) {
+	q := ParseSearchQuery("  Web tag:Prod  db tag: ")
+	assert.Equal(t, []string{"web", "db"}, q.Terms)
+	assert.Equal(t, []string{"prod"}, q.Tags)
+	assert.True(t, q.HasFacets())
+	assert.False(t, q.Empty())
+
+	assert.True(t, ParseSearchQuery("   ").Empty())
+	assert.False(t, ParseSearchQuery("web").HasFacets())
+}
+
+// TestFuzzyScore 测试子序列模糊匹配
+func TestFuzzyScore(t *testing.T) {
+	_, ok := fuzzyScore("pdb", "prod-db")
+	assert.True(t, ok)
+	_, ok = fuzzyScore("dbp", "prod-db")
+	assert.False(t, ok)
+	_, ok = fuzzyScore("", "prod-db")
+	assert.False(t, ok)
+
+	prefix, _ := fuzzyScore("prod", "prod-db")
+	contains, _ := fuzzyScore("prod", "old-prod")
+	scattered, _ := fuzzyScore("prod", "p-r-o-d")
+	assert.Greater(t, prefix, contains)
+	assert.Greater(t, contains, scattered)
+}
+
+// TestFilterServers 测试服务器搜索过滤和排序
+func TestFilterServers(t *testing.T) {
+	servers := []*ServerConfig{
+		{ID: "1", Alias: "web-1", Host: "10.0.0.1", User: "root", Tags: []string{"prod", "web"}},
+		{ID: "2", Alias: "db-1", Host: "10.0.0.2", User: "postgres", Tags: []string{"prod"}, Description: "primary database"},
+		{ID: "3", Alias: "web-2", Host: "10.0.1.1", User: "deploy", Tags: []string{"staging"}},
+	}
+
+	assert.Len(t, FilterServers(servers, ""), 3)
+	assert.Equal(t, []string{"1", "2"}, serverIDs(FilterServers(servers, "tag:PROD")))
+	assert.Equal(t, []string{"1"}, serverIDs(FilterServers(servers, "tag:prod tag:web")))
+	assert.Equal(t, []string{"1"}, serverIDs(FilterServers(servers, "web tag:prod")))
+	assert.Equal(t, []string{"2"}, serverIDs(FilterServers(servers, "postgres")))
+	assert.Equal(t, []string{"2"}, serverIDs(FilterServers(servers, "database")))
+	assert.Equal(t, []string{"3"}, serverIDs(FilterServers(servers, "staging")))
+	assert.Empty(t, FilterServers(servers, "tag:missing"))
+
+	// 别名前缀匹配排在模糊匹配之前，得分相同的保持原有顺序
+	assert.Equal(t, []string{"1", "3"}, serverIDs(FilterServers(servers, "web")))
+	assert.Equal(t, []string{"1", "2", "3"}, serverIDs(servers))
+}
+
+// TestMatchServer 测试单个服务器的匹配
+func TestMatchServer(t *testing.T) {
+	server := &ServerConfig{Alias: "prod-db", Host: "db.example.com", User: "admin", Tags: []string{"Prod"}}
+
+	_, ok := MatchServer(server, "pdb")
+	assert.True(t, ok)
+	_, ok = MatchServer(server, "tag:prod admin")
+	assert.True(t, ok)
+	_, ok = MatchServer(server, "tag:dev")
+	assert.False(t, ok)
+
+	aliasScore, _ := MatchServer(server, "prod")
+	descScore, _ := MatchServer(&ServerConfig{Description: "prod"}, "prod")
+	assert.Greater(t, aliasScore, descScore)
+}
+
+// TestListServersSorted 测试服务器列表顺序稳定
+func TestListServersSorted(t *testing.T) {
+	manager, err := NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+
+	for i, alias := range []string{"charlie", "", "alpha", "bravo"} {
+		server := NewServerConfig("zz.example.com")
+		server.Alias = alias
+		server.Port = 22 + i
+		server.Tags = []string{"prod"}
+		require.NoError(t, manager.AddServer(server))
+	}
+
+	var aliases []string
+	for i := 0; i < 5; i++ {
+		aliases = serverAliases(manager.ListServers())
+		assert.Equal(t, []string{"alpha", "bravo", "charlie", ""}, aliases)
+	}
+
+	servers, err := manager.FindServer("tag:prod")
+	require.NoError(t, err)
+	assert.Len(t, servers, 4)
+
+	_, err = manager.FindServer("tag:dev")
+	assert.Error(t, err)
+}
+
+// serverIDs 提取服务器ID列表
+func serverIDs(servers []*ServerConfig) []string {
+	var ids []string
+	for _, server := range servers {
+		ids = append(ids, server.ID)
+	}
+	return ids
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 配置文件写入参数
+const (
+	configBackups     = 5                     // 保留的轮换备份数
+	lockTimeout       = 10 * time.Second      // 等待其他进程释放配置文件锁的最长时间
+	lockRetryInterval = 50 * time.Millisecond // 获取配置文件锁的重试间隔
+)
+
+// parseConfig 解析配置文件内容，旧版本的配置在内存中升级（不备份、不写回）
+func parseConfig(data []byte) (*Config, error) {
+	data, _, err := migrateData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config := NewConfig()
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if config.Servers == nil {
+		config.Servers = make(map[string]*ServerConfig)
+	}
+	if config.ServerGroups == nil {
+		config.ServerGroups = make(map[string]*ServerGroup)
+	}
+	if config.ServerTemplates == nil {
+		config.ServerTemplates = make(map[string]*ServerTemplate)
+	}
+	if config.PortForwards == nil {
+		config.PortForwards = make(map[string]*PortForwardConfig)
+	}
+	if config.ForwardGroups == nil {
+		config.ForwardGroups = make(map[string]*ForwardGroup)
+	}
+	if config.Credentials == nil {
+		config.Credentials = make(map[string]*CredentialConfig)
+	}
+	if config.InventoryProviders == nil {
+		config.InventoryProviders = make(map[string]*InventoryProvider)
+	}
+	if config.Settings == nil {
+		config.Settings = NewConfig().Settings
+	}
+	return config, nil
+}
+
+// ReloadIfChanged 配置文件被其他进程修改时合并其修改，返回配置是否有变化
+// 本进程尚未保存的修改优先保留
+func (m *Manager) ReloadIfChanged() (bool, error) {
+	next, bases, err := m.split()
+	if err != nil {
+		return false, err
+	}
+	changed, err := m.mergeFromDisk(next, bases)
+	if err != nil || !changed {
+		return changed, err
+	}
+	m.applyLayers(next)
+	return true, m.rebuild()
+}
+
+// mergeFromDisk 将其他进程在本进程上次读写之后对各配置文件的修改合并到拆分后的各层内容
+// 按条目（服务器、端口转发、凭证等）和设置字段合并：本进程未修改的条目采用文件中的版本，
+// 双方都修改的条目保留本进程的版本
+func (m *Manager) mergeFromDisk(next, bases []layerState) (bool, error) {
+	changed := false
+	for i, l := range m.layers {
+		if l.Path == "" {
+			continue
+		}
+		data, err := os.ReadFile(l.Path)
+		if os.IsNotExist(err) {
+			data, err = nil, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("读取%s失败: %w", l, err)
+		}
+		if bytes.Equal(data, l.data) {
+			continue
+		}
+
+		theirs, err := l.parse(data)
+		if err != nil {
+			return false, fmt.Errorf("%s已被其他进程修改且无法读取: %w", l, err)
+		}
+		var conflicts []string
+		for _, s := range configSections {
+			conflicts = append(conflicts, mergeEntries(s.entries(bases[i].config), s.entries(next[i].config), s.entries(theirs.config))...)
+		}
+		conflicts = append(conflicts, mergeEntries(typedEntries[interface{}](bases[i].settings),
+			typedEntries[interface{}](next[i].settings), typedEntries[interface{}](theirs.settings))...)
+
+		l.data = data
+		changed = true
+		if len(conflicts) > 0 {
+			m.logger.Warn("配置条目同时被其他进程修改，保留本进程的修改", "path", l.Path, "entries", conflicts)
+		}
+		m.logger.Debug("已合并其他进程对配置的修改", "path", l.Path)
+	}
+	return changed, nil
+}
+
+// applyLayers 使用拆分合并后的内容更新各配置层
+func (m *Manager) applyLayers(next []layerState) {
+	for i, l := range m.layers {
+		l.layerState = next[i]
+	}
+}
+
+// mergeEntries 三方合并一段配置条目，直接修改 mine，返回双方都修改了的条目
+func mergeEntries(base, mine, theirs entryMap) []string {
+	var conflicts []string
+	for _, key := range theirs.keys() {
+		theirValue, _ := theirs.get(key)
+		baseValue, inBase := base.get(key)
+		mineValue, inMine := mine.get(key)
+		mineChanged := inMine != inBase || (inMine && !sameYAML(mineValue, baseValue))
+		if !mineChanged {
+			mine.set(key, theirValue)
+			continue
+		}
+		theirChanged := !inBase || !sameYAML(theirValue, baseValue)
+		if theirChanged && !(inMine && sameYAML(mineValue, theirValue)) {
+			conflicts = append(conflicts, key)
+		}
+	}
+
+	for _, key := range mine.keys() {
+		if _, inTheirs := theirs.get(key); inTheirs {
+			continue
+		}
+		// 其他进程删除了本进程未修改的条目
+		mineValue, _ := mine.get(key)
+		if baseValue, inBase := base.get(key); inBase && sameYAML(mineValue, baseValue) {
+			mine.remove(key)
+		}
+	}
+	return conflicts
+}
+
+// sameYAML 两个值序列化为 YAML 后是否相同
+func sameYAML(a, b interface{}) bool {
+	dataA, errA := yaml.Marshal(a)
+	dataB, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// writeFileAtomic 先写入同目录下的临时文件并同步到磁盘，再重命名替换目标文件，
+// 写入中途崩溃不会留下不完整的配置文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// 同步目录，确保重命名本身落盘（部分平台不支持，忽略错误）
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// rotatedBackupPath 第 n 份轮换备份的路径，如 config.yaml.bak.1（最新）
+func rotatedBackupPath(path string, n int) string {
+	return path + ".bak." + strconv.Itoa(n)
+}
+
+// rotateBackups 轮换备份当前的配置文件，最多保留 configBackups 份
+func rotateBackups(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+
+	for n := configBackups; n > 1; n-- {
+		if _, err := os.Stat(rotatedBackupPath(path, n-1)); err != nil {
+			continue
+		}
+		os.Remove(rotatedBackupPath(path, n))
+		if err := os.Rename(rotatedBackupPath(path, n-1), rotatedBackupPath(path, n)); err != nil {
+			return fmt.Errorf("轮换配置备份失败: %w", err)
+		}
+	}
+
+	// 当前文件随后会被原子替换，硬链接即可保留其内容；不支持硬链接时复制
+	latest := rotatedBackupPath(path, 1)
+	os.Remove(latest)
+	if err := os.Link(path, latest); err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("备份配置文件失败: %w", err)
+	}
+	if err := os.WriteFile(latest, data, 0600); err != nil {
+		return fmt.Errorf("备份配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// fileLock 进程间的咨询锁，保护配置文件的读-改-写
+type fileLock struct {
+	file *os.File
+}
+
+// lockConfig 锁定用户配置文件
+func (m *Manager) lockConfig() (*fileLock, error) {
+	return lockFile(m.configPath)
+}
+
+// lockFile 锁定配置文件，其他 gotssh 进程持有锁时等待，超时返回错误
+func lockFile(configPath string) (*fileLock, error) {
+	path := configPath + ".lock"
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("打开配置锁文件失败: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("锁定配置文件失败: %w", err)
+		}
+		if locked {
+			return &fileLock{file: f}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("等待配置文件锁超时，可能有其他 gotssh 进程正在写入配置 (%s)", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock 释放锁
+func (l *fileLock) Unlock() error {
+	unlockFile(l.file)
+	return l.file.Close()
+}
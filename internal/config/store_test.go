@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteFileAtomic 测试原子写入不留下临时文件
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	require.NoError(t, writeFileAtomic(path, []byte("new"), 0600))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// TestConcurrentManagers 测试多个进程（各自的配置管理器）同时修改配置时互不覆盖
+func TestConcurrentManagers(t *testing.T) {
+	path := createTempConfigFile(t)
+	first, err := NewManager(path)
+	require.NoError(t, err)
+	second, err := NewManager(path)
+	require.NoError(t, err)
+
+	// 两个进程各自添加服务器
+	require.NoError(t, first.AddServer(NewServerConfig("10.0.0.1")))
+	require.NoError(t, second.AddServer(NewServerConfig("10.0.0.2")))
+
+	reloaded, err := NewManager(path)
+	require.NoError(t, err)
+	assert.Len(t, reloaded.ListServers(), 2)
+
+	// 另一个进程的修改在重新检查时合并进来
+	changed, err := first.ReloadIfChanged()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Len(t, first.ListServers(), 2)
+	changed, err = first.ReloadIfChanged()
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// 删除和修改同样合并
+	hosts, err := first.GetServerByHost("10.0.0.1")
+	require.NoError(t, err)
+	require.NoError(t, first.DeleteServer(hosts[0].ID))
+	cred := NewCredentialConfig()
+	cred.Alias = "ops"
+	cred.Type = CredentialTypePassword
+	require.NoError(t, second.AddCredential(cred))
+
+	reloaded, err = NewManager(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2"}, serverHosts(reloaded.ListServers()))
+	assert.Len(t, reloaded.ListCredentials(), 1)
+
+	// 双方都修改同一台服务器时，后保存的进程的修改生效
+	_, err = first.ReloadIfChanged()
+	require.NoError(t, err)
+	firstCopy := *first.ListServers()[0]
+	firstCopy.Description = "first"
+	secondCopy := *second.ListServers()[0]
+	secondCopy.Description = "second"
+	require.NoError(t, first.UpdateServer(firstCopy.ID, &firstCopy))
+	require.NoError(t, second.UpdateServer(secondCopy.ID, &secondCopy))
+
+	reloaded, err = NewManager(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", reloaded.ListServers()[0].Description)
+}
+
+// TestParallelSaves 测试并发写入时不丢失修改
+func TestParallelSaves(t *testing.T) {
+	path := createTempConfigFile(t)
+	_, err := NewManager(path)
+	require.NoError(t, err)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			manager, err := NewManager(path)
+			if err == nil {
+				err = manager.AddServer(NewServerConfig(fmt.Sprintf("10.0.1.%d", i)))
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	reloaded, err := NewManager(path)
+	require.NoError(t, err)
+	assert.Len(t, reloaded.ListServers(), workers)
+}
+
+// TestSaveWaitsForLock 测试保存时等待其他进程释放配置文件锁
+func TestSaveWaitsForLock(t *testing.T) {
+	path := createTempConfigFile(t)
+	holder, err := NewManager(path)
+	require.NoError(t, err)
+	writer, err := NewManager(path)
+	require.NoError(t, err)
+
+	lock, err := holder.lockConfig()
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- writer.AddServer(NewServerConfig("10.0.0.1"))
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("持有锁时不应完成保存")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, lock.Unlock())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("释放锁后保存未完成")
+	}
+}
+
+// TestRotateBackups 测试保存时轮换备份
+func TestRotateBackups(t *testing.T) {
+	path := createTempConfigFile(t)
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+
+	var previous []byte
+	for i := 0; i < configBackups+2; i++ {
+		previous, err = os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, manager.AddServer(NewServerConfig(fmt.Sprintf("10.0.2.%d", i))))
+	}
+
+	for n := 1; n <= configBackups; n++ {
+		assert.FileExists(t, fmt.Sprintf("%s.bak.%d", path, n))
+	}
+	assert.NoFileExists(t, fmt.Sprintf("%s.bak.%d", path, configBackups+1))
+
+	latest, err := os.ReadFile(path + ".bak.1")
+	require.NoError(t, err)
+	assert.Equal(t, string(previous), string(latest))
+
+	// 内容未变化时不写入也不轮换
+	info, err := os.Stat(path + ".bak.1")
+	require.NoError(t, err)
+	require.NoError(t, manager.Save())
+	after, err := os.Stat(path + ".bak.1")
+	require.NoError(t, err)
+	assert.Equal(t, info.ModTime(), after.ModTime())
+}
+
+// serverHosts 服务器主机列表
+func serverHosts(servers []*ServerConfig) []string {
+	hosts := make([]string, 0, len(servers))
+	for _, server := range servers {
+		hosts = append(hosts, server.Host)
+	}
+	return hosts
+}
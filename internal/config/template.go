@@ -0,0 +1,330 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 模板展开参数
+const (
+	templateVar        = "{n}" // 主机和别名模式中代表序号的变量
+	maxTemplateServers = 1000  // 一次最多展开的服务器数
+	rangeSeparator     = "-"   // 范围起止分隔符
+	listSeparator      = ","   // 多个范围之间的分隔符
+)
+
+// rangeExpr 主机模式中的范围表达式，如 [01-40] 或 [1-3,7]
+var rangeExpr = regexp.MustCompile(`\[([^\[\]]+)\]`)
+
+// ServerTemplate 服务器模板，按主机模式批量创建共享认证、代理和标签的服务器
+type ServerTemplate struct {
+	Name          string       `yaml:"name"`                     // 模板名称
+	Host          string       `yaml:"host"`                     // 主机模式，如 node-[01-40].internal 或 node-{n}.internal
+	Alias         string       `yaml:"alias,omitempty"`          // 别名模式，如 k8s-{n}，为空时不设置别名
+	Port          int          `yaml:"port,omitempty"`           // SSH端口
+	User          string       `yaml:"user,omitempty"`           // 用户名
+	AuthType      AuthType     `yaml:"auth_type,omitempty"`      // 认证类型
+	CredentialID  string       `yaml:"credential_id,omitempty"`  // 引用的凭证ID
+	KeyPath       string       `yaml:"key_path,omitempty"`       // 密钥文件路径
+	Proxy         *ProxyConfig `yaml:"proxy,omitempty"`          // 代理配置
+	JumpHost      string       `yaml:"jump_host,omitempty"`      // 跳板机
+	Group         string       `yaml:"group,omitempty"`          // 所属分组
+	Tags          []string     `yaml:"tags,omitempty"`           // 标签
+	StartupScript string       `yaml:"startup_script,omitempty"` // 启动脚本
+	Description   string       `yaml:"description,omitempty"`    // 描述，可使用 {n}
+}
+
+// ParseRange 解析范围，如 "01-40"、"1-3,7"；起始值带前导零时结果按相同宽度补零
+func ParseRange(spec string) ([]string, error) {
+	var values []string
+	for _, part := range strings.Split(spec, listSeparator) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		start, end, isRange := strings.Cut(part, rangeSeparator)
+		if !isRange {
+			if _, err := strconv.Atoi(part); err != nil {
+				return nil, fmt.Errorf("无效的范围 '%s'", part)
+			}
+			values = append(values, part)
+			continue
+		}
+
+		from, err1 := strconv.Atoi(strings.TrimSpace(start))
+		to, err2 := strconv.Atoi(strings.TrimSpace(end))
+		if err1 != nil || err2 != nil || from < 0 || to < from {
+			return nil, fmt.Errorf("无效的范围 '%s'，应为 起始-结束，如 01-40", part)
+		}
+		if to-from+1 > maxTemplateServers {
+			return nil, fmt.Errorf("范围 '%s' 超过 %d 个", part, maxTemplateServers)
+		}
+
+		width := 0
+		if start = strings.TrimSpace(start); len(start) > 1 && start[0] == '0' {
+			width = len(start)
+		}
+		for i := from; i <= to; i++ {
+			values = append(values, fmt.Sprintf("%0*d", width, i))
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("范围不能为空")
+	}
+	if len(values) > maxTemplateServers {
+		return nil, fmt.Errorf("范围超过 %d 个", maxTemplateServers)
+	}
+	return values, nil
+}
+
+// ExpandHosts 展开主机模式，返回每个主机及其序号
+// 指定 rangeSpec 时替换模式中的 {n} 或第一个范围表达式；否则使用模式中的范围表达式
+func (t *ServerTemplate) ExpandHosts(rangeSpec string) (hosts, indexes []string, err error) {
+	pattern := t.Host
+	if strings.Count(pattern, templateVar) == 0 && !rangeExpr.MatchString(pattern) {
+		return nil, nil, fmt.Errorf("模板 '%s' 的主机模式 '%s' 中没有 {n} 或 [起始-结束]", t.Name, pattern)
+	}
+
+	var spec string
+	switch {
+	case rangeSpec != "":
+		spec = rangeSpec
+		if !strings.Contains(pattern, templateVar) {
+			loc := rangeExpr.FindStringIndex(pattern)
+			pattern = pattern[:loc[0]] + templateVar + pattern[loc[1]:]
+		}
+	case strings.Contains(pattern, templateVar):
+		return nil, nil, fmt.Errorf("模板 '%s' 的主机模式使用 {n}，需要指定范围", t.Name)
+	default:
+		match := rangeExpr.FindStringSubmatchIndex(pattern)
+		spec = pattern[match[2]:match[3]]
+		pattern = pattern[:match[0]] + templateVar + pattern[match[1]:]
+	}
+	if rangeExpr.MatchString(pattern) {
+		return nil, nil, fmt.Errorf("模板 '%s' 的主机模式只能包含一个范围", t.Name)
+	}
+
+	indexes, err = ParseRange(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, n := range indexes {
+		hosts = append(hosts, strings.ReplaceAll(pattern, templateVar, n))
+	}
+	return hosts, indexes, nil
+}
+
+// Expand 按模板生成服务器配置（尚未保存）
+func (t *ServerTemplate) Expand(rangeSpec string) ([]*ServerConfig, error) {
+	hosts, indexes, err := t.ExpandHosts(rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make([]*ServerConfig, 0, len(hosts))
+	for i, host := range hosts {
+		server := NewServerConfig(host)
+		server.Template = t.Name
+		server.Alias = strings.ReplaceAll(t.Alias, templateVar, indexes[i])
+		server.Port = t.Port
+		server.User = t.User
+		server.AuthType = t.AuthType
+		server.CredentialID = t.CredentialID
+		server.KeyPath = t.KeyPath
+		if t.Proxy != nil {
+			proxy := *t.Proxy
+			server.Proxy = &proxy
+		}
+		server.JumpHost = t.JumpHost
+		server.Group = NormalizeGroupPath(t.Group)
+		server.Tags = append([]string{}, t.Tags...)
+		server.StartupScript = t.StartupScript
+		server.Description = strings.ReplaceAll(t.Description, templateVar, indexes[i])
+
+		// 不在分组中时未设置的字段使用与手动添加相同的默认值
+		if server.Group == "" {
+			if server.Port == 0 {
+				server.Port = 22
+			}
+			if server.User == "" {
+				server.User = "root"
+			}
+			if server.AuthType == "" {
+				server.AuthType = AuthTypeAsk
+			}
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// AddServerTemplate 添加服务器模板
+func (m *Manager) AddServerTemplate(t *ServerTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("模板名称不能为空")
+	}
+	if _, exists := m.config.ServerTemplates[t.Name]; exists {
+		return fmt.Errorf("模板 '%s' 已存在", t.Name)
+	}
+	if _, _, err := t.ExpandHosts("1"); err != nil {
+		return err
+	}
+
+	m.config.ServerTemplates[t.Name] = t
+	return m.Save()
+}
+
+// DeleteServerTemplate 删除服务器模板，由模板创建的服务器保留
+func (m *Manager) DeleteServerTemplate(name string) error {
+	if _, exists := m.config.ServerTemplates[name]; !exists {
+		return fmt.Errorf("模板 '%s' 不存在", name)
+	}
+	delete(m.config.ServerTemplates, name)
+	return m.Save()
+}
+
+// GetServerTemplate 获取服务器模板
+func (m *Manager) GetServerTemplate(name string) (*ServerTemplate, error) {
+	t, exists := m.config.ServerTemplates[name]
+	if !exists {
+		return nil, fmt.Errorf("模板 '%s' 不存在", name)
+	}
+	if t.Name == "" {
+		t.Name = name
+	}
+	return t, nil
+}
+
+// ListServerTemplates 列出所有服务器模板，按名称排序
+func (m *Manager) ListServerTemplates() []*ServerTemplate {
+	templates := make([]*ServerTemplate, 0, len(m.config.ServerTemplates))
+	for name, t := range m.config.ServerTemplates {
+		if t.Name == "" {
+			t.Name = name
+		}
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// TemplatePreview 模板展开后的一项预览
+type TemplatePreview struct {
+	Server   *ServerConfig
+	Conflict string // 与已有服务器冲突的原因，为空表示可以添加
+}
+
+// PreviewServerTemplate 展开模板并检查与已有服务器的冲突
+func (m *Manager) PreviewServerTemplate(name, rangeSpec string) ([]TemplatePreview, error) {
+	t, err := m.GetServerTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := t.Expand(rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]TemplatePreview, 0, len(servers))
+	seen := make(map[string]bool)
+	seenAlias := make(map[string]bool)
+	for _, server := range servers {
+		preview := TemplatePreview{Server: server}
+		resolved := m.ResolveServer(server)
+		key := fmt.Sprintf("%s@%s:%d", resolved.User, resolved.Host, resolved.Port)
+		switch {
+		case seen[key] || (server.Alias != "" && seenAlias[server.Alias]):
+			preview.Conflict = "范围内重复"
+		case m.serverExists(resolved):
+			preview.Conflict = "服务器已存在"
+		case server.Alias != "" && m.aliasExists(server.Alias):
+			preview.Conflict = fmt.Sprintf("别名 '%s' 已存在", server.Alias)
+		}
+		seen[key] = true
+		seenAlias[server.Alias] = true
+		previews = append(previews, preview)
+	}
+	return previews, nil
+}
+
+// AddServersFromTemplate 按模板批量添加服务器，跳过冲突的服务器，返回添加和跳过的数量
+func (m *Manager) AddServersFromTemplate(name, rangeSpec string) (added, skipped int, err error) {
+	previews, err := m.PreviewServerTemplate(name, rangeSpec)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now()
+	for _, preview := range previews {
+		if preview.Conflict != "" {
+			skipped++
+			continue
+		}
+		server := preview.Server
+		for m.config.Servers[server.ID] != nil {
+			server.ID = generateID()
+		}
+		server.CreatedAt = now
+		server.UpdatedAt = now
+		m.config.Servers[server.ID] = server
+		added++
+	}
+	if added == 0 {
+		return 0, skipped, nil
+	}
+	return added, skipped, m.Save()
+}
+
+// ServersByTemplate 列出由模板创建的服务器
+func (m *Manager) ServersByTemplate(name string) []*ServerConfig {
+	var servers []*ServerConfig
+	for _, server := range m.ListServers() {
+		if server.Template == name {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// DeleteServersByTemplate 删除由模板创建的所有服务器及其端口转发，返回删除的数量
+func (m *Manager) DeleteServersByTemplate(name string) (int, error) {
+	servers := m.ServersByTemplate(name)
+	if len(servers) == 0 {
+		return 0, nil
+	}
+
+	for _, server := range servers {
+		m.removeServer(server.ID)
+	}
+	return len(servers), m.Save()
+}
+
+// serverExists 是否已存在相同用户、主机和端口的服务器（按继承分组默认值后的设置比较）
+func (m *Manager) serverExists(candidate *ServerConfig) bool {
+	for _, existing := range m.config.Servers {
+		existing = m.ResolveServer(existing)
+		if existing.Host == candidate.Host && existing.Port == candidate.Port && existing.User == candidate.User {
+			return true
+		}
+	}
+	return false
+}
+
+// aliasExists 别名是否已被使用
+func (m *Manager) aliasExists(alias string) bool {
+	for _, existing := range m.config.Servers {
+		if existing.Alias == alias {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRange 测试范围解析
+func TestParseRange(t *testing.T) {
+	values, err := ParseRange("01-03")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01", "02", "03"}, values)
+
+	values, err = ParseRange("8-10, 15")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"8", "9", "10", "15"}, values)
+
+	values, err = ParseRange("098-100")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"098", "099", "100"}, values)
+
+	for _, spec := range []string{"", "a-b", "5-1", "x", "1-2000"} {
+		_, err := ParseRange(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// TestExpandHosts 测试主机模式展开
+func TestExpandHosts(t *testing.T) {
+	bracket := &ServerTemplate{Name: "k8s", Host: "node-[01-03].internal"}
+	hosts, indexes, err := bracket.ExpandHosts("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node-01.internal", "node-02.internal", "node-03.internal"}, hosts)
+	assert.Equal(t, []string{"01", "02", "03"}, indexes)
+
+	// 指定范围时替换模式中的范围
+	hosts, _, err = bracket.ExpandHosts("7")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node-7.internal"}, hosts)
+
+	variable := &ServerTemplate{Name: "web", Host: "web{n}.example.com"}
+	_, _, err = variable.ExpandHosts("")
+	assert.Error(t, err)
+	hosts, _, err = variable.ExpandHosts("1-2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web1.example.com", "web2.example.com"}, hosts)
+
+	_, _, err = (&ServerTemplate{Name: "plain", Host: "example.com"}).ExpandHosts("1")
+	assert.Error(t, err)
+	_, _, err = (&ServerTemplate{Name: "two", Host: "r[1-2]-n[1-2]"}).ExpandHosts("")
+	assert.Error(t, err)
+}
+
+// TestExpandTemplate 测试按模板生成服务器配置
+func TestExpandTemplate(t *testing.T) {
+	tmpl := &ServerTemplate{
+		Name:         "k8s",
+		Host:         "node-[01-02].internal",
+		Alias:        "k8s-{n}",
+		User:         "ubuntu",
+		AuthType:     AuthTypeCredential,
+		CredentialID: "cred-k8s",
+		Proxy:        &ProxyConfig{Type: "socks5", Host: "proxy", Port: 1080},
+		Tags:         []string{"k8s"},
+		Description:  "节点 {n}",
+	}
+
+	servers, err := tmpl.Expand("")
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	assert.Equal(t, "node-02.internal", servers[1].Host)
+	assert.Equal(t, "k8s-02", servers[1].Alias)
+	assert.Equal(t, "节点 02", servers[1].Description)
+	assert.Equal(t, "k8s", servers[1].Template)
+	assert.Equal(t, 22, servers[1].Port)
+	assert.Equal(t, "ubuntu", servers[1].User)
+	assert.Equal(t, "cred-k8s", servers[1].CredentialID)
+	assert.Equal(t, []string{"k8s"}, servers[1].Tags)
+
+	// 代理和标签不在服务器之间共享
+	assert.NotSame(t, servers[0].Proxy, servers[1].Proxy)
+	servers[0].Tags[0] = "changed"
+	assert.Equal(t, "k8s", servers[1].Tags[0])
+
+	// 在分组中时未设置的字段留空，由分组继承
+	grouped := &ServerTemplate{Name: "db", Host: "db{n}", Group: " prod/db/ "}
+	servers, err = grouped.Expand("1")
+	require.NoError(t, err)
+	assert.Equal(t, "prod/db", servers[0].Group)
+	assert.Zero(t, servers[0].Port)
+	assert.Empty(t, servers[0].User)
+}
+
+// TestServerTemplateManagement 测试模板的添加、预览、批量添加和批量删除
+func TestServerTemplateManagement(t *testing.T) {
+	configPath := createTempConfigFile(t)
+	manager, err := NewManager(configPath)
+	require.NoError(t, err)
+
+	assert.Error(t, manager.AddServerTemplate(&ServerTemplate{Name: "bad", Host: "example.com"}))
+	require.NoError(t, manager.AddServerTemplate(&ServerTemplate{Name: "k8s", Host: "node-[01-04]", Alias: "k8s-{n}"}))
+	assert.Error(t, manager.AddServerTemplate(&ServerTemplate{Name: "k8s", Host: "other-[1-2]"}))
+
+	existing := NewServerConfig("node-02")
+	require.NoError(t, manager.AddServer(existing))
+	taken := NewServerConfig("10.0.0.9")
+	taken.Alias = "k8s-03"
+	require.NoError(t, manager.AddServer(taken))
+
+	previews, err := manager.PreviewServerTemplate("k8s", "")
+	require.NoError(t, err)
+	require.Len(t, previews, 4)
+	assert.Empty(t, previews[0].Conflict)
+	assert.Equal(t, "服务器已存在", previews[1].Conflict)
+	assert.Equal(t, "别名 'k8s-03' 已存在", previews[2].Conflict)
+	assert.Empty(t, previews[3].Conflict)
+
+	previews, err = manager.PreviewServerTemplate("k8s", "1,1")
+	require.NoError(t, err)
+	assert.Equal(t, "范围内重复", previews[1].Conflict)
+
+	_, err = manager.PreviewServerTemplate("missing", "")
+	assert.Error(t, err)
+
+	added, skipped, err := manager.AddServersFromTemplate("k8s", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 2, skipped)
+	assert.ElementsMatch(t, []string{"k8s-01", "k8s-04"}, serverAliases(manager.ServersByTemplate("k8s")))
+
+	// 再次添加时全部跳过
+	added, skipped, err = manager.AddServersFromTemplate("k8s", "")
+	require.NoError(t, err)
+	assert.Zero(t, added)
+	assert.Equal(t, 4, skipped)
+
+	// 重新加载后保留模板和来源
+	reloaded, err := NewManager(configPath)
+	require.NoError(t, err)
+	tmpl, err := reloaded.GetServerTemplate("k8s")
+	require.NoError(t, err)
+	assert.Equal(t, "node-[01-04]", tmpl.Host)
+	assert.Len(t, reloaded.ServersByTemplate("k8s"), 2)
+
+	// 批量删除同时删除端口转发，不影响手动添加的服务器
+	server, err := reloaded.GetServerByAlias("k8s-01")
+	require.NoError(t, err)
+	pf := NewPortForwardConfig(server.ID)
+	pf.LocalPort = 8080
+	pf.RemotePort = 80
+	require.NoError(t, reloaded.AddPortForward(pf))
+
+	deleted, err := reloaded.DeleteServersByTemplate("k8s")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Empty(t, reloaded.ServersByTemplate("k8s"))
+	assert.Empty(t, reloaded.ListPortForwards())
+	assert.Len(t, reloaded.ListServers(), 2)
+
+	require.NoError(t, reloaded.DeleteServerTemplate("k8s"))
+	assert.Empty(t, reloaded.ListServerTemplates())
+}
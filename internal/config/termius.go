@@ -0,0 +1,342 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseTermiusJSON 解析 Termius 或其他客户端导出的 JSON 会话，支持主机数组或
+// {"hosts": [...], "identities": [...], "groups": [...]} 形式。字段按常见名称识别：
+//
+//	主机：label/name、address/hostname/host、port、username/user、password、key/key_path、
+//	      identity（引用 identities 中的 id 或 label，或直接写对象）、group、tags、description/notes、
+//	      jump_host/jump_hosts/host_chain、port_forwarding/port_forwards/tunnels，也可以写在 ssh_config 中
+//	分组：id、label/name、parent_group/parent，主机的 group 可以是分组路径或分组的 id
+//	转发：type（local/remote/dynamic）、label、bind_address、bind_port、host、port
+func ParseTermiusJSON(data []byte) ([]*ImportEntry, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+
+	var hosts []interface{}
+	var doc map[string]interface{}
+	switch v := root.(type) {
+	case []interface{}:
+		hosts = v
+	case map[string]interface{}:
+		doc = v
+		hosts = jsonList(doc, "hosts", "sessions", "servers")
+		if hosts == nil {
+			return nil, fmt.Errorf("未找到主机列表（hosts）")
+		}
+	default:
+		return nil, fmt.Errorf("JSON 顶层应为主机数组或包含 hosts 的对象")
+	}
+
+	identities := make(map[string]map[string]interface{})
+	for _, item := range jsonList(doc, "identities", "credentials") {
+		if identity, ok := item.(map[string]interface{}); ok {
+			for _, key := range []string{"id", "label", "name"} {
+				if ref := jsonString(identity, key); ref != "" {
+					identities[ref] = identity
+				}
+			}
+		}
+	}
+	groups := termiusGroups(jsonList(doc, "groups"))
+
+	entries := make([]*ImportEntry, 0, len(hosts))
+	for i, item := range hosts {
+		host, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("第 %d 个主机不是对象", i+1)
+		}
+		entries = append(entries, termiusEntry(host, identities, groups))
+	}
+	return entries, nil
+}
+
+// termiusEntry 将一个 JSON 主机转换为待导入的服务器
+func termiusEntry(host map[string]interface{}, identities map[string]map[string]interface{}, groups map[string]string) *ImportEntry {
+	// ssh_config 中的设置与主机上的设置合并，主机上的优先
+	if sshConfig, ok := host["ssh_config"].(map[string]interface{}); ok {
+		merged := make(map[string]interface{}, len(host)+len(sshConfig))
+		for k, v := range sshConfig {
+			merged[k] = v
+		}
+		for k, v := range host {
+			merged[k] = v
+		}
+		host = merged
+	}
+
+	server := &ServerConfig{Tags: []string{}}
+	server.Host = jsonString(host, "address", "hostname", "host", "ip")
+	server.Alias = jsonString(host, "label", "name", "alias")
+	if server.Alias == "" {
+		server.Alias = server.Host
+	}
+	server.User = jsonString(host, "username", "user")
+	server.Description = jsonString(host, "description", "notes", "comment")
+	entry := &ImportEntry{Name: server.Alias, Server: server}
+
+	if group := jsonString(host, "group", "folder"); group != "" {
+		if path, ok := groups[group]; ok {
+			group = path
+		}
+		server.Group = NormalizeGroupPath(group)
+	}
+	for _, tag := range jsonList(host, "tags") {
+		if tag := jsonValueString(tag); tag != "" {
+			server.Tags = append(server.Tags, tag)
+		}
+	}
+
+	port, err := jsonPort(host, "port")
+	if err != nil {
+		entry.Conflict = fmt.Sprintf("端口 '%v' 无效", host["port"])
+		return entry
+	}
+	server.Port = port
+	if server.Host == "" {
+		entry.Conflict = "未设置主机"
+		return entry
+	}
+
+	// 引用或内联的身份作为凭证，主机上直接保存的密码和密钥作为服务器的认证方式
+	if identity := termiusIdentity(host["identity"], identities); identity != nil {
+		if server.User == "" {
+			server.User = jsonString(identity, "username", "user")
+		}
+		entry.Credential = termiusCredential(identity)
+	}
+	if entry.Credential == nil {
+		if password := jsonString(host, "password"); password != "" {
+			server.AuthType = AuthTypePassword
+			server.Password = password
+		} else if key := termiusKey(host); key != nil {
+			if key.KeyContent != "" {
+				key.Alias = server.Alias + "-key"
+				entry.Credential = key
+			} else {
+				entry.KeyPath = key.KeyPath
+			}
+		}
+	}
+
+	var hops []string
+	for _, hop := range jsonList(host, "jump_hosts", "host_chain") {
+		if hop := jsonValueString(hop); hop != "" {
+			hops = append(hops, hop)
+		}
+	}
+	if len(hops) == 0 {
+		if hop := jsonString(host, "jump_host", "proxy_jump"); hop != "" {
+			hops = append(hops, hop)
+		}
+	}
+	entry.JumpHost = strings.Join(hops, ",")
+
+	for _, item := range jsonList(host, "port_forwarding", "port_forwards", "tunnels", "forwards") {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pf, note := termiusForward(server.Alias, rule)
+		if pf != nil {
+			entry.Forwards = append(entry.Forwards, pf)
+		}
+		if note != "" {
+			entry.Notes = append(entry.Notes, note)
+		}
+	}
+	return entry
+}
+
+// termiusGroups 将分组列表转换为分组ID到分组路径的映射，按上级分组拼接路径
+func termiusGroups(items []interface{}) map[string]string {
+	byID := make(map[string]map[string]interface{})
+	for _, item := range items {
+		if group, ok := item.(map[string]interface{}); ok {
+			if id := jsonString(group, "id"); id != "" {
+				byID[id] = group
+			}
+		}
+	}
+
+	paths := make(map[string]string, len(byID))
+	for id := range byID {
+		var parts []string
+		seen := make(map[string]bool)
+		for current := id; current != "" && !seen[current] && byID[current] != nil; {
+			seen[current] = true
+			group := byID[current]
+			parts = append([]string{jsonString(group, "label", "name")}, parts...)
+			current = jsonString(group, "parent_group", "parent")
+		}
+		paths[id] = strings.Join(parts, groupSeparator)
+	}
+	return paths
+}
+
+// termiusIdentity 主机引用的身份：身份对象本身，或 identities 中的 id 或 label
+func termiusIdentity(value interface{}, identities map[string]map[string]interface{}) map[string]interface{} {
+	if identity, ok := value.(map[string]interface{}); ok {
+		return identity
+	}
+	if ref := jsonValueString(value); ref != "" {
+		return identities[ref]
+	}
+	return nil
+}
+
+// termiusCredential 由身份生成凭证，有密钥时为密钥凭证，只有用户名的身份返回 nil
+func termiusCredential(identity map[string]interface{}) *CredentialConfig {
+	cred := termiusKey(identity)
+	if cred == nil {
+		cred = NewCredentialConfig()
+	}
+	if alias := jsonString(identity, "label", "name"); alias != "" {
+		cred.Alias = alias
+	}
+	cred.Username = jsonString(identity, "username", "user")
+	cred.Password = jsonString(identity, "password")
+	if cred.Type == CredentialTypePassword && cred.Password == "" {
+		return nil
+	}
+	return cred
+}
+
+// termiusKey 对象中的私钥，可以是文件路径、密钥内容或 {path, private_key, passphrase} 对象，没有时返回 nil
+func termiusKey(obj map[string]interface{}) *CredentialConfig {
+	value := obj["ssh_key"]
+	for _, name := range []string{"key", "key_path", "private_key", "identity_file"} {
+		if value == nil {
+			value = obj[name]
+		}
+	}
+
+	cred := NewCredentialConfig()
+	cred.Type = CredentialTypeKey
+	switch key := value.(type) {
+	case string:
+		cred.KeyPath = key
+	case map[string]interface{}:
+		cred.Alias = jsonString(key, "label", "name")
+		cred.KeyPath = jsonString(key, "path", "key_path", "file")
+		cred.KeyContent = jsonString(key, "private_key", "content")
+		cred.KeyPassphrase = jsonString(key, "passphrase")
+	}
+	if strings.HasPrefix(strings.TrimSpace(cred.KeyPath), "-----BEGIN") {
+		cred.KeyContent, cred.KeyPath = cred.KeyPath, ""
+	}
+	if cred.KeyPath == "" && cred.KeyContent == "" {
+		return nil
+	}
+	return cred
+}
+
+// termiusForward 由转发规则生成端口转发，不支持的规则返回说明
+func termiusForward(name string, rule map[string]interface{}) (*PortForwardConfig, string) {
+	kind := strings.ToLower(jsonString(rule, "type", "kind"))
+	bindPort, err := jsonPort(rule, "bind_port", "listen_port")
+	if err != nil {
+		return nil, fmt.Sprintf("端口转发的监听端口 '%v' 无效，已忽略", rule["bind_port"])
+	}
+	port, err := jsonPort(rule, "port", "target_port")
+	if err != nil {
+		return nil, fmt.Sprintf("端口转发的目标端口 '%v' 无效，已忽略", rule["port"])
+	}
+
+	pf := NewPortForwardConfig("")
+	pf.Alias = jsonString(rule, "label", "name")
+	pf.Description = jsonString(rule, "description")
+	switch kind {
+	case "local", "l", "":
+		if bindPort == 0 {
+			bindPort, _ = jsonPort(rule, "local_port")
+		}
+		if port == 0 {
+			port, _ = jsonPort(rule, "remote_port")
+		}
+		if host := jsonString(rule, "bind_address"); host != "" {
+			pf.LocalHost = host
+		}
+		pf.LocalPort = bindPort
+		pf.RemoteHost = jsonString(rule, "host", "target_host", "remote_host")
+		pf.RemotePort = port
+	case "remote", "r":
+		if bindPort == 0 {
+			bindPort, _ = jsonPort(rule, "remote_port")
+		}
+		if port == 0 {
+			port, _ = jsonPort(rule, "local_port")
+		}
+		pf.Type = ForwardTypeRemote
+		if host := jsonString(rule, "bind_address"); host != "" {
+			pf.RemoteHost = host
+		}
+		pf.RemotePort = bindPort
+		pf.LocalHost = jsonString(rule, "host", "target_host", "local_host")
+		pf.LocalPort = port
+	case "dynamic", "d":
+		return nil, fmt.Sprintf("不支持本地动态转发（SOCKS）%d，已忽略", bindPort)
+	default:
+		return nil, fmt.Sprintf("未知的端口转发类型 '%s'，已忽略", kind)
+	}
+
+	if pf.LocalHost == "" || pf.RemoteHost == "" || port == 0 {
+		return nil, fmt.Sprintf("端口转发 '%s' 缺少目标地址，已忽略", pf.Alias)
+	}
+	if pf.Alias == "" {
+		pf.Alias = fmt.Sprintf("%s-%d", name, bindPort)
+	}
+	return pf, ""
+}
+
+// jsonString 第一个已设置的字段的字符串值，数字转换为字符串
+func jsonString(obj map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value := jsonValueString(obj[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// jsonValueString JSON 值的字符串形式，对象、数组和 null 返回空字符串
+func jsonValueString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// jsonPort 第一个已设置的字段的端口值，可以是数字或数字字符串，未设置时返回0
+func jsonPort(obj map[string]interface{}, keys ...string) (int, error) {
+	value := jsonString(obj, keys...)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > 65535 {
+		return 0, fmt.Errorf("无效的端口 '%s'", value)
+	}
+	return n, nil
+}
+
+// jsonList 第一个已设置的数组字段
+func jsonList(obj map[string]interface{}, keys ...string) []interface{} {
+	for _, key := range keys {
+		if list, ok := obj[key].([]interface{}); ok {
+			return list
+		}
+	}
+	return nil
+}
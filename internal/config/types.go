@@ -1,7 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,14 +20,33 @@ const (
 	AuthTypeAsk        AuthType = "ask"        // 每次询问
 )
 
+// DefaultSocketMode 本地Unix套接字默认权限
+const DefaultSocketMode os.FileMode = 0600
+
 // ForwardType 端口转发类型
 type ForwardType string
 
 const (
-	ForwardTypeLocal  ForwardType = "local"  // 本地端口转发
-	ForwardTypeRemote ForwardType = "remote" // 远程端口转发
+	ForwardTypeLocal         ForwardType = "local"          // 本地端口转发
+	ForwardTypeRemote        ForwardType = "remote"         // 远程端口转发
+	ForwardTypeRemoteDynamic ForwardType = "remote-dynamic" // 远程动态转发（服务器通过本机的SOCKS5代理访问网络）
+	ForwardTypeHTTPProxy     ForwardType = "http-proxy"     // 本地HTTP代理（经服务器访问网络）
 )
 
+// Valid 是否为支持的转发类型
+func (t ForwardType) Valid() bool {
+	switch t {
+	case ForwardTypeLocal, ForwardTypeRemote, ForwardTypeRemoteDynamic, ForwardTypeHTTPProxy:
+		return true
+	}
+	return false
+}
+
+// ListensRemote 是否在服务器上监听
+func (t ForwardType) ListensRemote() bool {
+	return t == ForwardTypeRemote || t == ForwardTypeRemoteDynamic
+}
+
 // CredentialType 凭证类型
 type CredentialType string
 
@@ -57,65 +81,268 @@ type ProxyConfig struct {
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	ID            string       `yaml:"id"`             // 服务器ID
-	Alias         string       `yaml:"alias"`          // 别名
-	Host          string       `yaml:"host"`           // 主机地址
-	Port          int          `yaml:"port"`           // SSH端口
-	User          string       `yaml:"user"`           // 用户名
-	AuthType      AuthType     `yaml:"auth_type"`      // 认证类型
-	CredentialID  string       `yaml:"credential_id"`  // 引用的凭证ID
-	Password      string       `yaml:"password"`       // 密码（如果使用密码认证）
-	KeyPath       string       `yaml:"key_path"`       // 密钥文件路径
-	KeyPassphrase string       `yaml:"key_passphrase"` // 密钥密码
-	StartupScript string       `yaml:"startup_script"` // 启动脚本
-	Proxy         *ProxyConfig `yaml:"proxy"`          // 代理配置
-	Tags          []string     `yaml:"tags"`           // 标签
-	Description   string       `yaml:"description"`    // 描述
-	CreatedAt     time.Time    `yaml:"created_at"`     // 创建时间
-	UpdatedAt     time.Time    `yaml:"updated_at"`     // 更新时间
+	ID            string       `yaml:"id"`                       // 服务器ID
+	Alias         string       `yaml:"alias"`                    // 别名
+	Host          string       `yaml:"host"`                     // 主机地址
+	Port          int          `yaml:"port"`                     // SSH端口
+	User          string       `yaml:"user"`                     // 用户名
+	AuthType      AuthType     `yaml:"auth_type"`                // 认证类型
+	CredentialID  string       `yaml:"credential_id"`            // 引用的凭证ID
+	Credential    string       `yaml:"credential,omitempty"`     // 引用的凭证别名，在个人配置中查找，用于共享清单
+	Password      string       `yaml:"password"`                 // 密码（如果使用密码认证）
+	KeyPath       string       `yaml:"key_path"`                 // 密钥文件路径
+	KeyPassphrase string       `yaml:"key_passphrase"`           // 密钥密码
+	StartupScript string       `yaml:"startup_script"`           // 启动脚本
+	RecordSession bool         `yaml:"record_session,omitempty"` // 录制交互式会话
+	RecordInput   bool         `yaml:"record_input,omitempty"`   // 录制会话时同时记录键盘输入
+	Proxy         *ProxyConfig `yaml:"proxy"`                    // 代理配置
+	JumpHost      string       `yaml:"jump_host,omitempty"`      // 跳板机（已保存服务器的别名或ID）
+	Group         string       `yaml:"group,omitempty"`          // 所属分组路径，如 prod/db
+	Template      string       `yaml:"template,omitempty"`       // 创建该服务器的模板名称
+	Tags          []string     `yaml:"tags"`                     // 标签
+	Description   string       `yaml:"description"`              // 描述
+	CreatedAt     time.Time    `yaml:"created_at"`               // 创建时间
+	UpdatedAt     time.Time    `yaml:"updated_at"`               // 更新时间
 }
 
 // PortForwardConfig 端口转发配置
 type PortForwardConfig struct {
-	ID          string      `yaml:"id"`          // 转发ID
-	Alias       string      `yaml:"alias"`       // 别名
-	ServerID    string      `yaml:"server_id"`   // 服务器ID
-	Type        ForwardType `yaml:"type"`        // 转发类型
-	LocalHost   string      `yaml:"local_host"`  // 本地主机
-	LocalPort   int         `yaml:"local_port"`  // 本地端口
-	RemoteHost  string      `yaml:"remote_host"` // 远程主机
-	RemotePort  int         `yaml:"remote_port"` // 远程端口
-	Description string      `yaml:"description"` // 描述
-	CreatedAt   time.Time   `yaml:"created_at"`  // 创建时间
-	UpdatedAt   time.Time   `yaml:"updated_at"`  // 更新时间
+	ID           string      `yaml:"id"`                      // 转发ID
+	Alias        string      `yaml:"alias"`                   // 别名
+	ServerID     string      `yaml:"server_id"`               // 服务器ID
+	Type         ForwardType `yaml:"type"`                    // 转发类型
+	LocalHost    string      `yaml:"local_host"`              // 本地主机
+	LocalPort    int         `yaml:"local_port"`              // 本地端口
+	RemoteHost   string      `yaml:"remote_host"`             // 远程主机
+	RemotePort   int         `yaml:"remote_port"`             // 远程端口
+	LocalSocket  string      `yaml:"local_socket,omitempty"`  // 本地Unix套接字路径（设置后替代本地主机和端口）
+	RemoteSocket string      `yaml:"remote_socket,omitempty"` // 远程Unix套接字路径（设置后替代远程主机和端口）
+	SocketMode   string      `yaml:"socket_mode,omitempty"`   // 本地Unix套接字权限（八进制，默认0600）
+	// 本地监听访问控制（仅本地转发）
+	AllowedCIDRs   []string `yaml:"allowed_cidrs,omitempty"`   // 允许访问的来源网段，为空表示不限制
+	MaxConnections int      `yaml:"max_connections,omitempty"` // 最大并发连接数，0表示不限制
+	IdleTimeout    int      `yaml:"idle_timeout,omitempty"`    // 连接空闲超时时间（秒），0表示不超时
+	RateLimit      int      `yaml:"rate_limit,omitempty"`      // 每秒最多接受的新连接数，0表示不限制
+	// HTTP代理认证（仅HTTP代理，为空表示不需要认证）
+	ProxyUsername string    `yaml:"proxy_username,omitempty"` // 代理用户名
+	ProxyPassword string    `yaml:"proxy_password,omitempty"` // 代理密码
+	AccessLog     bool      `yaml:"access_log,omitempty"`     // 记录每个转发连接的访问日志
+	Description   string    `yaml:"description"`              // 描述
+	CreatedAt     time.Time `yaml:"created_at"`               // 创建时间
+	UpdatedAt     time.Time `yaml:"updated_at"`               // 更新时间
+}
+
+// LocalNetwork 本地端点的网络类型（tcp 或 unix）
+func (pf *PortForwardConfig) LocalNetwork() string {
+	if pf.LocalSocket != "" {
+		return "unix"
+	}
+	return "tcp"
+}
+
+// LocalAddress 本地端点地址（host:port 或 Unix 套接字路径）
+func (pf *PortForwardConfig) LocalAddress() string {
+	if pf.LocalSocket != "" {
+		return pf.LocalSocket
+	}
+	return net.JoinHostPort(pf.LocalHost, strconv.Itoa(pf.LocalPort))
+}
+
+// Summary 端口转发的显示形式
+func (pf *PortForwardConfig) Summary() string {
+	return FormatForward(pf.Type, pf.LocalAddress(), pf.RemoteAddress())
+}
+
+// FormatForward 按转发类型生成 "监听端 -> 目标端" 的显示形式
+func FormatForward(t ForwardType, local, remote string) string {
+	switch t {
+	case ForwardTypeRemoteDynamic:
+		return remote + " -> SOCKS5(本机)"
+	case ForwardTypeHTTPProxy:
+		return local + " -> HTTP代理(经服务器)"
+	}
+	return local + " -> " + remote
+}
+
+// RemoteNetwork 远程端点的网络类型（tcp 或 unix）
+func (pf *PortForwardConfig) RemoteNetwork() string {
+	if pf.RemoteSocket != "" {
+		return "unix"
+	}
+	return "tcp"
+}
+
+// RemoteAddress 远程端点地址（host:port 或 Unix 套接字路径）
+func (pf *PortForwardConfig) RemoteAddress() string {
+	if pf.RemoteSocket != "" {
+		return pf.RemoteSocket
+	}
+	return net.JoinHostPort(pf.RemoteHost, strconv.Itoa(pf.RemotePort))
+}
+
+// SocketFileMode 解析本地Unix套接字权限
+func (pf *PortForwardConfig) SocketFileMode() (os.FileMode, error) {
+	if pf.SocketMode == "" {
+		return DefaultSocketMode, nil
+	}
+	mode, err := strconv.ParseUint(pf.SocketMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("无效的套接字权限 '%s'，应为八进制权限如 0600", pf.SocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// ListensLocal 是否在本机监听（本地转发或HTTP代理）
+func (pf *PortForwardConfig) ListensLocal() bool {
+	return pf.Type == ForwardTypeLocal || pf.Type == ForwardTypeHTTPProxy
+}
+
+// BindsLocalPort 是否在本地监听TCP端口（本机监听且未使用Unix套接字）
+func (pf *PortForwardConfig) BindsLocalPort() bool {
+	return pf.ListensLocal() && pf.LocalSocket == ""
+}
+
+// AutoLocalPort 本地端口是否为自动分配（local_port: 0）
+func (pf *PortForwardConfig) AutoLocalPort() bool {
+	return pf.BindsLocalPort() && pf.LocalPort == 0
+}
+
+// HasAccessControl 是否配置了本地监听访问控制
+func (pf *PortForwardConfig) HasAccessControl() bool {
+	return len(pf.AllowedCIDRs) > 0 || pf.MaxConnections > 0 || pf.IdleTimeout > 0 || pf.RateLimit > 0
+}
+
+// AllowedNetworks 解析允许访问的来源网段
+func (pf *PortForwardConfig) AllowedNetworks() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(pf.AllowedCIDRs))
+	for _, cidr := range pf.AllowedCIDRs {
+		network, err := ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ParseCIDR 解析网段，单个IP地址视为只包含该地址的网段
+func ParseCIDR(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("无效的IP地址或网段 '%s'", s)
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的IP地址或网段 '%s'", s)
+	}
+	return network, nil
+}
+
+// AutoRemotePort 远程端口是否由服务器分配（远程或远程动态转发且 remote_port: 0）
+func (pf *PortForwardConfig) AutoRemotePort() bool {
+	return pf.Type.ListensRemote() && pf.RemoteSocket == "" && pf.RemotePort == 0
+}
+
+// LocalPortOverlaps 检查两个端口转发是否会在本地监听同一端口
+func (pf *PortForwardConfig) LocalPortOverlaps(other *PortForwardConfig) bool {
+	if !pf.BindsLocalPort() || !other.BindsLocalPort() {
+		return false
+	}
+	if pf.LocalPort == 0 || pf.LocalPort != other.LocalPort {
+		return false
+	}
+	return LocalHostsOverlap(pf.LocalHost, other.LocalHost)
+}
+
+// LocalHostsOverlap 检查两个监听地址是否冲突，通配地址与任意地址冲突
+func LocalHostsOverlap(a, b string) bool {
+	normalize := func(host string) string {
+		switch host {
+		case "", "*", "0.0.0.0", "::":
+			return ""
+		case "localhost":
+			return "127.0.0.1"
+		}
+		return host
+	}
+	a, b = normalize(a), normalize(b)
+	return a == "" || b == "" || a == b
+}
+
+// ForwardGroup 端口转发组，用于一次启动多个端口转发
+type ForwardGroup struct {
+	ID           string              `yaml:"id"`           // 转发组ID
+	Name         string              `yaml:"name"`         // 转发组名称
+	Forwards     []string            `yaml:"forwards"`     // 成员端口转发别名
+	Dependencies map[string][]string `yaml:"dependencies"` // 启动依赖（别名 -> 需先启动的别名）
+	Description  string              `yaml:"description"`  // 描述
+	CreatedAt    time.Time           `yaml:"created_at"`   // 创建时间
+	UpdatedAt    time.Time           `yaml:"updated_at"`   // 更新时间
+}
+
+// ServerGroup 服务器分组，组内服务器未设置的用户名、端口、凭证、代理、跳板机和启动脚本继承分组的默认值
+type ServerGroup struct {
+	Path          string       `yaml:"path"`                     // 分组路径，如 prod/db
+	User          string       `yaml:"user,omitempty"`           // 默认用户名
+	Port          int          `yaml:"port,omitempty"`           // 默认SSH端口
+	CredentialID  string       `yaml:"credential_id,omitempty"`  // 默认凭证ID
+	Credential    string       `yaml:"credential,omitempty"`     // 默认凭证别名，在个人配置中查找，用于共享清单
+	Proxy         *ProxyConfig `yaml:"proxy,omitempty"`          // 默认代理配置
+	JumpHost      string       `yaml:"jump_host,omitempty"`      // 默认跳板机
+	StartupScript string       `yaml:"startup_script,omitempty"` // 默认启动脚本
+	Description   string       `yaml:"description"`              // 描述
+	CreatedAt     time.Time    `yaml:"created_at"`               // 创建时间
+	UpdatedAt     time.Time    `yaml:"updated_at"`               // 更新时间
 }
 
 // Config 主配置
 type Config struct {
-	ConfigVersion int                           `yaml:"config_version"` // 配置版本
-	Servers       map[string]*ServerConfig      `yaml:"servers"`        // 服务器配置
-	PortForwards  map[string]*PortForwardConfig `yaml:"port_forwards"`  // 端口转发配置
-	Credentials   map[string]*CredentialConfig  `yaml:"credentials"`    // 凭证配置
-	Settings      *Settings                     `yaml:"settings"`       // 全局设置
+	ConfigVersion      int                           `yaml:"config_version"`                // 配置版本
+	Servers            map[string]*ServerConfig      `yaml:"servers"`                       // 服务器配置
+	ServerGroups       map[string]*ServerGroup       `yaml:"server_groups"`                 // 服务器分组（路径 -> 分组）
+	ServerTemplates    map[string]*ServerTemplate    `yaml:"server_templates"`              // 服务器模板（名称 -> 模板）
+	PortForwards       map[string]*PortForwardConfig `yaml:"port_forwards"`                 // 端口转发配置
+	ForwardGroups      map[string]*ForwardGroup      `yaml:"forward_groups"`                // 端口转发组配置
+	Credentials        map[string]*CredentialConfig  `yaml:"credentials"`                   // 凭证配置
+	InventoryProviders map[string]*InventoryProvider `yaml:"inventory_providers,omitempty"` // 动态清单（名称 -> 提供者）
+	Settings           *Settings                     `yaml:"settings"`                      // 全局设置
 }
 
 // Settings 全局设置
 type Settings struct {
-	ConfigDir       string `yaml:"config_dir"`        // 配置目录
-	LogLevel        string `yaml:"log_level"`         // 日志级别
-	ConnectTimeout  int    `yaml:"connect_timeout"`   // 连接超时时间（秒）
-	DefaultUser     string `yaml:"default_user"`      // 默认用户名
-	DefaultPort     int    `yaml:"default_port"`      // 默认端口
-	DefaultAuthType string `yaml:"default_auth_type"` // 默认认证类型
+	ConfigDir       string `yaml:"config_dir"`           // 配置目录
+	LogLevel        string `yaml:"log_level"`            // 日志级别：trace、debug、info、warn、error
+	LogFormat       string `yaml:"log_format,omitempty"` // 日志格式：text（默认）或 json
+	LogFile         string `yaml:"log_file,omitempty"`   // 日志文件路径，为空时输出到标准错误
+	ConnectTimeout  int    `yaml:"connect_timeout"`      // 连接超时时间（秒）
+	DefaultUser     string `yaml:"default_user"`         // 默认用户名
+	DefaultPort     int    `yaml:"default_port"`         // 默认端口
+	DefaultAuthType string `yaml:"default_auth_type"`    // 默认认证类型
+	// 共享清单文件路径，相对路径相对于声明它的配置文件所在目录
+	Inventories []string `yaml:"inventories,omitempty"`
 }
 
 // NewConfig 创建新的配置实例
 func NewConfig() *Config {
 	return &Config{
-		ConfigVersion: 1,
-		Servers:       make(map[string]*ServerConfig),
-		PortForwards:  make(map[string]*PortForwardConfig),
-		Credentials:   make(map[string]*CredentialConfig),
+		ConfigVersion:      CurrentConfigVersion,
+		Servers:            make(map[string]*ServerConfig),
+		ServerGroups:       make(map[string]*ServerGroup),
+		ServerTemplates:    make(map[string]*ServerTemplate),
+		PortForwards:       make(map[string]*PortForwardConfig),
+		ForwardGroups:      make(map[string]*ForwardGroup),
+		Credentials:        make(map[string]*CredentialConfig),
+		InventoryProviders: make(map[string]*InventoryProvider),
 		Settings: &Settings{
 			LogLevel:        "info",
 			ConnectTimeout:  30,
@@ -155,6 +382,80 @@ func NewPortForwardConfig(serverID string) *PortForwardConfig {
 	}
 }
 
+// NewForwardGroup 创建新的端口转发组
+func NewForwardGroup(name string) *ForwardGroup {
+	now := time.Now()
+	return &ForwardGroup{
+		ID:           generateID(),
+		Name:         name,
+		Forwards:     []string{},
+		Dependencies: make(map[string][]string),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Stages 按依赖关系将成员划分为启动阶段，同一阶段内的成员可并发启动
+func (g *ForwardGroup) Stages() ([][]string, error) {
+	// 成员的端口转发被删除后组可能为空，此时启动没有意义
+	if len(g.Forwards) == 0 {
+		return nil, fmt.Errorf("转发组 '%s' 没有成员", g.Name)
+	}
+	members := make(map[string]bool, len(g.Forwards))
+	for _, alias := range g.Forwards {
+		if members[alias] {
+			return nil, fmt.Errorf("转发组 '%s' 中存在重复成员 '%s'", g.Name, alias)
+		}
+		members[alias] = true
+	}
+
+	pending := make(map[string][]string, len(g.Forwards))
+	for _, alias := range g.Forwards {
+		for _, dep := range g.Dependencies[alias] {
+			if !members[dep] {
+				return nil, fmt.Errorf("转发组 '%s' 中 '%s' 依赖的 '%s' 不是组成员", g.Name, alias, dep)
+			}
+		}
+		pending[alias] = g.Dependencies[alias]
+	}
+	for alias := range g.Dependencies {
+		if !members[alias] {
+			return nil, fmt.Errorf("转发组 '%s' 的依赖配置引用了非成员 '%s'", g.Name, alias)
+		}
+	}
+
+	var stages [][]string
+	started := make(map[string]bool, len(g.Forwards))
+	for len(started) < len(g.Forwards) {
+		var stage []string
+		// 按成员声明顺序遍历，保证结果稳定
+		for _, alias := range g.Forwards {
+			if started[alias] {
+				continue
+			}
+			ready := true
+			for _, dep := range pending[alias] {
+				if !started[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				stage = append(stage, alias)
+			}
+		}
+		if len(stage) == 0 {
+			return nil, fmt.Errorf("转发组 '%s' 的依赖关系存在循环", g.Name)
+		}
+		for _, alias := range stage {
+			started[alias] = true
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
+
 // NewCredentialConfig 创建新的凭证配置
 func NewCredentialConfig() *CredentialConfig {
 	now := time.Now()
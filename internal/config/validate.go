@@ -0,0 +1,685 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"gotssh/internal/logging"
+)
+
+// Severity 配置问题的严重程度
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 错误：配置无法使用，加载和保存时拒绝
+	SeverityWarning Severity = "warning" // 警告：引用失效、别名重复等，不影响加载
+)
+
+// secureFileMode 配置文件和私钥文件应有的权限
+const secureFileMode os.FileMode = 0600
+
+// Problem 配置中的一个问题
+type Problem struct {
+	Severity Severity
+	Path     []string // 问题在配置中的位置，如 [servers <id> port]
+	File     string   // 问题所在的配置文件，未知时为空
+	Line     int      // 在配置文件中的行号，未知时为0
+	Column   int      // 在配置文件中的列号，未知时为0
+	Message  string
+	Fix      string // 自动修复方式的说明，为空表示需要手动修改
+
+	fix func(m *Manager) error
+}
+
+// Location 问题在配置中的位置，如 servers.<id>.port
+func (p Problem) Location() string {
+	return strings.Join(p.Path, ".")
+}
+
+// Fixable 是否可以自动修复
+func (p Problem) Fixable() bool {
+	return p.fix != nil
+}
+
+// String 问题的显示形式，如 "12:5 servers.<id>.port: 端口 70000 超出范围"
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "%d:%d ", p.Line, p.Column)
+	}
+	if len(p.Path) > 0 {
+		b.WriteString(p.Location())
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidationError 配置校验失败，包含所有错误级别的问题
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置校验失败，共 %d 个错误:", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  ")
+		file := p.File
+		if file == "" {
+			file = e.File
+		}
+		if file != "" && p.Line > 0 {
+			fmt.Fprintf(&b, "%s:%d:%d: %s: %s", file, p.Line, p.Column, p.Location(), p.Message)
+		} else {
+			b.WriteString(p.String())
+		}
+	}
+	return b.String()
+}
+
+// Errors 筛选出错误级别的问题
+func Errors(problems []Problem) []Problem {
+	var errs []Problem
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			errs = append(errs, p)
+		}
+	}
+	return errs
+}
+
+// validator 收集配置问题
+type validator struct {
+	config   *Config
+	problems []Problem
+}
+
+func (v *validator) add(severity Severity, path []string, fix string, fn func(m *Manager) error, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+		Fix:      fix,
+		fix:      fn,
+	})
+}
+
+func (v *validator) errorf(path []string, format string, args ...interface{}) {
+	v.add(SeverityError, path, "", nil, format, args...)
+}
+
+// Validate 校验配置，返回按配置段和键排序的问题列表
+// 字段取值无效（端口越界、未知的认证/代理/转发类型等）为错误，引用失效和别名重复为警告
+func Validate(c *Config) []Problem {
+	v := &validator{config: c}
+	v.validateServers()
+	v.validateServerGroups()
+	v.validatePortForwards()
+	v.validateForwardGroups()
+	v.validateCredentials()
+	v.validateInventoryProviders()
+	v.validateSettings()
+	return v.problems
+}
+
+func (v *validator) validateServers() {
+	aliases := make(map[string][]*ServerConfig)
+	for _, id := range sortedKeys(v.config.Servers) {
+		server := v.config.Servers[id]
+		path := []string{"servers", id}
+		if server == nil {
+			v.errorf(path, "服务器配置为空")
+			continue
+		}
+
+		if strings.TrimSpace(server.Host) == "" {
+			v.errorf(at(path, "host"), "主机地址不能为空")
+		}
+		v.checkPort(at(path, "port"), server.Port, true)
+		v.checkAuthType(path, server)
+		v.checkProxy(at(path, "proxy"), server.Proxy)
+		v.checkCredentialRef(path, &server.CredentialID, func() {
+			if server.AuthType == AuthTypeCredential {
+				server.AuthType = AuthTypeAsk
+			}
+		})
+		v.checkCredentialAlias(path, server.Credential)
+		v.checkJumpHost(path, &server.JumpHost, server.ID)
+
+		if server.Alias != "" {
+			aliases[server.Alias] = append(aliases[server.Alias], server)
+		}
+	}
+
+	for _, alias := range sortedKeys(aliases) {
+		servers := aliases[alias]
+		if len(servers) < 2 {
+			continue
+		}
+		sort.SliceStable(servers, func(i, j int) bool {
+			return servers[i].CreatedAt.Before(servers[j].CreatedAt)
+		})
+		for _, server := range servers[1:] {
+			server := server
+			v.add(SeverityWarning, []string{"servers", server.ID, "alias"}, "重命名别名", func(m *Manager) error {
+				server.Alias = m.uniqueServerAlias(server.Alias)
+				return nil
+			}, "别名 '%s' 与其他服务器重复", alias)
+		}
+	}
+}
+
+func (v *validator) validateServerGroups() {
+	for _, key := range sortedKeys(v.config.ServerGroups) {
+		group := v.config.ServerGroups[key]
+		path := []string{"server_groups", key}
+		if group == nil {
+			v.errorf(path, "分组配置为空")
+			continue
+		}
+		v.checkPort(at(path, "port"), group.Port, true)
+		v.checkProxy(at(path, "proxy"), group.Proxy)
+		v.checkCredentialRef(path, &group.CredentialID, nil)
+		v.checkCredentialAlias(path, group.Credential)
+		v.checkJumpHost(path, &group.JumpHost, "")
+	}
+}
+
+func (v *validator) validatePortForwards() {
+	aliases := make(map[string][]*PortForwardConfig)
+	for _, id := range sortedKeys(v.config.PortForwards) {
+		pf := v.config.PortForwards[id]
+		path := []string{"port_forwards", id}
+		if pf == nil {
+			v.errorf(path, "端口转发配置为空")
+			continue
+		}
+
+		if !pf.Type.Valid() {
+			v.errorf(at(path, "type"), "未知的转发类型 '%s'（可选 local、remote、remote-dynamic、http-proxy）", pf.Type)
+		}
+		v.checkPort(at(path, "local_port"), pf.LocalPort, true)
+		v.checkPort(at(path, "remote_port"), pf.RemotePort, true)
+		if _, err := pf.SocketFileMode(); err != nil {
+			v.errorf(at(path, "socket_mode"), "%v", err)
+		}
+		if _, err := pf.AllowedNetworks(); err != nil {
+			v.errorf(at(path, "allowed_cidrs"), "%v", err)
+		}
+		if pf.MaxConnections < 0 {
+			v.errorf(at(path, "max_connections"), "最大并发连接数不能为负数")
+		}
+		if pf.IdleTimeout < 0 {
+			v.errorf(at(path, "idle_timeout"), "空闲超时时间不能为负数")
+		}
+		if pf.RateLimit < 0 {
+			v.errorf(at(path, "rate_limit"), "连接速率限制不能为负数")
+		}
+
+		if _, exists := v.config.Servers[pf.ServerID]; !exists {
+			pfID := id
+			v.add(SeverityWarning, at(path, "server_id"), "删除该端口转发", func(m *Manager) error {
+				m.removePortForward(pfID)
+				return nil
+			}, "服务器 '%s' 不存在", pf.ServerID)
+		}
+
+		if pf.Alias != "" {
+			aliases[pf.Alias] = append(aliases[pf.Alias], pf)
+		}
+	}
+
+	for _, alias := range sortedKeys(aliases) {
+		forwards := aliases[alias]
+		if len(forwards) < 2 {
+			continue
+		}
+		sort.SliceStable(forwards, func(i, j int) bool {
+			return forwards[i].CreatedAt.Before(forwards[j].CreatedAt)
+		})
+		for _, pf := range forwards[1:] {
+			pf := pf
+			v.add(SeverityWarning, []string{"port_forwards", pf.ID, "alias"}, "重命名别名", func(m *Manager) error {
+				pf.Alias = uniqueAlias(pf.Alias, func(alias string) bool {
+					_, err := m.GetPortForwardByAlias(alias)
+					return err == nil
+				})
+				return nil
+			}, "别名 '%s' 与其他端口转发重复", alias)
+		}
+	}
+}
+
+func (v *validator) validateForwardGroups() {
+	forwardAliases := make(map[string]bool)
+	for _, pf := range v.config.PortForwards {
+		if pf != nil && pf.Alias != "" {
+			forwardAliases[pf.Alias] = true
+		}
+	}
+
+	for _, id := range sortedKeys(v.config.ForwardGroups) {
+		id := id
+		group := v.config.ForwardGroups[id]
+		path := []string{"forward_groups", id}
+		if group == nil {
+			v.errorf(path, "转发组配置为空")
+			continue
+		}
+		if len(group.Forwards) == 0 {
+			v.add(SeverityWarning, at(path, "forwards"), "删除转发组", func(m *Manager) error {
+				delete(m.config.ForwardGroups, id)
+				return nil
+			}, "转发组 '%s' 没有成员", group.Name)
+			continue
+		}
+		for i, alias := range group.Forwards {
+			if forwardAliases[alias] {
+				continue
+			}
+			alias := alias
+			v.add(SeverityWarning, at(path, "forwards", strconv.Itoa(i)), "从转发组中移除", func(m *Manager) error {
+				m.renameGroupMember(alias, "")
+				// 移除后没有成员的转发组一并删除
+				if g := m.config.ForwardGroups[id]; g != nil && len(g.Forwards) == 0 {
+					delete(m.config.ForwardGroups, id)
+				}
+				return nil
+			}, "端口转发 '%s' 不存在", alias)
+		}
+	}
+}
+
+func (v *validator) validateInventoryProviders() {
+	for _, name := range sortedKeys(v.config.InventoryProviders) {
+		p := v.config.InventoryProviders[name]
+		path := []string{providersSection, name}
+		if p == nil {
+			v.errorf(path, "动态清单配置为空")
+			continue
+		}
+		if strings.ContainsAny(name, `/\:`) {
+			v.errorf(path, "动态清单名称 '%s' 不能包含 /、\\ 或 :", name)
+		}
+		switch {
+		case p.Command == "" && p.File == "":
+			v.errorf(path, "动态清单需要设置 command 或 file")
+		case p.Command != "" && p.File != "":
+			v.errorf(path, "动态清单不能同时设置 command 和 file")
+		}
+		if p.Timeout < 0 {
+			v.errorf(at(path, "timeout"), "命令超时时间不能为负数")
+		}
+		v.checkCredentialAlias(path, p.Credential)
+	}
+}
+
+func (v *validator) validateCredentials() {
+	aliases := make(map[string][]*CredentialConfig)
+	for _, id := range sortedKeys(v.config.Credentials) {
+		cred := v.config.Credentials[id]
+		path := []string{"credentials", id}
+		if cred == nil {
+			v.errorf(path, "凭证配置为空")
+			continue
+		}
+		if cred.Type != CredentialTypePassword && cred.Type != CredentialTypeKey {
+			v.errorf(at(path, "type"), "未知的凭证类型 '%s'（可选 password、key）", cred.Type)
+		}
+		if cred.Alias != "" {
+			aliases[cred.Alias] = append(aliases[cred.Alias], cred)
+		}
+	}
+
+	for _, alias := range sortedKeys(aliases) {
+		creds := aliases[alias]
+		if len(creds) < 2 {
+			continue
+		}
+		sort.SliceStable(creds, func(i, j int) bool {
+			return creds[i].CreatedAt.Before(creds[j].CreatedAt)
+		})
+		for _, cred := range creds[1:] {
+			cred := cred
+			v.add(SeverityWarning, []string{"credentials", cred.ID, "alias"}, "重命名别名", func(m *Manager) error {
+				cred.Alias = uniqueAlias(cred.Alias, func(alias string) bool {
+					_, err := m.GetCredentialByAlias(alias)
+					return err == nil
+				})
+				return nil
+			}, "别名 '%s' 与其他凭证重复", alias)
+		}
+	}
+}
+
+func (v *validator) validateSettings() {
+	settings := v.config.Settings
+	if settings == nil {
+		return
+	}
+	path := []string{"settings"}
+
+	// 日志设置无效时程序使用默认值，只作为警告，以免所有命令都无法运行
+	if _, err := logging.ParseLevel(settings.LogLevel); err != nil {
+		v.add(SeverityWarning, at(path, "log_level"), "改为 info", func(m *Manager) error {
+			m.config.Settings.LogLevel = "info"
+			return nil
+		}, "%v，当前使用 info", err)
+	}
+	if _, err := logging.ParseFormat(settings.LogFormat); err != nil {
+		v.add(SeverityWarning, at(path, "log_format"), "改为 text", func(m *Manager) error {
+			m.config.Settings.LogFormat = logging.FormatText
+			return nil
+		}, "%v，当前使用 text", err)
+	}
+	if settings.ConnectTimeout < 0 {
+		v.errorf(at(path, "connect_timeout"), "连接超时时间不能为负数")
+	}
+	v.checkPort(at(path, "default_port"), settings.DefaultPort, true)
+	switch AuthType(settings.DefaultAuthType) {
+	case "", AuthTypePassword, AuthTypeKey, AuthTypeCredential, AuthTypeAsk:
+	default:
+		v.add(SeverityError, at(path, "default_auth_type"), "改为 ask", func(m *Manager) error {
+			m.config.Settings.DefaultAuthType = string(AuthTypeAsk)
+			return nil
+		}, "未知的认证类型 '%s'", settings.DefaultAuthType)
+	}
+}
+
+// checkPort 检查端口范围，allowZero 表示0有特殊含义（继承默认值或自动分配）
+func (v *validator) checkPort(path []string, port int, allowZero bool) {
+	if port == 0 && allowZero {
+		return
+	}
+	if port < 1 || port > 65535 {
+		v.errorf(path, "端口 %d 超出范围 (1-65535)", port)
+	}
+}
+
+// checkAuthType 检查服务器的认证类型，为空表示继承分组凭证或每次询问
+func (v *validator) checkAuthType(path []string, server *ServerConfig) {
+	switch server.AuthType {
+	case "", AuthTypePassword, AuthTypeKey, AuthTypeCredential, AuthTypeAsk:
+		return
+	}
+	v.add(SeverityError, at(path, "auth_type"), "改为每次询问 (ask)", func(m *Manager) error {
+		server.AuthType = AuthTypeAsk
+		return nil
+	}, "未知的认证类型 '%s'（可选 password、key、credential、ask）", server.AuthType)
+}
+
+// checkProxy 检查代理配置
+func (v *validator) checkProxy(path []string, proxy *ProxyConfig) {
+	if proxy == nil {
+		return
+	}
+	switch proxy.Type {
+	case "http", "socks5", "socks5h":
+	default:
+		v.errorf(at(path, "type"), "未知的代理类型 '%s'（可选 http、socks5）", proxy.Type)
+	}
+	if strings.TrimSpace(proxy.Host) == "" {
+		v.errorf(at(path, "host"), "代理主机不能为空")
+	}
+	v.checkPort(at(path, "port"), proxy.Port, false)
+}
+
+// checkCredentialRef 检查凭证引用，修复时清除引用并执行 onFix
+func (v *validator) checkCredentialRef(path []string, credentialID *string, onFix func()) {
+	if *credentialID == "" {
+		return
+	}
+	if _, exists := v.config.Credentials[*credentialID]; exists {
+		return
+	}
+	v.add(SeverityWarning, at(path, "credential_id"), "清除凭证引用", func(m *Manager) error {
+		*credentialID = ""
+		if onFix != nil {
+			onFix()
+		}
+		return nil
+	}, "凭证 '%s' 不存在", *credentialID)
+}
+
+// checkCredentialAlias 检查按别名引用的凭证，共享清单中的条目引用个人配置中的凭证，
+// 缺少时需要用户自行添加，不能自动修复
+func (v *validator) checkCredentialAlias(path []string, alias string) {
+	if alias == "" {
+		return
+	}
+	for _, cred := range v.config.Credentials {
+		if cred != nil && cred.Alias == alias {
+			return
+		}
+	}
+	v.add(SeverityWarning, at(path, "credential"), "", nil, "凭证别名 '%s' 不存在，请在个人配置中添加该凭证", alias)
+}
+
+// checkJumpHost 检查跳板机引用（已保存服务器的别名或ID）
+func (v *validator) checkJumpHost(path []string, jumpHost *string, selfID string) {
+	if *jumpHost == "" {
+		return
+	}
+	ref := *jumpHost
+	for id, server := range v.config.Servers {
+		if server == nil || (id != ref && server.Alias != ref) {
+			continue
+		}
+		if id == selfID {
+			v.errorf(at(path, "jump_host"), "不能使用自身作为跳板机")
+		}
+		return
+	}
+	v.add(SeverityWarning, at(path, "jump_host"), "清除跳板机", func(m *Manager) error {
+		*jumpHost = ""
+		return nil
+	}, "跳板机 '%s' 不存在", ref)
+}
+
+// Diagnose 全面检查配置，除 Validate 的检查外还检查密钥文件和文件权限，并标出问题在配置文件中的位置
+func (m *Manager) Diagnose() []Problem {
+	v := &validator{config: m.config}
+	for _, l := range m.layers {
+		if l.Path != "" && !l.ReadOnly && l.data != nil {
+			v.checkFileMode(nil, l.Path, l.Kind.String()+"文件")
+		}
+	}
+	problems := append(v.problems, Validate(m.config)...)
+
+	v.problems = nil
+	for _, id := range sortedKeys(m.config.Servers) {
+		if server := m.config.Servers[id]; server != nil && server.KeyPath != "" {
+			v.checkKeyFile([]string{"servers", id, "key_path"}, server.KeyPath)
+		}
+	}
+	for _, id := range sortedKeys(m.config.Credentials) {
+		if cred := m.config.Credentials[id]; cred != nil && cred.KeyPath != "" {
+			v.checkKeyFile([]string{"credentials", id, "key_path"}, cred.KeyPath)
+		}
+	}
+	problems = append(problems, v.problems...)
+	problems = append(problems, m.inventoryProblems()...)
+
+	m.locate(problems)
+	return problems
+}
+
+// locate 在问题所在条目的来源配置文件中查找行号和列号
+func (m *Manager) locate(problems []Problem) {
+	byLayer := make(map[*Layer][]int)
+	for i, p := range problems {
+		l := m.primary
+		switch {
+		case p.File != "":
+			l = m.layerByPath(p.File)
+		case len(p.Path) >= 2:
+			l = m.Origin(p.Path[0], p.Path[1])
+		}
+		if l != nil && l.data != nil {
+			byLayer[l] = append(byLayer[l], i)
+		}
+	}
+
+	for l, indexes := range byLayer {
+		located := make([]Problem, len(indexes))
+		for j, i := range indexes {
+			located[j] = problems[i]
+		}
+		locateProblems(l.data, located)
+		for j, i := range indexes {
+			problems[i].Line, problems[i].Column = located[j].Line, located[j].Column
+			problems[i].File = l.Path
+		}
+	}
+}
+
+// Fix 自动修复可修复的问题并保存配置，返回修复的数量
+func (m *Manager) Fix(problems []Problem) (int, error) {
+	fixed := 0
+	for _, p := range problems {
+		if p.fix == nil {
+			continue
+		}
+		if err := p.fix(m); err != nil {
+			return fixed, fmt.Errorf("修复 %s 失败: %w", p.Location(), err)
+		}
+		fixed++
+	}
+	if fixed == 0 {
+		return 0, nil
+	}
+	return fixed, m.Save()
+}
+
+// layerByPath 按文件路径查找配置层
+func (m *Manager) layerByPath(path string) *Layer {
+	for _, l := range m.layers {
+		if l.Path == path {
+			return l
+		}
+	}
+	return nil
+}
+
+// checkKeyFile 检查私钥文件是否可读且权限安全
+func (v *validator) checkKeyFile(path []string, keyPath string) {
+	f, err := os.Open(expandHome(keyPath))
+	if err != nil {
+		v.add(SeverityWarning, path, "", nil, "密钥文件不可读: %v", err)
+		return
+	}
+	f.Close()
+	v.checkFileMode(path, keyPath, "密钥文件")
+}
+
+// checkFileMode 检查文件权限，包含密码或私钥的文件不应被其他用户读取
+func (v *validator) checkFileMode(path []string, file, kind string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	file = expandHome(file)
+	info, err := os.Stat(file)
+	if err != nil {
+		return
+	}
+	mode := info.Mode().Perm()
+	if mode&0077 == 0 {
+		return
+	}
+	v.add(SeverityWarning, path, fmt.Sprintf("修改权限为 %04o", secureFileMode), func(m *Manager) error {
+		return os.Chmod(file, secureFileMode)
+	}, "%s %s 的权限为 %04o，其他用户可以读取", kind, file, mode)
+}
+
+// locateProblems 根据问题的配置路径在 YAML 中查找对应的行号和列号
+func locateProblems(data []byte, problems []Problem) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return
+	}
+	for i := range problems {
+		if len(problems[i].Path) == 0 {
+			continue
+		}
+		if node := findNode(root.Content[0], problems[i].Path); node != nil {
+			problems[i].Line = node.Line
+			problems[i].Column = node.Column
+		}
+	}
+}
+
+// findNode 按路径查找 YAML 节点，路径中的字段不存在时返回最近的上级节点
+func findNode(node *yaml.Node, path []string) *yaml.Node {
+	for _, key := range path {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+// uniqueServerAlias 生成未被使用的服务器别名
+func (m *Manager) uniqueServerAlias(alias string) string {
+	return uniqueAlias(alias, m.aliasExists)
+}
+
+// uniqueAlias 在别名后追加序号直到未被使用，如 web -> web-2
+func uniqueAlias(alias string, exists func(string) bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", alias, i)
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// expandHome 展开路径开头的 ~
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
+// at 在配置路径后追加字段，返回新的路径
+func at(path []string, keys ...string) []string {
+	return append(append([]string{}, path...), keys...)
+}
+
+// sortedKeys 按字典序返回 map 的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
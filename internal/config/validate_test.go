@@ -0,0 +1,209 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenConfig 含有各类问题的配置文件
+const brokenConfig = `config_version: 2
+servers:
+  s1:
+    id: s1
+    alias: web
+    host: 10.0.0.1
+    port: 22
+    user: root
+    auth_type: credential
+    credential_id: gone
+    jump_host: bastion
+  s2:
+    id: s2
+    alias: web
+    host: 10.0.0.2
+    port: 22
+    user: root
+    auth_type: ask
+    created_at: 2024-01-01T00:00:00Z
+port_forwards:
+  f1:
+    id: f1
+    alias: t1
+    server_id: missing
+    type: local
+    local_port: 8080
+    remote_host: 127.0.0.1
+    remote_port: 80
+forward_groups:
+  g1:
+    id: g1
+    name: dev
+    forwards: [t1, nope]
+`
+
+// problemLocations 问题位置列表
+func problemLocations(problems []Problem) []string {
+	locations := make([]string, 0, len(problems))
+	for _, p := range problems {
+		locations = append(locations, p.Location())
+	}
+	return locations
+}
+
+// writeConfig 写入配置文件
+func writeConfig(t *testing.T, content string) string {
+	path := createTempConfigFile(t)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+// TestValidate 测试配置校验
+func TestValidate(t *testing.T) {
+	t.Run("默认配置没有问题", func(t *testing.T) {
+		assert.Empty(t, Validate(NewConfig()))
+	})
+
+	t.Run("字段取值无效", func(t *testing.T) {
+		c := NewConfig()
+		c.Servers["s1"] = &ServerConfig{ID: "s1", Host: "", Port: 70000, AuthType: "token",
+			Proxy: &ProxyConfig{Type: "ftp", Host: "proxy", Port: 1080}}
+		c.PortForwards["f1"] = &PortForwardConfig{ID: "f1", ServerID: "s1", Type: "bogus", LocalPort: -1,
+			SocketMode: "999", AllowedCIDRs: []string{"not-a-cidr"}}
+		c.Credentials["c1"] = &CredentialConfig{ID: "c1", Type: "token"}
+
+		problems := Validate(c)
+		assert.Len(t, Errors(problems), len(problems))
+		assert.Equal(t, []string{
+			"servers.s1.host",
+			"servers.s1.port",
+			"servers.s1.auth_type",
+			"servers.s1.proxy.type",
+			"port_forwards.f1.type",
+			"port_forwards.f1.local_port",
+			"port_forwards.f1.socket_mode",
+			"port_forwards.f1.allowed_cidrs",
+			"credentials.c1.type",
+		}, problemLocations(problems))
+	})
+
+	t.Run("日志设置无效为警告", func(t *testing.T) {
+		c := NewConfig()
+		c.Settings.LogLevel = "loud"
+		c.Settings.LogFormat = "xml"
+
+		problems := Validate(c)
+		assert.Empty(t, Errors(problems))
+		assert.Equal(t, []string{"settings.log_level", "settings.log_format"}, problemLocations(problems))
+		for _, p := range problems {
+			assert.NotEmpty(t, p.Fix)
+		}
+	})
+
+	t.Run("引用失效和别名重复为警告", func(t *testing.T) {
+		manager, err := NewManager(writeConfig(t, brokenConfig))
+		require.NoError(t, err)
+
+		problems := manager.Problems()
+		assert.Empty(t, Errors(problems))
+		assert.Equal(t, []string{
+			"servers.s1.credential_id",
+			"servers.s1.jump_host",
+			"servers.s2.alias",
+			"port_forwards.f1.server_id",
+			"forward_groups.g1.forwards.1",
+		}, problemLocations(problems))
+
+		// 较晚创建的服务器的别名被视为重复
+		assert.Equal(t, 14, problems[2].Line)
+		assert.Equal(t, 12, problems[2].Column)
+	})
+}
+
+// TestLoadValidation 测试加载和保存时的校验
+func TestLoadValidation(t *testing.T) {
+	path := writeConfig(t, `config_version: 2
+servers:
+  s1:
+    id: s1
+    host: 10.0.0.1
+    port: 70000
+    auth_type: ask
+`)
+
+	_, err := NewManager(path)
+	require.Error(t, err)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Problems, 1)
+	assert.Equal(t, 6, validationErr.Problems[0].Line)
+	assert.Equal(t, 11, validationErr.Problems[0].Column)
+	assert.Contains(t, err.Error(), path+":6:11: servers.s1.port: 端口 70000 超出范围")
+
+	// 宽松模式下可以加载，用于检查和修复
+	manager, err := NewLenientManager(path)
+	require.NoError(t, err)
+	assert.Len(t, manager.Problems(), 1)
+
+	// 保存时拒绝无效的配置
+	manager, err = NewManager(createTempConfigFile(t))
+	require.NoError(t, err)
+	server := NewServerConfig("10.0.0.1")
+	server.Port = -1
+	assert.Error(t, manager.AddServer(server))
+}
+
+// TestDiagnoseAndFix 测试检查和自动修复
+func TestDiagnoseAndFix(t *testing.T) {
+	path := writeConfig(t, brokenConfig)
+	require.NoError(t, os.Chmod(path, 0644))
+	keyPath := filepath.Join(t.TempDir(), "id_rsa")
+	require.NoError(t, os.WriteFile(keyPath, []byte("key"), 0644))
+
+	manager, err := NewManager(path)
+	require.NoError(t, err)
+	manager.GetConfig().Servers["s2"].KeyPath = keyPath
+	manager.GetConfig().Credentials["c1"] = &CredentialConfig{ID: "c1", Alias: "cred", Type: CredentialTypeKey,
+		KeyPath: filepath.Join(t.TempDir(), "missing")}
+
+	problems := manager.Diagnose()
+	locations := problemLocations(problems)
+	assert.Equal(t, "", locations[0], "配置文件权限")
+	assert.Contains(t, locations, "servers.s2.key_path")
+	assert.Contains(t, locations, "credentials.c1.key_path")
+
+	fixed, err := manager.Fix(problems)
+	require.NoError(t, err)
+	assert.Equal(t, 7, fixed)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 重新加载后只剩下不可自动修复的问题
+	reloaded, err := NewManager(path)
+	require.NoError(t, err)
+	remaining := reloaded.Diagnose()
+	assert.Equal(t, []string{"credentials.c1.key_path"}, problemLocations(remaining))
+
+	s1, err := reloaded.GetServer("s1")
+	require.NoError(t, err)
+	assert.Empty(t, s1.CredentialID)
+	assert.Equal(t, AuthTypeAsk, s1.AuthType)
+	assert.Empty(t, s1.JumpHost)
+	assert.Equal(t, "web", s1.Alias)
+	s2, err := reloaded.GetServer("s2")
+	require.NoError(t, err)
+	assert.Equal(t, "web-2", s2.Alias)
+	assert.Empty(t, reloaded.ListPortForwards())
+	// 移除全部失效成员后转发组没有成员，一并删除
+	_, err = reloaded.GetForwardGroup("g1")
+	assert.Error(t, err)
+}
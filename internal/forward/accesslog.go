@@ -0,0 +1,285 @@
+package forward
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gotssh/internal/logging"
+	"gotssh/internal/ssh"
+)
+
+// 访问日志轮转参数
+const (
+	accessLogMaxSize    = 10 * 1024 * 1024 // 单个日志文件最大字节数
+	accessLogMaxBackups = 3                // 保留的历史日志文件数
+)
+
+// AccessLogEntry 访问日志中的一条记录（JSON行）
+type AccessLogEntry struct {
+	Time          time.Time `json:"time"`            // 连接建立时间
+	Forward       string    `json:"forward"`         // 端口转发别名
+	Source        string    `json:"source"`          // 来源地址
+	Destination   string    `json:"destination"`     // 目标地址
+	DurationMs    int64     `json:"duration_ms"`     // 持续时间（毫秒）
+	BytesSent     int64     `json:"bytes_sent"`      // 来源发往目标的字节数
+	BytesReceived int64     `json:"bytes_received"`  // 目标发往来源的字节数
+	CloseReason   string    `json:"close_reason"`    // 关闭原因
+	Error         string    `json:"error,omitempty"` // 错误详情
+}
+
+// String 访问日志记录的显示形式
+func (e AccessLogEntry) String() string {
+	line := fmt.Sprintf("%s %s -> %s %s ↑%d ↓%d [%s]",
+		e.Time.Local().Format("2006-01-02 15:04:05"), e.Source, e.Destination,
+		time.Duration(e.DurationMs)*time.Millisecond, e.BytesSent, e.BytesReceived, e.CloseReason)
+	if e.Error != "" {
+		line += " " + e.Error
+	}
+	return line
+}
+
+// AccessLogPath 端口转发访问日志文件路径
+func AccessLogPath(configDir, pfID string) string {
+	return filepath.Join(configDir, "logs", "forward-"+pfID+".jsonl")
+}
+
+// AccessLogger 按大小轮转的JSON行访问日志
+type AccessLogger struct {
+	path       string
+	forward    string
+	maxSize    int64
+	maxBackups int
+	logger     *slog.Logger
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenAccessLogger 打开（必要时创建）访问日志文件
+func OpenAccessLogger(path, forward string) (*AccessLogger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+
+	l := &AccessLogger{
+		path:       path,
+		forward:    forward,
+		maxSize:    accessLogMaxSize,
+		maxBackups: accessLogMaxBackups,
+		logger:     logging.Nop(),
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open 以追加方式打开日志文件
+func (l *AccessLogger) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("打开访问日志失败: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("读取访问日志信息失败: %w", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Record 写入一条连接记录，可直接作为 ssh.ConnRecorder 使用
+func (l *AccessLogger) Record(rec ssh.ConnRecord) {
+	entry := AccessLogEntry{
+		Time:          rec.Start,
+		Forward:       l.forward,
+		Source:        rec.Source,
+		Destination:   rec.Destination,
+		DurationMs:    rec.Duration.Milliseconds(),
+		BytesSent:     rec.Sent,
+		BytesReceived: rec.Received,
+		CloseReason:   rec.CloseReason,
+		Error:         rec.Error,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return
+	}
+	if l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			l.logger.Warn("轮转访问日志失败", "path", l.path, "error", err)
+			return
+		}
+	}
+
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	if err != nil {
+		l.logger.Warn("写入访问日志失败", "path", l.path, "error", err)
+	}
+}
+
+// rotate 将当前日志依次重命名为 .1 .2 ...，超出保留数的最旧文件被覆盖
+// 调用方需持有 l.mu
+func (l *AccessLogger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if l.maxBackups > 0 {
+		if err := os.Rename(l.path, l.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(l.path); err != nil {
+		return err
+	}
+
+	return l.open()
+}
+
+// Close 关闭访问日志
+func (l *AccessLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// ReadAccessLog 读取当前日志文件的最后 limit 条记录（limit<=0 表示全部），
+// 同时返回读取结束位置，用于继续跟踪
+func ReadAccessLog(path string, limit int) ([]AccessLogEntry, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var entries []AccessLogEntry
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// 不完整的最后一行留给跟踪时读取
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		offset += int64(len(line))
+
+		if entry, ok := parseAccessLogLine(line); ok {
+			entries = append(entries, entry)
+			if limit > 0 && len(entries) > limit {
+				entries = entries[1:]
+			}
+		}
+	}
+	return entries, offset, nil
+}
+
+// FollowAccessLog 从 offset 开始持续读取新写入的记录，直到 ctx 结束
+// 日志轮转或被截断后从新文件开头继续读取
+func FollowAccessLog(ctx context.Context, path string, offset int64, interval time.Duration, handle func(AccessLogEntry)) error {
+	var current os.FileInfo
+	if info, err := os.Stat(path); err == nil {
+		current = info
+	}
+	var pending []byte
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			// 轮转过程中文件可能短暂不存在
+			continue
+		}
+		if current == nil || !os.SameFile(current, info) || info.Size() < offset {
+			offset = 0
+			pending = nil
+		}
+		current = info
+		if info.Size() == offset {
+			continue
+		}
+
+		data, err := readFrom(path, offset)
+		if err != nil {
+			return err
+		}
+		offset += int64(len(data))
+
+		pending = append(pending, data...)
+		for {
+			i := bytes.IndexByte(pending, '\n')
+			if i < 0 {
+				break
+			}
+			if entry, ok := parseAccessLogLine(pending[:i+1]); ok {
+				handle(entry)
+			}
+			pending = pending[i+1:]
+		}
+	}
+}
+
+// readFrom 读取文件从 offset 开始的全部内容
+func readFrom(path string, offset int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(file)
+}
+
+// parseAccessLogLine 解析一行访问日志，无法解析的行被忽略
+func parseAccessLogLine(line []byte) (AccessLogEntry, bool) {
+	var entry AccessLogEntry
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return entry, false
+	}
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return entry, false
+	}
+	return entry, true
+}
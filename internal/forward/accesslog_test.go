@@ -0,0 +1,125 @@
+package forward
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/ssh"
+)
+
+// testConnRecord 构造测试用连接记录
+func testConnRecord(source string) ssh.ConnRecord {
+	return ssh.ConnRecord{
+		Start:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:      source,
+		Destination: "10.0.0.1:3306",
+		Duration:    1500 * time.Millisecond,
+		Sent:        100,
+		Received:    2048,
+		CloseReason: ssh.CloseReasonNormal,
+	}
+}
+
+// TestAccessLogger 测试访问日志写入和读取
+func TestAccessLogger(t *testing.T) {
+	path := AccessLogPath(t.TempDir(), "pf-1")
+	logger, err := OpenAccessLogger(path, "mysql")
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		logger.Record(testConnRecord("127.0.0.1:5000" + string(rune('0'+i))))
+	}
+	require.NoError(t, logger.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, offset, err := ReadAccessLog(path, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	assert.Equal(t, info.Size(), offset)
+
+	entry := entries[0]
+	assert.Equal(t, "mysql", entry.Forward)
+	assert.Equal(t, "127.0.0.1:50000", entry.Source)
+	assert.Equal(t, "10.0.0.1:3306", entry.Destination)
+	assert.EqualValues(t, 1500, entry.DurationMs)
+	assert.EqualValues(t, 100, entry.BytesSent)
+	assert.EqualValues(t, 2048, entry.BytesReceived)
+	assert.Equal(t, ssh.CloseReasonNormal, entry.CloseReason)
+	assert.Contains(t, entry.String(), "127.0.0.1:50000 -> 10.0.0.1:3306 1.5s")
+
+	// 只读取最后几条
+	entries, _, err = ReadAccessLog(path, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "127.0.0.1:50004", entries[1].Source)
+}
+
+// TestAccessLoggerRotation 测试访问日志轮转
+func TestAccessLoggerRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forward.jsonl")
+	logger, err := OpenAccessLogger(path, "mysql")
+	require.NoError(t, err)
+	logger.maxSize = 512
+	logger.maxBackups = 2
+
+	for i := 0; i < 20; i++ {
+		logger.Record(testConnRecord("127.0.0.1:50000"))
+	}
+	require.NoError(t, logger.Close())
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err, name)
+		assert.LessOrEqual(t, info.Size(), int64(512), name)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestFollowAccessLog 测试持续跟踪访问日志（包括轮转）
+func TestFollowAccessLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forward.jsonl")
+	logger, err := OpenAccessLogger(path, "mysql")
+	require.NoError(t, err)
+	defer logger.Close()
+	logger.Record(testConnRecord("old"))
+
+	_, offset, err := ReadAccessLog(path, 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan string, 10)
+	go FollowAccessLog(ctx, path, offset, 10*time.Millisecond, func(entry AccessLogEntry) {
+		received <- entry.Source
+	})
+
+	expect := func(source string) {
+		select {
+		case got := <-received:
+			assert.Equal(t, source, got)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("等待访问记录 %s 超时", source)
+		}
+	}
+
+	logger.Record(testConnRecord("new"))
+	expect("new")
+
+	// 轮转后从新文件开头读取
+	logger.mu.Lock()
+	require.NoError(t, logger.rotate())
+	logger.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	logger.Record(testConnRecord("rotated"))
+	expect("rotated")
+}
@@ -0,0 +1,230 @@
+package forward
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gotssh/internal/config"
+)
+
+// StartForwardGroup 启动转发组中的所有端口转发，返回全部成员（包括已在运行而复用的成员）
+// 同一阶段的成员并发启动，存在依赖时等待前一阶段全部开始监听后再启动下一阶段
+func (m *Manager) StartForwardGroup(group *config.ForwardGroup) ([]*ActiveForward, error) {
+	forwards, _, err := m.startForwardGroup(group)
+	return forwards, err
+}
+
+// groupMember 转发组成员的启动结果
+type groupMember struct {
+	forward *ActiveForward
+	started bool // 由本次启动，而不是复用已在运行的转发
+	err     error
+}
+
+// startForwardGroup 启动转发组，另外返回由本次启动的成员。
+// 失败时只停止本次启动的成员，已在运行而复用的成员保持运行
+func (m *Manager) startForwardGroup(group *config.ForwardGroup) (forwards, started []*ActiveForward, err error) {
+	stages, err := group.Stages()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, stage := range stages {
+		members := make([]groupMember, len(stage))
+		var wg sync.WaitGroup
+		for j, alias := range stage {
+			wg.Add(1)
+			go func(j int, alias string) {
+				defer wg.Done()
+				members[j] = m.startGroupMember(alias)
+			}(j, alias)
+		}
+		wg.Wait()
+
+		var stageErr error
+		stageForwards := make([]*ActiveForward, 0, len(stage))
+		for _, member := range members {
+			if member.started {
+				started = append(started, member.forward)
+			}
+			if member.err != nil {
+				if stageErr == nil {
+					stageErr = member.err
+				}
+				continue
+			}
+			stageForwards = append(stageForwards, member.forward)
+		}
+		if stageErr != nil {
+			m.stopForwards(started)
+			return nil, nil, stageErr
+		}
+		forwards = append(forwards, stageForwards...)
+
+		// 最后一个阶段无需等待
+		if i == len(stages)-1 {
+			break
+		}
+		for _, forward := range stageForwards {
+			if err := m.waitForwardRunning(forward); err != nil {
+				m.stopForwards(started)
+				return nil, nil, fmt.Errorf("等待依赖 '%s' 启动失败: %w", forward.Config.Alias, err)
+			}
+		}
+	}
+
+	return forwards, started, nil
+}
+
+// startGroupMember 启动转发组的一个成员，已在运行的成员直接复用
+func (m *Manager) startGroupMember(alias string) groupMember {
+	pfConfig, err := m.configManager.GetPortForwardByAlias(alias)
+	if err != nil {
+		return groupMember{err: fmt.Errorf("获取端口转发配置失败: %w", err)}
+	}
+
+	var member groupMember
+	if !m.IsForwardActive(pfConfig.ID) {
+		if err := m.StartPortForward(pfConfig); err != nil {
+			return groupMember{err: fmt.Errorf("启动端口转发 '%s' 失败: %w", alias, err)}
+		}
+		member.started = true
+	}
+
+	member.forward, err = m.GetActiveForward(pfConfig.ID)
+	if err != nil {
+		member.started = false
+		member.err = fmt.Errorf("端口转发 '%s' 启动后立即退出: %w", alias, err)
+	}
+	return member
+}
+
+// StopForwardGroup 停止转发组中所有运行中的端口转发
+func (m *Manager) StopForwardGroup(group *config.ForwardGroup) error {
+	for _, alias := range group.Forwards {
+		pfConfig, err := m.configManager.GetPortForwardByAlias(alias)
+		if err != nil {
+			continue
+		}
+		if !m.IsForwardActive(pfConfig.ID) {
+			continue
+		}
+		if err := m.StopPortForward(pfConfig.ID); err != nil {
+			fmt.Printf("停止端口转发 %s 失败: %v\n", alias, err)
+		}
+	}
+	return nil
+}
+
+// stopForwards 停止指定的端口转发，用于组启动失败时回滚和按 Ctrl+C 停止
+func (m *Manager) stopForwards(forwards []*ActiveForward) {
+	for _, forward := range forwards {
+		if m.IsForwardActive(forward.ID) {
+			m.StopPortForward(forward.ID)
+		}
+	}
+}
+
+// waitForwardRunning 等待端口转发进入运行状态，即本地或远程监听已建立
+func (m *Manager) waitForwardRunning(forward *ActiveForward) error {
+	// 最长等待时间覆盖所有重试
+	deadline := time.After(m.ConnectTimeout * time.Duration(m.MaxRetries+1))
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		switch forward.State() {
+		case ForwardStateRunning:
+			return nil
+		case ForwardStateFailed:
+			return forward.LastError()
+		}
+
+		select {
+		case <-forward.Done:
+			if err := forward.LastError(); err != nil {
+				return err
+			}
+			return fmt.Errorf("端口转发已结束")
+		case <-deadline:
+			return fmt.Errorf("等待超时")
+		case <-ticker.C:
+		}
+	}
+}
+
+// FormatGroupStatus 生成转发组成员的状态表
+func FormatGroupStatus(group *config.ForwardGroup, forwards []*ActiveForward) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== 转发组 [%s] 状态 ===\n", group.Name)
+	for _, forward := range forwards {
+		pf := forward.Config
+		fmt.Fprintf(&b, "%-20s %s (%s) [%s]",
+			pf.Alias, forward.Summary(), pf.Type, forward.State())
+		if err := forward.LastError(); err != nil && forward.State() != ForwardStateRunning {
+			fmt.Fprintf(&b, " - %v", err)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// StartForwardGroupWithSignalHandler 启动转发组并处理信号，按一次 Ctrl+C 停止本次启动的所有成员
+func (m *Manager) StartForwardGroupWithSignalHandler(name string) error {
+	group, err := m.configManager.GetForwardGroupByName(name)
+	if err != nil {
+		return fmt.Errorf("获取转发组配置失败: %w", err)
+	}
+
+	// 设置信号处理
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	forwards, started, err := m.startForwardGroup(group)
+	if err != nil {
+		return err
+	}
+
+	// 所有成员结束时退出
+	allDone := make(chan struct{})
+	go func() {
+		for _, forward := range forwards {
+			<-forward.Done
+		}
+		close(allDone)
+	}()
+
+	// 状态变化时刷新组合状态视图
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	lastStatus := FormatGroupStatus(group, forwards)
+	fmt.Print(lastStatus)
+
+	for {
+		select {
+		case sig := <-sigChan:
+			fmt.Printf("\n接收到停止信号 (%v)，正在关闭转发组 [%s]...\n", sig, group.Name)
+			// 启动前已在运行的成员不属于本次启动，保持运行
+			m.stopForwards(started)
+			fmt.Printf("转发组 [%s] 已停止\n", group.Name)
+			return nil
+		case <-allDone:
+			fmt.Print(FormatGroupStatus(group, forwards))
+			fmt.Printf("转发组 [%s] 的所有端口转发已结束\n", group.Name)
+			return nil
+		case <-ticker.C:
+			status := FormatGroupStatus(group, forwards)
+			if status != lastStatus {
+				fmt.Print(status)
+				lastStatus = status
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package forward
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/config"
+)
+
+// createTestForwardGroup 创建包含多个端口转发的测试转发组
+func createTestForwardGroup(t *testing.T, manager *Manager, aliases ...string) *config.ForwardGroup {
+	// 使用不可达端口，连接会立即被拒绝
+	server := config.NewServerConfig("127.0.0.1")
+	server.Port = 1
+	server.User = "test"
+	server.AuthType = config.AuthTypePassword
+	server.Password = "test"
+	require.NoError(t, manager.configManager.AddServer(server))
+
+	for _, alias := range aliases {
+		pf := config.NewPortForwardConfig(server.ID)
+		pf.Alias = alias
+		pf.LocalPort = freePort(t)
+		pf.RemotePort = 80
+		require.NoError(t, manager.configManager.AddPortForward(pf))
+	}
+
+	group := config.NewForwardGroup("devstack")
+	group.Forwards = aliases
+	require.NoError(t, manager.configManager.AddForwardGroup(group))
+
+	return group
+}
+
+// freePort 获取当前可以绑定的本地端口，避免与其他程序占用的端口冲突
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// TestStartForwardGroup 测试启动转发组
+func TestStartForwardGroup(t *testing.T) {
+	t.Run("并发启动所有成员", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		group := createTestForwardGroup(t, manager, "db", "redis", "kafka")
+
+		forwards, err := manager.StartForwardGroup(group)
+		require.NoError(t, err)
+		assert.Len(t, forwards, 3)
+
+		for _, forward := range forwards {
+			assert.True(t, manager.IsForwardActive(forward.ID))
+		}
+
+		assert.NoError(t, manager.StopForwardGroup(group))
+		for _, forward := range forwards {
+			assert.False(t, manager.IsForwardActive(forward.ID))
+		}
+	})
+
+	t.Run("依赖启动失败时回滚", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		manager.SetTimeouts(time.Second, time.Second, time.Second, time.Second, 0)
+		group := createTestForwardGroup(t, manager, "db", "app")
+		group.Dependencies = map[string][]string{"app": {"db"}}
+
+		_, err := manager.StartForwardGroup(group)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "db")
+		assert.Empty(t, manager.ListActiveForwards())
+	})
+
+	t.Run("失败时不停止已在运行的成员", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		group := createTestForwardGroup(t, manager, "db", "cache", "redis")
+		reused, err := manager.configManager.GetPortForwardByAlias("db")
+		require.NoError(t, err)
+		require.NoError(t, manager.StartPortForward(reused))
+		defer manager.StopPortForward(reused.ID)
+
+		// 占用 redis 的本地端口，使其启动失败
+		blocked, err := manager.configManager.GetPortForwardByAlias("redis")
+		require.NoError(t, err)
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", blocked.LocalPort))
+		require.NoError(t, err)
+		defer listener.Close()
+
+		_, err = manager.StartForwardGroup(group)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "redis")
+		assert.True(t, manager.IsForwardActive(reused.ID))
+		assert.Len(t, manager.ListActiveForwards(), 1)
+	})
+
+	t.Run("无效的依赖", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		group := config.NewForwardGroup("broken")
+		group.Forwards = []string{"a", "b"}
+		group.Dependencies = map[string][]string{"a": {"b"}, "b": {"a"}}
+
+		_, err := manager.StartForwardGroup(group)
+		assert.Error(t, err)
+		assert.Empty(t, manager.ListActiveForwards())
+	})
+}
+
+// TestFormatGroupStatus 测试转发组状态输出
+func TestFormatGroupStatus(t *testing.T) {
+	t.Run("显示成员状态和错误", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		group := config.NewForwardGroup("devstack")
+		running := &ActiveForward{
+			ID:     "pf-1",
+			Config: &config.PortForwardConfig{Alias: "db", LocalHost: "127.0.0.1", LocalPort: 5432, RemoteHost: "127.0.0.1", RemotePort: 5432, Type: config.ForwardTypeLocal},
+			ctx:    ctx,
+		}
+		running.setState(ForwardStateRunning, nil)
+
+		failed := &ActiveForward{
+			ID:     "pf-2",
+			Config: &config.PortForwardConfig{Alias: "redis", LocalHost: "127.0.0.1", LocalPort: 6379, RemoteHost: "127.0.0.1", RemotePort: 6379, Type: config.ForwardTypeLocal},
+			ctx:    ctx,
+		}
+		failed.setState(ForwardStateFailed, fmt.Errorf("connection refused"))
+
+		status := FormatGroupStatus(group, []*ActiveForward{running, failed})
+		assert.Contains(t, status, "devstack")
+		assert.Contains(t, status, "127.0.0.1:5432 -> 127.0.0.1:5432")
+		assert.Contains(t, status, string(ForwardStateRunning))
+		assert.Contains(t, status, string(ForwardStateFailed))
+		assert.Contains(t, status, "connection refused")
+	})
+}
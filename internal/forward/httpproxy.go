@@ -0,0 +1,195 @@
+package forward
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+
+	"gotssh/internal/ssh"
+)
+
+// httpProxy 本地HTTP代理，支持 CONNECT 隧道和绝对URI形式的普通HTTP请求
+// 所有上游连接通过 dial 建立（通常经由SSH连接）
+type httpProxy struct {
+	dial        func(network, address string) (net.Conn, error)
+	username    string // 为空表示不需要认证
+	password    string
+	pipeOptions ssh.PipeOptions
+	recorder    ssh.ConnRecorder // 连接记录回调，为 nil 时不记录
+	reverse     *httputil.ReverseProxy
+}
+
+// newHTTPProxy 创建HTTP代理
+func newHTTPProxy(dial func(network, address string) (net.Conn, error), username, password string, opts ssh.PipeOptions) *httpProxy {
+	p := &httpProxy{
+		dial:        dial,
+		username:    username,
+		password:    password,
+		pipeOptions: opts,
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return p.dial(network, address)
+		},
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     90 * time.Second,
+		// 代理自身不再使用环境变量中的上游代理
+		Proxy: nil,
+	}
+	p.reverse = &httputil.ReverseProxy{
+		// 请求中已是绝对URI，原样转发；逐跳头部由 ReverseProxy 移除
+		Rewrite:   func(r *httputil.ProxyRequest) {},
+		Transport: transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if rw, ok := w.(*recordingWriter); ok {
+				rw.err = err
+			}
+			http.Error(w, fmt.Sprintf("代理请求失败: %v", err), http.StatusBadGateway)
+		},
+	}
+	return p
+}
+
+// ServeHTTP 处理代理请求
+func (p *httpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	if !p.authorized(r) {
+		p.recorder.Reject(start, r.RemoteAddr, r.Host, ssh.CloseReasonRejected, fmt.Errorf("代理认证失败"))
+		w.Header().Set("Proxy-Authenticate", `Basic realm="gotssh"`)
+		http.Error(w, "需要代理认证", http.StatusProxyAuthRequired)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		p.handleConnect(w, r)
+		return
+	}
+
+	if !r.URL.IsAbs() {
+		http.Error(w, "这是一个HTTP代理，请求需要使用绝对URI", http.StatusBadRequest)
+		return
+	}
+
+	// 按实际转发的字节计数，分块传输的请求体没有 Content-Length
+	body := &countingBody{ReadCloser: r.Body}
+	r.Body = body
+	rw := &recordingWriter{ResponseWriter: w}
+	p.reverse.ServeHTTP(rw, r)
+
+	if rw.err != nil {
+		p.recorder.Reject(start, r.RemoteAddr, r.URL.Host, ssh.CloseReasonDialFailed, rw.err)
+		return
+	}
+	p.recorder.Record(start, r.RemoteAddr, r.URL.Host, body.read, rw.written, nil)
+}
+
+// countingBody 统计请求体读取的字节数，用于访问日志
+type countingBody struct {
+	io.ReadCloser
+	read int64
+}
+
+// Read 读取请求体并计数
+func (b *countingBody) Read(data []byte) (int, error) {
+	n, err := b.ReadCloser.Read(data)
+	b.read += int64(n)
+	return n, err
+}
+
+// recordingWriter 统计响应字节数并保存上游错误，用于访问日志
+type recordingWriter struct {
+	http.ResponseWriter
+	written int64
+	err     error
+}
+
+// Write 写入响应并计数
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.written += int64(n)
+	return n, err
+}
+
+// Flush 支持流式响应
+func (w *recordingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// authorized 检查 Proxy-Authorization 中的Basic认证
+func (p *httpProxy) authorized(r *http.Request) bool {
+	if p.username == "" {
+		return true
+	}
+
+	auth := r.Header.Get("Proxy-Authorization")
+	scheme, encoded, ok := strings.Cut(auth, " ")
+	if !ok || !strings.EqualFold(scheme, "Basic") {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return false
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return false
+	}
+
+	userMatch := subtle.ConstantTimeCompare([]byte(username), []byte(p.username)) == 1
+	passMatch := subtle.ConstantTimeCompare([]byte(password), []byte(p.password)) == 1
+	return userMatch && passMatch
+}
+
+// handleConnect 建立 CONNECT 隧道
+func (p *httpProxy) handleConnect(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "不支持 CONNECT", http.StatusInternalServerError)
+		return
+	}
+
+	upstream, err := p.dial("tcp", r.Host)
+	if err != nil {
+		p.recorder.Reject(start, r.RemoteAddr, r.Host, ssh.CloseReasonDialFailed, err)
+		http.Error(w, fmt.Sprintf("连接 %s 失败: %v", r.Host, err), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		p.recorder.Reject(start, r.RemoteAddr, r.Host, ssh.CloseReasonError, err)
+		return
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		p.recorder.Reject(start, r.RemoteAddr, r.Host, ssh.CloseReasonError, err)
+		return
+	}
+
+	// 客户端可能在收到应答前已发送数据（如TLS ClientHello），先转发已缓冲的部分
+	var early int64
+	if n := buffered.Reader.Buffered(); n > 0 {
+		data, _ := buffered.Reader.Peek(n)
+		if _, err := upstream.Write(data); err != nil {
+			p.recorder.Reject(start, r.RemoteAddr, r.Host, ssh.CloseReasonError, err)
+			return
+		}
+		early = int64(n)
+	}
+
+	sent, received, err := ssh.Pipe(conn, upstream, p.pipeOptions)
+	p.recorder.Record(start, r.RemoteAddr, r.Host, early+sent, received, err)
+}
@@ -0,0 +1,153 @@
+package forward
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/ssh"
+)
+
+// startHTTPProxy 启动直接连接目标的测试HTTP代理
+func startHTTPProxy(t *testing.T, username, password string) string {
+	dial := func(network, address string) (net.Conn, error) {
+		return net.DialTimeout(network, address, time.Second)
+	}
+	server := httptest.NewServer(newHTTPProxy(dial, username, password, ssh.PipeOptions{}))
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().String()
+}
+
+// TestHTTPProxy 测试HTTP代理
+func TestHTTPProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "path=%s auth=%q", r.URL.Path, r.Header.Get("Proxy-Authorization"))
+	}))
+	defer backend.Close()
+
+	proxyClient := func(proxyURL *url.URL) *http.Client {
+		return &http.Client{
+			Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+			Timeout:   5 * time.Second,
+		}
+	}
+
+	t.Run("绝对URI请求", func(t *testing.T) {
+		proxyAddr := startHTTPProxy(t, "", "")
+		client := proxyClient(&url.URL{Scheme: "http", Host: proxyAddr})
+
+		resp, err := client.Get(backend.URL + "/hello")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `path=/hello auth=""`, string(body))
+	})
+
+	t.Run("分块请求体计数", func(t *testing.T) {
+		dial := func(network, address string) (net.Conn, error) {
+			return net.DialTimeout(network, address, time.Second)
+		}
+		records := make(chan ssh.ConnRecord, 1)
+		proxy := newHTTPProxy(dial, "", "", ssh.PipeOptions{})
+		proxy.recorder = func(rec ssh.ConnRecord) { records <- rec }
+		server := httptest.NewServer(proxy)
+		defer server.Close()
+		client := proxyClient(&url.URL{Scheme: "http", Host: server.Listener.Addr().String()})
+
+		// 未知长度的请求体使用分块传输，ContentLength 为 -1
+		body := io.MultiReader(strings.NewReader("hello "), strings.NewReader("world"))
+		resp, err := client.Post(backend.URL+"/upload", "text/plain", body)
+		require.NoError(t, err)
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		select {
+		case rec := <-records:
+			assert.Equal(t, int64(len("hello world")), rec.Sent)
+			assert.Equal(t, int64(len(`path=/upload auth=""`)), rec.Received)
+		case <-time.After(5 * time.Second):
+			t.Fatal("没有收到连接记录")
+		}
+	})
+
+	t.Run("CONNECT隧道", func(t *testing.T) {
+		proxyAddr := startHTTPProxy(t, "", "")
+		echo := startEcho(t)
+
+		conn, err := net.Dial("tcp", proxyAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// 在应答前就发送隧道数据，验证缓冲数据被转发
+		fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\nearly data\n", echo.Addr(), echo.Addr())
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "early data\n", line)
+	})
+
+	t.Run("CONNECT目标不可达", func(t *testing.T) {
+		proxyAddr := startHTTPProxy(t, "", "")
+		closed, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		target := closed.Addr().String()
+		closed.Close()
+
+		conn, err := net.Dial("tcp", proxyAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", target, target)
+		resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	})
+
+	t.Run("非代理请求", func(t *testing.T) {
+		proxyAddr := startHTTPProxy(t, "", "")
+		resp, err := http.Get("http://" + proxyAddr + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Basic认证", func(t *testing.T) {
+		proxyAddr := startHTTPProxy(t, "alice", "secret")
+
+		resp, err := proxyClient(&url.URL{Scheme: "http", Host: proxyAddr}).Get(backend.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusProxyAuthRequired, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Proxy-Authenticate"), "Basic")
+
+		resp, err = proxyClient(&url.URL{Scheme: "http", Host: proxyAddr, User: url.UserPassword("alice", "wrong")}).Get(backend.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusProxyAuthRequired, resp.StatusCode)
+
+		resp, err = proxyClient(&url.URL{Scheme: "http", Host: proxyAddr, User: url.UserPassword("alice", "secret")}).Get(backend.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		// 代理认证头不会转发给上游
+		assert.Contains(t, string(body), `auth=""`)
+	})
+}
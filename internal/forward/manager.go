@@ -3,20 +3,39 @@ package forward
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"gotssh/internal/config"
+	"gotssh/internal/logging"
 	"gotssh/internal/ssh"
 )
 
+// ForwardState 端口转发运行状态
+type ForwardState string
+
+const (
+	ForwardStateConnecting ForwardState = "连接中" // 正在建立SSH连接和监听
+	ForwardStateRunning    ForwardState = "运行中" // 已开始监听，转发已建立
+	ForwardStateRetrying   ForwardState = "重试中" // 连接失败，等待重试
+	ForwardStateFailed     ForwardState = "失败"  // 已放弃重试
+	ForwardStateStopped    ForwardState = "已停止" // 已正常停止
+)
+
 // Manager 端口转发管理器
 type Manager struct {
 	configManager  *config.Manager
 	activeForwards map[string]*ActiveForward
+	mu             sync.RWMutex
+	logger         *slog.Logger
 	// 新增超时配置
 	ConnectTimeout    time.Duration
 	ReadTimeout       time.Duration
@@ -36,6 +55,105 @@ type ActiveForward struct {
 	cancel     context.CancelFunc
 	errChan    chan error
 	retryCount int
+	// 实际监听的本地端口（自动分配时与配置不同）
+	localPort int
+	// 远程转发实际监听的远程端点（服务器分配端口时与配置不同）
+	remoteBound string
+	// 本地监听访问控制，重试时保留计数
+	access *ssh.AccessControl
+	// 访问日志，未启用时为 nil
+	accessLog *AccessLogger
+	// 带有转发标识的日志记录器
+	logger *slog.Logger
+	// 运行状态
+	stateMu sync.RWMutex
+	state   ForwardState
+	lastErr error
+}
+
+// State 获取端口转发当前状态
+func (f *ActiveForward) State() ForwardState {
+	f.stateMu.RLock()
+	defer f.stateMu.RUnlock()
+	if f.state == "" {
+		return ForwardStateConnecting
+	}
+	return f.state
+}
+
+// log 获取带有转发标识的日志记录器
+func (f *ActiveForward) log() *slog.Logger {
+	if f.logger == nil {
+		return logging.Nop()
+	}
+	return f.logger
+}
+
+// LastError 获取端口转发最近一次错误
+func (f *ActiveForward) LastError() error {
+	f.stateMu.RLock()
+	defer f.stateMu.RUnlock()
+	return f.lastErr
+}
+
+// LocalAddress 实际监听的本地地址
+func (f *ActiveForward) LocalAddress() string {
+	if !f.Config.BindsLocalPort() || f.localPort == 0 {
+		return f.Config.LocalAddress()
+	}
+	return net.JoinHostPort(f.Config.LocalHost, strconv.Itoa(f.localPort))
+}
+
+// Summary 使用实际地址的显示形式
+func (f *ActiveForward) Summary() string {
+	return config.FormatForward(f.Config.Type, f.LocalAddress(), f.RemoteAddress())
+}
+
+// RemoteAddress 实际的远程地址，远程转发监听成功后为服务器上的实际监听地址
+func (f *ActiveForward) RemoteAddress() string {
+	f.stateMu.RLock()
+	defer f.stateMu.RUnlock()
+	if f.remoteBound != "" {
+		return f.remoteBound
+	}
+	return f.Config.RemoteAddress()
+}
+
+// AccessStats 获取本地监听的访问控制计数，未配置访问控制时返回 false
+func (f *ActiveForward) AccessStats() (ssh.AccessStats, bool) {
+	if f.access == nil {
+		return ssh.AccessStats{}, false
+	}
+	return f.access.Stats(), true
+}
+
+// setRemoteBound 记录远程转发实际监听的地址
+func (f *ActiveForward) setRemoteBound(address string) {
+	f.stateMu.Lock()
+	defer f.stateMu.Unlock()
+	f.remoteBound = address
+}
+
+// endpoints 构建本次运行的本地和远程端点，本地端口使用实际分配的端口
+func (f *ActiveForward) endpoints() (local, remote ssh.Endpoint, err error) {
+	local, remote, err = forwardEndpoints(f.Config)
+	if err != nil {
+		return local, remote, err
+	}
+	if f.Config.BindsLocalPort() {
+		local = ssh.TCPEndpoint(f.LocalAddress())
+	}
+	return local, remote, nil
+}
+
+// setState 更新端口转发状态
+func (f *ActiveForward) setState(state ForwardState, err error) {
+	f.stateMu.Lock()
+	defer f.stateMu.Unlock()
+	f.state = state
+	if err != nil {
+		f.lastErr = err
+	}
 }
 
 // NewManager 创建新的端口转发管理器
@@ -43,6 +161,7 @@ func NewManager(configManager *config.Manager) *Manager {
 	return &Manager{
 		configManager:  configManager,
 		activeForwards: make(map[string]*ActiveForward),
+		logger:         configManager.Logger(),
 		// 默认超时配置
 		ConnectTimeout:    30 * time.Second,
 		ReadTimeout:       60 * time.Second,
@@ -52,6 +171,14 @@ func NewManager(configManager *config.Manager) *Manager {
 	}
 }
 
+// SetLogger 设置日志记录器，为 nil 时丢弃日志
+func (m *Manager) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = logging.Nop()
+	}
+	m.logger = logger
+}
+
 // SetTimeouts 设置超时配置
 func (m *Manager) SetTimeouts(connectTimeout, readTimeout, writeTimeout, keepAliveInterval time.Duration, maxRetries int) {
 	m.ConnectTimeout = connectTimeout
@@ -63,10 +190,55 @@ func (m *Manager) SetTimeouts(connectTimeout, readTimeout, writeTimeout, keepAli
 
 // StartPortForward 启动端口转发
 func (m *Manager) StartPortForward(pfConfig *config.PortForwardConfig) error {
+	// 提前校验端点配置，避免后台协程启动后才失败
+	if _, _, err := forwardEndpoints(pfConfig); err != nil {
+		return err
+	}
+	access, err := accessControl(pfConfig)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// 检查是否已经存在同样的端口转发
 	if _, exists := m.activeForwards[pfConfig.ID]; exists {
 		return fmt.Errorf("端口转发 %s 已经在运行", pfConfig.ID)
 	}
+	if !pfConfig.Type.Valid() {
+		return fmt.Errorf("不支持的转发类型: %s", pfConfig.Type)
+	}
+
+	// 检查本地端口是否可用，自动模式下分配空闲端口
+	localPort := pfConfig.LocalPort
+	if pfConfig.BindsLocalPort() {
+		if err := m.checkActivePortConflict(pfConfig); err != nil {
+			return err
+		}
+		port, err := CheckLocalPort(pfConfig.LocalHost, pfConfig.LocalPort)
+		if err != nil {
+			return err
+		}
+		localPort = port
+		if pfConfig.AutoLocalPort() {
+			fmt.Printf("端口转发 %s 自动分配本地端口: %d\n", pfConfig.ID, localPort)
+		}
+	}
+
+	// 打开访问日志
+	var accessLog *AccessLogger
+	if pfConfig.AccessLog {
+		name := pfConfig.Alias
+		if name == "" {
+			name = pfConfig.ID
+		}
+		accessLog, err = OpenAccessLogger(AccessLogPath(m.configManager.ConfigDir(), pfConfig.ID), name)
+		if err != nil {
+			return err
+		}
+		accessLog.logger = m.logger
+	}
 
 	// 创建上下文和取消函数
 	ctx, cancel := context.WithCancel(context.Background())
@@ -80,17 +252,22 @@ func (m *Manager) StartPortForward(pfConfig *config.PortForwardConfig) error {
 		cancel:     cancel,
 		errChan:    make(chan error, 1),
 		retryCount: 0,
+		localPort:  localPort,
+		access:     access,
+		accessLog:  accessLog,
+		logger:     m.logger.With("forward", pfConfig.ID),
+		state:      ForwardStateConnecting,
 	}
 
+	// 存储活动转发
+	m.activeForwards[pfConfig.ID] = forward
+
 	// 启动端口转发协程
 	go m.runPortForwardWithRetry(forward)
 
 	// 启动连接监控协程
 	go m.monitorConnection(forward)
 
-	// 存储活动转发
-	m.activeForwards[pfConfig.ID] = forward
-
 	return nil
 }
 
@@ -100,14 +277,20 @@ func (m *Manager) runPortForwardWithRetry(forward *ActiveForward) {
 		if forward.SSHClient != nil {
 			forward.SSHClient.Close()
 		}
-		delete(m.activeForwards, forward.ID)
+		if forward.State() != ForwardStateFailed {
+			forward.setState(ForwardStateStopped, nil)
+		}
+		if forward.accessLog != nil {
+			forward.accessLog.Close()
+		}
+		m.removeActiveForward(forward)
 		close(forward.Done)
 	}()
 
 	for forward.retryCount <= m.MaxRetries {
 		select {
 		case <-forward.ctx.Done():
-			fmt.Printf("端口转发 %s 已取消\n", forward.ID)
+			forward.log().Debug("端口转发已取消")
 			return
 		default:
 		}
@@ -115,20 +298,29 @@ func (m *Manager) runPortForwardWithRetry(forward *ActiveForward) {
 		// 获取服务器配置
 		serverConfig, err := m.configManager.GetServer(forward.Config.ServerID)
 		if err != nil {
-			forward.errChan <- fmt.Errorf("获取服务器配置失败: %w", err)
+			m.failForward(forward, fmt.Errorf("获取服务器配置失败: %w", err))
 			return
 		}
 
-		// 创建SSH客户端
+		// 创建SSH客户端，读超时用于半关闭后等待另一方向，写超时用于单次写入
 		client := ssh.NewClient(serverConfig, m.configManager)
+		client.SetLogger(forward.log())
+		client.SetPipeOptions(ssh.PipeOptions{
+			HalfCloseTimeout: m.ReadTimeout,
+			WriteTimeout:     m.WriteTimeout,
+		})
+		if forward.accessLog != nil {
+			client.SetConnRecorder(forward.accessLog.Record)
+		}
+		forward.setState(ForwardStateConnecting, nil)
 
 		// 设置连接超时
 		if err := m.connectWithTimeout(client, forward.ctx); err != nil {
 			forward.retryCount++
-			fmt.Printf("端口转发 %s 连接失败 (重试 %d/%d): %v\n",
-				forward.ID, forward.retryCount, m.MaxRetries, err)
+			forward.log().Warn("端口转发连接失败", "retry", forward.retryCount, "max_retries", m.MaxRetries, "error", err)
 
 			if forward.retryCount <= m.MaxRetries {
+				forward.setState(ForwardStateRetrying, err)
 				// 等待一段时间后重试
 				select {
 				case <-forward.ctx.Done():
@@ -138,7 +330,7 @@ func (m *Manager) runPortForwardWithRetry(forward *ActiveForward) {
 				}
 			}
 
-			forward.errChan <- fmt.Errorf("连接失败，已达到最大重试次数: %w", err)
+			m.failForward(forward, fmt.Errorf("连接失败，已达到最大重试次数: %w", err))
 			return
 		}
 
@@ -146,25 +338,43 @@ func (m *Manager) runPortForwardWithRetry(forward *ActiveForward) {
 		forward.retryCount = 0 // 重置重试计数
 
 		// 启动端口转发
-		localAddr := fmt.Sprintf("%s:%d", forward.Config.LocalHost, forward.Config.LocalPort)
-		remoteAddr := fmt.Sprintf("%s:%d", forward.Config.RemoteHost, forward.Config.RemotePort)
+		local, remote, err := forward.endpoints()
+		if err != nil {
+			m.failForward(forward, err)
+			return
+		}
+
+		fmt.Printf("端口转发 %s 已启动: %s -> %s\n", forward.ID, local, remote)
 
-		fmt.Printf("端口转发 %s 已启动: %s -> %s\n", forward.ID, localAddr, remoteAddr)
+		// 监听成功后才进入运行状态，依赖该转发的转发组成员此时才能启动
+		onListen := func() {
+			forward.setState(ForwardStateRunning, nil)
+		}
+		onRemoteListen := func(bound ssh.Endpoint) {
+			forward.setRemoteBound(bound.Address)
+			onListen()
+		}
 
 		var forwardErr error
-		if forward.Config.Type == config.ForwardTypeLocal {
-			forwardErr = m.localPortForwardWithContext(client, forward.ctx, localAddr, remoteAddr)
-		} else {
-			forwardErr = m.remotePortForwardWithContext(client, forward.ctx, remoteAddr, localAddr)
+		switch forward.Config.Type {
+		case config.ForwardTypeLocal:
+			forwardErr = m.localPortForwardWithContext(client, forward.ctx, local, remote, forward.access, onListen)
+		case config.ForwardTypeRemoteDynamic:
+			forwardErr = m.remoteDynamicForwardWithContext(client, forward.ctx, remote, onRemoteListen)
+		case config.ForwardTypeHTTPProxy:
+			forwardErr = m.httpProxyForwardWithContext(client, forward.ctx, local, forward.Config, onListen)
+		default:
+			forwardErr = m.remotePortForwardWithContext(client, forward.ctx, remote, local, onRemoteListen)
 		}
 
 		if forwardErr != nil {
-			fmt.Printf("端口转发 %s 错误: %v\n", forward.ID, forwardErr)
+			forward.log().Error("端口转发错误", "error", forwardErr)
 
 			// 检查是否是网络错误，如果是则重试
 			if isNetworkError(forwardErr) && forward.retryCount < m.MaxRetries {
 				forward.retryCount++
-				fmt.Printf("网络错误，准备重试 %d/%d\n", forward.retryCount, m.MaxRetries)
+				forward.setState(ForwardStateRetrying, forwardErr)
+				forward.log().Warn("网络错误，准备重试", "retry", forward.retryCount, "max_retries", m.MaxRetries)
 
 				// 关闭当前连接
 				if forward.SSHClient != nil {
@@ -180,7 +390,7 @@ func (m *Manager) runPortForwardWithRetry(forward *ActiveForward) {
 					continue
 				}
 			} else {
-				forward.errChan <- forwardErr
+				m.failForward(forward, forwardErr)
 				return
 			}
 		}
@@ -190,6 +400,85 @@ func (m *Manager) runPortForwardWithRetry(forward *ActiveForward) {
 	}
 }
 
+// forwardEndpoints 根据端口转发配置构建本地和远程端点
+func forwardEndpoints(pf *config.PortForwardConfig) (local, remote ssh.Endpoint, err error) {
+	if pf.LocalSocket != "" {
+		mode, err := pf.SocketFileMode()
+		if err != nil {
+			return local, remote, err
+		}
+		local = ssh.UnixEndpoint(pf.LocalSocket, mode)
+	} else {
+		local = ssh.TCPEndpoint(pf.LocalAddress())
+	}
+
+	if pf.RemoteSocket != "" {
+		remote = ssh.UnixEndpoint(pf.RemoteSocket, 0)
+	} else {
+		remote = ssh.TCPEndpoint(pf.RemoteAddress())
+	}
+
+	return local, remote, nil
+}
+
+// checkActivePortConflict 检查本地端口是否已被其他运行中的端口转发占用
+// 调用方需持有 m.mu
+func (m *Manager) checkActivePortConflict(pf *config.PortForwardConfig) error {
+	if pf.AutoLocalPort() {
+		return nil
+	}
+	for _, forward := range m.activeForwards {
+		if !forward.Config.BindsLocalPort() || forward.localPort != pf.LocalPort {
+			continue
+		}
+		if config.LocalHostsOverlap(forward.Config.LocalHost, pf.LocalHost) {
+			name := forward.Config.Alias
+			if name == "" {
+				name = forward.ID
+			}
+			return fmt.Errorf("本地端口 %d 已被运行中的端口转发 '%s' 占用", pf.LocalPort, name)
+		}
+	}
+	return nil
+}
+
+// accessControl 根据端口转发配置构建本地监听的访问控制，未配置时返回 nil
+func accessControl(pf *config.PortForwardConfig) (*ssh.AccessControl, error) {
+	if pf.Type != config.ForwardTypeLocal || !pf.HasAccessControl() {
+		return nil, nil
+	}
+
+	networks, err := pf.AllowedNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.AccessControl{
+		AllowedNets:    networks,
+		MaxConnections: pf.MaxConnections,
+		IdleTimeout:    time.Duration(pf.IdleTimeout) * time.Second,
+		RateLimit:      pf.RateLimit,
+	}, nil
+}
+
+// failForward 将端口转发标记为失败并上报错误
+func (m *Manager) failForward(forward *ActiveForward, err error) {
+	forward.setState(ForwardStateFailed, err)
+	select {
+	case forward.errChan <- err:
+	default:
+	}
+}
+
+// removeActiveForward 从活动列表中移除端口转发（仅当记录仍属于该转发时）
+func (m *Manager) removeActiveForward(forward *ActiveForward) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, exists := m.activeForwards[forward.ID]; exists && current == forward {
+		delete(m.activeForwards, forward.ID)
+	}
+}
+
 // connectWithTimeout 带超时的连接
 func (m *Manager) connectWithTimeout(client *ssh.Client, ctx context.Context) error {
 	connChan := make(chan error, 1)
@@ -224,7 +513,7 @@ func (m *Manager) monitorConnection(forward *ActiveForward) {
 
 			// 检查连接状态
 			if !forward.SSHClient.IsConnected() {
-				fmt.Printf("端口转发 %s 连接已断开，触发重连\n", forward.ID)
+				forward.log().Warn("SSH连接已断开，触发重连")
 
 				// 发送错误信号触发重连
 				select {
@@ -233,7 +522,7 @@ func (m *Manager) monitorConnection(forward *ActiveForward) {
 				}
 			}
 		case err := <-forward.errChan:
-			fmt.Printf("端口转发 %s 监控到错误: %v\n", forward.ID, err)
+			forward.log().Warn("监控到端口转发错误", "error", err)
 		}
 	}
 }
@@ -266,11 +555,11 @@ func isNetworkError(err error) bool {
 }
 
 // localPortForwardWithContext 本地端口转发（带Context）
-func (m *Manager) localPortForwardWithContext(client *ssh.Client, ctx context.Context, localAddr, remoteAddr string) error {
+func (m *Manager) localPortForwardWithContext(client *ssh.Client, ctx context.Context, local, remote ssh.Endpoint, access *ssh.AccessControl, onListen func()) error {
 	errChan := make(chan error, 1)
 
 	go func() {
-		errChan <- client.LocalPortForwardWithTimeout(localAddr, remoteAddr, m.ReadTimeout)
+		errChan <- client.LocalForwardNotify(local, remote, m.ReadTimeout, access, onListen)
 	}()
 
 	select {
@@ -282,11 +571,40 @@ func (m *Manager) localPortForwardWithContext(client *ssh.Client, ctx context.Co
 }
 
 // remotePortForwardWithContext 远程端口转发（带Context）
-func (m *Manager) remotePortForwardWithContext(client *ssh.Client, ctx context.Context, remoteAddr, localAddr string) error {
+func (m *Manager) remotePortForwardWithContext(client *ssh.Client, ctx context.Context, remote, local ssh.Endpoint, onListen func(ssh.Endpoint)) error {
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- client.RemoteForwardNotify(remote, local, m.ReadTimeout, onListen)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// remoteDynamicForwardWithContext 远程动态转发（带Context）
+// 服务器上的监听端口接入的连接交给本机的SOCKS5服务器处理，由本机连接目标
+func (m *Manager) remoteDynamicForwardWithContext(client *ssh.Client, ctx context.Context, remote ssh.Endpoint, onListen func(ssh.Endpoint)) error {
 	errChan := make(chan error, 1)
+	socks := newDirectSocksServer(m.ConnectTimeout, ssh.PipeOptions{
+		HalfCloseTimeout: m.ReadTimeout,
+		WriteTimeout:     m.WriteTimeout,
+	})
+	socks.recorder = client.ConnRecorder()
 
 	go func() {
-		errChan <- client.RemotePortForwardWithTimeout(remoteAddr, localAddr, m.ReadTimeout)
+		errChan <- client.ServeRemote(remote, func(bound ssh.Endpoint) {
+			fmt.Printf("远程动态转发已启动: 服务器上的 %s 可作为 SOCKS5 代理，经本机访问网络\n", bound)
+			onListen(bound)
+		}, func(conn net.Conn) {
+			if err := socks.ServeConn(conn); err != nil {
+				client.Logger().Warn("SOCKS5 连接处理失败", "error", err)
+			}
+		})
 	}()
 
 	select {
@@ -297,9 +615,52 @@ func (m *Manager) remotePortForwardWithContext(client *ssh.Client, ctx context.C
 	}
 }
 
+// httpProxyForwardWithContext 本地HTTP代理（带Context），上游连接经由SSH建立
+func (m *Manager) httpProxyForwardWithContext(client *ssh.Client, ctx context.Context, local ssh.Endpoint, pf *config.PortForwardConfig, onListen func()) error {
+	listener, err := ssh.ListenLocal(local)
+	if err != nil {
+		return fmt.Errorf("监听本地端口失败: %w", err)
+	}
+	onListen()
+
+	proxy := newHTTPProxy(client.Dial, pf.ProxyUsername, pf.ProxyPassword, ssh.PipeOptions{
+		HalfCloseTimeout: m.ReadTimeout,
+		WriteTimeout:     m.WriteTimeout,
+	})
+	proxy.recorder = client.ConnRecorder()
+	server := &http.Server{
+		Handler:           proxy,
+		ReadHeaderTimeout: m.ReadTimeout,
+	}
+	defer server.Close()
+
+	fmt.Printf("HTTP代理已启动: %s（经服务器访问网络）\n", local)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	connClosed := make(chan error, 1)
+	go func() {
+		connClosed <- client.Wait()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("HTTP代理服务失败: %w", err)
+	case <-connClosed:
+		return fmt.Errorf("SSH连接已断开")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // StopPortForward 停止端口转发
 func (m *Manager) StopPortForward(pfID string) error {
+	m.mu.RLock()
 	forward, exists := m.activeForwards[pfID]
+	m.mu.RUnlock()
 	if !exists {
 		return fmt.Errorf("端口转发 %s 不存在或未运行", pfID)
 	}
@@ -310,7 +671,7 @@ func (m *Manager) StopPortForward(pfID string) error {
 	// 关闭SSH连接
 	if forward.SSHClient != nil {
 		if err := forward.SSHClient.Close(); err != nil {
-			fmt.Printf("关闭SSH连接时出现错误: %v\n", err)
+			forward.log().Debug("关闭SSH连接时出现错误", "error", err)
 		}
 	}
 
@@ -319,11 +680,11 @@ func (m *Manager) StopPortForward(pfID string) error {
 	case <-forward.Done:
 		// 转发已停止
 		fmt.Printf("端口转发 %s 已停止\n", pfID)
-		delete(m.activeForwards, pfID)
+		m.removeActiveForward(forward)
 	case <-time.After(5 * time.Second):
 		// 超时，强制清理
-		fmt.Printf("等待端口转发 %s 停止超时，强制清理\n", pfID)
-		delete(m.activeForwards, pfID)
+		forward.log().Warn("等待端口转发停止超时，强制清理")
+		m.removeActiveForward(forward)
 	}
 
 	return nil
@@ -331,7 +692,14 @@ func (m *Manager) StopPortForward(pfID string) error {
 
 // StopAllPortForwards 停止所有端口转发
 func (m *Manager) StopAllPortForwards() error {
+	m.mu.RLock()
+	pfIDs := make([]string, 0, len(m.activeForwards))
 	for pfID := range m.activeForwards {
+		pfIDs = append(pfIDs, pfID)
+	}
+	m.mu.RUnlock()
+
+	for _, pfID := range pfIDs {
 		if err := m.StopPortForward(pfID); err != nil {
 			fmt.Printf("停止端口转发 %s 失败: %v\n", pfID, err)
 		}
@@ -341,6 +709,9 @@ func (m *Manager) StopAllPortForwards() error {
 
 // ListActiveForwards 列出所有活动的端口转发
 func (m *Manager) ListActiveForwards() []*ActiveForward {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	forwards := make([]*ActiveForward, 0)
 	for _, forward := range m.activeForwards {
 		forwards = append(forwards, forward)
@@ -350,6 +721,8 @@ func (m *Manager) ListActiveForwards() []*ActiveForward {
 
 // IsForwardActive 检查端口转发是否活动
 func (m *Manager) IsForwardActive(pfID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	_, exists := m.activeForwards[pfID]
 	return exists
 }
@@ -376,7 +749,10 @@ func (m *Manager) StartPortForwardWithSignalHandler(pfConfig *config.PortForward
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	// 等待信号或转发结束
-	forward := m.activeForwards[pfConfig.ID]
+	forward, err := m.GetActiveForward(pfConfig.ID)
+	if err != nil {
+		return err
+	}
 	select {
 	case sig := <-sigChan:
 		fmt.Printf("\n接收到停止信号 (%v)，正在关闭端口转发...\n", sig)
@@ -405,6 +781,8 @@ func (m *Manager) StartPortForwardByAliasWithSignalHandler(alias string) error {
 
 // GetActiveForward 获取活动的端口转发
 func (m *Manager) GetActiveForward(pfID string) (*ActiveForward, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	forward, exists := m.activeForwards[pfID]
 	if !exists {
 		return nil, fmt.Errorf("端口转发 %s 不存在或未运行", pfID)
@@ -440,9 +818,7 @@ func (m *Manager) TestPortForward(pfConfig *config.PortForwardConfig) error {
 		return fmt.Errorf("SSH连接不稳定")
 	}
 
-	fmt.Printf("端口转发配置测试成功: %s -> %s\n",
-		fmt.Sprintf("%s:%d", pfConfig.LocalHost, pfConfig.LocalPort),
-		fmt.Sprintf("%s:%d", pfConfig.RemoteHost, pfConfig.RemotePort))
+	fmt.Printf("端口转发配置测试成功: %s\n", pfConfig.Summary())
 
 	return nil
 }
@@ -408,6 +408,109 @@ func TestErrorHandling(t *testing.T) {
 	})
 }
 
+// TestForwardEndpoints 测试根据配置构建转发端点
+func TestForwardEndpoints(t *testing.T) {
+	t.Run("TCP端点", func(t *testing.T) {
+		pf := config.NewPortForwardConfig("server")
+		pf.LocalPort = 8080
+		pf.RemotePort = 80
+
+		local, remote, err := forwardEndpoints(pf)
+		require.NoError(t, err)
+		assert.Equal(t, "tcp", local.Network)
+		assert.Equal(t, "127.0.0.1:8080", local.Address)
+		assert.Equal(t, "tcp", remote.Network)
+		assert.Equal(t, "127.0.0.1:80", remote.Address)
+	})
+
+	t.Run("Unix套接字端点", func(t *testing.T) {
+		pf := config.NewPortForwardConfig("server")
+		pf.LocalSocket = "/tmp/docker.sock"
+		pf.RemoteSocket = "/var/run/docker.sock"
+		pf.SocketMode = "0660"
+
+		local, remote, err := forwardEndpoints(pf)
+		require.NoError(t, err)
+		assert.True(t, local.IsUnix())
+		assert.Equal(t, "/tmp/docker.sock", local.Address)
+		assert.EqualValues(t, 0660, local.Mode)
+		assert.True(t, remote.IsUnix())
+		assert.Equal(t, "/var/run/docker.sock", remote.Address)
+	})
+
+	t.Run("无效的套接字权限", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		pf := config.NewPortForwardConfig("server")
+		pf.LocalSocket = "/tmp/docker.sock"
+		pf.SocketMode = "abc"
+
+		err := manager.StartPortForward(pf)
+		assert.Error(t, err)
+		assert.False(t, manager.IsForwardActive(pf.ID))
+	})
+}
+
+// TestActiveForwardAddresses 测试运行中端口转发的实际地址
+func TestActiveForwardAddresses(t *testing.T) {
+	pf := config.NewPortForwardConfig("server")
+	pf.Type = config.ForwardTypeRemote
+	pf.LocalPort = 3000
+	pf.RemoteHost = "0.0.0.0"
+	pf.RemotePort = 0
+	assert.True(t, pf.AutoRemotePort())
+
+	forward := &ActiveForward{ID: pf.ID, Config: pf}
+	assert.Equal(t, "127.0.0.1:3000", forward.LocalAddress())
+	assert.Equal(t, "0.0.0.0:0", forward.RemoteAddress())
+
+	forward.setRemoteBound("0.0.0.0:41234")
+	assert.Equal(t, "0.0.0.0:41234", forward.RemoteAddress())
+	assert.Equal(t, 0, pf.RemotePort, "服务器分配端口不应修改保存的配置")
+}
+
+// TestAccessControlFromConfig 测试根据配置构建访问控制
+func TestAccessControlFromConfig(t *testing.T) {
+	pf := config.NewPortForwardConfig("server")
+	access, err := accessControl(pf)
+	require.NoError(t, err)
+	assert.Nil(t, access)
+
+	pf.AllowedCIDRs = []string{"192.168.0.0/16"}
+	pf.MaxConnections = 10
+	pf.IdleTimeout = 30
+	access, err = accessControl(pf)
+	require.NoError(t, err)
+	require.NotNil(t, access)
+	assert.Len(t, access.AllowedNets, 1)
+	assert.Equal(t, 10, access.MaxConnections)
+	assert.Equal(t, 30*time.Second, access.IdleTimeout)
+
+	// 远程转发不使用本地监听访问控制
+	pf.Type = config.ForwardTypeRemote
+	access, err = accessControl(pf)
+	require.NoError(t, err)
+	assert.Nil(t, access)
+
+	// 无效网段在启动前报错
+	manager := createTestForwardManager(t)
+	pf.Type = config.ForwardTypeLocal
+	pf.AllowedCIDRs = []string{"invalid"}
+	assert.Error(t, manager.StartPortForward(pf))
+	assert.False(t, manager.IsForwardActive(pf.ID))
+}
+
+// TestStartPortForwardInvalidType 测试不支持的转发类型
+func TestStartPortForwardInvalidType(t *testing.T) {
+	manager := createTestForwardManager(t)
+	pf := config.NewPortForwardConfig("server")
+	pf.Type = "unknown"
+
+	err := manager.StartPortForward(pf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown")
+	assert.False(t, manager.IsForwardActive(pf.ID))
+}
+
 // BenchmarkNewManager 性能测试
 func BenchmarkNewManager(b *testing.B) {
 	tempDir := b.TempDir()
@@ -0,0 +1,36 @@
+package forward
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// PortOwner 占用端口的进程信息
+type PortOwner struct {
+	PID     int    // 进程ID
+	Command string // 进程名
+}
+
+// String 进程信息的显示形式
+func (o *PortOwner) String() string {
+	return fmt.Sprintf("%s (PID %d)", o.Command, o.PID)
+}
+
+// CheckLocalPort 检查本地地址是否可以监听，端口为0时分配一个空闲端口
+// 返回实际可用的端口
+func CheckLocalPort(host string, port int) (int, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		if port == 0 {
+			return 0, fmt.Errorf("无法在 %s 上分配空闲端口: %w", host, err)
+		}
+		if owner, ownerErr := FindPortOwner(port); ownerErr == nil && owner != nil {
+			return 0, fmt.Errorf("本地端口 %d 已被进程 %s 占用", port, owner)
+		}
+		return 0, fmt.Errorf("本地端口 %d 无法监听: %w", port, err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
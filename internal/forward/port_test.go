@@ -0,0 +1,98 @@
+package forward
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckLocalPort 测试本地端口可用性检查
+func TestCheckLocalPort(t *testing.T) {
+	t.Run("自动分配端口", func(t *testing.T) {
+		port, err := CheckLocalPort("127.0.0.1", 0)
+		require.NoError(t, err)
+		assert.Greater(t, port, 0)
+	})
+
+	t.Run("端口已被占用", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		port := listener.Addr().(*net.TCPAddr).Port
+
+		_, err = CheckLocalPort("127.0.0.1", port)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), strconv.Itoa(port))
+	})
+}
+
+// TestFindPortOwner 测试查找占用端口的进程
+func TestFindPortOwner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	owner, err := FindPortOwner(port)
+	if err != nil {
+		t.Skipf("当前平台不支持查找端口占用进程: %v", err)
+	}
+	require.NotNil(t, owner)
+	assert.Equal(t, os.Getpid(), owner.PID)
+	assert.NotEmpty(t, owner.Command)
+}
+
+// TestStartPortForwardPortConflict 测试启动时的端口冲突检测和自动分配
+func TestStartPortForwardPortConflict(t *testing.T) {
+	t.Run("端口被外部进程占用", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		pf := createTestPortForwardConfig(t, manager)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		pf.LocalPort = listener.Addr().(*net.TCPAddr).Port
+
+		err = manager.StartPortForward(pf)
+		assert.Error(t, err)
+		assert.False(t, manager.IsForwardActive(pf.ID))
+	})
+
+	t.Run("端口被运行中的端口转发占用", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		manager.SetTimeouts(manager.ConnectTimeout, manager.ReadTimeout, manager.WriteTimeout, manager.KeepAliveInterval, 0)
+		pf := createTestPortForwardConfig(t, manager)
+		running := &ActiveForward{ID: "running", Config: pf, localPort: pf.LocalPort}
+		manager.activeForwards[running.ID] = running
+
+		other := *pf
+		other.ID = "other"
+		other.Alias = "other"
+		other.LocalHost = "0.0.0.0"
+
+		err := manager.StartPortForward(&other)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "test-forward")
+	})
+
+	t.Run("自动分配本地端口", func(t *testing.T) {
+		manager := createTestForwardManager(t)
+		manager.SetTimeouts(manager.ConnectTimeout, manager.ReadTimeout, manager.WriteTimeout, manager.KeepAliveInterval, 0)
+		pf := createTestPortForwardConfig(t, manager)
+		pf.LocalPort = 0
+
+		require.NoError(t, manager.StartPortForward(pf))
+		defer manager.StopAllPortForwards()
+
+		forward, err := manager.GetActiveForward(pf.ID)
+		if err != nil {
+			t.Skip("端口转发已结束")
+		}
+		assert.NotEqual(t, "127.0.0.1:0", forward.LocalAddress())
+		assert.Equal(t, 0, pf.LocalPort, "自动分配不应修改保存的配置")
+	})
+}
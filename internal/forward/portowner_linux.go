@@ -0,0 +1,96 @@
+//go:build linux
+
+package forward
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tcpListenState /proc/net/tcp 中 LISTEN 状态的编码
+const tcpListenState = "0A"
+
+// FindPortOwner 通过 /proc 查找监听指定TCP端口的进程
+// 无权限读取其他用户进程时返回 nil
+func FindPortOwner(port int) (*PortOwner, error) {
+	inodes := make(map[string]bool)
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		found, err := listenSocketInodes(path, port)
+		if err != nil {
+			continue
+		}
+		for _, inode := range found {
+			inodes[inode] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, fmt.Errorf("未找到监听端口 %d 的套接字", port)
+	}
+
+	procDirs, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, procDir := range procDirs {
+		fds, err := os.ReadDir(filepath.Join(procDir, "fd"))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(procDir, "fd", fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			if !inodes[inode] {
+				continue
+			}
+
+			pid, _ := strconv.Atoi(filepath.Base(procDir))
+			comm, _ := os.ReadFile(filepath.Join(procDir, "comm"))
+			return &PortOwner{PID: pid, Command: strings.TrimSpace(string(comm))}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// listenSocketInodes 解析 /proc/net/tcp 格式的文件，返回监听指定端口的套接字inode
+func listenSocketInodes(path string, port int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseListenSocketInodes(bufio.NewScanner(file), port), nil
+}
+
+// parseListenSocketInodes 从 /proc/net/tcp 内容中提取监听指定端口的inode
+func parseListenSocketInodes(scanner *bufio.Scanner, port int) []string {
+	var inodes []string
+	scanner.Scan() // 跳过表头
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+
+		// local_address 格式为 十六进制地址:十六进制端口
+		idx := strings.LastIndex(fields[1], ":")
+		if idx < 0 {
+			continue
+		}
+		localPort, err := strconv.ParseInt(fields[1][idx+1:], 16, 32)
+		if err != nil || int(localPort) != port {
+			continue
+		}
+		inodes = append(inodes, fields[9])
+	}
+	return inodes
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package forward
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseListenSocketInodes 测试解析 /proc/net/tcp 内容
+func TestParseListenSocketInodes(t *testing.T) {
+	content := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 23456 1 0000000000000000 20 4 30 10 -1
+   2: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 34567 1 0000000000000000 100 0 0 10 0
+`
+	inodes := parseListenSocketInodes(bufio.NewScanner(strings.NewReader(content)), 8080)
+	assert.Equal(t, []string{"12345"}, inodes)
+
+	inodes = parseListenSocketInodes(bufio.NewScanner(strings.NewReader(content)), 22)
+	assert.Equal(t, []string{"34567"}, inodes)
+
+	assert.Empty(t, parseListenSocketInodes(bufio.NewScanner(strings.NewReader(content)), 443))
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package forward
+
+import "fmt"
+
+// FindPortOwner 查找监听指定TCP端口的进程（仅支持Linux）
+func FindPortOwner(port int) (*PortOwner, error) {
+	return nil, fmt.Errorf("当前平台不支持查找端口占用进程")
+}
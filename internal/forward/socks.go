@@ -0,0 +1,202 @@
+package forward
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gotssh/internal/ssh"
+)
+
+// SOCKS5 协议常量（RFC 1928）
+const (
+	socksVersion5 = 0x05
+
+	socksMethodNoAuth       = 0x00
+	socksMethodNoAcceptable = 0xff
+
+	socksCmdConnect = 0x01
+
+	socksAtypIPv4   = 0x01
+	socksAtypDomain = 0x03
+	socksAtypIPv6   = 0x04
+
+	socksRepSucceeded           = 0x00
+	socksRepGeneralFailure      = 0x01
+	socksRepNetworkUnreachable  = 0x03
+	socksRepHostUnreachable     = 0x04
+	socksRepConnectionRefused   = 0x05
+	socksRepCommandNotSupported = 0x07
+	socksRepAddrNotSupported    = 0x08
+)
+
+// socksServer 最小的SOCKS5服务器，仅支持无认证和 CONNECT 命令
+type socksServer struct {
+	dial        func(network, address string) (net.Conn, error) // 连接目标地址
+	pipeOptions ssh.PipeOptions
+	recorder    ssh.ConnRecorder // 连接记录回调，为 nil 时不记录
+}
+
+// newDirectSocksServer 创建直接从本机连接目标的SOCKS5服务器
+func newDirectSocksServer(dialTimeout time.Duration, opts ssh.PipeOptions) *socksServer {
+	return &socksServer{
+		dial: func(network, address string) (net.Conn, error) {
+			return net.DialTimeout(network, address, dialTimeout)
+		},
+		pipeOptions: opts,
+	}
+}
+
+// ServeConn 处理一个SOCKS5客户端连接，完成握手后转发数据，结束时关闭连接
+func (s *socksServer) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+	start := time.Now()
+	source := conn.RemoteAddr().String()
+
+	// 握手阶段设置超时，避免客户端不发送数据占用连接
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	target, err := s.handshake(conn)
+	if err != nil {
+		s.recorder.Reject(start, source, "", ssh.CloseReasonError, err)
+		return err
+	}
+
+	upstream, err := s.dial("tcp", target)
+	if err != nil {
+		writeSocksReply(conn, socksReplyCode(err), nil)
+		s.recorder.Reject(start, source, target, ssh.CloseReasonDialFailed, err)
+		return fmt.Errorf("连接目标 %s 失败: %w", target, err)
+	}
+	defer upstream.Close()
+
+	bound, _ := upstream.LocalAddr().(*net.TCPAddr)
+	if err := writeSocksReply(conn, socksRepSucceeded, bound); err != nil {
+		s.recorder.Reject(start, source, target, ssh.CloseReasonError, err)
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+
+	sent, received, err := ssh.Pipe(conn, upstream, s.pipeOptions)
+	s.recorder.Record(start, source, target, sent, received, err)
+	return err
+}
+
+// handshake 完成方法协商并读取 CONNECT 请求，返回目标地址
+func (s *socksServer) handshake(conn net.Conn) (string, error) {
+	// 方法协商：VER NMETHODS METHODS...
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", fmt.Errorf("读取SOCKS握手失败: %w", err)
+	}
+	if header[0] != socksVersion5 {
+		return "", fmt.Errorf("不支持的SOCKS版本: %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", fmt.Errorf("读取SOCKS认证方法失败: %w", err)
+	}
+
+	method := byte(socksMethodNoAcceptable)
+	for _, m := range methods {
+		if m == socksMethodNoAuth {
+			method = socksMethodNoAuth
+			break
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion5, method}); err != nil {
+		return "", err
+	}
+	if method == socksMethodNoAcceptable {
+		return "", fmt.Errorf("客户端不支持无认证方式")
+	}
+
+	// 请求：VER CMD RSV ATYP DST.ADDR DST.PORT
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", fmt.Errorf("读取SOCKS请求失败: %w", err)
+	}
+	if request[1] != socksCmdConnect {
+		writeSocksReply(conn, socksRepCommandNotSupported, nil)
+		return "", fmt.Errorf("不支持的SOCKS命令: %d", request[1])
+	}
+
+	var host string
+	switch request[3] {
+	case socksAtypIPv4, socksAtypIPv6:
+		size := net.IPv4len
+		if request[3] == socksAtypIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case socksAtypDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+		host = string(domain)
+	default:
+		writeSocksReply(conn, socksRepAddrNotSupported, nil)
+		return "", fmt.Errorf("不支持的SOCKS地址类型: %d", request[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// writeSocksReply 发送SOCKS5应答
+func writeSocksReply(conn net.Conn, rep byte, bound *net.TCPAddr) error {
+	reply := []byte{socksVersion5, rep, 0x00}
+	if bound != nil && bound.IP.To4() != nil {
+		reply = append(reply, socksAtypIPv4)
+		reply = append(reply, bound.IP.To4()...)
+	} else if bound != nil {
+		reply = append(reply, socksAtypIPv6)
+		reply = append(reply, bound.IP.To16()...)
+	} else {
+		reply = append(reply, socksAtypIPv4, 0, 0, 0, 0)
+	}
+
+	port := 0
+	if bound != nil {
+		port = bound.Port
+	}
+	reply = binary.BigEndian.AppendUint16(reply, uint16(port))
+
+	_, err := conn.Write(reply)
+	return err
+}
+
+// socksReplyCode 根据连接错误选择SOCKS5应答码
+func socksReplyCode(err error) byte {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return socksRepConnectionRefused
+	case errors.Is(err, syscall.ENETUNREACH):
+		return socksRepNetworkUnreachable
+	case errors.Is(err, syscall.EHOSTUNREACH):
+		return socksRepHostUnreachable
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return socksRepHostUnreachable
+	}
+	return socksRepGeneralFailure
+}
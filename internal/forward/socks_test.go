@@ -0,0 +1,183 @@
+package forward
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/proxy"
+
+	"gotssh/internal/ssh"
+)
+
+// startSocksServer 在本地端口上运行测试用SOCKS5服务器
+func startSocksServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	socks := newDirectSocksServer(time.Second, ssh.PipeOptions{})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go socks.ServeConn(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// startEcho 启动TCP回显服务器
+func startEcho(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener
+}
+
+// TestSocksServer 测试SOCKS5服务器
+func TestSocksServer(t *testing.T) {
+	socksAddr := startSocksServer(t)
+	dialer, err := proxy.SOCKS5("tcp", socksAddr, nil, proxy.Direct)
+	require.NoError(t, err)
+
+	t.Run("通过IP地址连接", func(t *testing.T) {
+		echo := startEcho(t)
+		conn, err := dialer.Dial("tcp", echo.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("hello socks\n"))
+		require.NoError(t, err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "hello socks\n", line)
+	})
+
+	t.Run("通过域名连接", func(t *testing.T) {
+		echo := startEcho(t)
+		port := echo.Addr().(*net.TCPAddr).Port
+		conn, err := dialer.Dial("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)))
+		require.NoError(t, err)
+		conn.Close()
+	})
+
+	t.Run("目标拒绝连接", func(t *testing.T) {
+		closed, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := closed.Addr().String()
+		closed.Close()
+
+		_, err = dialer.Dial("tcp", addr)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refused")
+	})
+
+	t.Run("不支持的命令", func(t *testing.T) {
+		conn, err := net.Dial("tcp", socksAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// 协商无认证后发送 BIND 请求
+		conn.Write([]byte{socksVersion5, 1, socksMethodNoAuth})
+		method := make([]byte, 2)
+		_, err = io.ReadFull(conn, method)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{socksVersion5, socksMethodNoAuth}, method)
+
+		conn.Write([]byte{socksVersion5, 0x02, 0x00, socksAtypIPv4, 127, 0, 0, 1, 0, 80})
+		reply := make([]byte, 10)
+		_, err = io.ReadFull(conn, reply)
+		require.NoError(t, err)
+		assert.Equal(t, byte(socksRepCommandNotSupported), reply[1])
+	})
+
+	t.Run("不支持的认证方式", func(t *testing.T) {
+		conn, err := net.Dial("tcp", socksAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		conn.Write([]byte{socksVersion5, 1, 0x02})
+		method := make([]byte, 2)
+		_, err = io.ReadFull(conn, method)
+		require.NoError(t, err)
+		assert.Equal(t, byte(socksMethodNoAcceptable), method[1])
+	})
+}
+
+// TestSocksServerRecord 测试SOCKS5连接的访问记录
+func TestSocksServerRecord(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	records := make(chan ssh.ConnRecord, 4)
+	socks := newDirectSocksServer(time.Second, ssh.PipeOptions{})
+	socks.recorder = func(rec ssh.ConnRecord) { records <- rec }
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go socks.ServeConn(conn)
+		}
+	}()
+
+	dialer, err := proxy.SOCKS5("tcp", listener.Addr().String(), nil, proxy.Direct)
+	require.NoError(t, err)
+	echo := startEcho(t)
+
+	conn, err := dialer.Dial("tcp", echo.Addr().String())
+	require.NoError(t, err)
+	conn.Write([]byte("hello\n"))
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	conn.Close()
+
+	select {
+	case rec := <-records:
+		assert.Equal(t, echo.Addr().String(), rec.Destination)
+		assert.EqualValues(t, 6, rec.Sent)
+		assert.EqualValues(t, 6, rec.Received)
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待访问记录超时")
+	}
+
+	// 目标不可达时记录连接失败
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := closed.Addr().String()
+	closed.Close()
+	_, err = dialer.Dial("tcp", addr)
+	require.Error(t, err)
+
+	select {
+	case rec := <-records:
+		assert.Equal(t, addr, rec.Destination)
+		assert.Equal(t, ssh.CloseReasonDialFailed, rec.CloseReason)
+		assert.NotEmpty(t, rec.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待访问记录超时")
+	}
+}
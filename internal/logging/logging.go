@@ -0,0 +1,149 @@
+// Package logging 基于 log/slog 的日志初始化
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LevelTrace 比调试更详细的级别，用于SSH握手跟踪（相当于 ssh -vvv）
+const LevelTrace = slog.LevelDebug - 4
+
+// 日志格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options 日志配置
+type Options struct {
+	Level   string // 日志级别：trace、debug、info、warn、error
+	Format  string // 日志格式：text 或 json
+	File    string // 日志文件路径，为空时输出到标准错误
+	Verbose int    // -v 的次数，1 为 debug，2 及以上为 trace，优先于 Level
+}
+
+// ParseLevel 解析日志级别名称，空字符串视为 info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("不支持的日志级别: %s（可选 trace、debug、info、warn、error）", name)
+	}
+}
+
+// ParseFormat 解析日志格式名称，空字符串视为 text
+func ParseFormat(name string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(name)); format {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return format, nil
+	default:
+		return FormatText, fmt.Errorf("不支持的日志格式: %s（可选 text、json）", name)
+	}
+}
+
+// level 计算最终日志级别，-v 只会降低级别输出更多日志
+func (o Options) level() (slog.Level, error) {
+	level, err := ParseLevel(o.Level)
+	if err != nil {
+		return level, err
+	}
+
+	switch {
+	case o.Verbose >= 2 && level > LevelTrace:
+		level = LevelTrace
+	case o.Verbose == 1 && level > slog.LevelDebug:
+		level = slog.LevelDebug
+	}
+	return level, nil
+}
+
+// New 根据配置创建日志记录器，返回的 io.Closer 用于关闭日志文件
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	level, err := opts.level()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.File), 0700); err != nil {
+			return nil, nil, fmt.Errorf("创建日志目录失败: %w", err)
+		}
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("打开日志文件失败: %w", err)
+		}
+		out = file
+		closer = file
+	}
+
+	handler, err := NewHandler(out, opts.Format, level)
+	if err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+	return slog.New(handler), closer, nil
+}
+
+// NewHandler 创建指定格式的日志处理器
+func NewHandler(out io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	handlerOpts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceLevelName,
+	}
+
+	format, err := ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(out, handlerOpts), nil
+	}
+	return slog.NewTextHandler(out, handlerOpts), nil
+}
+
+// replaceLevelName 将 trace 级别显示为 TRACE 而不是 DEBUG-4
+func replaceLevelName(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level <= LevelTrace {
+			attr.Value = slog.StringValue("TRACE")
+		}
+	}
+	return attr
+}
+
+// Nop 返回丢弃所有日志的记录器
+func Nop() *slog.Logger {
+	return slog.New(nopHandler{})
+}
+
+// nopHandler 丢弃所有日志
+type nopHandler struct{}
+
+func (nopHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (nopHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h nopHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h nopHandler) WithGroup(string) slog.Handler           { return h }
+
+// nopCloser 标准错误输出无需关闭
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
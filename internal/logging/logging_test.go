@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseLevel 测试日志级别解析
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level slog.Level
+	}{
+		{"trace", LevelTrace},
+		{"debug", slog.LevelDebug},
+		{"", slog.LevelInfo},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.level, level, tt.name)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+// TestParseFormat 测试日志格式解析
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]string{"": FormatText, "text": FormatText, "JSON": FormatJSON} {
+		format, err := ParseFormat(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, format, name)
+	}
+
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+// TestOptionsLevel 测试 -v 对日志级别的影响
+func TestOptionsLevel(t *testing.T) {
+	tests := []struct {
+		opts  Options
+		level slog.Level
+	}{
+		{Options{Level: "info"}, slog.LevelInfo},
+		{Options{Level: "info", Verbose: 1}, slog.LevelDebug},
+		{Options{Level: "error", Verbose: 2}, LevelTrace},
+		{Options{Level: "trace", Verbose: 1}, LevelTrace},
+	}
+	for _, tt := range tests {
+		level, err := tt.opts.level()
+		require.NoError(t, err)
+		assert.Equal(t, tt.level, level, "%+v", tt.opts)
+	}
+}
+
+// TestNewHandler 测试文本和JSON格式
+func TestNewHandler(t *testing.T) {
+	t.Run("文本格式显示TRACE级别", func(t *testing.T) {
+		var buf bytes.Buffer
+		handler, err := NewHandler(&buf, FormatText, LevelTrace)
+		require.NoError(t, err)
+		slog.New(handler).Log(context.Background(), LevelTrace, "握手", "step", 1)
+		assert.Contains(t, buf.String(), "level=TRACE")
+		assert.Contains(t, buf.String(), "msg=握手 step=1")
+	})
+
+	t.Run("JSON格式", func(t *testing.T) {
+		var buf bytes.Buffer
+		handler, err := NewHandler(&buf, FormatJSON, slog.LevelInfo)
+		require.NoError(t, err)
+		logger := slog.New(handler)
+		logger.Debug("不输出")
+		logger.Warn("连接失败", "error", "refused")
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "连接失败", record["msg"])
+		assert.Equal(t, "refused", record["error"])
+	})
+
+	t.Run("不支持的格式", func(t *testing.T) {
+		_, err := NewHandler(&bytes.Buffer{}, "xml", slog.LevelInfo)
+		assert.Error(t, err)
+	})
+}
+
+// TestNewWithFile 测试写入日志文件
+func TestNewWithFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "gotssh.log")
+	logger, closer, err := New(Options{Level: "warn", File: path, Verbose: 1})
+	require.NoError(t, err)
+
+	logger.Debug("调试信息")
+	require.NoError(t, closer.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "调试信息")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+// TestNop 测试丢弃日志
+func TestNop(t *testing.T) {
+	logger := Nop()
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelError))
+	assert.NotPanics(t, func() { logger.With("a", 1).Error("丢弃") })
+}
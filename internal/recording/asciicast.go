@@ -0,0 +1,209 @@
+// Package recording 交互式会话的录制（asciicast v2 格式）、索引与回放
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 事件类型
+const (
+	EventOutput = "o" // 终端输出
+	EventInput  = "i" // 键盘输入
+)
+
+// Header asciicast v2 文件头
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event 一条录制事件
+type Event struct {
+	Time float64 // 距开始的秒数
+	Type string  // 事件类型
+	Data string  // 事件数据
+}
+
+// MarshalJSON 事件编码为 [time, type, data] 数组
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Type, e.Data})
+}
+
+// UnmarshalJSON 从 [time, type, data] 数组解码事件
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("事件应包含3个字段，实际为 %d 个", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(fields[2], &e.Data)
+}
+
+// Writer asciicast v2 写入器，输出和输入流分别通过 Output 和 Input 写入
+type Writer struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	start  time.Time
+	err    error
+
+	output *stream
+	input  *stream
+}
+
+// NewWriter 写入文件头并创建写入器，w 实现 io.Closer 时在 Close 中关闭
+func NewWriter(w io.Writer, header Header, recordInput bool) (*Writer, error) {
+	header.Version = 2
+	if header.Timestamp == 0 {
+		header.Timestamp = time.Now().Unix()
+	}
+
+	writer := &Writer{
+		w:     bufio.NewWriter(w),
+		start: time.Now(),
+	}
+	if closer, ok := w.(io.Closer); ok {
+		writer.closer = closer
+	}
+	writer.output = &stream{writer: writer, kind: EventOutput}
+	if recordInput {
+		writer.input = &stream{writer: writer, kind: EventInput}
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.w.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	if err := writer.w.Flush(); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Output 终端输出的写入端
+func (w *Writer) Output() io.Writer {
+	return w.output
+}
+
+// Input 键盘输入的写入端，未启用输入录制时丢弃数据
+func (w *Writer) Input() io.Writer {
+	if w.input == nil {
+		return io.Discard
+	}
+	return w.input
+}
+
+// writeEvent 写入一条事件，录制出错后不影响会话本身
+func (w *Writer) writeEvent(kind string, data []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return
+	}
+
+	event := Event{
+		Time: time.Since(w.start).Seconds(),
+		Type: kind,
+		Data: string(data),
+	}
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		w.err = err
+		return
+	}
+	if _, err := w.w.Write(append(encoded, '\n')); err != nil {
+		w.err = err
+		return
+	}
+	w.err = w.w.Flush()
+}
+
+// Close 写入未完成的数据并关闭
+func (w *Writer) Close() error {
+	w.output.flush()
+	if w.input != nil {
+		w.input.flush()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.err
+	if flushErr := w.w.Flush(); err == nil {
+		err = flushErr
+	}
+	if w.closer != nil {
+		if closeErr := w.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// stream 单个方向的数据流，保留被截断的UTF-8字符直到下一次写入
+type stream struct {
+	writer  *Writer
+	kind    string
+	mu      sync.Mutex
+	pending []byte
+}
+
+// Write 记录数据，始终返回成功以免影响会话
+func (s *stream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := append(s.pending, p...)
+	cut := incompleteSuffix(data)
+	s.pending = append([]byte(nil), data[len(data)-cut:]...)
+	if complete := data[:len(data)-cut]; len(complete) > 0 {
+		s.writer.writeEvent(s.kind, complete)
+	}
+	return len(p), nil
+}
+
+// flush 写入剩余的数据
+func (s *stream) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) > 0 {
+		s.writer.writeEvent(s.kind, s.pending)
+		s.pending = nil
+	}
+}
+
+// incompleteSuffix 返回末尾未完整的UTF-8字符的字节数
+func incompleteSuffix(data []byte) int {
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(data); i++ {
+		b := data[len(data)-i]
+		if !utf8.RuneStart(b) {
+			continue
+		}
+		// 找到字符起始字节，检查其后的字节是否足够
+		if !utf8.FullRune(data[len(data)-i:]) {
+			return i
+		}
+		return 0
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// indexFile 录制索引文件名（JSON行）
+const indexFile = "index.jsonl"
+
+// Session 被录制会话的服务器信息
+type Session struct {
+	ServerID string `json:"server_id"`
+	Server   string `json:"server"` // 服务器别名，未设置别名时为主机
+	User     string `json:"user"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+}
+
+// Entry 录制索引中的一条记录
+type Entry struct {
+	Session
+	File        string    `json:"file"`  // 录制文件名（相对于录制目录）
+	Start       time.Time `json:"start"` // 开始时间
+	RecordInput bool      `json:"record_input,omitempty"`
+}
+
+// Info 录制列表中的一项
+type Info struct {
+	Entry
+	Path     string        // 录制文件完整路径
+	Size     int64         // 文件大小
+	Duration time.Duration // 录制时长
+	Missing  bool          // 录制文件已被删除
+}
+
+// Dir 录制文件目录
+func Dir(configDir string) string {
+	return filepath.Join(configDir, "recordings")
+}
+
+// Recording 正在进行的会话录制
+type Recording struct {
+	*Writer
+	Path string
+}
+
+// unsafeName 文件名中不允许的字符
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Start 在录制目录中创建录制文件并写入索引
+func Start(configDir string, session Session, width, height int, recordInput bool) (*Recording, error) {
+	dir := Dir(configDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建录制目录失败: %w", err)
+	}
+
+	start := time.Now()
+	name := unsafeName.ReplaceAllString(session.Server, "_")
+	if name == "" {
+		name = "session"
+	}
+	file := fmt.Sprintf("%s-%s.cast", name, start.Format("20060102-150405"))
+	path := filepath.Join(dir, file)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		file = fmt.Sprintf("%s-%s-%d.cast", name, start.Format("20060102-150405"), start.UnixNano()%1000000)
+		path = filepath.Join(dir, file)
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("创建录制文件失败: %w", err)
+	}
+
+	writer, err := NewWriter(f, Header{
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     fmt.Sprintf("%s@%s:%d", session.User, session.Host, session.Port),
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	}, recordInput)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("写入录制文件失败: %w", err)
+	}
+
+	entry := Entry{Session: session, File: file, Start: start, RecordInput: recordInput}
+	if err := appendIndex(dir, entry); err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	return &Recording{Writer: writer, Path: path}, nil
+}
+
+// appendIndex 追加一条索引记录
+func appendIndex(dir string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, indexFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("打开录制索引失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入录制索引失败: %w", err)
+	}
+	return nil
+}
+
+// List 列出录制索引中的会话，按开始时间从新到旧排序
+func List(configDir string) ([]*Info, error) {
+	dir := Dir(configDir)
+	f, err := os.Open(filepath.Join(dir, indexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开录制索引失败: %w", err)
+	}
+	defer f.Close()
+
+	var infos []*Info
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		info := &Info{Entry: entry, Path: filepath.Join(dir, entry.File)}
+		if stat, err := os.Stat(info.Path); err != nil {
+			info.Missing = true
+		} else {
+			info.Size = stat.Size()
+			info.Duration, _ = fileDuration(info.Path)
+		}
+		infos = append(infos, info)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取录制索引失败: %w", err)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Start.After(infos[j].Start)
+	})
+	return infos, nil
+}
+
+// fileDuration 读取录制文件的时长
+func fileDuration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	reader, err := NewReader(f)
+	if err != nil {
+		return 0, err
+	}
+	return Duration(reader)
+}
+
+// Resolve 解析回放参数：可以是文件路径、录制目录中的文件名，或 sessions ls 中的序号
+func Resolve(configDir, name string) (string, error) {
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	}
+
+	path := filepath.Join(Dir(configDir), name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(name, "%d", &index); err == nil && fmt.Sprint(index) == name {
+		infos, err := List(configDir)
+		if err != nil {
+			return "", err
+		}
+		if index < 1 || index > len(infos) {
+			return "", fmt.Errorf("录制序号 %d 超出范围 (共 %d 个录制)", index, len(infos))
+		}
+		return infos[index-1].Path, nil
+	}
+
+	return "", fmt.Errorf("录制文件 '%s' 不存在", name)
+}
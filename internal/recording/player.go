@@ -0,0 +1,123 @@
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Reader asciicast v2 读取器
+type Reader struct {
+	Header  Header
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader 读取并校验文件头
+func NewReader(r io.Reader) (*Reader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	reader := &Reader{scanner: scanner}
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("录制文件为空")
+	}
+	reader.line = 1
+	if err := json.Unmarshal(scanner.Bytes(), &reader.Header); err != nil {
+		return nil, fmt.Errorf("解析录制文件头失败: %w", err)
+	}
+	if reader.Header.Version != 2 {
+		return nil, fmt.Errorf("不支持的 asciicast 版本: %d", reader.Header.Version)
+	}
+	return reader, nil
+}
+
+// Next 读取下一条事件，结束时返回 io.EOF
+// 会话被中断时最后一行可能不完整，这种情况视为结束
+func (r *Reader) Next() (Event, error) {
+	var event Event
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &event); err != nil {
+			if r.scanner.Scan() {
+				return event, fmt.Errorf("第 %d 行: 解析事件失败: %w", r.line, err)
+			}
+			return event, io.EOF
+		}
+		return event, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return event, err
+	}
+	return event, io.EOF
+}
+
+// PlayOptions 回放选项
+type PlayOptions struct {
+	Speed     float64       // 回放速度倍数，<=0 时按 1 处理
+	IdleLimit time.Duration // 事件之间最长等待时间，0 表示不限制
+}
+
+// Play 按录制时的节奏把输出事件写入 out，输入事件被跳过
+func Play(ctx context.Context, r *Reader, out io.Writer, opts PlayOptions) error {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	var last float64
+	for {
+		event, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		wait := time.Duration((event.Time - last) / speed * float64(time.Second))
+		last = event.Time
+		if opts.IdleLimit > 0 && wait > opts.IdleLimit {
+			wait = opts.IdleLimit
+		}
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		if event.Type != EventOutput {
+			continue
+		}
+		if _, err := io.WriteString(out, event.Data); err != nil {
+			return err
+		}
+	}
+}
+
+// Duration 读取全部事件，返回最后一条事件的时间
+func Duration(r *Reader) (time.Duration, error) {
+	var last float64
+	for {
+		event, err := r.Next()
+		if err == io.EOF {
+			return time.Duration(last * float64(time.Second)), nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		last = event.Time
+	}
+}
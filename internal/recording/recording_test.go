@@ -0,0 +1,181 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriterRoundTrip 测试写入和读取 asciicast 文件
+func TestWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, Header{Width: 120, Height: 40, Title: "test"}, true)
+	require.NoError(t, err)
+
+	writer.Output().Write([]byte("$ ls\r\n"))
+	writer.Input().Write([]byte("exit\r"))
+	// 中文字符被拆分到两次写入中
+	data := []byte("你好\r\n")
+	writer.Output().Write(data[:4])
+	writer.Output().Write(data[4:])
+	require.NoError(t, writer.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Contains(t, lines[0], `"version":2`)
+	assert.Contains(t, lines[0], `"width":120`)
+
+	reader, err := NewReader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 120, reader.Header.Width)
+	assert.Equal(t, "test", reader.Header.Title)
+
+	var events []Event
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			break
+		}
+		events = append(events, event)
+	}
+	require.Len(t, events, 4)
+	assert.Equal(t, EventOutput, events[0].Type)
+	assert.Equal(t, "$ ls\r\n", events[0].Data)
+	assert.Equal(t, EventInput, events[1].Type)
+	assert.Equal(t, "你", events[2].Data)
+	assert.Equal(t, "好\r\n", events[3].Data)
+	for i := 1; i < len(events); i++ {
+		assert.GreaterOrEqual(t, events[i].Time, events[i-1].Time)
+	}
+}
+
+// TestWriterWithoutInput 测试未启用输入录制时丢弃输入
+func TestWriterWithoutInput(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, Header{Width: 80, Height: 24}, false)
+	require.NoError(t, err)
+	writer.Input().Write([]byte("secret"))
+	writer.Output().Write([]byte("output"))
+	require.NoError(t, writer.Close())
+
+	assert.NotContains(t, buf.String(), "secret")
+	assert.Contains(t, buf.String(), "output")
+}
+
+// TestReaderInvalid 测试无效的录制文件
+func TestReaderInvalid(t *testing.T) {
+	_, err := NewReader(strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, err = NewReader(strings.NewReader(`{"version":1}` + "\n"))
+	assert.Error(t, err)
+
+	// 被中断的录制最后一行不完整，视为结束
+	reader, err := NewReader(strings.NewReader(`{"version":2,"width":80,"height":24}` + "\n" +
+		`[0.5,"o","a"]` + "\n" + `[1.0,"o","b`))
+	require.NoError(t, err)
+	duration, err := Duration(reader)
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, duration)
+
+	// 中间的行损坏时报错
+	reader, err = NewReader(strings.NewReader(`{"version":2,"width":80,"height":24}` + "\n" +
+		`[0.5,"o"` + "\n" + `[1.0,"o","b"]` + "\n"))
+	require.NoError(t, err)
+	_, err = reader.Next()
+	assert.Error(t, err)
+}
+
+// TestPlay 测试回放速度和空闲限制
+func TestPlay(t *testing.T) {
+	cast := `{"version":2,"width":80,"height":24}` + "\n" +
+		`[0.2,"o","hello "]` + "\n" +
+		`[0.3,"i","x"]` + "\n" +
+		`[10.0,"o","world"]` + "\n"
+
+	reader, err := NewReader(strings.NewReader(cast))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	start := time.Now()
+	err = Play(context.Background(), reader, &out, PlayOptions{Speed: 4, IdleLimit: 100 * time.Millisecond})
+	require.NoError(t, err)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, "hello world", out.String())
+	// 0.2/4 + 0.1/4 + 空闲上限 0.1
+	assert.GreaterOrEqual(t, elapsed, 150*time.Millisecond)
+	assert.Less(t, elapsed, 2*time.Second)
+
+	// 取消回放
+	reader, err = NewReader(strings.NewReader(cast))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, Play(ctx, reader, &out, PlayOptions{}), context.Canceled)
+}
+
+// TestStartAndList 测试录制文件创建、索引和查找
+func TestStartAndList(t *testing.T) {
+	configDir := t.TempDir()
+	session := Session{ServerID: "srv-1", Server: "web/01", User: "root", Host: "10.0.0.1", Port: 22}
+
+	rec, err := Start(configDir, session, 100, 30, false)
+	require.NoError(t, err)
+	rec.Output().Write([]byte("hello"))
+	require.NoError(t, rec.Close())
+
+	assert.Equal(t, Dir(configDir), filepath.Dir(rec.Path))
+	assert.True(t, strings.HasPrefix(filepath.Base(rec.Path), "web_01-"))
+	info, err := os.Stat(rec.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 同一秒内的第二个录制使用不同的文件名
+	second, err := Start(configDir, session, 100, 30, true)
+	require.NoError(t, err)
+	require.NoError(t, second.Close())
+	assert.NotEqual(t, rec.Path, second.Path)
+
+	infos, err := List(configDir)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "srv-1", infos[0].ServerID)
+	assert.Equal(t, second.Path, infos[0].Path)
+	assert.True(t, infos[0].RecordInput)
+	assert.Greater(t, infos[1].Size, int64(0))
+
+	// 删除的录制文件仍在列表中并标记
+	require.NoError(t, os.Remove(second.Path))
+	infos, err = List(configDir)
+	require.NoError(t, err)
+	assert.True(t, infos[0].Missing)
+
+	// 解析回放参数
+	path, err := Resolve(configDir, "2")
+	require.NoError(t, err)
+	assert.Equal(t, rec.Path, path)
+	path, err = Resolve(configDir, filepath.Base(rec.Path))
+	require.NoError(t, err)
+	assert.Equal(t, rec.Path, path)
+	path, err = Resolve(configDir, rec.Path)
+	require.NoError(t, err)
+	assert.Equal(t, rec.Path, path)
+	_, err = Resolve(configDir, "9")
+	assert.Error(t, err)
+	_, err = Resolve(configDir, "missing.cast")
+	assert.Error(t, err)
+}
+
+// TestListEmpty 测试没有录制时的列表
+func TestListEmpty(t *testing.T) {
+	infos, err := List(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, infos)
+}
@@ -0,0 +1,134 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AccessControl 本地转发监听的访问控制和连接限制
+type AccessControl struct {
+	AllowedNets    []*net.IPNet  // 允许访问的来源网段，为空表示不限制
+	MaxConnections int           // 最大并发连接数，0表示不限制
+	IdleTimeout    time.Duration // 连接空闲超时，0表示不超时
+	RateLimit      int           // 每秒最多接受的新连接数，0表示不限制
+
+	active         atomic.Int64
+	accepted       atomic.Int64
+	rejectedSource atomic.Int64
+	rejectedLimit  atomic.Int64
+	rejectedRate   atomic.Int64
+
+	rateMu     sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+}
+
+// AccessStats 访问控制计数
+type AccessStats struct {
+	Active         int64 // 当前活动连接数
+	Accepted       int64 // 已接受的连接数
+	RejectedSource int64 // 来源不在允许网段而被拒绝的连接数
+	RejectedLimit  int64 // 超过并发上限而被拒绝的连接数
+	RejectedRate   int64 // 超过速率限制而被拒绝的连接数
+}
+
+// Rejected 被拒绝的连接总数
+func (s AccessStats) Rejected() int64 {
+	return s.RejectedSource + s.RejectedLimit + s.RejectedRate
+}
+
+// String 计数的显示形式
+func (s AccessStats) String() string {
+	return fmt.Sprintf("活动 %d, 已接受 %d, 已拒绝 %d (来源 %d/并发 %d/速率 %d)",
+		s.Active, s.Accepted, s.Rejected(), s.RejectedSource, s.RejectedLimit, s.RejectedRate)
+}
+
+// Stats 获取访问控制计数
+func (a *AccessControl) Stats() AccessStats {
+	if a == nil {
+		return AccessStats{}
+	}
+	return AccessStats{
+		Active:         a.active.Load(),
+		Accepted:       a.accepted.Load(),
+		RejectedSource: a.rejectedSource.Load(),
+		RejectedLimit:  a.rejectedLimit.Load(),
+		RejectedRate:   a.rejectedRate.Load(),
+	}
+}
+
+// admit 检查新连接是否允许接入，允许时返回释放函数，拒绝时返回原因
+func (a *AccessControl) admit(conn net.Conn) (release func(), err error) {
+	if a == nil {
+		return func() {}, nil
+	}
+
+	if !a.allowedSource(conn.RemoteAddr()) {
+		a.rejectedSource.Add(1)
+		return nil, fmt.Errorf("来源 %s 不在允许的网段内", conn.RemoteAddr())
+	}
+
+	if !a.takeToken() {
+		a.rejectedRate.Add(1)
+		return nil, fmt.Errorf("超过每秒 %d 个新连接的速率限制", a.RateLimit)
+	}
+
+	if active := a.active.Add(1); a.MaxConnections > 0 && active > int64(a.MaxConnections) {
+		a.active.Add(-1)
+		a.rejectedLimit.Add(1)
+		return nil, fmt.Errorf("已达到最大并发连接数 %d", a.MaxConnections)
+	}
+
+	a.accepted.Add(1)
+	var once sync.Once
+	return func() { once.Do(func() { a.active.Add(-1) }) }, nil
+}
+
+// allowedSource 检查来源地址是否在允许的网段内，非TCP连接（如Unix套接字）不做限制
+func (a *AccessControl) allowedSource(addr net.Addr) bool {
+	if len(a.AllowedNets) == 0 {
+		return true
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return true
+	}
+
+	for _, network := range a.AllowedNets {
+		if network.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// takeToken 令牌桶限速，桶容量等于每秒速率
+func (a *AccessControl) takeToken() bool {
+	if a.RateLimit <= 0 {
+		return true
+	}
+
+	a.rateMu.Lock()
+	defer a.rateMu.Unlock()
+
+	now := time.Now()
+	if a.lastRefill.IsZero() {
+		a.tokens = float64(a.RateLimit)
+	} else {
+		a.tokens += now.Sub(a.lastRefill).Seconds() * float64(a.RateLimit)
+		if a.tokens > float64(a.RateLimit) {
+			a.tokens = float64(a.RateLimit)
+		}
+	}
+	a.lastRefill = now
+
+	if a.tokens < 1 {
+		return false
+	}
+	a.tokens--
+	return true
+}
@@ -0,0 +1,174 @@
+package ssh
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn 仅提供来源地址的测试连接
+type fakeConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c *fakeConn) RemoteAddr() net.Addr { return c.remote }
+
+func tcpConnFrom(ip string) net.Conn {
+	return &fakeConn{remote: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}}
+}
+
+// TestAccessControlAdmit 测试访问控制的准入判断
+func TestAccessControlAdmit(t *testing.T) {
+	t.Run("未配置时不限制", func(t *testing.T) {
+		var access *AccessControl
+		release, err := access.admit(tcpConnFrom("8.8.8.8"))
+		require.NoError(t, err)
+		release()
+		assert.Equal(t, AccessStats{}, access.Stats())
+	})
+
+	t.Run("来源网段", func(t *testing.T) {
+		_, network, _ := net.ParseCIDR("10.0.0.0/8")
+		access := &AccessControl{AllowedNets: []*net.IPNet{network}}
+
+		release, err := access.admit(tcpConnFrom("10.1.2.3"))
+		require.NoError(t, err)
+		release()
+
+		_, err = access.admit(tcpConnFrom("192.168.1.1"))
+		assert.Error(t, err)
+
+		// Unix套接字连接不受网段限制
+		release, err = access.admit(&fakeConn{remote: &net.UnixAddr{Name: "@", Net: "unix"}})
+		require.NoError(t, err)
+		release()
+
+		stats := access.Stats()
+		assert.EqualValues(t, 2, stats.Accepted)
+		assert.EqualValues(t, 1, stats.RejectedSource)
+		assert.EqualValues(t, 1, stats.Rejected())
+	})
+
+	t.Run("最大并发连接数", func(t *testing.T) {
+		access := &AccessControl{MaxConnections: 2}
+
+		release1, err := access.admit(tcpConnFrom("127.0.0.1"))
+		require.NoError(t, err)
+		release2, err := access.admit(tcpConnFrom("127.0.0.1"))
+		require.NoError(t, err)
+
+		_, err = access.admit(tcpConnFrom("127.0.0.1"))
+		assert.Error(t, err)
+		assert.EqualValues(t, 2, access.Stats().Active)
+
+		// 释放后可以再次接入，重复释放不影响计数
+		release1()
+		release1()
+		release3, err := access.admit(tcpConnFrom("127.0.0.1"))
+		require.NoError(t, err)
+		release2()
+		release3()
+
+		stats := access.Stats()
+		assert.EqualValues(t, 0, stats.Active)
+		assert.EqualValues(t, 1, stats.RejectedLimit)
+	})
+
+	t.Run("速率限制", func(t *testing.T) {
+		access := &AccessControl{RateLimit: 2}
+
+		for i := 0; i < 2; i++ {
+			release, err := access.admit(tcpConnFrom("127.0.0.1"))
+			require.NoError(t, err)
+			release()
+		}
+		_, err := access.admit(tcpConnFrom("127.0.0.1"))
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, access.Stats().RejectedRate)
+
+		// 令牌随时间恢复
+		time.Sleep(600 * time.Millisecond)
+		release, err := access.admit(tcpConnFrom("127.0.0.1"))
+		require.NoError(t, err)
+		release()
+	})
+}
+
+// TestLocalForwardWithAccess 测试本地转发监听上的访问控制
+func TestLocalForwardWithAccess(t *testing.T) {
+	startForward := func(t *testing.T, access *AccessControl) string {
+		server := startTestSSHServer(t)
+		client := server.Connect(t)
+		echo := startEchoServer(t, "tcp", "127.0.0.1:0")
+
+		probe, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		localAddr := probe.Addr().String()
+		probe.Close()
+
+		go client.LocalForwardWithAccess(TCPEndpoint(localAddr), TCPEndpoint(echo.Addr().String()), 200*time.Millisecond, access)
+		require.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", localAddr)
+			if err == nil {
+				conn.Close()
+			}
+			return err == nil
+		}, 5*time.Second, 20*time.Millisecond)
+		return localAddr
+	}
+
+	// assertClosed 校验连接被对端关闭
+	assertClosed := func(t *testing.T, conn net.Conn) {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, err := conn.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err)
+	}
+
+	t.Run("拒绝不在允许网段的来源", func(t *testing.T) {
+		_, network, _ := net.ParseCIDR("10.0.0.0/8")
+		access := &AccessControl{AllowedNets: []*net.IPNet{network}}
+		localAddr := startForward(t, access)
+
+		conn, err := net.Dial("tcp", localAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+		assertClosed(t, conn)
+		assert.Greater(t, access.Stats().RejectedSource, int64(0))
+	})
+
+	t.Run("超过并发上限时拒绝", func(t *testing.T) {
+		access := &AccessControl{MaxConnections: 1}
+		localAddr := startForward(t, access)
+		require.Eventually(t, func() bool { return access.Stats().Active == 0 }, 5*time.Second, 20*time.Millisecond)
+
+		first, err := net.Dial("tcp", localAddr)
+		require.NoError(t, err)
+		defer first.Close()
+		require.Eventually(t, func() bool { return access.Stats().Active == 1 }, 5*time.Second, 20*time.Millisecond)
+
+		second, err := net.Dial("tcp", localAddr)
+		require.NoError(t, err)
+		defer second.Close()
+		assertClosed(t, second)
+		assert.EqualValues(t, 1, access.Stats().RejectedLimit)
+	})
+
+	t.Run("空闲超时关闭连接", func(t *testing.T) {
+		access := &AccessControl{IdleTimeout: 300 * time.Millisecond}
+		localAddr := startForward(t, access)
+
+		assertEcho(t, "tcp", localAddr)
+
+		conn, err := net.Dial("tcp", localAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+		start := time.Now()
+		assertClosed(t, conn)
+		assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+	})
+}
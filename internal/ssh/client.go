@@ -3,10 +3,12 @@ package ssh
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -25,13 +27,23 @@ type Client struct {
 	credential    *config.CredentialConfig
 	configManager *config.Manager
 	conn          *ssh.Client
+	jump          *Client      // 跳板机连接，经跳板机连接时在 Close 中一并关闭
+	hops          int          // 作为跳板机时所处的级数
+	pipeOptions   PipeOptions  // 转发连接的超时设置
+	recorder      ConnRecorder // 转发连接记录回调
+	logger        *slog.Logger
+	// 命令行强制启用的会话录制，与服务器配置中的设置取并集
+	recordSession bool
+	recordInput   bool
 }
 
-// NewClient 创建新的SSH客户端
+// NewClient 创建新的SSH客户端，服务器未设置的字段继承所在分组的默认值
 func NewClient(cfg *config.ServerConfig, configManager *config.Manager) *Client {
+	cfg = configManager.ResolveServer(cfg)
 	client := &Client{
 		config:        cfg,
 		configManager: configManager,
+		logger:        configManager.Logger().With("server", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))),
 	}
 
 	// 如果服务器配置引用了凭证，尝试加载凭证
@@ -44,35 +56,59 @@ func NewClient(cfg *config.ServerConfig, configManager *config.Manager) *Client
 	return client
 }
 
+// SetPipeOptions 设置转发连接的超时
+func (c *Client) SetPipeOptions(opts PipeOptions) {
+	c.pipeOptions = opts
+}
+
 // Connect 连接到SSH服务器
 func (c *Client) Connect() error {
 	sshConfig, err := c.buildSSHConfig()
 	if err != nil {
 		return fmt.Errorf("构建SSH配置失败: %w", err)
 	}
+	sshConfig.HostKeyCallback = c.traceHostKey(sshConfig.HostKeyCallback)
+	sshConfig.BannerCallback = c.traceBanner
+	c.traceAlgorithms(sshConfig)
 
 	var conn net.Conn
-	address := fmt.Sprintf("%s:%d", c.config.Host, c.config.Port)
+	address := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	start := time.Now()
 
-	// 如果配置了代理，则使用代理连接
-	if c.config.Proxy != nil {
+	// 配置了跳板机时经跳板机连接，否则如果配置了代理，则使用代理连接
+	if c.config.JumpHost != "" {
+		conn, err = c.dialViaJumpHost(address)
+		if err != nil {
+			c.Logger().Debug("跳板机连接失败", "jump_host", c.config.JumpHost, "error", err)
+			return err
+		}
+	} else if c.config.Proxy != nil {
+		c.Logger().Debug("通过代理连接", "proxy", c.config.Proxy.Type, "proxy_host", c.config.Proxy.Host, "proxy_port", c.config.Proxy.Port)
 		conn, err = c.connectViaProxy(address)
 		if err != nil {
+			c.Logger().Debug("代理连接失败", "error", err)
 			return fmt.Errorf("代理连接失败: %w", err)
 		}
 	} else {
+		c.Logger().Debug("正在连接", "address", address, "user", sshConfig.User)
 		conn, err = net.DialTimeout("tcp", address, time.Duration(30)*time.Second)
 		if err != nil {
+			c.Logger().Debug("TCP连接失败", "error", err)
 			return fmt.Errorf("连接失败: %w", err)
 		}
 	}
+	c.trace("TCP连接已建立", "local", conn.LocalAddr().String(), "remote", conn.RemoteAddr().String(), "elapsed", time.Since(start))
 
 	// 创建SSH连接
 	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, sshConfig)
 	if err != nil {
 		conn.Close()
+		c.closeJump()
+		c.Logger().Debug("SSH握手失败", "error", err, "elapsed", time.Since(start))
 		return fmt.Errorf("SSH握手失败: %w", err)
 	}
+	c.traceHandshake(sshConn)
+	c.trace("连接建立耗时", "elapsed", time.Since(start))
 
 	c.conn = ssh.NewClient(sshConn, chans, reqs)
 	return nil
@@ -125,7 +161,7 @@ func (c *Client) buildSSHConfig() (*ssh.ClientConfig, error) {
 		if password == "" {
 			return nil, fmt.Errorf("密码认证需要提供密码")
 		}
-		authMethods = append(authMethods, ssh.Password(password))
+		authMethods = append(authMethods, c.passwordAuth(password))
 
 	case config.AuthTypeKey:
 		keyAuth, err := c.getKeyAuth()
@@ -141,7 +177,7 @@ func (c *Client) buildSSHConfig() (*ssh.ClientConfig, error) {
 				if c.credential.Password == "" {
 					return nil, fmt.Errorf("凭证中的密码为空")
 				}
-				authMethods = append(authMethods, ssh.Password(c.credential.Password))
+				authMethods = append(authMethods, c.passwordAuth(c.credential.Password))
 			} else if c.credential.Type == config.CredentialTypeKey {
 				keyAuth, err := c.getCredentialKeyAuth()
 				if err != nil {
@@ -215,7 +251,7 @@ func (c *Client) getKeyAuth() (ssh.AuthMethod, error) {
 		return nil, fmt.Errorf("解析私钥失败: %w", err)
 	}
 
-	return ssh.PublicKeys(signer), nil
+	return c.publicKeysAuth(signer), nil
 }
 
 // getCredentialKeyAuth 获取凭证密钥认证
@@ -250,7 +286,7 @@ func (c *Client) getCredentialKeyAuth() (ssh.AuthMethod, error) {
 		return nil, fmt.Errorf("解析私钥失败: %w", err)
 	}
 
-	return ssh.PublicKeys(signer), nil
+	return c.publicKeysAuth(signer), nil
 }
 
 // getAgentAuth 获取SSH代理认证
@@ -260,7 +296,7 @@ func (c *Client) getAgentAuth() (ssh.AuthMethod, error) {
 		return nil, fmt.Errorf("连接SSH代理失败: %w", err)
 	}
 
-	return ssh.PublicKeysCallback(agent.NewClient(sshAgent).Signers), nil
+	return c.publicKeysCallbackAuth("agent", agent.NewClient(sshAgent).Signers), nil
 }
 
 // getDefaultKeyAuth 获取默认密钥认证
@@ -288,7 +324,8 @@ func (c *Client) getDefaultKeyAuth() (ssh.AuthMethod, error) {
 				continue
 			}
 
-			return ssh.PublicKeys(signer), nil
+			c.Logger().Debug("使用默认密钥", "path", keyPath)
+			return c.publicKeysAuth(signer), nil
 		}
 	}
 
@@ -320,7 +357,7 @@ func (c *Client) getInteractiveAuth() ([]ssh.AuthMethod, error) {
 			return nil, fmt.Errorf("读取密码失败: %w", err)
 		}
 		fmt.Println()
-		authMethods = append(authMethods, ssh.Password(string(password)))
+		authMethods = append(authMethods, c.passwordAuth(string(password)))
 
 	case 2:
 		fmt.Print("请输入密钥文件路径: ")
@@ -350,7 +387,7 @@ func (c *Client) getInteractiveAuth() ([]ssh.AuthMethod, error) {
 			}
 		}
 
-		authMethods = append(authMethods, ssh.PublicKeys(signer))
+		authMethods = append(authMethods, c.publicKeysAuth(signer))
 
 	case 3:
 		agentAuth, err := c.getAgentAuth()
@@ -379,7 +416,7 @@ func (c *Client) getInteractiveAuth() ([]ssh.AuthMethod, error) {
 			if c.credential.Password == "" {
 				return nil, fmt.Errorf("凭证中的密码为空")
 			}
-			authMethods = append(authMethods, ssh.Password(c.credential.Password))
+			authMethods = append(authMethods, c.passwordAuth(c.credential.Password))
 		case config.CredentialTypeKey:
 			keyAuth, err := c.getCredentialKeyAuth()
 			if err != nil {
@@ -425,8 +462,16 @@ func (c *Client) ExecuteCommand(command string) (string, error) {
 	return string(output), nil
 }
 
-// Shell 创建交互式Shell
+// Shell 创建交互式Shell，结束后记录连接历史
 func (c *Client) Shell() error {
+	start := time.Now()
+	err := c.shell()
+	c.recordHistory(start, err)
+	return err
+}
+
+// shell 运行交互式Shell直到会话结束
+func (c *Client) shell() error {
 	session, err := c.NewSession()
 	if err != nil {
 		return err
@@ -440,8 +485,29 @@ func (c *Client) Shell() error {
 		}
 	}
 
-	// 设置终端模式
+	// 设置IO
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+	session.Stdin = os.Stdin
+
+	// 会话录制，失败时仅提示，不影响会话
 	fd := int(os.Stdin.Fd())
+	if rec, err := c.startRecording(fd); err != nil {
+		fmt.Printf("警告: 启动会话录制失败: %v\n", err)
+	} else if rec != nil {
+		defer func() {
+			if err := rec.Close(); err != nil {
+				c.Logger().Warn("关闭会话录制失败", "path", rec.Path, "error", err)
+			}
+			fmt.Printf("会话录制已保存: %s\n", rec.Path)
+		}()
+		fmt.Printf("🔴 本次会话正在录制: %s\n", rec.Path)
+		session.Stdout = io.MultiWriter(os.Stdout, rec.Output())
+		session.Stderr = io.MultiWriter(os.Stderr, rec.Output())
+		session.Stdin = io.TeeReader(os.Stdin, rec.Input())
+	}
+
+	// 设置终端模式
 	if term.IsTerminal(fd) {
 		state, err := term.MakeRaw(fd)
 		if err != nil {
@@ -465,11 +531,6 @@ func (c *Client) Shell() error {
 		}
 	}
 
-	// 设置IO
-	session.Stdout = os.Stdout
-	session.Stderr = os.Stderr
-	session.Stdin = os.Stdin
-
 	// 启动Shell
 	if err := session.Shell(); err != nil {
 		return fmt.Errorf("启动Shell失败: %w", err)
@@ -478,7 +539,7 @@ func (c *Client) Shell() error {
 	// 等待会话结束
 	if err := session.Wait(); err != nil {
 		if exitErr, ok := err.(*ssh.ExitError); ok {
-			return fmt.Errorf("Shell退出，退出码: %d", exitErr.ExitStatus())
+			return &ShellExitError{Status: exitErr.ExitStatus()}
 		}
 		return fmt.Errorf("Shell会话错误: %w", err)
 	}
@@ -488,10 +549,12 @@ func (c *Client) Shell() error {
 
 // Close 关闭连接
 func (c *Client) Close() error {
+	var err error
 	if c.conn != nil {
-		return c.conn.Close()
+		err = c.conn.Close()
 	}
-	return nil
+	c.closeJump()
+	return err
 }
 
 // LocalPortForward 本地端口转发
@@ -515,7 +578,7 @@ func (c *Client) LocalPortForward(localAddr, remoteAddr string) error {
 		if err != nil {
 			// 检查是否是因为listener关闭导致的错误
 			if strings.Contains(err.Error(), "use of closed network connection") {
-				fmt.Println("端口转发监听器已关闭")
+				c.Logger().Debug("端口转发监听器已关闭")
 				return nil
 			}
 			return fmt.Errorf("接受连接失败: %w", err)
@@ -527,14 +590,13 @@ func (c *Client) LocalPortForward(localAddr, remoteAddr string) error {
 			// 连接到远程地址
 			remoteConn, err := c.conn.Dial("tcp", remoteAddr)
 			if err != nil {
-				fmt.Printf("连接远程地址失败: %v\n", err)
+				c.Logger().Warn("连接远程地址失败", "destination", remoteAddr, "error", err)
 				return
 			}
 			defer remoteConn.Close()
 
 			// 双向数据转发
-			go io.Copy(localConn, remoteConn)
-			io.Copy(remoteConn, localConn)
+			Pipe(localConn, remoteConn, c.pipeOptions)
 		}()
 	}
 }
@@ -560,7 +622,7 @@ func (c *Client) RemotePortForward(remoteAddr, localAddr string) error {
 		if err != nil {
 			// 检查是否是因为listener关闭导致的错误
 			if strings.Contains(err.Error(), "use of closed network connection") {
-				fmt.Println("端口转发监听器已关闭")
+				c.Logger().Debug("端口转发监听器已关闭")
 				return nil
 			}
 			return fmt.Errorf("接受远程连接失败: %w", err)
@@ -572,18 +634,34 @@ func (c *Client) RemotePortForward(remoteAddr, localAddr string) error {
 			// 连接到本地地址
 			localConn, err := net.Dial("tcp", localAddr)
 			if err != nil {
-				fmt.Printf("连接本地地址失败: %v\n", err)
+				c.Logger().Warn("连接本地地址失败", "destination", localAddr, "error", err)
 				return
 			}
 			defer localConn.Close()
 
 			// 双向数据转发
-			go io.Copy(remoteConn, localConn)
-			io.Copy(localConn, remoteConn)
+			Pipe(remoteConn, localConn, c.pipeOptions)
 		}()
 	}
 }
 
+// Dial 通过SSH连接访问目标地址，Unix套接字通过 direct-streamlocal@openssh.com 通道连接
+func (c *Client) Dial(network, address string) (net.Conn, error) {
+	if c.conn == nil {
+		return nil, fmt.Errorf("SSH连接未建立")
+	}
+	c.trace("打开通道", "network", network, "address", address)
+	return c.conn.Dial(network, address)
+}
+
+// Wait 等待SSH连接关闭
+func (c *Client) Wait() error {
+	if c.conn == nil {
+		return fmt.Errorf("SSH连接未建立")
+	}
+	return c.conn.Wait()
+}
+
 // IsConnected 检查连接状态
 func (c *Client) IsConnected() bool {
 	if c.conn == nil {
@@ -629,7 +707,7 @@ func (c *Client) StartKeepAlive(interval time.Duration) {
 		for range ticker.C {
 			if err := c.SendKeepAlive(); err != nil {
 				// Keep-alive失败，可能连接已断开
-				fmt.Printf("Keep-alive失败: %v\n", err)
+				c.Logger().Warn("Keep-alive失败", "error", err)
 				return
 			}
 		}
@@ -638,6 +716,21 @@ func (c *Client) StartKeepAlive(interval time.Duration) {
 
 // LocalPortForwardWithTimeout 本地端口转发（带超时）
 func (c *Client) LocalPortForwardWithTimeout(localAddr, remoteAddr string, timeout time.Duration) error {
+	return c.LocalForwardWithTimeout(TCPEndpoint(localAddr), TCPEndpoint(remoteAddr), timeout)
+}
+
+// LocalForwardWithTimeout 本地转发（带超时），本地和远程端点均支持TCP和Unix套接字
+func (c *Client) LocalForwardWithTimeout(local, remote Endpoint, timeout time.Duration) error {
+	return c.LocalForwardWithAccess(local, remote, timeout, nil)
+}
+
+// LocalForwardWithAccess 本地转发（带超时和访问控制），access 为 nil 时不做限制
+func (c *Client) LocalForwardWithAccess(local, remote Endpoint, timeout time.Duration, access *AccessControl) error {
+	return c.LocalForwardNotify(local, remote, timeout, access, nil)
+}
+
+// LocalForwardNotify 本地转发（带超时和访问控制），本地端点监听成功后调用 onListen
+func (c *Client) LocalForwardNotify(local, remote Endpoint, timeout time.Duration, access *AccessControl, onListen func()) error {
 	if c.conn == nil {
 		return fmt.Errorf("SSH连接未建立")
 	}
@@ -645,20 +738,23 @@ func (c *Client) LocalPortForwardWithTimeout(localAddr, remoteAddr string, timeo
 	// 启动Keep-alive
 	c.StartKeepAlive(30 * time.Second)
 
-	// 监听本地端口
-	listener, err := net.Listen("tcp", localAddr)
+	// 监听本地端点
+	listener, err := ListenLocal(local)
 	if err != nil {
 		return fmt.Errorf("监听本地端口失败: %w", err)
 	}
 	defer listener.Close()
 
-	fmt.Printf("本地端口转发已启动: %s -> %s\n", localAddr, remoteAddr)
+	fmt.Printf("本地端口转发已启动: %s -> %s\n", local, remote)
 	fmt.Println("按 Ctrl+C 停止转发")
+	if onListen != nil {
+		onListen()
+	}
 
 	for {
 		// 设置Accept超时
-		if tcpListener, ok := listener.(*net.TCPListener); ok {
-			tcpListener.SetDeadline(time.Now().Add(timeout))
+		if deadlineListener, ok := listener.(interface{ SetDeadline(time.Time) error }); ok {
+			deadlineListener.SetDeadline(time.Now().Add(timeout))
 		}
 
 		localConn, err := listener.Accept()
@@ -674,32 +770,92 @@ func (c *Client) LocalPortForwardWithTimeout(localAddr, remoteAddr string, timeo
 
 			// 检查是否是因为listener关闭导致的错误
 			if strings.Contains(err.Error(), "use of closed network connection") {
-				fmt.Println("端口转发监听器已关闭")
+				c.Logger().Debug("端口转发监听器已关闭")
 				return nil
 			}
 			return fmt.Errorf("接受连接失败: %w", err)
 		}
 
+		start := time.Now()
+		source := localConn.RemoteAddr().String()
+
+		// 访问控制：来源网段、速率和并发数
+		release, err := access.admit(localConn)
+		if err != nil {
+			c.Logger().Warn("拒绝连接", "source", source, "error", err)
+			c.recorder.Reject(start, source, remote.String(), CloseReasonRejected, err)
+			localConn.Close()
+			continue
+		}
+
 		go func() {
+			defer release()
 			defer localConn.Close()
 
-			// 连接到远程地址
-			remoteConn, err := c.conn.Dial("tcp", remoteAddr)
+			// 连接到远程端点，Unix套接字通过 direct-streamlocal@openssh.com 通道连接
+			remoteConn, err := c.conn.Dial(remote.Network, remote.Address)
 			if err != nil {
-				fmt.Printf("连接远程地址失败: %v\n", err)
+				c.Logger().Warn("连接远程地址失败", "source", source, "destination", remote.String(), "error", err)
+				c.recorder.Reject(start, source, remote.String(), CloseReasonDialFailed, err)
 				return
 			}
 			defer remoteConn.Close()
 
-			// 双向数据转发
-			go io.Copy(localConn, remoteConn)
-			io.Copy(remoteConn, localConn)
+			// 双向数据转发，访问控制的空闲超时作用于每个连接
+			opts := c.pipeOptions
+			if access != nil && access.IdleTimeout > 0 {
+				opts.IdleTimeout = access.IdleTimeout
+			}
+			sent, received, err := Pipe(localConn, remoteConn, opts)
+			c.logConnClosed(source, remote.String(), sent, received, err)
+			c.recorder.Record(start, source, remote.String(), sent, received, err)
 		}()
 	}
 }
 
 // RemotePortForwardWithTimeout 远程端口转发（带超时）
 func (c *Client) RemotePortForwardWithTimeout(remoteAddr, localAddr string, timeout time.Duration) error {
+	return c.RemoteForwardWithTimeout(TCPEndpoint(remoteAddr), TCPEndpoint(localAddr), timeout)
+}
+
+// RemoteForwardWithTimeout 远程转发（带超时），远程和本地端点均支持TCP和Unix套接字
+func (c *Client) RemoteForwardWithTimeout(remote, local Endpoint, timeout time.Duration) error {
+	return c.RemoteForwardNotify(remote, local, timeout, nil)
+}
+
+// RemoteForwardNotify 远程转发（带超时），监听成功后通过 onListen 回报实际监听的远程端点
+// 远程端口为0时由服务器分配端口
+func (c *Client) RemoteForwardNotify(remote, local Endpoint, timeout time.Duration, onListen func(bound Endpoint)) error {
+	return c.ServeRemote(remote, func(bound Endpoint) {
+		fmt.Printf("远程端口转发已启动: %s -> %s\n", bound, local)
+		fmt.Println("按 Ctrl+C 停止转发")
+		if onListen != nil {
+			onListen(bound)
+		}
+	}, func(remoteConn net.Conn) {
+		defer remoteConn.Close()
+		start := time.Now()
+		source := remoteConn.RemoteAddr().String()
+
+		// 连接到本地端点
+		localConn, err := net.DialTimeout(local.Network, local.Address, timeout)
+		if err != nil {
+			c.Logger().Warn("连接本地地址失败", "source", source, "destination", local.String(), "error", err)
+			c.recorder.Reject(start, source, local.String(), CloseReasonDialFailed, err)
+			return
+		}
+		defer localConn.Close()
+
+		// 双向数据转发
+		sent, received, err := Pipe(remoteConn, localConn, c.pipeOptions)
+		c.logConnClosed(source, local.String(), sent, received, err)
+		c.recorder.Record(start, source, local.String(), sent, received, err)
+	})
+}
+
+// ServeRemote 在服务器上监听远程端点，并把每个接入的连接交给 handle 处理
+// handle 在独立的协程中运行，负责关闭连接
+func (c *Client) ServeRemote(remote Endpoint, onListen func(bound Endpoint), handle func(conn net.Conn)) error {
 	if c.conn == nil {
 		return fmt.Errorf("SSH连接未建立")
 	}
@@ -707,41 +863,35 @@ func (c *Client) RemotePortForwardWithTimeout(remoteAddr, localAddr string, time
 	// 启动Keep-alive
 	c.StartKeepAlive(30 * time.Second)
 
-	// 监听远程端口
-	listener, err := c.conn.Listen("tcp", remoteAddr)
+	// 监听远程端点，Unix套接字通过 streamlocal-forward@openssh.com 请求监听
+	listener, err := c.listenRemote(remote)
 	if err != nil {
 		return fmt.Errorf("监听远程端口失败: %w", err)
 	}
 	defer listener.Close()
 
-	fmt.Printf("远程端口转发已启动: %s -> %s\n", remoteAddr, localAddr)
-	fmt.Println("按 Ctrl+C 停止转发")
+	bound := remote
+	if !remote.IsUnix() {
+		bound = TCPEndpoint(listener.Addr().String())
+		if _, port, _ := net.SplitHostPort(remote.Address); port == "0" {
+			fmt.Printf("服务器分配的远程端口: %d\n", listener.Addr().(*net.TCPAddr).Port)
+		}
+	}
+	if onListen != nil {
+		onListen(bound)
+	}
 
 	for {
 		remoteConn, err := listener.Accept()
 		if err != nil {
 			// 检查是否是因为listener关闭导致的错误
 			if strings.Contains(err.Error(), "use of closed network connection") {
-				fmt.Println("端口转发监听器已关闭")
+				c.Logger().Debug("端口转发监听器已关闭")
 				return nil
 			}
 			return fmt.Errorf("接受远程连接失败: %w", err)
 		}
 
-		go func() {
-			defer remoteConn.Close()
-
-			// 连接到本地地址
-			localConn, err := net.DialTimeout("tcp", localAddr, timeout)
-			if err != nil {
-				fmt.Printf("连接本地地址失败: %v\n", err)
-				return
-			}
-			defer localConn.Close()
-
-			// 双向数据转发
-			go io.Copy(remoteConn, localConn)
-			io.Copy(localConn, remoteConn)
-		}()
+		go handle(remoteConn)
 	}
 }
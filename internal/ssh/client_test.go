@@ -1,10 +1,13 @@
 package ssh
 
 import (
+	"bufio"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -452,3 +455,117 @@ func BenchmarkBuildSSHConfig(b *testing.B) {
 		client.buildSSHConfig()
 	}
 }
+
+// TestRemoteForwardAssignedPort 测试服务器分配远程端口和拒绝转发时的诊断信息
+func TestRemoteForwardAssignedPort(t *testing.T) {
+	t.Run("远程端口为0时回报服务器分配的端口", func(t *testing.T) {
+		server := startTestSSHServer(t)
+		client := server.Connect(t)
+		echo := startEchoServer(t, "tcp", "127.0.0.1:0")
+
+		boundChan := make(chan Endpoint, 1)
+		go client.RemoteForwardNotify(TCPEndpoint("127.0.0.1:0"), TCPEndpoint(echo.Addr().String()), time.Second, func(bound Endpoint) {
+			boundChan <- bound
+		})
+
+		var bound Endpoint
+		select {
+		case bound = <-boundChan:
+		case <-time.After(5 * time.Second):
+			t.Fatal("等待远程监听超时")
+		}
+
+		_, port, err := net.SplitHostPort(bound.Address)
+		require.NoError(t, err)
+		assert.NotEqual(t, "0", port)
+		assertEcho(t, "tcp", bound.Address)
+	})
+
+	t.Run("服务器拒绝转发时给出诊断", func(t *testing.T) {
+		server := startTestSSHServer(t)
+		server.rejectFwd = true
+		client := server.Connect(t)
+
+		err := client.RemoteForwardWithTimeout(TCPEndpoint("0.0.0.0:80"), TCPEndpoint("127.0.0.1:1"), time.Second)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "特权端口")
+		assert.Contains(t, err.Error(), "GatewayPorts")
+		assert.Contains(t, err.Error(), "AllowTcpForwarding")
+	})
+}
+
+// TestLocalForwardNotify 测试本地转发在监听成功后回报，回报时已可以接受连接
+func TestLocalForwardNotify(t *testing.T) {
+	server := startTestSSHServer(t)
+	client := server.Connect(t)
+	echo := startEchoServer(t, "tcp", "127.0.0.1:0")
+
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	localAddr := probe.Addr().String()
+	probe.Close()
+
+	listening := make(chan struct{})
+	go client.LocalForwardNotify(TCPEndpoint(localAddr), TCPEndpoint(echo.Addr().String()), time.Second, nil, func() {
+		close(listening)
+	})
+
+	select {
+	case <-listening:
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待本地监听超时")
+	}
+	assertEcho(t, "tcp", localAddr)
+}
+
+// TestRemoteBindAddress 测试远程监听地址规范化
+func TestRemoteBindAddress(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"127.0.0.1:8080", "127.0.0.1:8080"},
+		{":8080", "0.0.0.0:8080"},
+		{"*:0", "0.0.0.0:0"},
+		{"[::1]:22", "[::1]:22"},
+	}
+	for _, tt := range tests {
+		address, err := RemoteBindAddress(tt.input)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, address)
+	}
+
+	_, err := RemoteBindAddress("no-port")
+	assert.Error(t, err)
+}
+
+// TestServeRemote 测试远程监听的连接交给自定义处理函数
+func TestServeRemote(t *testing.T) {
+	server := startTestSSHServer(t)
+	client := server.Connect(t)
+
+	boundChan := make(chan Endpoint, 1)
+	go client.ServeRemote(TCPEndpoint("127.0.0.1:0"), func(bound Endpoint) {
+		boundChan <- bound
+	}, func(conn net.Conn) {
+		defer conn.Close()
+		conn.Write([]byte("hello gotssh\n"))
+		io.Copy(io.Discard, conn)
+	})
+
+	var bound Endpoint
+	select {
+	case bound = <-boundChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待远程监听超时")
+	}
+
+	conn, err := net.DialTimeout("tcp", bound.Address, 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello gotssh\n", line)
+}
@@ -0,0 +1,189 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// ExecOptions 远程执行命令的选项
+type ExecOptions struct {
+	Stdin   io.Reader        // 标准输入，为空时远程命令立即读到文件结束
+	Stdout  io.Writer        // 标准输出
+	Stderr  io.Writer        // 标准错误，分配伪终端时远程的标准错误合并到标准输出
+	Env     []string         // KEY=VALUE 形式的环境变量，服务器需要在 sshd 的 AcceptEnv 中允许
+	PTY     bool             // 分配伪终端，标准输入是终端时切换到原始模式并同步窗口大小
+	Signals <-chan os.Signal // 转发到远程命令的信号，服务器需要支持 signal 请求（OpenSSH 7.9 及以上）
+}
+
+// CommandExitError 远程命令以非零退出码结束，被信号终止时退出码为 128 加信号值
+type CommandExitError struct {
+	Status int
+	Signal string // 终止命令的信号，如 TERM
+}
+
+func (e *CommandExitError) Error() string {
+	if e.Signal != "" {
+		return fmt.Sprintf("命令被信号 SIG%s 终止", e.Signal)
+	}
+	return fmt.Sprintf("命令退出，退出码: %d", e.Status)
+}
+
+// sshSignals 可以转发到远程命令的本地信号
+var sshSignals = map[os.Signal]ssh.Signal{
+	syscall.SIGINT:  ssh.SIGINT,
+	syscall.SIGTERM: ssh.SIGTERM,
+	syscall.SIGHUP:  ssh.SIGHUP,
+	syscall.SIGQUIT: ssh.SIGQUIT,
+}
+
+// signalNumbers 信号名对应的编号，用于计算被信号终止的命令的退出码
+var signalNumbers = map[string]int{
+	"HUP": 1, "INT": 2, "QUIT": 3, "ILL": 4, "ABRT": 6, "FPE": 8, "KILL": 9,
+	"USR1": 10, "SEGV": 11, "USR2": 12, "PIPE": 13, "ALRM": 14, "TERM": 15,
+}
+
+// forceInterrupts 连续收到多少次中断后不再等待远程命令，直接关闭会话
+const forceInterrupts = 3
+
+// Exec 在远程执行命令，标准输入、输出和错误分别连接到 opts 中的读写器，命令结束后返回。
+// 命令以非零退出码结束时返回 *CommandExitError
+func (c *Client) Exec(command string, opts ExecOptions) error {
+	session, err := c.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	for _, env := range opts.Env {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("无效的环境变量 '%s'，应为 KEY=VALUE", env)
+		}
+		if err := session.Setenv(name, value); err != nil {
+			c.Logger().Warn("服务器拒绝设置环境变量，请检查 sshd 的 AcceptEnv", "name", name, "error", err)
+		}
+	}
+
+	if opts.PTY {
+		restore, err := c.requestPty(session, opts.Stdin)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	session.Stdout = opts.Stdout
+	session.Stderr = opts.Stderr
+	if opts.Stdin != nil {
+		// 不直接设置 session.Stdin：本地输入没有结束（如终端）时 Wait 会一直等待复制完成
+		stdin, err := session.StdinPipe()
+		if err != nil {
+			return fmt.Errorf("创建标准输入失败: %w", err)
+		}
+		go func() {
+			io.Copy(stdin, opts.Stdin)
+			stdin.Close()
+		}()
+	}
+
+	c.Logger().Debug("执行远程命令", "command", command, "pty", opts.PTY)
+	if err := session.Start(command); err != nil {
+		return fmt.Errorf("执行命令失败: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go c.forwardSignals(session, opts.Signals, done)
+
+	err = session.Wait()
+	var exitErr *ssh.ExitError
+	var missingErr *ssh.ExitMissingError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr):
+		status := exitErr.ExitStatus()
+		if exitErr.Signal() != "" {
+			status = 128 + signalNumbers[exitErr.Signal()]
+		}
+		return &CommandExitError{Status: status, Signal: exitErr.Signal()}
+	case errors.As(err, &missingErr):
+		return fmt.Errorf("远程命令结束但未返回退出码")
+	default:
+		return fmt.Errorf("命令执行错误: %w", err)
+	}
+}
+
+// requestPty 为会话分配伪终端，标准输入是终端时切换到原始模式并同步窗口大小，返回恢复终端的函数
+func (c *Client) requestPty(session *ssh.Session, stdin io.Reader) (func(), error) {
+	width, height := 80, 24
+	fd := -1
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fd = int(f.Fd())
+		if w, h, err := term.GetSize(fd); err == nil {
+			width, height = w, h
+		}
+	}
+
+	termType := os.Getenv("TERM")
+	if termType == "" {
+		termType = "xterm-256color"
+	}
+	if err := session.RequestPty(termType, height, width, ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}); err != nil {
+		return nil, fmt.Errorf("请求伪终端失败: %w", err)
+	}
+	if fd < 0 {
+		return func() {}, nil
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("设置终端模式失败: %w", err)
+	}
+	stopWatch := watchWindowSize(fd, session)
+	return func() {
+		stopWatch()
+		term.Restore(fd, state)
+	}, nil
+}
+
+// forwardSignals 将收到的本地信号转发到远程命令，直到 done 关闭。
+// 服务器不支持信号时命令不会结束，连续中断 forceInterrupts 次后关闭会话
+func (c *Client) forwardSignals(session *ssh.Session, signals <-chan os.Signal, done <-chan struct{}) {
+	interrupts := 0
+	for {
+		select {
+		case <-done:
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				return
+			}
+			name, supported := sshSignals[sig]
+			if !supported {
+				continue
+			}
+			if name == ssh.SIGINT {
+				if interrupts++; interrupts >= forceInterrupts {
+					c.Logger().Warn("远程命令未响应中断，关闭会话")
+					session.Close()
+					return
+				}
+			}
+			if err := session.Signal(name); err != nil {
+				c.Logger().Debug("转发信号失败", "signal", name, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ssh
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecStreams 测试远程命令的标准输入、输出和错误分别传递
+func TestExecStreams(t *testing.T) {
+	client := startTestSSHServer(t).Connect(t)
+
+	var stdout, stderr bytes.Buffer
+	err := client.Exec("cat; echo oops >&2", ExecOptions{
+		Stdin:  strings.NewReader("hello\n"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", stdout.String())
+	assert.Equal(t, "oops\n", stderr.String())
+
+	// 没有标准输入时远程命令读到文件结束
+	stdout.Reset()
+	require.NoError(t, client.Exec("cat; echo done", ExecOptions{Stdout: &stdout}))
+	assert.Equal(t, "done\n", stdout.String())
+}
+
+// TestExecExitStatus 测试远程命令的退出码和被信号终止
+func TestExecExitStatus(t *testing.T) {
+	client := startTestSSHServer(t).Connect(t)
+
+	err := client.Exec("exit 3", ExecOptions{})
+	var exitErr *CommandExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.Status)
+	assert.Equal(t, "命令退出，退出码: 3", exitErr.Error())
+
+	err = client.Exec("kill -TERM $$", ExecOptions{})
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 143, exitErr.Status)
+	assert.Equal(t, "TERM", exitErr.Signal)
+}
+
+// TestExecEnv 测试通过 SSH 设置远程命令的环境变量
+func TestExecEnv(t *testing.T) {
+	client := startTestSSHServer(t).Connect(t)
+
+	var stdout bytes.Buffer
+	require.NoError(t, client.Exec(`echo "$GREETING,$EMPTY."`, ExecOptions{
+		Stdout: &stdout,
+		Env:    []string{"GREETING=hello=world", "EMPTY="},
+	}))
+	assert.Equal(t, "hello=world,.\n", stdout.String())
+
+	assert.Error(t, client.Exec("true", ExecOptions{Env: []string{"INVALID"}}))
+}
+
+// readyWriter 收到指定内容后关闭 ready 通道
+type readyWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	want  string
+	ready chan struct{}
+}
+
+func (w *readyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := w.buf.Write(p)
+	if w.ready != nil && strings.Contains(w.buf.String(), w.want) {
+		close(w.ready)
+		w.ready = nil
+	}
+	return n, err
+}
+
+// TestExecSignals 测试本地信号转发到远程命令
+func TestExecSignals(t *testing.T) {
+	client := startTestSSHServer(t).Connect(t)
+
+	ready := make(chan struct{})
+	stdout := &readyWriter{want: "ready", ready: ready}
+	signals := make(chan os.Signal, 1)
+	go func() {
+		select {
+		case <-ready:
+			signals <- syscall.SIGTERM
+		case <-time.After(5 * time.Second):
+		}
+	}()
+
+	err := client.Exec("trap 'exit 42' TERM; echo ready; while :; do sleep 0.1; done", ExecOptions{
+		Stdout:  stdout,
+		Signals: signals,
+	})
+	var exitErr *CommandExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 42, exitErr.Status)
+}
+
+// TestExecPTY 测试请求伪终端，标准输入不是终端时不切换终端模式
+func TestExecPTY(t *testing.T) {
+	client := startTestSSHServer(t).Connect(t)
+
+	var stdout bytes.Buffer
+	require.NoError(t, client.Exec("echo pty", ExecOptions{Stdout: &stdout, PTY: true}))
+	assert.Equal(t, "pty\n", stdout.String())
+}
@@ -0,0 +1,70 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gotssh/internal/config"
+)
+
+// ShellExitError 远程Shell以非零退出码结束
+type ShellExitError struct {
+	Status int
+}
+
+func (e *ShellExitError) Error() string {
+	return fmt.Sprintf("Shell退出，退出码: %d", e.Status)
+}
+
+// ExitStatus 根据 Shell 返回的错误得到退出码，异常结束时返回 -1
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ShellExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Status
+	}
+	return -1
+}
+
+// recordHistory 记录一次交互式会话到连接历史，失败时只记录日志
+func (c *Client) recordHistory(start time.Time, err error) {
+	if c.configManager == nil {
+		return
+	}
+
+	name := c.config.Alias
+	if name == "" {
+		name = c.config.Host
+	}
+	user := c.config.User
+	if c.credential != nil && c.credential.Username != "" {
+		user = c.credential.Username
+	}
+
+	entry := config.HistoryEntry{
+		ServerID:   c.config.ID,
+		Server:     name,
+		User:       user,
+		Host:       c.config.Host,
+		Port:       c.config.Port,
+		Start:      start,
+		Duration:   time.Since(start),
+		ExitStatus: ExitStatus(err),
+	}
+	if c.credential != nil {
+		entry.Credential = c.credential.Alias
+		if entry.Credential == "" {
+			entry.Credential = c.credential.ID
+		}
+	}
+	if entry.ExitStatus == -1 {
+		entry.Error = err.Error()
+	}
+
+	if err := c.configManager.RecordConnection(entry); err != nil {
+		c.Logger().Warn("记录连接历史失败", "error", err)
+	}
+}
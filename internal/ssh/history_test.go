@@ -0,0 +1,55 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/config"
+)
+
+// TestExitStatus 测试从Shell错误中得到退出码
+func TestExitStatus(t *testing.T) {
+	assert.Equal(t, 0, ExitStatus(nil))
+	assert.Equal(t, 2, ExitStatus(&ShellExitError{Status: 2}))
+	assert.Equal(t, 130, ExitStatus(fmt.Errorf("Shell启动失败: %w", &ShellExitError{Status: 130})))
+	assert.Equal(t, -1, ExitStatus(errors.New("连接断开")))
+	assert.Equal(t, "Shell退出，退出码: 2", (&ShellExitError{Status: 2}).Error())
+}
+
+// TestRecordHistory 测试会话结束后记录连接历史
+func TestRecordHistory(t *testing.T) {
+	configManager := createTestConfigManager(t)
+	credential := config.NewCredentialConfig()
+	credential.Alias = "ops"
+	credential.Username = "deploy"
+	credential.Password = "secret"
+	require.NoError(t, configManager.AddCredential(credential))
+
+	server := &config.ServerConfig{ID: "srv-1", Host: "10.0.0.1", Port: 22, User: "root", AuthType: config.AuthTypeCredential, CredentialID: credential.ID}
+	client := NewClient(server, configManager)
+
+	start := time.Now().Add(-time.Minute)
+	client.recordHistory(start, &ShellExitError{Status: 1})
+	client.recordHistory(time.Now(), errors.New("Shell会话错误: EOF"))
+
+	entries, err := configManager.ConnectionHistory(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, -1, entries[0].ExitStatus)
+	assert.Equal(t, "Shell会话错误: EOF", entries[0].Error)
+
+	entry := entries[1]
+	assert.Equal(t, "srv-1", entry.ServerID)
+	assert.Equal(t, "10.0.0.1", entry.Server)
+	assert.Equal(t, "deploy", entry.User)
+	assert.Equal(t, "ops", entry.Credential)
+	assert.Equal(t, 1, entry.ExitStatus)
+	assert.Empty(t, entry.Error)
+	assert.GreaterOrEqual(t, entry.Duration, time.Minute)
+}
@@ -0,0 +1,52 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+)
+
+// maxJumpHops 跳板机的最大级数，超过时视为跳板机配置成环
+const maxJumpHops = 8
+
+// dialViaJumpHost 先连接跳板机，再经跳板机连接目标地址
+// 跳板机本身也可以配置跳板机，形成多级跳转
+func (c *Client) dialViaJumpHost(address string) (net.Conn, error) {
+	if c.configManager == nil {
+		return nil, fmt.Errorf("未加载配置，无法查找跳板机 '%s'", c.config.JumpHost)
+	}
+	if c.hops >= maxJumpHops {
+		return nil, fmt.Errorf("跳板机超过 %d 级，请检查跳板机配置是否成环", maxJumpHops)
+	}
+
+	jumpConfig, err := c.configManager.FindServerRef(c.config.JumpHost)
+	if err != nil {
+		return nil, fmt.Errorf("查找跳板机失败: %w", err)
+	}
+	if jumpConfig.ID == c.config.ID {
+		return nil, fmt.Errorf("服务器不能使用自身作为跳板机")
+	}
+
+	jump := NewClient(jumpConfig, c.configManager)
+	jump.hops = c.hops + 1
+	c.Logger().Debug("通过跳板机连接", "jump_host", c.config.JumpHost,
+		"jump_address", net.JoinHostPort(jump.config.Host, fmt.Sprint(jump.config.Port)))
+	if err := jump.Connect(); err != nil {
+		return nil, fmt.Errorf("连接跳板机 '%s' 失败: %w", c.config.JumpHost, err)
+	}
+
+	conn, err := jump.Dial("tcp", address)
+	if err != nil {
+		jump.Close()
+		return nil, fmt.Errorf("经跳板机连接失败: %w", err)
+	}
+	c.jump = jump
+	return conn, nil
+}
+
+// closeJump 关闭跳板机连接
+func (c *Client) closeJump() {
+	if c.jump != nil {
+		c.jump.Close()
+		c.jump = nil
+	}
+}
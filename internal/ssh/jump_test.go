@@ -0,0 +1,86 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/config"
+)
+
+// TestJumpHost 测试经跳板机连接，跳板机由分组默认值继承
+func TestJumpHost(t *testing.T) {
+	configManager := createTestConfigManager(t)
+	bastion := startTestSSHServer(t)
+	target := startTestSSHServer(t)
+
+	jumpConfig := bastion.ServerConfig()
+	jumpConfig.ID = ""
+	jumpConfig.Alias = "bastion"
+	require.NoError(t, configManager.AddServer(jumpConfig))
+
+	group := config.NewServerGroup("prod")
+	group.JumpHost = "bastion"
+	require.NoError(t, configManager.AddServerGroup(group))
+
+	targetConfig := target.ServerConfig()
+	targetConfig.ID = ""
+	targetConfig.User = "target-user"
+	targetConfig.Group = "prod"
+	require.NoError(t, configManager.AddServer(targetConfig))
+
+	client := NewClient(targetConfig, configManager)
+	require.NoError(t, client.Connect())
+	require.NotNil(t, client.jump)
+	assert.Equal(t, "bastion", client.config.JumpHost)
+
+	conn, err := client.Dial("tcp", target.listener.Addr().String())
+	require.NoError(t, err)
+	conn.Close()
+
+	jump := client.jump
+	require.NoError(t, client.Close())
+	assert.Nil(t, client.jump)
+	assert.False(t, jump.IsConnected())
+}
+
+// TestJumpHostErrors 测试跳板机配置错误
+func TestJumpHostErrors(t *testing.T) {
+	configManager := createTestConfigManager(t)
+	server := startTestSSHServer(t)
+
+	t.Run("跳板机不存在", func(t *testing.T) {
+		cfg := server.ServerConfig()
+		cfg.JumpHost = "missing"
+		err := NewClient(cfg, configManager).Connect()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "查找跳板机失败")
+	})
+
+	t.Run("未加载配置", func(t *testing.T) {
+		cfg := server.ServerConfig()
+		cfg.JumpHost = "bastion"
+		assert.Error(t, NewClient(cfg, nil).Connect())
+	})
+
+	t.Run("跳板机成环", func(t *testing.T) {
+		a := server.ServerConfig()
+		a.ID = ""
+		a.Alias = "a"
+		a.User = "user-a"
+		a.JumpHost = "b"
+		require.NoError(t, configManager.AddServer(a))
+
+		b := server.ServerConfig()
+		b.ID = ""
+		b.Alias = "b"
+		b.User = "user-b"
+		b.JumpHost = "a"
+		require.NoError(t, configManager.AddServer(b))
+
+		err := NewClient(a, configManager).Connect()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "成环")
+	})
+}
@@ -0,0 +1,232 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pipeBufferSize 转发缓冲区大小
+const pipeBufferSize = 32 * 1024
+
+// bufferPool 转发缓冲区池，避免每个连接分配新的缓冲区
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, pipeBufferSize)
+		return &buf
+	},
+}
+
+// PipeOptions 双向转发的超时设置，为0表示不限制
+type PipeOptions struct {
+	IdleTimeout      time.Duration // 两个方向都没有数据传输超过该时间后关闭
+	HalfCloseTimeout time.Duration // 一个方向结束（半关闭）后，另一方向保持静默超过该时间后关闭
+	WriteTimeout     time.Duration // 单次写入阻塞超过该时间后关闭
+}
+
+// enabled 是否设置了任意超时
+func (o PipeOptions) enabled() bool {
+	return o.IdleTimeout > 0 || o.HalfCloseTimeout > 0 || o.WriteTimeout > 0
+}
+
+// checkInterval 超时检查间隔
+func (o PipeOptions) checkInterval() time.Duration {
+	interval := time.Second
+	for _, timeout := range []time.Duration{o.IdleTimeout, o.HalfCloseTimeout, o.WriteTimeout} {
+		if timeout > 0 && timeout/4 < interval {
+			interval = timeout / 4
+		}
+	}
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	return interval
+}
+
+// ErrPipeTimeout 转发因超时被关闭
+var ErrPipeTimeout = errors.New("转发超时")
+
+// closeWriter 支持半关闭的连接
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// pipe 一次双向转发的状态
+type pipe struct {
+	a, b       net.Conn
+	opts       PipeOptions
+	lastActive atomic.Int64    // 最近一次传输数据的时间（UnixNano）
+	writing    [2]atomic.Int64 // 各方向正在进行的写入开始时间，0表示没有写入
+	halfClosed atomic.Int64    // 第一个方向结束的时间，0表示两个方向都在传输
+	closeOnce  sync.Once
+	timeoutErr atomic.Value
+}
+
+// Pipe 在两个连接之间双向转发数据，直到两个方向都结束
+// 一个方向读到EOF时对另一端执行 CloseWrite 传递半关闭，另一方向继续转发；
+// 任一方向出错或超时时关闭两端。返回 a->b 和 b->a 方向传输的字节数
+func Pipe(a, b net.Conn, opts PipeOptions) (sent, received int64, err error) {
+	p := &pipe{a: a, b: b, opts: opts}
+	p.touch()
+
+	type result struct {
+		n   int64
+		err error
+	}
+	aToB := make(chan result, 1)
+	bToA := make(chan result, 1)
+	go func() {
+		n, err := p.copy(b, a, 0)
+		aToB <- result{n, err}
+	}()
+	go func() {
+		n, err := p.copy(a, b, 1)
+		bToA <- result{n, err}
+	}()
+
+	done := make(chan struct{})
+	if opts.enabled() {
+		go p.watch(done)
+	}
+
+	var first, second result
+	var firstDir chan result
+	select {
+	case first = <-aToB:
+		firstDir = bToA
+	case first = <-bToA:
+		firstDir = aToB
+	}
+	if first.err != nil {
+		p.close()
+	} else {
+		p.halfClosed.Store(time.Now().UnixNano())
+	}
+	second = <-firstDir
+	close(done)
+	p.close()
+
+	if firstDir == bToA {
+		sent, received = first.n, second.n
+	} else {
+		sent, received = second.n, first.n
+	}
+
+	if timeoutErr, ok := p.timeoutErr.Load().(error); ok {
+		return sent, received, timeoutErr
+	}
+	if first.err != nil {
+		return sent, received, first.err
+	}
+	if second.err != nil && !errors.Is(second.err, net.ErrClosed) {
+		return sent, received, second.err
+	}
+	return sent, received, nil
+}
+
+// copy 从 src 读取数据写入 dst，读到EOF时半关闭 dst
+func (p *pipe) copy(dst, src net.Conn, dir int) (int64, error) {
+	bufPtr := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(bufPtr)
+	buf := *bufPtr
+
+	var written int64
+	for {
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			p.touch()
+			p.writing[dir].Store(time.Now().UnixNano())
+			wn, writeErr := dst.Write(buf[:n])
+			p.writing[dir].Store(0)
+			written += int64(wn)
+			if writeErr != nil {
+				return written, writeErr
+			}
+			if wn != n {
+				return written, io.ErrShortWrite
+			}
+			p.touch()
+		}
+
+		if readErr != nil {
+			if readErr != io.EOF {
+				return written, readErr
+			}
+			// 传递半关闭，不支持时只能关闭整个连接
+			if cw, ok := dst.(closeWriter); ok {
+				if err := cw.CloseWrite(); err == nil {
+					return written, nil
+				}
+			}
+			p.close()
+			return written, nil
+		}
+	}
+}
+
+// touch 记录数据传输时间
+func (p *pipe) touch() {
+	p.lastActive.Store(time.Now().UnixNano())
+}
+
+// close 关闭两端连接
+func (p *pipe) close() {
+	p.closeOnce.Do(func() {
+		p.a.Close()
+		p.b.Close()
+	})
+}
+
+// watch 定期检查超时，超时后关闭两端连接使阻塞的读写返回
+func (p *pipe) watch(done <-chan struct{}) {
+	ticker := time.NewTicker(p.opts.checkInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			if err := p.checkTimeout(now); err != nil {
+				p.timeoutErr.Store(err)
+				p.close()
+				return
+			}
+		}
+	}
+}
+
+// checkTimeout 检查是否超过任一超时限制
+func (p *pipe) checkTimeout(now time.Time) error {
+	idle := now.Sub(time.Unix(0, p.lastActive.Load()))
+
+	if p.opts.IdleTimeout > 0 && idle > p.opts.IdleTimeout {
+		return fmt.Errorf("%w: 空闲超过 %v", ErrPipeTimeout, p.opts.IdleTimeout)
+	}
+
+	if p.opts.HalfCloseTimeout > 0 {
+		if halfClosed := p.halfClosed.Load(); halfClosed != 0 {
+			since := now.Sub(time.Unix(0, halfClosed))
+			if idle < since {
+				since = idle
+			}
+			if since > p.opts.HalfCloseTimeout {
+				return fmt.Errorf("%w: 半关闭后 %v 内没有数据", ErrPipeTimeout, p.opts.HalfCloseTimeout)
+			}
+		}
+	}
+
+	if p.opts.WriteTimeout > 0 {
+		for dir := range p.writing {
+			if start := p.writing[dir].Load(); start != 0 && now.Sub(time.Unix(0, start)) > p.opts.WriteTimeout {
+				return fmt.Errorf("%w: 写入阻塞超过 %v", ErrPipeTimeout, p.opts.WriteTimeout)
+			}
+		}
+	}
+
+	return nil
+}
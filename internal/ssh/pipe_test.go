@@ -0,0 +1,217 @@
+package ssh
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tcpPair 创建一对已连接的TCP连接
+func tcpPair(tb testing.TB) (net.Conn, net.Conn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(tb, err)
+	server, ok := <-accepted
+	require.True(tb, ok)
+
+	tb.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+// startPipe 建立 client <-> Pipe <-> backend 的转发链路
+func startPipe(tb testing.TB, opts PipeOptions) (client, backend net.Conn, result chan error) {
+	client, front := tcpPair(tb)
+	back, backend := tcpPair(tb)
+
+	result = make(chan error, 1)
+	go func() {
+		_, _, err := Pipe(front, back, opts)
+		result <- err
+	}()
+	return client, backend, result
+}
+
+// waitPipe 等待转发结束
+func waitPipe(t *testing.T, result chan error) error {
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待转发结束超时")
+		return nil
+	}
+}
+
+// TestPipeHalfClose 测试半关闭在转发中的传递
+func TestPipeHalfClose(t *testing.T) {
+	client, backend, result := startPipe(t, PipeOptions{})
+
+	// 后端读完请求（直到EOF）后才返回响应，依赖半关闭
+	go func() {
+		request, _ := io.ReadAll(backend)
+		backend.Write(append([]byte("echo:"), request...))
+		backend.Close()
+	}()
+
+	_, err := client.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, client.(*net.TCPConn).CloseWrite())
+
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	response, err := io.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "echo:request", string(response))
+
+	assert.NoError(t, waitPipe(t, result))
+}
+
+// TestPipeByteCounts 测试转发字节数统计
+func TestPipeByteCounts(t *testing.T) {
+	client, front := tcpPair(t)
+	back, backend := tcpPair(t)
+
+	type counts struct{ sent, received int64 }
+	result := make(chan counts, 1)
+	go func() {
+		sent, received, _ := Pipe(front, back, PipeOptions{})
+		result <- counts{sent, received}
+	}()
+
+	go func() {
+		io.Copy(io.Discard, backend)
+		backend.Write(make([]byte, 300))
+		backend.Close()
+	}()
+
+	client.Write(make([]byte, 1000))
+	client.(*net.TCPConn).CloseWrite()
+	io.Copy(io.Discard, client)
+
+	got := <-result
+	assert.EqualValues(t, 1000, got.sent)
+	assert.EqualValues(t, 300, got.received)
+}
+
+// TestPipeTimeouts 测试转发超时
+func TestPipeTimeouts(t *testing.T) {
+	t.Run("空闲超时", func(t *testing.T) {
+		client, _, result := startPipe(t, PipeOptions{IdleTimeout: 100 * time.Millisecond})
+
+		start := time.Now()
+		err := waitPipe(t, result)
+		assert.True(t, errors.Is(err, ErrPipeTimeout), "err = %v", err)
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+		// 客户端连接被关闭
+		client.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = client.Read(make([]byte, 1))
+		assert.Error(t, err)
+	})
+
+	t.Run("半关闭后超时", func(t *testing.T) {
+		client, _, result := startPipe(t, PipeOptions{HalfCloseTimeout: 100 * time.Millisecond})
+
+		// 未半关闭时不受限制
+		select {
+		case err := <-result:
+			t.Fatalf("转发提前结束: %v", err)
+		case <-time.After(300 * time.Millisecond):
+		}
+
+		require.NoError(t, client.(*net.TCPConn).CloseWrite())
+		err := waitPipe(t, result)
+		assert.True(t, errors.Is(err, ErrPipeTimeout), "err = %v", err)
+	})
+
+	t.Run("写入阻塞超时", func(t *testing.T) {
+		client, backend, result := startPipe(t, PipeOptions{WriteTimeout: 200 * time.Millisecond})
+		backend.(*net.TCPConn).SetReadBuffer(4096)
+
+		// 后端不读取数据，写入最终阻塞
+		go func() {
+			chunk := make([]byte, 64*1024)
+			for {
+				if _, err := client.Write(chunk); err != nil {
+					return
+				}
+			}
+		}()
+
+		err := waitPipe(t, result)
+		assert.True(t, errors.Is(err, ErrPipeTimeout), "err = %v", err)
+	})
+}
+
+// TestPipeOptionsCheckInterval 测试超时检查间隔
+func TestPipeOptionsCheckInterval(t *testing.T) {
+	assert.False(t, PipeOptions{}.enabled())
+	assert.Equal(t, time.Second, PipeOptions{IdleTimeout: time.Minute}.checkInterval())
+	assert.Equal(t, 50*time.Millisecond, PipeOptions{IdleTimeout: time.Minute, WriteTimeout: 200 * time.Millisecond}.checkInterval())
+	assert.Equal(t, 10*time.Millisecond, PipeOptions{HalfCloseTimeout: time.Millisecond}.checkInterval())
+}
+
+// benchmarkTransfer 通过转发链路单向传输数据并测量吞吐量
+func benchmarkTransfer(b *testing.B, forward func(front, back net.Conn)) {
+	client, front := tcpPair(b)
+	back, backend := tcpPair(b)
+	go forward(front, back)
+
+	chunk := make([]byte, 64*1024)
+	done := make(chan struct{})
+	go func() {
+		io.CopyN(io.Discard, backend, int64(len(chunk))*int64(b.N))
+		close(done)
+	}()
+
+	b.SetBytes(int64(len(chunk)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Write(chunk); err != nil {
+			b.Fatal(err)
+		}
+	}
+	<-done
+}
+
+// BenchmarkPipe 测试 Pipe 的转发吞吐量
+func BenchmarkPipe(b *testing.B) {
+	benchmarkTransfer(b, func(front, back net.Conn) {
+		Pipe(front, back, PipeOptions{})
+	})
+}
+
+// BenchmarkPipeWithTimeouts 测试启用超时检查时的转发吞吐量
+func BenchmarkPipeWithTimeouts(b *testing.B) {
+	benchmarkTransfer(b, func(front, back net.Conn) {
+		Pipe(front, back, PipeOptions{IdleTimeout: time.Minute, HalfCloseTimeout: time.Minute, WriteTimeout: time.Minute})
+	})
+}
+
+// BenchmarkIOCopy 作为对照的 io.Copy 转发吞吐量
+func BenchmarkIOCopy(b *testing.B) {
+	benchmarkTransfer(b, func(front, back net.Conn) {
+		go io.Copy(front, back)
+		io.Copy(back, front)
+	})
+}
@@ -0,0 +1,95 @@
+package ssh
+
+import (
+	"errors"
+	"time"
+)
+
+// 连接关闭原因
+const (
+	CloseReasonNormal     = "closed"      // 两端正常结束
+	CloseReasonTimeout    = "timeout"     // 空闲、半关闭或写入超时
+	CloseReasonError      = "error"       // 传输出错
+	CloseReasonRejected   = "rejected"    // 被访问控制拒绝
+	CloseReasonDialFailed = "dial_failed" // 连接目标失败
+)
+
+// ConnRecord 一次转发连接的记录
+type ConnRecord struct {
+	Start       time.Time     // 连接建立时间
+	Source      string        // 来源地址
+	Destination string        // 目标地址
+	Duration    time.Duration // 持续时间
+	Sent        int64         // 来源发往目标的字节数
+	Received    int64         // 目标发往来源的字节数
+	CloseReason string        // 关闭原因
+	Error       string        // 错误详情
+}
+
+// ConnRecorder 接收转发连接记录的回调
+type ConnRecorder func(ConnRecord)
+
+// SetConnRecorder 设置转发连接记录回调，为 nil 时不记录
+func (c *Client) SetConnRecorder(recorder ConnRecorder) {
+	c.recorder = recorder
+}
+
+// ConnRecorder 获取转发连接记录回调
+func (c *Client) ConnRecorder() ConnRecorder {
+	return c.recorder
+}
+
+// record 调用记录回调
+func (r ConnRecorder) record(start time.Time, source, destination string, sent, received int64, reason string, err error) {
+	if r == nil {
+		return
+	}
+	rec := ConnRecord{
+		Start:       start,
+		Source:      source,
+		Destination: destination,
+		Duration:    time.Since(start),
+		Sent:        sent,
+		Received:    received,
+		CloseReason: reason,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	r(rec)
+}
+
+// Record 记录一次结束的连接，根据错误推断关闭原因
+func (r ConnRecorder) Record(start time.Time, source, destination string, sent, received int64, err error) {
+	r.record(start, source, destination, sent, received, PipeCloseReason(err), err)
+}
+
+// Reject 记录一次被拒绝或连接目标失败的连接
+func (r ConnRecorder) Reject(start time.Time, source, destination, reason string, err error) {
+	r.record(start, source, destination, 0, 0, reason, err)
+}
+
+// logConnClosed 记录转发连接结束，超时和出错的连接使用更高的级别
+func (c *Client) logConnClosed(source, destination string, sent, received int64, err error) {
+	args := []any{"source", source, "destination", destination, "sent", sent, "received", received}
+	switch {
+	case err == nil:
+		c.Logger().Debug("转发连接已关闭", args...)
+	case errors.Is(err, ErrPipeTimeout):
+		c.Logger().Info("转发连接超时关闭", append(args, "error", err)...)
+	default:
+		c.Logger().Debug("转发连接异常关闭", append(args, "error", err)...)
+	}
+}
+
+// PipeCloseReason 根据 Pipe 返回的错误推断关闭原因
+func PipeCloseReason(err error) string {
+	switch {
+	case err == nil:
+		return CloseReasonNormal
+	case errors.Is(err, ErrPipeTimeout):
+		return CloseReasonTimeout
+	default:
+		return CloseReasonError
+	}
+}
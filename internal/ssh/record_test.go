@@ -0,0 +1,81 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipeCloseReason 测试关闭原因推断
+func TestPipeCloseReason(t *testing.T) {
+	assert.Equal(t, CloseReasonNormal, PipeCloseReason(nil))
+	assert.Equal(t, CloseReasonTimeout, PipeCloseReason(fmt.Errorf("写入: %w", ErrPipeTimeout)))
+	assert.Equal(t, CloseReasonError, PipeCloseReason(errors.New("连接被重置")))
+}
+
+// TestConnRecorderNil 测试未设置回调时不记录
+func TestConnRecorderNil(t *testing.T) {
+	var recorder ConnRecorder
+	assert.NotPanics(t, func() {
+		recorder.Record(time.Now(), "a", "b", 1, 2, nil)
+		recorder.Reject(time.Now(), "a", "b", CloseReasonRejected, errors.New("拒绝"))
+	})
+}
+
+// TestLocalForwardRecord 测试本地转发的连接记录
+func TestLocalForwardRecord(t *testing.T) {
+	server := startTestSSHServer(t)
+	client := server.Connect(t)
+	echo := startEchoServer(t, "tcp", "127.0.0.1:0")
+
+	var mu sync.Mutex
+	var records []ConnRecord
+	client.SetConnRecorder(func(rec ConnRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, rec)
+	})
+
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	localAddr := probe.Addr().String()
+	probe.Close()
+
+	_, network, _ := net.ParseCIDR("127.0.0.1/32")
+	access := &AccessControl{AllowedNets: []*net.IPNet{network}}
+	go client.LocalForwardWithAccess(TCPEndpoint(localAddr), TCPEndpoint(echo.Addr().String()), time.Second, access)
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", localAddr)
+		if err != nil {
+			return false
+		}
+		defer conn.Close()
+		conn.Write([]byte("ping"))
+		conn.(*net.TCPConn).CloseWrite()
+		data, _ := io.ReadAll(conn)
+		return string(data) == "ping"
+	}, 5*time.Second, 20*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(records) > 0
+	}, 5*time.Second, 20*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	rec := records[len(records)-1]
+	assert.Equal(t, echo.Addr().String(), rec.Destination)
+	assert.Contains(t, rec.Source, "127.0.0.1:")
+	assert.EqualValues(t, 4, rec.Sent)
+	assert.EqualValues(t, 4, rec.Received)
+	assert.Equal(t, CloseReasonNormal, rec.CloseReason)
+	assert.False(t, rec.Start.IsZero())
+}
@@ -0,0 +1,57 @@
+package ssh
+
+import (
+	"fmt"
+
+	"golang.org/x/term"
+
+	"gotssh/internal/recording"
+)
+
+// SetRecording 强制启用会话录制（如命令行 --record），服务器配置中启用的录制不受影响
+func (c *Client) SetRecording(record, recordInput bool) {
+	c.recordSession = record
+	c.recordInput = recordInput
+}
+
+// recordingEnabled 是否录制本次会话，以及是否记录键盘输入
+func (c *Client) recordingEnabled() (record, recordInput bool) {
+	record = c.recordSession || c.config.RecordSession
+	recordInput = c.recordInput || c.config.RecordInput
+	return record, record && recordInput
+}
+
+// startRecording 按配置开始录制，未启用时返回 nil
+func (c *Client) startRecording(fd int) (*recording.Recording, error) {
+	record, recordInput := c.recordingEnabled()
+	if !record {
+		return nil, nil
+	}
+	if c.configManager == nil {
+		return nil, fmt.Errorf("未初始化配置管理器，无法确定录制目录")
+	}
+
+	width, height := 80, 24
+	if term.IsTerminal(fd) {
+		if w, h, err := term.GetSize(fd); err == nil {
+			width, height = w, h
+		}
+	}
+
+	name := c.config.Alias
+	if name == "" {
+		name = c.config.Host
+	}
+	rec, err := recording.Start(c.configManager.ConfigDir(), recording.Session{
+		ServerID: c.config.ID,
+		Server:   name,
+		User:     c.config.User,
+		Host:     c.config.Host,
+		Port:     c.config.Port,
+	}, width, height, recordInput)
+	if err != nil {
+		return nil, err
+	}
+	c.Logger().Info("开始录制会话", "path", rec.Path, "record_input", recordInput)
+	return rec, nil
+}
@@ -0,0 +1,61 @@
+package ssh
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/config"
+	"gotssh/internal/recording"
+)
+
+// TestRecordingEnabled 测试服务器配置和命令行参数共同决定会话录制
+func TestRecordingEnabled(t *testing.T) {
+	tests := []struct {
+		name                     string
+		server                   config.ServerConfig
+		record, input            bool
+		wantRecord, wantRecInput bool
+	}{
+		{"默认不录制", config.ServerConfig{}, false, false, false, false},
+		{"服务器启用录制", config.ServerConfig{RecordSession: true}, false, false, true, false},
+		{"命令行启用录制", config.ServerConfig{}, true, false, true, false},
+		{"命令行同时录制输入", config.ServerConfig{RecordSession: true}, true, true, true, true},
+		{"未启用录制时忽略输入设置", config.ServerConfig{RecordInput: true}, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.server
+			client := NewClient(&server, nil)
+			client.SetRecording(tt.record, tt.input)
+			record, input := client.recordingEnabled()
+			assert.Equal(t, tt.wantRecord, record)
+			assert.Equal(t, tt.wantRecInput, input)
+		})
+	}
+}
+
+// TestStartRecording 测试会话录制文件写入配置目录
+func TestStartRecording(t *testing.T) {
+	configManager := createTestConfigManager(t)
+	server := &config.ServerConfig{ID: "srv-1", Alias: "web01", Host: "10.0.0.1", Port: 22, User: "root", RecordSession: true}
+	client := NewClient(server, configManager)
+
+	rec, err := client.startRecording(int(os.Stdin.Fd()))
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	require.NoError(t, rec.Close())
+
+	infos, err := recording.List(configManager.ConfigDir())
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "srv-1", infos[0].ServerID)
+	assert.Equal(t, "web01", infos[0].Server)
+
+	// 未启用时不创建录制
+	rec, err = NewClient(&config.ServerConfig{}, configManager).startRecording(0)
+	assert.NoError(t, err)
+	assert.Nil(t, rec)
+}
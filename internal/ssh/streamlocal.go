@@ -0,0 +1,146 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Endpoint 转发端点
+type Endpoint struct {
+	Network string      // 网络类型：tcp 或 unix
+	Address string      // host:port 或 Unix 套接字路径
+	Mode    os.FileMode // 本地Unix套接字权限，为0时不修改
+}
+
+// TCPEndpoint 创建TCP端点
+func TCPEndpoint(address string) Endpoint {
+	return Endpoint{Network: "tcp", Address: address}
+}
+
+// UnixEndpoint 创建Unix套接字端点
+func UnixEndpoint(path string, mode os.FileMode) Endpoint {
+	return Endpoint{Network: "unix", Address: path, Mode: mode}
+}
+
+// IsUnix 是否为Unix套接字端点
+func (e Endpoint) IsUnix() bool {
+	return e.Network == "unix"
+}
+
+// String 端点的显示形式
+func (e Endpoint) String() string {
+	if e.IsUnix() {
+		return "unix:" + e.Address
+	}
+	return e.Address
+}
+
+// ListenLocal 在本地端点上监听，Unix套接字会清理残留文件并设置权限
+func ListenLocal(ep Endpoint) (net.Listener, error) {
+	if !ep.IsUnix() {
+		return net.Listen(ep.Network, ep.Address)
+	}
+
+	if err := removeStaleSocket(ep.Address); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", ep.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// 关闭监听器时删除套接字文件
+	if unixListener, ok := listener.(*net.UnixListener); ok {
+		unixListener.SetUnlinkOnClose(true)
+	}
+
+	if ep.Mode != 0 {
+		if err := os.Chmod(ep.Address, ep.Mode); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("设置套接字权限失败: %w", err)
+		}
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket 删除无人监听的残留套接字文件，拒绝覆盖普通文件或仍在使用的套接字
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("检查套接字文件失败: %w", err)
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s 已存在且不是套接字文件", path)
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("套接字 %s 正在被其他进程使用", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("删除残留套接字文件失败: %w", err)
+	}
+	return nil
+}
+
+// listenRemote 在远程端点上监听
+func (c *Client) listenRemote(ep Endpoint) (net.Listener, error) {
+	if !ep.IsUnix() {
+		address, err := RemoteBindAddress(ep.Address)
+		if err != nil {
+			return nil, err
+		}
+		ep.Address = address
+	}
+
+	listener, err := c.conn.Listen(ep.Network, ep.Address)
+	if err != nil {
+		if ep.IsUnix() {
+			return nil, fmt.Errorf("%w（远程套接字 %s 可能已存在，或服务器未开启 AllowStreamLocalForwarding；可在服务器上设置 StreamLocalBindUnlink yes）", err, ep.Address)
+		}
+		return nil, fmt.Errorf("%w（%s）", err, remoteTCPForwardHint(ep.Address))
+	}
+
+	// 关闭时只取消转发，不在服务器上执行命令删除套接字文件；残留文件由服务器的 StreamLocalBindUnlink 处理
+	return listener, nil
+}
+
+// RemoteBindAddress 规范化远程监听地址，空地址和 * 表示监听服务器所有网卡
+func RemoteBindAddress(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("无效的远程监听地址 '%s': %w", address, err)
+	}
+	if host == "" || host == "*" {
+		host = "0.0.0.0"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// remoteTCPForwardHint 服务器拒绝 tcpip-forward 请求时的常见原因
+func remoteTCPForwardHint(address string) string {
+	host, port, _ := net.SplitHostPort(address)
+	var reasons []string
+	if p, err := strconv.Atoi(port); err == nil && p > 0 && p < 1024 {
+		reasons = append(reasons, fmt.Sprintf("端口 %d 为特权端口，仅 root 用户可以监听", p))
+	} else if port != "0" {
+		reasons = append(reasons, fmt.Sprintf("服务器上的端口 %s 已被占用", port))
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		reasons = append(reasons, fmt.Sprintf("监听非回环地址 %s 需要服务器设置 GatewayPorts clientspecified 或 yes", host))
+	}
+	reasons = append(reasons, "服务器设置了 AllowTcpForwarding no/local 或 PermitListen 限制了远程转发")
+	return "服务器拒绝了远程转发请求，可能原因：" + strings.Join(reasons, "；")
+}
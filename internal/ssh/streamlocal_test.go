@@ -0,0 +1,176 @@
+package ssh
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketDir 创建较短的临时目录，避免Unix套接字路径超长
+func socketDir(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "gotssh")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// waitForSocket 等待套接字文件出现
+func waitForSocket(t *testing.T, path string) {
+	require.Eventually(t, func() bool {
+		info, err := os.Stat(path)
+		return err == nil && info.Mode()&os.ModeSocket != 0
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+// assertEcho 通过连接发送一行数据并校验回显
+func assertEcho(t *testing.T, network, address string) {
+	conn, err := net.DialTimeout(network, address, 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello gotssh\n"))
+	require.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello gotssh\n", line)
+}
+
+// TestEndpoint 测试转发端点
+func TestEndpoint(t *testing.T) {
+	t.Run("TCP端点", func(t *testing.T) {
+		ep := TCPEndpoint("127.0.0.1:8080")
+		assert.False(t, ep.IsUnix())
+		assert.Equal(t, "127.0.0.1:8080", ep.String())
+	})
+
+	t.Run("Unix套接字端点", func(t *testing.T) {
+		ep := UnixEndpoint("/var/run/docker.sock", 0600)
+		assert.True(t, ep.IsUnix())
+		assert.Equal(t, "unix:/var/run/docker.sock", ep.String())
+		assert.Equal(t, os.FileMode(0600), ep.Mode)
+	})
+}
+
+// TestListenLocalUnix 测试本地Unix套接字监听
+func TestListenLocalUnix(t *testing.T) {
+	t.Run("设置权限并在关闭时删除", func(t *testing.T) {
+		path := filepath.Join(socketDir(t), "local.sock")
+
+		listener, err := ListenLocal(UnixEndpoint(path, 0660))
+		require.NoError(t, err)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+
+		listener.Close()
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("清理残留套接字", func(t *testing.T) {
+		path := filepath.Join(socketDir(t), "stale.sock")
+
+		// 创建残留套接字文件
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		stale.Close()
+
+		listener, err := ListenLocal(UnixEndpoint(path, 0))
+		require.NoError(t, err)
+		listener.Close()
+	})
+
+	t.Run("拒绝覆盖普通文件", func(t *testing.T) {
+		path := filepath.Join(socketDir(t), "file.sock")
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+
+		_, err := ListenLocal(UnixEndpoint(path, 0))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "不是套接字文件")
+	})
+
+	t.Run("拒绝使用中的套接字", func(t *testing.T) {
+		path := filepath.Join(socketDir(t), "busy.sock")
+		startEchoServer(t, "unix", path)
+
+		_, err := ListenLocal(UnixEndpoint(path, 0))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "正在被其他进程使用")
+	})
+}
+
+// TestUnixSocketForwarding 测试通过SSH服务器转发Unix套接字
+func TestUnixSocketForwarding(t *testing.T) {
+	t.Run("本地套接字转发到远程套接字", func(t *testing.T) {
+		server := startTestSSHServer(t)
+		client := server.Connect(t)
+
+		dir := socketDir(t)
+		remotePath := filepath.Join(dir, "remote.sock")
+		localPath := filepath.Join(dir, "local.sock")
+		startEchoServer(t, "unix", remotePath)
+
+		go client.LocalForwardWithTimeout(UnixEndpoint(localPath, 0600), UnixEndpoint(remotePath, 0), 200*time.Millisecond)
+		waitForSocket(t, localPath)
+
+		assertEcho(t, "unix", localPath)
+	})
+
+	t.Run("本地TCP端口转发到远程套接字", func(t *testing.T) {
+		server := startTestSSHServer(t)
+		client := server.Connect(t)
+
+		remotePath := filepath.Join(socketDir(t), "remote.sock")
+		startEchoServer(t, "unix", remotePath)
+
+		// 预先获取空闲端口
+		probe, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		localAddr := probe.Addr().String()
+		probe.Close()
+
+		go client.LocalForwardWithTimeout(TCPEndpoint(localAddr), UnixEndpoint(remotePath, 0), 200*time.Millisecond)
+		require.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", localAddr)
+			if err == nil {
+				conn.Close()
+			}
+			return err == nil
+		}, 5*time.Second, 20*time.Millisecond)
+
+		assertEcho(t, "tcp", localAddr)
+	})
+
+	t.Run("远程套接字转发到本地TCP端口", func(t *testing.T) {
+		server := startTestSSHServer(t)
+		client := server.Connect(t)
+
+		echo := startEchoServer(t, "tcp", "127.0.0.1:0")
+		remotePath := filepath.Join(socketDir(t), "remote.sock")
+
+		go client.RemoteForwardWithTimeout(UnixEndpoint(remotePath, 0), TCPEndpoint(echo.Addr().String()), time.Second)
+		waitForSocket(t, remotePath)
+
+		assertEcho(t, "unix", remotePath)
+	})
+
+	t.Run("远程套接字监听失败时给出提示", func(t *testing.T) {
+		server := startTestSSHServer(t)
+		server.rejectFwd = true
+		client := server.Connect(t)
+
+		err := client.RemoteForwardWithTimeout(UnixEndpoint("/tmp/gotssh-rejected.sock", 0), TCPEndpoint("127.0.0.1:1"), time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "StreamLocalBindUnlink")
+	})
+}
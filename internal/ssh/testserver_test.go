@@ -0,0 +1,397 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"gotssh/internal/config"
+)
+
+// testSSHServer 用于测试的最小SSH服务器，支持会话执行、TCP和Unix套接字转发
+type testSSHServer struct {
+	t        *testing.T
+	listener net.Listener
+	config   *ssh.ServerConfig
+
+	mu        sync.Mutex
+	forwards  map[string]net.Listener
+	rejectFwd bool // 拒绝所有远程转发请求
+}
+
+// startTestSSHServer 启动测试SSH服务器，密码为 test-password
+func startTestSSHServer(t *testing.T) *testSSHServer {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) == "test-password" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("密码错误")
+		},
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &testSSHServer{
+		t:        t,
+		listener: listener,
+		config:   serverConfig,
+		forwards: make(map[string]net.Listener),
+	}
+	t.Cleanup(server.Close)
+
+	go server.serve()
+	return server
+}
+
+// ServerConfig 返回连接该测试服务器的服务器配置
+func (s *testSSHServer) ServerConfig() *config.ServerConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return &config.ServerConfig{
+		ID:       "test-server-id",
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		User:     "test-user",
+		AuthType: config.AuthTypePassword,
+		Password: "test-password",
+	}
+}
+
+// Connect 创建已连接到测试服务器的客户端
+func (s *testSSHServer) Connect(t *testing.T) *Client {
+	client := NewClient(s.ServerConfig(), nil)
+	require.NoError(t, client.Connect())
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// Close 关闭测试服务器
+func (s *testSSHServer) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, l := range s.forwards {
+		l.Close()
+		delete(s.forwards, key)
+	}
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *testSSHServer) handleConn(conn net.Conn) {
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+
+	go s.handleGlobalRequests(sshConn, reqs)
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			go s.handleSession(newChannel)
+		case "direct-tcpip":
+			var payload struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+				newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			go s.handleDirect(newChannel, "tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+		case "direct-streamlocal@openssh.com":
+			var payload struct {
+				SocketPath string
+				Reserved0  string
+				Reserved1  uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+				newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			go s.handleDirect(newChannel, "unix", payload.SocketPath)
+		default:
+			newChannel.Reject(ssh.UnknownChannelType, "不支持的通道类型")
+		}
+	}
+}
+
+func (s *testSSHServer) handleDirect(newChannel ssh.NewChannel, network, address string) {
+	target, err := net.Dial(network, address)
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	pipeTestConns(channel, target)
+}
+
+func (s *testSSHServer) handleSession(newChannel ssh.NewChannel) {
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+
+	var env []string
+	for req := range reqs {
+		switch req.Type {
+		case "exec":
+			var payload struct{ Command string }
+			ssh.Unmarshal(req.Payload, &payload)
+			req.Reply(true, nil)
+			s.exec(channel, reqs, payload.Command, env)
+			return
+		case "env":
+			var payload struct{ Name, Value string }
+			ssh.Unmarshal(req.Payload, &payload)
+			env = append(env, payload.Name+"="+payload.Value)
+			req.Reply(true, nil)
+		default:
+			req.Reply(req.Type == "pty-req", nil)
+		}
+	}
+}
+
+// testSignals 测试服务器支持的 signal 请求
+var testSignals = map[string]os.Signal{"INT": syscall.SIGINT, "TERM": syscall.SIGTERM}
+
+// exec 用 sh 执行命令，标准输入、输出和错误连接到通道，处理执行期间的 signal 请求，
+// 结束后发送 exit-status 或 exit-signal
+func (s *testSSHServer) exec(channel ssh.Channel, reqs <-chan *ssh.Request, command string, env []string) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = channel
+	cmd.Stderr = channel.Stderr()
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{127}))
+		return
+	}
+	go func() {
+		io.Copy(stdin, channel)
+		stdin.Close()
+	}()
+	go func() {
+		for req := range reqs {
+			if req.Type == "signal" {
+				var payload struct{ Signal string }
+				ssh.Unmarshal(req.Payload, &payload)
+				if sig, ok := testSignals[payload.Signal]; ok {
+					cmd.Process.Signal(sig)
+				}
+			}
+			req.Reply(false, nil)
+		}
+	}()
+
+	status := 0
+	if err := cmd.Wait(); err != nil {
+		status = 1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+				for name, sig := range testSignals {
+					if sig == ws.Signal() {
+						channel.SendRequest("exit-signal", false, ssh.Marshal(struct {
+							Signal     string
+							CoreDumped bool
+							Error      string
+							Lang       string
+						}{Signal: name}))
+						return
+					}
+				}
+			}
+			status = exitErr.ExitCode()
+		}
+	}
+	channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+}
+
+func (s *testSSHServer) handleGlobalRequests(conn *ssh.ServerConn, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		switch req.Type {
+		case "tcpip-forward":
+			var payload struct {
+				Addr string
+				Port uint32
+			}
+			if s.rejectFwd || ssh.Unmarshal(req.Payload, &payload) != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			listener, err := net.Listen("tcp", net.JoinHostPort(payload.Addr, strconv.Itoa(int(payload.Port))))
+			if err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			port := uint32(listener.Addr().(*net.TCPAddr).Port)
+			key := net.JoinHostPort(payload.Addr, strconv.Itoa(int(port)))
+			s.addForward(key, listener)
+			req.Reply(true, ssh.Marshal(struct{ Port uint32 }{port}))
+
+			go s.acceptForwarded(conn, listener, "forwarded-tcpip", func(c net.Conn) []byte {
+				origin := c.RemoteAddr().(*net.TCPAddr)
+				return ssh.Marshal(struct {
+					Addr       string
+					Port       uint32
+					OriginAddr string
+					OriginPort uint32
+				}{payload.Addr, port, origin.IP.String(), uint32(origin.Port)})
+			})
+		case "cancel-tcpip-forward":
+			var payload struct {
+				Addr string
+				Port uint32
+			}
+			ssh.Unmarshal(req.Payload, &payload)
+			s.removeForward(net.JoinHostPort(payload.Addr, strconv.Itoa(int(payload.Port))))
+			req.Reply(true, nil)
+		case "streamlocal-forward@openssh.com":
+			var payload struct{ SocketPath string }
+			if s.rejectFwd || ssh.Unmarshal(req.Payload, &payload) != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			listener, err := net.Listen("unix", payload.SocketPath)
+			if err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			s.addForward(payload.SocketPath, listener)
+			req.Reply(true, nil)
+
+			go s.acceptForwarded(conn, listener, "forwarded-streamlocal@openssh.com", func(net.Conn) []byte {
+				return ssh.Marshal(struct {
+					SocketPath string
+					Reserved0  string
+				}{payload.SocketPath, ""})
+			})
+		case "cancel-streamlocal-forward@openssh.com":
+			var payload struct{ SocketPath string }
+			ssh.Unmarshal(req.Payload, &payload)
+			s.removeForward(payload.SocketPath)
+			req.Reply(true, nil)
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+func (s *testSSHServer) addForward(key string, listener net.Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forwards[key] = listener
+}
+
+func (s *testSSHServer) removeForward(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if listener, ok := s.forwards[key]; ok {
+		listener.Close()
+		delete(s.forwards, key)
+	}
+}
+
+func (s *testSSHServer) acceptForwarded(conn *ssh.ServerConn, listener net.Listener, channelType string, extra func(net.Conn) []byte) {
+	for {
+		c, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			channel, reqs, err := conn.OpenChannel(channelType, extra(c))
+			if err != nil {
+				c.Close()
+				return
+			}
+			go ssh.DiscardRequests(reqs)
+			pipeTestConns(channel, c)
+		}()
+	}
+}
+
+// pipeTestConns 双向转发并在两端结束后关闭
+func pipeTestConns(channel ssh.Channel, conn net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(channel, conn)
+		channel.CloseWrite()
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(conn, channel)
+		if tcpConn, ok := conn.(interface{ CloseWrite() error }); ok {
+			tcpConn.CloseWrite()
+		}
+	}()
+	wg.Wait()
+	channel.Close()
+	conn.Close()
+}
+
+// startEchoServer 启动回显服务器
+func startEchoServer(t *testing.T, network, address string) net.Listener {
+	listener, err := net.Listen(network, address)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener
+}
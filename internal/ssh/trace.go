@@ -0,0 +1,115 @@
+package ssh
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"net"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"gotssh/internal/logging"
+)
+
+// SetLogger 设置日志记录器，为 nil 时丢弃日志
+func (c *Client) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = logging.Nop()
+	}
+	c.logger = logger
+}
+
+// Logger 获取日志记录器
+func (c *Client) Logger() *slog.Logger {
+	if c.logger == nil {
+		return logging.Nop()
+	}
+	return c.logger
+}
+
+// trace 记录SSH握手跟踪日志（相当于 ssh -vvv 的输出）
+func (c *Client) trace(msg string, args ...any) {
+	c.Logger().Log(context.Background(), logging.LevelTrace, msg, args...)
+}
+
+// traceAlgorithms 记录客户端提议的算法，未指定时使用 x/crypto/ssh 的默认值
+func (c *Client) traceAlgorithms(cfg *ssh.ClientConfig) {
+	algorithms := func(names []string) string {
+		if len(names) == 0 {
+			return "默认"
+		}
+		return strings.Join(names, ",")
+	}
+	c.trace("客户端算法提议",
+		"kex", algorithms(cfg.KeyExchanges),
+		"ciphers", algorithms(cfg.Ciphers),
+		"macs", algorithms(cfg.MACs),
+		"host_key", algorithms(cfg.HostKeyAlgorithms),
+		"auth_methods", len(cfg.Auth))
+}
+
+// traceHostKey 包装主机密钥回调，记录服务器主机密钥
+func (c *Client) traceHostKey(inner ssh.HostKeyCallback) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		c.trace("服务器主机密钥",
+			"host", hostname,
+			"remote", remote.String(),
+			"type", key.Type(),
+			"fingerprint", ssh.FingerprintSHA256(key))
+		err := inner(hostname, remote, key)
+		if err != nil {
+			c.Logger().Debug("主机密钥验证失败", "host", hostname, "error", err)
+		}
+		return err
+	}
+}
+
+// traceBanner 记录服务器在认证前发送的横幅
+func (c *Client) traceBanner(message string) error {
+	c.Logger().Debug("服务器横幅", "banner", strings.TrimSpace(message))
+	return nil
+}
+
+// traceHandshake 记录握手完成后的连接信息
+func (c *Client) traceHandshake(conn ssh.Conn) {
+	c.Logger().Debug("SSH握手完成",
+		"user", conn.User(),
+		"client_version", string(conn.ClientVersion()),
+		"server_version", string(conn.ServerVersion()))
+	c.trace("会话标识", "session_id", hex.EncodeToString(conn.SessionID()))
+}
+
+// passwordAuth 密码认证，尝试时记录跟踪日志
+func (c *Client) passwordAuth(password string) ssh.AuthMethod {
+	return ssh.PasswordCallback(func() (string, error) {
+		c.trace("尝试认证", "method", "password")
+		return password, nil
+	})
+}
+
+// publicKeysAuth 公钥认证，记录提供的每个公钥
+func (c *Client) publicKeysAuth(signers ...ssh.Signer) ssh.AuthMethod {
+	return c.publicKeysCallbackAuth("publickey", func() ([]ssh.Signer, error) {
+		return signers, nil
+	})
+}
+
+// publicKeysCallbackAuth 通过回调获取公钥的认证（如SSH代理），记录提供的每个公钥
+func (c *Client) publicKeysCallbackAuth(source string, getSigners func() ([]ssh.Signer, error)) ssh.AuthMethod {
+	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		signers, err := getSigners()
+		if err != nil {
+			c.Logger().Debug("获取公钥失败", "source", source, "error", err)
+			return nil, err
+		}
+		for _, signer := range signers {
+			c.trace("尝试认证",
+				"method", "publickey",
+				"source", source,
+				"type", signer.PublicKey().Type(),
+				"fingerprint", ssh.FingerprintSHA256(signer.PublicKey()))
+		}
+		return signers, nil
+	})
+}
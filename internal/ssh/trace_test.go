@@ -0,0 +1,54 @@
+package ssh
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/config"
+	"gotssh/internal/logging"
+)
+
+// TestHandshakeTrace 测试SSH握手跟踪日志
+func TestHandshakeTrace(t *testing.T) {
+	server := startTestSSHServer(t)
+
+	var buf bytes.Buffer
+	handler, err := logging.NewHandler(&buf, logging.FormatText, logging.LevelTrace)
+	require.NoError(t, err)
+
+	client := NewClient(server.ServerConfig(), nil)
+	client.SetLogger(slog.New(handler))
+	require.NoError(t, client.Connect())
+	defer client.Close()
+
+	output := buf.String()
+	for _, msg := range []string{"客户端算法提议", "TCP连接已建立", "服务器主机密钥", "尝试认证", "SSH握手完成", "会话标识"} {
+		assert.Contains(t, output, msg)
+	}
+	assert.Contains(t, output, "fingerprint=SHA256:")
+	assert.Contains(t, output, "method=password")
+	assert.NotContains(t, output, "test-password")
+}
+
+// TestClientLoggerFromConfigManager 测试SSH客户端沿用配置管理器的日志记录器
+func TestClientLoggerFromConfigManager(t *testing.T) {
+	configManager := createTestConfigManager(t)
+
+	var buf bytes.Buffer
+	handler, err := logging.NewHandler(&buf, logging.FormatText, slog.LevelDebug)
+	require.NoError(t, err)
+	configManager.SetLogger(slog.New(handler))
+
+	client := NewClient(&config.ServerConfig{Host: "127.0.0.1", Port: 2222}, configManager)
+	client.Logger().Debug("测试")
+	assert.Contains(t, buf.String(), "server=127.0.0.1:2222")
+
+	// 未提供配置管理器时丢弃日志
+	assert.NotPanics(t, func() {
+		NewClient(&config.ServerConfig{Host: "127.0.0.1", Port: 22}, nil).Logger().Error("丢弃")
+	})
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package ssh
+
+import "golang.org/x/crypto/ssh"
+
+// watchWindowSize Windows 没有窗口大小变化的信号，远程伪终端保持开始时的大小
+func watchWindowSize(fd int, session *ssh.Session) func() {
+	return func() {}
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"gotssh/internal/config"
+)
+
+// backupSections 备份中各段的显示名称，按恢复顺序排列
+var backupSections = []struct{ name, title string }{
+	{"credentials", "凭证"},
+	{"server_groups", "服务器分组"},
+	{"server_templates", "服务器模板"},
+	{"servers", "服务器"},
+	{"port_forwards", "端口转发"},
+	{"forward_groups", "转发组"},
+	{"inventory_providers", "动态清单"},
+}
+
+// restoreMarks 恢复计划中各操作的标记
+var restoreMarks = map[config.RestoreAction]string{
+	config.RestoreAdd:       "+",
+	config.RestoreOverwrite: "~",
+	config.RestoreRename:    "+",
+	config.RestoreSkip:      "✗",
+}
+
+// FormatBackupInfo 备份的概况：创建时间、来源主机和各段的条目数
+func FormatBackupInfo(info *config.BackupInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "备份时间: %s", info.CreatedAt.Format("2006-01-02 15:04:05"))
+	if info.Hostname != "" {
+		fmt.Fprintf(&b, "（%s）", info.Hostname)
+	}
+	b.WriteString("\n")
+	var counts []string
+	for _, s := range backupSections {
+		if n := info.Counts[s.name]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", s.title, n))
+		}
+	}
+	if info.Settings > 0 {
+		counts = append(counts, fmt.Sprintf("设置 %d 项", info.Settings))
+	}
+	if info.Keys > 0 {
+		counts = append(counts, fmt.Sprintf("私钥文件 %d", info.Keys))
+	}
+	if len(counts) == 0 {
+		counts = append(counts, "空")
+	}
+	fmt.Fprintf(&b, "内容: %s\n", strings.Join(counts, "，"))
+	return b.String()
+}
+
+// FormatRestorePlan 恢复计划的预览，按段列出新增、覆盖、重命名和跳过的条目，以及设置和私钥文件
+func FormatRestorePlan(plan *config.RestorePlan) string {
+	var b strings.Builder
+	if plan.Removed > 0 {
+		fmt.Fprintf(&b, "\n替换模式：将删除用户配置中的 %d 个条目\n", plan.Removed)
+	}
+	for _, s := range backupSections {
+		var items []*config.RestoreItem
+		for _, item := range plan.Items {
+			if item.Section == s.name {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n=== %s ===\n", s.title)
+		for _, item := range items {
+			line := fmt.Sprintf("  %s %s", restoreMarks[item.Action], item.Label)
+			switch item.Action {
+			case config.RestoreOverwrite:
+				line += " (覆盖)"
+			case config.RestoreSkip:
+				line += fmt.Sprintf(" (跳过: %s)", item.Note)
+			}
+			b.WriteString(line + "\n")
+			if item.Action != config.RestoreSkip && item.Note != "" {
+				fmt.Fprintf(&b, "      %s\n", item.Note)
+			}
+		}
+	}
+	if len(plan.Settings) > 0 {
+		fmt.Fprintf(&b, "\n=== 设置 ===\n  恢复 %d 项设置\n", len(plan.Settings))
+	}
+	if len(plan.Keys) > 0 || len(plan.Notes) > 0 {
+		b.WriteString("\n=== 私钥文件 ===\n")
+		for _, key := range plan.Keys {
+			fmt.Fprintf(&b, "  + %s\n", key.Path)
+		}
+		for _, note := range plan.Notes {
+			fmt.Fprintf(&b, "  %s\n", note)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gotssh/internal/config"
+)
+
+// TestFormatBackupInfo 测试备份概况显示时间、来源主机和各段的条目数
+func TestFormatBackupInfo(t *testing.T) {
+	info := &config.BackupInfo{
+		CreatedAt: time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local),
+		Hostname:  "laptop",
+		Counts:    map[string]int{"servers": 3, "credentials": 1},
+		Settings:  2,
+		Keys:      1,
+	}
+	assert.Equal(t, "备份时间: 2026-10-18 09:30:00（laptop）\n内容: 凭证 1，服务器 3，设置 2 项，私钥文件 1\n", FormatBackupInfo(info))
+	assert.Contains(t, FormatBackupInfo(&config.BackupInfo{}), "内容: 空\n")
+}
+
+// TestFormatRestorePlan 测试恢复预览按段列出条目的操作、设置和私钥文件
+func TestFormatRestorePlan(t *testing.T) {
+	plan := &config.RestorePlan{
+		Removed: 2,
+		Items: []*config.RestoreItem{
+			{Section: "credentials", Label: "deploy", Action: config.RestoreAdd},
+			{Section: "servers", Label: "web", Action: config.RestoreOverwrite},
+			{Section: "servers", Label: "bastion", Action: config.RestoreRename, Note: "别名 'bastion' 已被使用，改为 'bastion-2'"},
+			{Section: "servers", Label: "dev", Action: config.RestoreSkip, Note: "与现有条目相同"},
+		},
+		Settings: map[string]interface{}{"default_user": "admin"},
+		Keys:     []*config.BackupKey{{Path: "~/.ssh/deploy"}},
+		Notes:    []string{"私钥文件 ~/.ssh/id_rsa 已存在且内容不同，不覆盖"},
+	}
+
+	preview := FormatRestorePlan(plan)
+	assert.Contains(t, preview, "替换模式：将删除用户配置中的 2 个条目\n")
+	assert.Contains(t, preview, "=== 凭证 ===\n  + deploy\n")
+	assert.Contains(t, preview, "=== 服务器 ===\n  ~ web (覆盖)\n  + bastion\n      别名 'bastion' 已被使用，改为 'bastion-2'\n  ✗ dev (跳过: 与现有条目相同)\n")
+	assert.Contains(t, preview, "=== 设置 ===\n  恢复 1 项设置\n")
+	assert.Contains(t, preview, "=== 私钥文件 ===\n  + ~/.ssh/deploy\n  私钥文件 ~/.ssh/id_rsa 已存在且内容不同，不覆盖\n")
+}
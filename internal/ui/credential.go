@@ -25,6 +25,8 @@ func NewCredentialMenu(configManager *config.Manager) *CredentialMenu {
 // ShowCredentialMenu 显示凭证管理菜单
 func (cm *CredentialMenu) ShowCredentialMenu() error {
 	for {
+		reloadConfig(cm.configManager)
+
 		prompt := promptui.Select{
 			Label: "凭证管理",
 			Items: []string{
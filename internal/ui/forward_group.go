@@ -0,0 +1,378 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"gotssh/internal/config"
+	"gotssh/internal/forward"
+
+	"github.com/manifoldco/promptui"
+)
+
+// ShowForwardGroupMenu 显示转发组管理菜单
+func (m *Menu) ShowForwardGroupMenu() error {
+	for {
+		reloadConfig(m.configManager)
+
+		prompt := promptui.Select{
+			Label: "转发组管理",
+			Items: []string{
+				"添加转发组",
+				"查看转发组列表",
+				"启动转发组",
+				"停止转发组",
+				"编辑转发组",
+				"删除转发组",
+				"返回上级菜单",
+			},
+		}
+
+		_, result, err := prompt.Run()
+		if err != nil {
+			return fmt.Errorf("选择转发组管理菜单失败: %w", err)
+		}
+
+		switch result {
+		case "添加转发组":
+			if err := m.AddForwardGroup(); err != nil {
+				fmt.Printf("添加转发组失败: %v\n", err)
+			}
+		case "查看转发组列表":
+			m.ShowForwardGroupList()
+		case "启动转发组":
+			if err := m.StartForwardGroup(); err != nil {
+				fmt.Printf("启动转发组失败: %v\n", err)
+			}
+		case "停止转发组":
+			if err := m.StopForwardGroup(); err != nil {
+				fmt.Printf("停止转发组失败: %v\n", err)
+			}
+		case "编辑转发组":
+			if err := m.EditForwardGroup(); err != nil {
+				fmt.Printf("编辑转发组失败: %v\n", err)
+			}
+		case "删除转发组":
+			if err := m.DeleteForwardGroup(); err != nil {
+				fmt.Printf("删除转发组失败: %v\n", err)
+			}
+		case "返回上级菜单":
+			return nil
+		}
+	}
+}
+
+// AddForwardGroup 添加转发组
+func (m *Menu) AddForwardGroup() error {
+	aliases := m.forwardAliases()
+	if len(aliases) == 0 {
+		fmt.Println("请先添加带别名的端口转发")
+		return nil
+	}
+
+	// 名称
+	namePrompt := promptui.Prompt{
+		Label: "转发组名称",
+		Validate: func(input string) error {
+			if strings.TrimSpace(input) == "" {
+				return fmt.Errorf("转发组名称不能为空")
+			}
+			if strings.HasPrefix(input, "@") {
+				return fmt.Errorf("转发组名称不能以 @ 开头")
+			}
+			return nil
+		},
+	}
+	name, err := namePrompt.Run()
+	if err != nil {
+		return err
+	}
+
+	group := config.NewForwardGroup(strings.TrimSpace(name))
+
+	if err := m.configureForwardGroup(group, aliases); err != nil {
+		return err
+	}
+
+	if err := m.configManager.AddForwardGroup(group); err != nil {
+		return fmt.Errorf("保存转发组配置失败: %w", err)
+	}
+
+	fmt.Printf("转发组 %s 添加成功！(%d 个端口转发)\n", group.Name, len(group.Forwards))
+	return nil
+}
+
+// configureForwardGroup 交互式配置转发组成员、依赖和描述
+func (m *Menu) configureForwardGroup(group *config.ForwardGroup, aliases []string) error {
+	// 选择成员
+	members, err := selectAliases("选择组内端口转发", aliases, group.Forwards)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("转发组至少需要一个端口转发")
+	}
+	group.Forwards = members
+
+	// 启动依赖（可选）
+	deps := make(map[string][]string)
+	if len(members) > 1 {
+		depPrompt := promptui.Select{
+			Label: "是否配置启动依赖",
+			Items: []string{"否", "是"},
+		}
+		_, depResult, err := depPrompt.Run()
+		if err != nil {
+			return err
+		}
+
+		if depResult == "是" {
+			for _, alias := range members {
+				var candidates []string
+				for _, other := range members {
+					if other != alias {
+						candidates = append(candidates, other)
+					}
+				}
+
+				selected, err := selectAliases(fmt.Sprintf("[%s] 需要先启动", alias), candidates, group.Dependencies[alias])
+				if err != nil {
+					return err
+				}
+				if len(selected) > 0 {
+					deps[alias] = selected
+				}
+			}
+		}
+	}
+	group.Dependencies = deps
+
+	if _, err := group.Stages(); err != nil {
+		return err
+	}
+
+	// 描述
+	descPrompt := promptui.Prompt{
+		Label:   "描述 (可选)",
+		Default: group.Description,
+	}
+	desc, err := descPrompt.Run()
+	if err != nil {
+		return err
+	}
+	group.Description = desc
+
+	return nil
+}
+
+// ShowForwardGroupList 显示转发组列表
+func (m *Menu) ShowForwardGroupList() {
+	if m.configManager == nil {
+		fmt.Println("配置管理器未初始化")
+		return
+	}
+
+	groups := m.configManager.ListForwardGroups()
+	if len(groups) == 0 {
+		fmt.Println("暂无转发组配置")
+		return
+	}
+
+	fmt.Println("\n=== 转发组列表 ===")
+	for i, group := range groups {
+		fmt.Printf("%d. [%s] %s", i+1, group.Name, strings.Join(group.Forwards, ", "))
+		if len(group.Dependencies) > 0 {
+			if stages, err := group.Stages(); err == nil {
+				var parts []string
+				for _, stage := range stages {
+					parts = append(parts, strings.Join(stage, "+"))
+				}
+				fmt.Printf(" (启动顺序: %s)", strings.Join(parts, " -> "))
+			}
+		}
+		if group.Description != "" {
+			fmt.Printf(" - %s", group.Description)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+}
+
+// StartForwardGroup 在后台启动转发组
+func (m *Menu) StartForwardGroup() error {
+	group, err := m.selectForwardGroup("选择要启动的转发组")
+	if err != nil || group == nil {
+		return err
+	}
+
+	fmt.Printf("正在启动转发组 [%s] ...\n", group.Name)
+
+	forwards, err := m.forwardManager.StartForwardGroup(group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(forward.FormatGroupStatus(group, forwards))
+	fmt.Printf("✅ 转发组已启动！\n")
+	return nil
+}
+
+// StopForwardGroup 停止转发组
+func (m *Menu) StopForwardGroup() error {
+	group, err := m.selectForwardGroup("选择要停止的转发组")
+	if err != nil || group == nil {
+		return err
+	}
+
+	if err := m.forwardManager.StopForwardGroup(group); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 转发组 [%s] 已停止！\n", group.Name)
+	return nil
+}
+
+// EditForwardGroup 编辑转发组
+func (m *Menu) EditForwardGroup() error {
+	group, err := m.selectForwardGroup("选择要编辑的转发组")
+	if err != nil || group == nil {
+		return err
+	}
+
+	edited := *group
+	edited.Forwards = append([]string(nil), group.Forwards...)
+
+	namePrompt := promptui.Prompt{
+		Label:   "转发组名称",
+		Default: group.Name,
+		Validate: func(input string) error {
+			if strings.TrimSpace(input) == "" {
+				return fmt.Errorf("转发组名称不能为空")
+			}
+			return nil
+		},
+	}
+	name, err := namePrompt.Run()
+	if err != nil {
+		return err
+	}
+	edited.Name = strings.TrimSpace(name)
+
+	if err := m.configureForwardGroup(&edited, m.forwardAliases()); err != nil {
+		return err
+	}
+
+	if err := m.configManager.UpdateForwardGroup(group.ID, &edited); err != nil {
+		return fmt.Errorf("保存转发组配置失败: %w", err)
+	}
+
+	fmt.Printf("✅ 转发组 %s 配置已更新！\n", edited.Name)
+	return nil
+}
+
+// DeleteForwardGroup 删除转发组
+func (m *Menu) DeleteForwardGroup() error {
+	group, err := m.selectForwardGroup("选择要删除的转发组")
+	if err != nil || group == nil {
+		return err
+	}
+
+	confirmPrompt := promptui.Select{
+		Label: fmt.Sprintf("确定要删除转发组 %s 吗？（不会删除组内端口转发）", group.Name),
+		Items: []string{"是", "否"},
+	}
+	_, confirmResult, err := confirmPrompt.Run()
+	if err != nil {
+		return err
+	}
+
+	if confirmResult == "是" {
+		if err := m.configManager.DeleteForwardGroup(group.ID); err != nil {
+			return fmt.Errorf("删除转发组失败: %w", err)
+		}
+		fmt.Printf("转发组 %s 已删除\n", group.Name)
+	}
+
+	return nil
+}
+
+// selectForwardGroup 选择转发组，没有转发组时返回 nil
+func (m *Menu) selectForwardGroup(label string) (*config.ForwardGroup, error) {
+	groups := m.configManager.ListForwardGroups()
+	if len(groups) == 0 {
+		fmt.Println("暂无转发组配置")
+		return nil, nil
+	}
+
+	var items []string
+	for _, group := range groups {
+		items = append(items, fmt.Sprintf("[%s] %s", group.Name, strings.Join(group.Forwards, ", ")))
+	}
+
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+	index, _, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return groups[index], nil
+}
+
+// forwardAliases 获取所有带别名的端口转发别名
+func (m *Menu) forwardAliases() []string {
+	var aliases []string
+	for _, pf := range m.configManager.ListPortForwards() {
+		if pf.Alias != "" {
+			aliases = append(aliases, pf.Alias)
+		}
+	}
+	return aliases
+}
+
+// selectAliases 通过反复选择实现多选，选择"完成"结束
+func selectAliases(label string, candidates, selected []string) ([]string, error) {
+	chosen := make(map[string]bool)
+	for _, alias := range selected {
+		chosen[alias] = true
+	}
+
+	const done = "完成"
+	for {
+		items := []string{done}
+		for _, alias := range candidates {
+			mark := "[ ]"
+			if chosen[alias] {
+				mark = "[x]"
+			}
+			items = append(items, fmt.Sprintf("%s %s", mark, alias))
+		}
+
+		prompt := promptui.Select{
+			Label: label + " (选择切换，选择\"完成\"结束)",
+			Items: items,
+			Size:  10,
+		}
+		index, _, err := prompt.Run()
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			break
+		}
+
+		alias := candidates[index-1]
+		chosen[alias] = !chosen[alias]
+	}
+
+	// 保持候选列表中的顺序
+	var result []string
+	for _, alias := range candidates {
+		if chosen[alias] {
+			result = append(result, alias)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/config"
+)
+
+// TestShowForwardGroupList 测试显示转发组列表功能
+func TestShowForwardGroupList(t *testing.T) {
+	t.Run("空转发组列表", func(t *testing.T) {
+		menu, _, _ := createTestUIManager(t)
+
+		assert.NotPanics(t, func() {
+			menu.ShowForwardGroupList()
+		})
+	})
+
+	t.Run("有转发组的列表", func(t *testing.T) {
+		menu, configManager, _ := createTestUIManager(t)
+
+		server := createTestServer(t, configManager)
+		createTestPortForward(t, configManager, server.ID)
+
+		group := config.NewForwardGroup("devstack")
+		group.Forwards = []string{"test-forward"}
+		require.NoError(t, configManager.AddForwardGroup(group))
+
+		assert.NotPanics(t, func() {
+			menu.ShowForwardGroupList()
+		})
+	})
+}
+
+// TestForwardAliases 测试获取端口转发别名
+func TestForwardAliases(t *testing.T) {
+	t.Run("只返回带别名的端口转发", func(t *testing.T) {
+		menu, configManager, _ := createTestUIManager(t)
+
+		server := createTestServer(t, configManager)
+		createTestPortForward(t, configManager, server.ID)
+
+		unnamed := config.NewPortForwardConfig(server.ID)
+		unnamed.LocalPort = 9090
+		unnamed.RemotePort = 90
+		require.NoError(t, configManager.AddPortForward(unnamed))
+
+		assert.Equal(t, []string{"test-forward"}, menu.forwardAliases())
+	})
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"gotssh/internal/config"
+)
+
+// FormatImportPreview 导入的预览，标出将被跳过的服务器以及私钥、凭证、跳板机、端口转发和附加说明
+func FormatImportPreview(entries []*config.ImportEntry) string {
+	var b strings.Builder
+	count := 0
+	for _, entry := range entries {
+		if entry.Conflict == "" {
+			count++
+		}
+	}
+	fmt.Fprintf(&b, "\n=== 将导入 %d 台服务器 ===\n", count)
+	for _, entry := range entries {
+		if entry.Conflict != "" {
+			fmt.Fprintf(&b, "  ✗ %s (跳过: %s)\n", ServerLabel(entry.Server), entry.Conflict)
+			continue
+		}
+		fmt.Fprintf(&b, "  + %s\n", ServerLabel(entry.Server))
+		if entry.KeyPath != "" {
+			fmt.Fprintf(&b, "      私钥: %s\n", entry.KeyPath)
+		}
+		if entry.JumpHost != "" {
+			fmt.Fprintf(&b, "      跳板机: %s\n", entry.JumpHost)
+		}
+		if entry.Credential != nil {
+			fmt.Fprintf(&b, "      凭证: [%s] %s (%s)\n", entry.Credential.Alias, entry.Credential.Username, entry.Credential.Type)
+		}
+		for _, pf := range entry.Forwards {
+			fmt.Fprintf(&b, "      转发: %s\n", pf.Summary())
+		}
+		for _, note := range entry.Notes {
+			fmt.Fprintf(&b, "      %s\n", note)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gotssh/internal/config"
+)
+
+// TestFormatImportPreview 测试导入预览显示私钥、凭证、跳板机、端口转发和跳过的服务器
+func TestFormatImportPreview(t *testing.T) {
+	entries := []*config.ImportEntry{
+		{
+			Server:   &config.ServerConfig{Alias: "web-1", User: "deploy", Host: "10.0.0.1", Port: 22, Tags: []string{"web"}},
+			KeyPath:  "~/.ssh/deploy",
+			JumpHost: "ops@bastion:2222",
+			Notes:    []string{"别名 'web' 已存在，改为 'web-2'"},
+		},
+		{Server: &config.ServerConfig{Alias: "db-1", User: "root", Host: "10.0.1.1", Port: 22}, Conflict: "服务器已存在"},
+		{
+			Server:     &config.ServerConfig{Alias: "api", User: "admin", Host: "10.0.2.1", Port: 22},
+			Credential: &config.CredentialConfig{Alias: "admin", Username: "admin", Type: config.CredentialTypePassword},
+			Forwards: []*config.PortForwardConfig{
+				{Type: config.ForwardTypeLocal, LocalHost: "127.0.0.1", LocalPort: 8080, RemoteHost: "localhost", RemotePort: 80},
+			},
+		},
+	}
+
+	preview := FormatImportPreview(entries)
+	assert.Contains(t, preview, "=== 将导入 2 台服务器 ===")
+	assert.Contains(t, preview, "  + [web-1] deploy@10.0.0.1:22 #web\n      私钥: ~/.ssh/deploy\n      跳板机: ops@bastion:2222\n")
+	assert.Contains(t, preview, "      别名 'web' 已存在，改为 'web-2'\n")
+	assert.Contains(t, preview, "      凭证: [admin] admin (password)\n      转发: 127.0.0.1:8080 -> localhost:80\n")
+	assert.Contains(t, preview, "  ✗ [db-1] root@10.0.1.1:22 (跳过: 服务器已存在)\n")
+}
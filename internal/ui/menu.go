@@ -18,6 +18,13 @@ type Menu struct {
 	forwardManager *forward.Manager
 }
 
+// reloadConfig 显示菜单前合并其他 gotssh 进程（如后台运行的端口转发）对配置文件的修改
+func reloadConfig(configManager *config.Manager) {
+	if _, err := configManager.ReloadIfChanged(); err != nil {
+		fmt.Printf("重新加载配置失败: %v\n", err)
+	}
+}
+
 // NewMenu 创建新的菜单实例
 func NewMenu(configManager *config.Manager, forwardManager *forward.Manager) *Menu {
 	return &Menu{
@@ -29,6 +36,8 @@ func NewMenu(configManager *config.Manager, forwardManager *forward.Manager) *Me
 // ShowServerMenu 显示服务器管理菜单
 func (m *Menu) ShowServerMenu() error {
 	for {
+		reloadConfig(m.configManager)
+
 		prompt := promptui.Select{
 			Label: "服务器管理",
 			Items: []string{
@@ -38,6 +47,9 @@ func (m *Menu) ShowServerMenu() error {
 				"编辑服务器",
 				"删除服务器",
 				"测试连接",
+				"分组管理",
+				"从模板批量添加",
+				"按模板批量删除",
 				"退出",
 			},
 		}
@@ -70,6 +82,18 @@ func (m *Menu) ShowServerMenu() error {
 			if err := m.TestServerConnection(); err != nil {
 				fmt.Printf("测试连接失败: %v\n", err)
 			}
+		case "分组管理":
+			if err := m.ShowServerGroupMenu(); err != nil {
+				fmt.Printf("分组管理失败: %v\n", err)
+			}
+		case "从模板批量添加":
+			if err := m.AddServersFromTemplate(); err != nil {
+				fmt.Printf("从模板批量添加失败: %v\n", err)
+			}
+		case "按模板批量删除":
+			if err := m.DeleteServersByTemplate(); err != nil {
+				fmt.Printf("按模板批量删除失败: %v\n", err)
+			}
 		case "退出":
 			return nil
 		}
@@ -93,10 +117,21 @@ func (m *Menu) AddServer() error {
 		return err
 	}
 
+	// 分组，组内服务器继承分组的默认设置
+	groupPrompt := promptui.Prompt{
+		Label: "分组 (可选，如 prod/db)",
+	}
+	group, err := groupPrompt.Run()
+	if err != nil {
+		return err
+	}
+	group = config.NormalizeGroupPath(group)
+	inherited := m.configManager.ResolveServer(&config.ServerConfig{Host: host, Group: group})
+
 	// 端口
 	portPrompt := promptui.Prompt{
-		Label:   "端口 (默认22)",
-		Default: "22",
+		Label:   fmt.Sprintf("端口 (默认%d)", inherited.Port),
+		Default: strconv.Itoa(inherited.Port),
 		Validate: func(input string) error {
 			if input == "" {
 				return nil
@@ -116,8 +151,8 @@ func (m *Menu) AddServer() error {
 
 	// 用户名
 	userPrompt := promptui.Prompt{
-		Label:   "用户名 (默认root)",
-		Default: "root",
+		Label:   fmt.Sprintf("用户名 (默认%s)", inherited.User),
+		Default: inherited.User,
 	}
 	user, err := userPrompt.Run()
 	if err != nil {
@@ -133,26 +168,39 @@ func (m *Menu) AddServer() error {
 		return err
 	}
 
-	// 认证类型
+	// 认证类型，分组设置了默认凭证时可以继承
+	authItems := []string{
+		"每次询问",
+		"密码认证",
+		"密钥认证",
+		"登录凭证",
+	}
+	if inherited.CredentialID != "" {
+		authItems = append([]string{"继承分组凭证"}, authItems...)
+	}
 	authPrompt := promptui.Select{
 		Label: "认证类型",
-		Items: []string{
-			"每次询问",
-			"密码认证",
-			"密钥认证",
-			"登录凭证",
-		},
+		Items: authItems,
 	}
 	_, authResult, err := authPrompt.Run()
 	if err != nil {
 		return err
 	}
 
-	// 创建服务器配置
+	// 创建服务器配置，与分组默认值相同的字段留空以便随分组更新
 	server := config.NewServerConfig(host)
+	server.Group = group
 	server.Port = port
 	server.User = user
 	server.Alias = alias
+	if group != "" {
+		if server.Port == inherited.Port {
+			server.Port = 0
+		}
+		if server.User == inherited.User {
+			server.User = ""
+		}
+	}
 
 	switch authResult {
 	case "密码认证":
@@ -225,6 +273,9 @@ func (m *Menu) AddServer() error {
 
 	case "每次询问":
 		server.AuthType = config.AuthTypeAsk
+
+	case "继承分组凭证":
+		server.AuthType = ""
 	}
 
 	// 代理配置（可选）
@@ -246,6 +297,16 @@ func (m *Menu) AddServer() error {
 		server.Proxy = proxyConfig
 	}
 
+	// 跳板机（可选）
+	jumpPrompt := promptui.Prompt{
+		Label: "跳板机 (可选，已保存服务器的别名)",
+	}
+	jumpHost, err := jumpPrompt.Run()
+	if err != nil {
+		return err
+	}
+	server.JumpHost = strings.TrimSpace(jumpHost)
+
 	// 启动脚本（可选）
 	scriptPrompt := promptui.Prompt{
 		Label: "启动脚本 (可选)",
@@ -256,6 +317,11 @@ func (m *Menu) AddServer() error {
 	}
 	server.StartupScript = script
 
+	// 会话录制
+	if err := configureRecording(server); err != nil {
+		return err
+	}
+
 	// 描述（可选）
 	descPrompt := promptui.Prompt{
 		Label: "描述 (可选)",
@@ -266,6 +332,16 @@ func (m *Menu) AddServer() error {
 	}
 	server.Description = desc
 
+	// 标签（可选）
+	tagsPrompt := promptui.Prompt{
+		Label: "标签 (可选，逗号分隔，可用 tag:标签 搜索)",
+	}
+	tags, err := tagsPrompt.Run()
+	if err != nil {
+		return err
+	}
+	server.Tags = splitList(tags)
+
 	// 保存服务器配置
 	if err := m.configManager.AddServer(server); err != nil {
 		return fmt.Errorf("保存服务器配置失败: %w", err)
@@ -275,6 +351,29 @@ func (m *Menu) AddServer() error {
 	return nil
 }
 
+// configureRecording 配置交互式会话录制
+func configureRecording(server *config.ServerConfig) error {
+	cursor := 0
+	if server.RecordSession && server.RecordInput {
+		cursor = 2
+	} else if server.RecordSession {
+		cursor = 1
+	}
+
+	recordPrompt := promptui.Select{
+		Label:     "会话录制 (asciicast 格式，可用 gotssh replay 回放)",
+		Items:     []string{"不录制", "录制终端输出", "录制终端输出和键盘输入"},
+		CursorPos: cursor,
+	}
+	index, _, err := recordPrompt.Run()
+	if err != nil {
+		return err
+	}
+	server.RecordSession = index > 0
+	server.RecordInput = index == 2
+	return nil
+}
+
 // configureProxy 配置代理
 func (m *Menu) configureProxy() (*config.ProxyConfig, error) {
 	// 代理类型
@@ -364,22 +463,7 @@ func (m *Menu) ShowServerList() {
 	}
 
 	fmt.Println("\n=== 服务器列表 ===")
-	for i, server := range servers {
-		fmt.Printf("%d. ", i+1)
-		if server.Alias != "" {
-			fmt.Printf("[%s] ", server.Alias)
-		}
-		fmt.Printf("%s@%s:%d", server.User, server.Host, server.Port)
-		if server.Description != "" {
-			fmt.Printf(" - %s", server.Description)
-		}
-		fmt.Printf(" (认证: %s)", server.AuthType)
-		if server.Proxy != nil {
-			fmt.Printf(" [代理: %s]", server.Proxy.Type)
-		}
-		fmt.Printf(" [创建时间: %s]", server.CreatedAt.Format("2006-01-02 15:04:05"))
-		fmt.Println()
-	}
+	fmt.Print(m.serverTree())
 	fmt.Println()
 }
 
@@ -392,29 +476,17 @@ func (m *Menu) EditServer() error {
 	}
 
 	// 选择要编辑的服务器
-	var items []string
-	for _, server := range servers {
-		item := fmt.Sprintf("%s@%s:%d", server.User, server.Host, server.Port)
-		if server.Alias != "" {
-			item = fmt.Sprintf("[%s] %s", server.Alias, item)
-		}
-		items = append(items, item)
-	}
-
-	prompt := promptui.Select{
-		Label: "选择要编辑的服务器",
-		Items: items,
-	}
-
-	index, _, err := prompt.Run()
+	server, err := PickServer("选择要编辑的服务器", servers, DynamicNote(m.configManager))
 	if err != nil {
 		return err
 	}
-
-	server := servers[index]
+	if m.configManager.IsDynamic(server.ID) {
+		fmt.Printf("服务器 %s 来自动态清单，不能编辑\n", ServerLabel(server))
+		return nil
+	}
 	originalServer := *server // 复制原始配置
 
-	fmt.Printf("正在编辑服务器: %s@%s:%d\n", server.User, server.Host, server.Port)
+	fmt.Printf("正在编辑服务器: %s\n", ServerLabel(server))
 
 	// 编辑主机地址
 	hostPrompt := promptui.Prompt{
@@ -433,10 +505,30 @@ func (m *Menu) EditServer() error {
 	}
 	server.Host = host
 
+	// 编辑分组
+	groupPrompt := promptui.Prompt{
+		Label:   "分组 (如 prod/db，留空表示不分组)",
+		Default: server.Group,
+	}
+	group, err := groupPrompt.Run()
+	if err != nil {
+		return err
+	}
+	server.Group = config.NormalizeGroupPath(group)
+
+	// 分组中的服务器端口和用户名留空表示继承分组默认值
+	portLabel, userLabel, portDefault := "端口", "用户名", ""
+	if server.Group != "" {
+		portLabel, userLabel = "端口 (留空继承分组)", "用户名 (留空继承分组)"
+	}
+	if server.Port != 0 {
+		portDefault = strconv.Itoa(server.Port)
+	}
+
 	// 编辑端口
 	portPrompt := promptui.Prompt{
-		Label:   "端口",
-		Default: fmt.Sprintf("%d", server.Port),
+		Label:   portLabel,
+		Default: portDefault,
 		Validate: func(input string) error {
 			if input == "" {
 				return nil
@@ -456,7 +548,7 @@ func (m *Menu) EditServer() error {
 
 	// 编辑用户名
 	userPrompt := promptui.Prompt{
-		Label:   "用户名",
+		Label:   userLabel,
 		Default: server.User,
 	}
 	user, err := userPrompt.Run()
@@ -487,6 +579,28 @@ func (m *Menu) EditServer() error {
 	}
 	server.Description = desc
 
+	// 编辑标签
+	tagsPrompt := promptui.Prompt{
+		Label:   "标签 (逗号分隔)",
+		Default: strings.Join(server.Tags, ", "),
+	}
+	tags, err := tagsPrompt.Run()
+	if err != nil {
+		return err
+	}
+	server.Tags = splitList(tags)
+
+	// 编辑跳板机
+	jumpPrompt := promptui.Prompt{
+		Label:   "跳板机 (已保存服务器的别名，留空表示不使用)",
+		Default: server.JumpHost,
+	}
+	jumpHost, err := jumpPrompt.Run()
+	if err != nil {
+		return err
+	}
+	server.JumpHost = strings.TrimSpace(jumpHost)
+
 	// 编辑启动脚本
 	scriptPrompt := promptui.Prompt{
 		Label:   "启动脚本",
@@ -498,10 +612,20 @@ func (m *Menu) EditServer() error {
 	}
 	server.StartupScript = script
 
-	// 编辑认证类型
+	// 编辑会话录制
+	if err := configureRecording(server); err != nil {
+		return err
+	}
+
+	// 编辑认证类型，分组中的服务器可以继承分组凭证
 	authTypes := []string{"每次询问", "密码认证", "密钥认证", "登录凭证"}
+	if server.Group != "" {
+		authTypes = append(authTypes, "继承分组凭证")
+	}
 	var currentAuthIndex int
 	switch server.AuthType {
+	case "":
+		currentAuthIndex = len(authTypes) - 1
 	case config.AuthTypeAsk:
 		currentAuthIndex = 0
 	case config.AuthTypePassword:
@@ -600,6 +724,10 @@ func (m *Menu) EditServer() error {
 	case "每次询问":
 		server.AuthType = config.AuthTypeAsk
 		server.CredentialID = ""
+
+	case "继承分组凭证":
+		server.AuthType = ""
+		server.CredentialID = ""
 	}
 
 	// 确认保存
@@ -635,30 +763,18 @@ func (m *Menu) DeleteServer() error {
 	}
 
 	// 选择要删除的服务器
-	var items []string
-	for _, server := range servers {
-		item := fmt.Sprintf("%s@%s:%d", server.User, server.Host, server.Port)
-		if server.Alias != "" {
-			item = fmt.Sprintf("[%s] %s", server.Alias, item)
-		}
-		items = append(items, item)
-	}
-
-	prompt := promptui.Select{
-		Label: "选择要删除的服务器",
-		Items: items,
-	}
-
-	index, _, err := prompt.Run()
+	server, err := PickServer("选择要删除的服务器", servers, DynamicNote(m.configManager))
 	if err != nil {
 		return err
 	}
-
-	server := servers[index]
+	if m.configManager.IsDynamic(server.ID) {
+		fmt.Printf("服务器 %s 来自动态清单，不能删除\n", ServerLabel(server))
+		return nil
+	}
 
 	// 确认删除
 	confirmPrompt := promptui.Select{
-		Label: fmt.Sprintf("确定要删除服务器 %s@%s:%d 吗？", server.User, server.Host, server.Port),
+		Label: fmt.Sprintf("确定要删除服务器 %s 吗？", ServerLabel(server)),
 		Items: []string{"是", "否"},
 	}
 
@@ -671,7 +787,7 @@ func (m *Menu) DeleteServer() error {
 		if err := m.configManager.DeleteServer(server.ID); err != nil {
 			return fmt.Errorf("删除服务器失败: %w", err)
 		}
-		fmt.Printf("服务器 %s@%s:%d 已删除\n", server.User, server.Host, server.Port)
+		fmt.Printf("服务器 %s 已删除\n", ServerLabel(server))
 	}
 
 	return nil
@@ -685,27 +801,13 @@ func (m *Menu) ConnectServer() error {
 		return nil
 	}
 
-	// 选择要连接的服务器
-	var items []string
-	for _, server := range servers {
-		item := fmt.Sprintf("%s@%s:%d", server.User, server.Host, server.Port)
-		if server.Alias != "" {
-			item = fmt.Sprintf("[%s] %s", server.Alias, item)
-		}
-		items = append(items, item)
-	}
-
-	prompt := promptui.Select{
-		Label: "选择要连接的服务器",
-		Items: items,
-	}
-
-	index, _, err := prompt.Run()
+	// 选择要连接的服务器，最常连接的服务器排在最前
+	m.configManager.SortServersByFrecency(servers)
+	server, err := PickServer("选择要连接的服务器", servers, LastConnectedNote(m.configManager.LastConnected()))
 	if err != nil {
 		return err
 	}
-
-	server := servers[index]
+	server = m.configManager.ResolveServer(server)
 
 	fmt.Printf("正在连接到 %s@%s:%d", server.User, server.Host, server.Port)
 	if server.Alias != "" {
@@ -741,26 +843,11 @@ func (m *Menu) TestServerConnection() error {
 	}
 
 	// 选择要测试的服务器
-	var items []string
-	for _, server := range servers {
-		item := fmt.Sprintf("%s@%s:%d", server.User, server.Host, server.Port)
-		if server.Alias != "" {
-			item = fmt.Sprintf("[%s] %s", server.Alias, item)
-		}
-		items = append(items, item)
-	}
-
-	prompt := promptui.Select{
-		Label: "选择要测试的服务器",
-		Items: items,
-	}
-
-	index, _, err := prompt.Run()
+	server, err := PickServer("选择要测试的服务器", servers, nil)
 	if err != nil {
 		return err
 	}
-
-	server := servers[index]
+	server = m.configManager.ResolveServer(server)
 
 	fmt.Printf("正在测试连接到 %s@%s:%d ...\n", server.User, server.Host, server.Port)
 
@@ -789,6 +876,8 @@ func (m *Menu) TestServerConnection() error {
 // ShowPortForwardMenu 显示端口转发管理菜单
 func (m *Menu) ShowPortForwardMenu() error {
 	for {
+		reloadConfig(m.configManager)
+
 		prompt := promptui.Select{
 			Label: "端口转发管理",
 			Items: []string{
@@ -798,6 +887,7 @@ func (m *Menu) ShowPortForwardMenu() error {
 				"停止端口转发",
 				"删除端口转发",
 				"测试端口转发",
+				"转发组管理",
 				"返回主菜单",
 			},
 		}
@@ -830,6 +920,10 @@ func (m *Menu) ShowPortForwardMenu() error {
 			if err := m.TestPortForward(); err != nil {
 				fmt.Printf("测试端口转发失败: %v\n", err)
 			}
+		case "转发组管理":
+			if err := m.ShowForwardGroupMenu(); err != nil {
+				fmt.Printf("转发组管理失败: %v\n", err)
+			}
 		case "返回主菜单":
 			return nil
 		}
@@ -845,133 +939,364 @@ func (m *Menu) AddPortForward() error {
 		return nil
 	}
 
-	var serverItems []string
-	for _, server := range servers {
-		item := fmt.Sprintf("%s@%s:%d", server.User, server.Host, server.Port)
-		if server.Alias != "" {
-			item = fmt.Sprintf("[%s] %s", server.Alias, item)
-		}
-		serverItems = append(serverItems, item)
-	}
-
-	serverPrompt := promptui.Select{
-		Label: "选择服务器",
-		Items: serverItems,
-	}
-
-	serverIndex, _, err := serverPrompt.Run()
+	server, err := PickServer("选择服务器", servers, nil)
 	if err != nil {
 		return err
 	}
 
-	server := servers[serverIndex]
-
 	// 转发类型
+	forwardTypes := []struct {
+		label string
+		value config.ForwardType
+	}{
+		{"本地端口转发", config.ForwardTypeLocal},
+		{"远程端口转发", config.ForwardTypeRemote},
+		{"远程动态转发 (服务器经本机SOCKS5代理上网)", config.ForwardTypeRemoteDynamic},
+		{"HTTP代理 (本机经服务器上网)", config.ForwardTypeHTTPProxy},
+	}
+	var typeItems []string
+	for _, t := range forwardTypes {
+		typeItems = append(typeItems, t.label)
+	}
 	typePrompt := promptui.Select{
 		Label: "转发类型",
-		Items: []string{
-			"本地端口转发",
-			"远程端口转发",
-		},
+		Items: typeItems,
 	}
-	_, typeResult, err := typePrompt.Run()
+	typeIndex, _, err := typePrompt.Run()
 	if err != nil {
 		return err
 	}
 
 	// 创建端口转发配置
 	pf := config.NewPortForwardConfig(server.ID)
+	pf.Type = forwardTypes[typeIndex].value
 
-	if typeResult == "本地端口转发" {
-		pf.Type = config.ForwardTypeLocal
+	// 本地端点，本地转发支持端口0自动分配；远程动态转发由本机SOCKS5服务器直接连接目标，无需本地端点
+	if pf.Type != config.ForwardTypeRemoteDynamic {
+		pf.LocalHost, pf.LocalPort, pf.LocalSocket, err = m.configureEndpoint("本地", pf.ListensLocal())
+		if err != nil {
+			return err
+		}
+		if err := m.resolveLocalPortConflict(pf); err != nil {
+			return err
+		}
+		if pf.LocalSocket != "" {
+			modePrompt := promptui.Prompt{
+				Label:   "本地套接字权限 (八进制)",
+				Default: fmt.Sprintf("%04o", config.DefaultSocketMode),
+				Validate: func(input string) error {
+					_, err := (&config.PortForwardConfig{SocketMode: input}).SocketFileMode()
+					return err
+				},
+			}
+			mode, err := modePrompt.Run()
+			if err != nil {
+				return err
+			}
+			pf.SocketMode = mode
+		}
+	}
+
+	// 远程端点
+	// 远程转发的远程端点是服务器上的监听地址，支持端口0由服务器分配
+	if pf.Type.ListensRemote() {
+		fmt.Println("提示: 远程主机为服务器上的监听地址，127.0.0.1 仅服务器本机可访问，0.0.0.0 监听所有网卡（需服务器设置 GatewayPorts clientspecified 或 yes）")
+	}
+	// HTTP代理按请求动态连接目标，无需远程端点
+	if pf.Type == config.ForwardTypeHTTPProxy {
+		if err := configureProxyAuth(pf); err != nil {
+			return err
+		}
 	} else {
-		pf.Type = config.ForwardTypeRemote
+		pf.RemoteHost, pf.RemotePort, pf.RemoteSocket, err = m.configureEndpoint("远程", pf.Type.ListensRemote())
+		if err != nil {
+			return err
+		}
 	}
 
-	// 本地主机
-	localHostPrompt := promptui.Prompt{
-		Label:   "本地主机 (默认127.0.0.1)",
-		Default: "127.0.0.1",
+	// 本地监听访问控制
+	if pf.Type == config.ForwardTypeLocal {
+		if err := configureAccessControl(pf); err != nil {
+			return err
+		}
 	}
-	localHost, err := localHostPrompt.Run()
+
+	// 访问日志
+	accessLogPrompt := promptui.Select{
+		Label: "是否记录访问日志 (来源、目标、时长、流量，可用 gotssh tunnel logs 查看)",
+		Items: []string{"否", "是"},
+	}
+	_, accessLog, err := accessLogPrompt.Run()
+	if err != nil {
+		return err
+	}
+	pf.AccessLog = accessLog == "是"
+
+	// 别名
+	aliasPrompt := promptui.Prompt{
+		Label: "别名 (可选)",
+	}
+	alias, err := aliasPrompt.Run()
+	if err != nil {
+		return err
+	}
+	pf.Alias = alias
+
+	// 描述
+	descPrompt := promptui.Prompt{
+		Label: "描述 (可选)",
+	}
+	desc, err := descPrompt.Run()
 	if err != nil {
 		return err
 	}
-	pf.LocalHost = localHost
+	pf.Description = desc
+
+	// 保存端口转发配置
+	if err := m.configManager.AddPortForward(pf); err != nil {
+		return fmt.Errorf("保存端口转发配置失败: %w", err)
+	}
 
-	// 本地端口
-	localPortPrompt := promptui.Prompt{
-		Label: "本地端口",
+	fmt.Printf("端口转发配置已添加: %s\n", pf.Summary())
+	if pf.AutoLocalPort() {
+		fmt.Println("本地端口将在每次启动时自动分配")
+	}
+	if pf.AutoRemotePort() {
+		fmt.Println("远程端口将在每次启动时由服务器分配")
+	}
+	return nil
+}
+
+// configureEndpoint 配置转发端点，返回TCP主机和端口或Unix套接字路径
+// allowAuto 为 true 时允许输入端口0表示自动分配
+func (m *Menu) configureEndpoint(side string, allowAuto bool) (host string, port int, socket string, err error) {
+	kindPrompt := promptui.Select{
+		Label: side + "端点类型",
+		Items: []string{"TCP端口", "Unix套接字"},
+	}
+	_, kind, err := kindPrompt.Run()
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	if kind == "Unix套接字" {
+		socketPrompt := promptui.Prompt{
+			Label: side + "套接字路径",
+			Validate: func(input string) error {
+				if strings.TrimSpace(input) == "" {
+					return fmt.Errorf("套接字路径不能为空")
+				}
+				return nil
+			},
+		}
+		socket, err = socketPrompt.Run()
+		if err != nil {
+			return "", 0, "", err
+		}
+		return "", 0, strings.TrimSpace(socket), nil
+	}
+
+	hostPrompt := promptui.Prompt{
+		Label:   side + "主机 (默认127.0.0.1)",
+		Default: "127.0.0.1",
+	}
+	host, err = hostPrompt.Run()
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	port, err = promptPort(side+"端口", allowAuto)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	return host, port, "", nil
+}
+
+// promptPort 输入端口，allowAuto 为 true 时允许0表示自动分配
+func promptPort(label string, allowAuto bool) (int, error) {
+	minPort := 1
+	if allowAuto {
+		minPort = 0
+		label += " (0 表示自动分配)"
+	}
+
+	portPrompt := promptui.Prompt{
+		Label: label,
 		Validate: func(input string) error {
 			port, err := strconv.Atoi(input)
-			if err != nil || port < 1 || port > 65535 {
-				return fmt.Errorf("端口必须是1-65535之间的数字")
+			if err != nil || port < minPort || port > 65535 {
+				return fmt.Errorf("端口必须是%d-65535之间的数字", minPort)
 			}
 			return nil
 		},
 	}
-	localPortStr, err := localPortPrompt.Run()
+	portStr, err := portPrompt.Run()
 	if err != nil {
-		return err
+		return 0, err
 	}
-	pf.LocalPort, _ = strconv.Atoi(localPortStr)
+	port, _ := strconv.Atoi(portStr)
+	return port, nil
+}
 
-	// 远程主机
-	remoteHostPrompt := promptui.Prompt{
-		Label:   "远程主机 (默认127.0.0.1)",
-		Default: "127.0.0.1",
+// resolveLocalPortConflict 检查本地端口是否与已保存的端口转发重叠或已被占用
+// 发现冲突时让用户重新输入、改为自动分配或保留
+func (m *Menu) resolveLocalPortConflict(pf *config.PortForwardConfig) error {
+	for pf.BindsLocalPort() && pf.LocalPort != 0 {
+		var problems []string
+		if conflicts := m.configManager.FindLocalPortConflicts(pf); len(conflicts) > 0 {
+			problems = append(problems, fmt.Sprintf("本地端口 %d 与已保存的端口转发 %s 重叠", pf.LocalPort, forwardNames(conflicts)))
+		}
+		if _, err := forward.CheckLocalPort(pf.LocalHost, pf.LocalPort); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if len(problems) == 0 {
+			return nil
+		}
+
+		for _, problem := range problems {
+			fmt.Printf("⚠️  %s\n", problem)
+		}
+
+		prompt := promptui.Select{
+			Label: "如何处理端口冲突",
+			Items: []string{"重新输入端口", "自动分配端口", "仍然使用该端口"},
+		}
+		_, result, err := prompt.Run()
+		if err != nil {
+			return err
+		}
+
+		switch result {
+		case "重新输入端口":
+			port, err := promptPort("本地端口", true)
+			if err != nil {
+				return err
+			}
+			pf.LocalPort = port
+		case "自动分配端口":
+			pf.LocalPort = 0
+		default:
+			return nil
+		}
 	}
-	remoteHost, err := remoteHostPrompt.Run()
+	return nil
+}
+
+// configureAccessControl 交互式配置本地监听的访问控制和连接限制
+func configureAccessControl(pf *config.PortForwardConfig) error {
+	enablePrompt := promptui.Select{
+		Label: "是否配置访问控制和连接限制",
+		Items: []string{"否", "是"},
+	}
+	_, enable, err := enablePrompt.Run()
 	if err != nil {
 		return err
 	}
-	pf.RemoteHost = remoteHost
+	if enable == "否" {
+		return nil
+	}
 
-	// 远程端口
-	remotePortPrompt := promptui.Prompt{
-		Label: "远程端口",
+	cidrPrompt := promptui.Prompt{
+		Label: "允许的来源网段 (逗号分隔，如 127.0.0.1,10.0.0.0/8，留空不限制)",
 		Validate: func(input string) error {
-			port, err := strconv.Atoi(input)
-			if err != nil || port < 1 || port > 65535 {
-				return fmt.Errorf("端口必须是1-65535之间的数字")
+			for _, cidr := range splitList(input) {
+				if _, err := config.ParseCIDR(cidr); err != nil {
+					return err
+				}
 			}
 			return nil
 		},
 	}
-	remotePortStr, err := remotePortPrompt.Run()
+	cidrs, err := cidrPrompt.Run()
 	if err != nil {
 		return err
 	}
-	pf.RemotePort, _ = strconv.Atoi(remotePortStr)
+	pf.AllowedCIDRs = splitList(cidrs)
 
-	// 别名
-	aliasPrompt := promptui.Prompt{
-		Label: "别名 (可选)",
+	limits := []struct {
+		label string
+		value *int
+	}{
+		{"最大并发连接数 (0 表示不限制)", &pf.MaxConnections},
+		{"连接空闲超时秒数 (0 表示不超时)", &pf.IdleTimeout},
+		{"每秒最多新连接数 (0 表示不限制)", &pf.RateLimit},
 	}
-	alias, err := aliasPrompt.Run()
+	for _, limit := range limits {
+		prompt := promptui.Prompt{
+			Label:   limit.label,
+			Default: "0",
+			Validate: func(input string) error {
+				if n, err := strconv.Atoi(input); err != nil || n < 0 {
+					return fmt.Errorf("请输入非负整数")
+				}
+				return nil
+			},
+		}
+		result, err := prompt.Run()
+		if err != nil {
+			return err
+		}
+		*limit.value, _ = strconv.Atoi(result)
+	}
+
+	return nil
+}
+
+// configureProxyAuth 交互式配置HTTP代理的Basic认证
+func configureProxyAuth(pf *config.PortForwardConfig) error {
+	usernamePrompt := promptui.Prompt{
+		Label: "代理用户名 (留空不需要认证)",
+	}
+	username, err := usernamePrompt.Run()
 	if err != nil {
 		return err
 	}
-	pf.Alias = alias
+	pf.ProxyUsername = strings.TrimSpace(username)
+	if pf.ProxyUsername == "" {
+		return nil
+	}
 
-	// 描述
-	descPrompt := promptui.Prompt{
-		Label: "描述 (可选)",
+	passwordPrompt := promptui.Prompt{
+		Label: "代理密码",
+		Mask:  '*',
+		Validate: func(input string) error {
+			if input == "" {
+				return fmt.Errorf("代理密码不能为空")
+			}
+			return nil
+		},
 	}
-	desc, err := descPrompt.Run()
+	password, err := passwordPrompt.Run()
 	if err != nil {
 		return err
 	}
-	pf.Description = desc
+	pf.ProxyPassword = password
+	return nil
+}
 
-	// 保存端口转发配置
-	if err := m.configManager.AddPortForward(pf); err != nil {
-		return fmt.Errorf("保存端口转发配置失败: %w", err)
+// splitList 按逗号分隔并去除空白项
+func splitList(input string) []string {
+	var items []string
+	for _, item := range strings.Split(input, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	fmt.Printf("端口转发配置已添加: %s:%d -> %s:%d\n",
-		pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort)
-	return nil
+// forwardNames 端口转发的显示名称列表（别名优先）
+func forwardNames(pfs []*config.PortForwardConfig) string {
+	names := make([]string, 0, len(pfs))
+	for _, pf := range pfs {
+		if pf.Alias != "" {
+			names = append(names, pf.Alias)
+		} else {
+			names = append(names, pf.Summary())
+		}
+	}
+	return strings.Join(names, ", ")
 }
 
 // ShowPortForwardList 显示端口转发列表
@@ -993,13 +1318,29 @@ func (m *Menu) ShowPortForwardList() {
 		if pf.Alias != "" {
 			fmt.Printf("[%s] ", pf.Alias)
 		}
-		fmt.Printf("%s:%d -> %s:%d",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort)
+		fmt.Print(pf.Summary())
 		fmt.Printf(" (%s)", pf.Type)
 
 		// 显示状态
 		status := m.forwardManager.GetForwardStatus(pf.ID)
 		fmt.Printf(" [状态: %s]", status)
+		if forward, err := m.forwardManager.GetActiveForward(pf.ID); err == nil {
+			if pf.AutoLocalPort() {
+				fmt.Printf(" [监听: %s]", forward.LocalAddress())
+			} else if pf.AutoRemotePort() {
+				fmt.Printf(" [远程监听: %s]", forward.RemoteAddress())
+			}
+			if stats, ok := forward.AccessStats(); ok {
+				fmt.Printf(" [连接: %s]", stats)
+			}
+		}
+
+		if conflicts := m.configManager.FindLocalPortConflicts(pf); len(conflicts) > 0 {
+			fmt.Printf(" [端口冲突: %s]", forwardNames(conflicts))
+		}
+		if pf.AccessLog {
+			fmt.Print(" [访问日志]")
+		}
 
 		if pf.Description != "" {
 			fmt.Printf(" - %s", pf.Description)
@@ -1034,8 +1375,7 @@ func (m *Menu) StartPortForward() error {
 	// 选择要启动的端口转发
 	var items []string
 	for _, pf := range availablePFs {
-		item := fmt.Sprintf("%s:%d -> %s:%d (%s)",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort, pf.Type)
+		item := fmt.Sprintf("%s (%s)", pf.Summary(), pf.Type)
 		if pf.Alias != "" {
 			item = fmt.Sprintf("[%s] %s", pf.Alias, item)
 		}
@@ -1054,14 +1394,16 @@ func (m *Menu) StartPortForward() error {
 
 	pf := availablePFs[index]
 
-	fmt.Printf("正在启动端口转发: %s:%d -> %s:%d ...\n",
-		pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort)
+	fmt.Printf("正在启动端口转发: %s ...\n", pf.Summary())
 
 	// 启动端口转发
 	if err := m.forwardManager.StartPortForward(pf); err != nil {
 		return fmt.Errorf("启动端口转发失败: %w", err)
 	}
 
+	if forward, err := m.forwardManager.GetActiveForward(pf.ID); err == nil && pf.AutoLocalPort() {
+		fmt.Printf("本地监听地址: %s\n", forward.LocalAddress())
+	}
 	fmt.Printf("✅ 端口转发已启动！\n")
 	fmt.Printf("使用 Ctrl+C 或停止命令来停止转发\n")
 
@@ -1080,8 +1422,7 @@ func (m *Menu) StopPortForward() error {
 	var items []string
 	for _, forward := range activeForwards {
 		pf := forward.Config
-		item := fmt.Sprintf("%s:%d -> %s:%d (%s)",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort, pf.Type)
+		item := fmt.Sprintf("%s (%s)", forward.Summary(), pf.Type)
 		if pf.Alias != "" {
 			item = fmt.Sprintf("[%s] %s", pf.Alias, item)
 		}
@@ -1100,9 +1441,7 @@ func (m *Menu) StopPortForward() error {
 
 	forward := activeForwards[index]
 
-	fmt.Printf("正在停止端口转发: %s:%d -> %s:%d ...\n",
-		forward.Config.LocalHost, forward.Config.LocalPort,
-		forward.Config.RemoteHost, forward.Config.RemotePort)
+	fmt.Printf("正在停止端口转发: %s ...\n", forward.Summary())
 
 	// 停止端口转发
 	if err := m.forwardManager.StopPortForward(forward.ID); err != nil {
@@ -1124,8 +1463,7 @@ func (m *Menu) DeletePortForward() error {
 	// 选择要删除的端口转发
 	var items []string
 	for _, pf := range pfs {
-		item := fmt.Sprintf("%s:%d -> %s:%d (%s)",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort, pf.Type)
+		item := fmt.Sprintf("%s (%s)", pf.Summary(), pf.Type)
 		if pf.Alias != "" {
 			item = fmt.Sprintf("[%s] %s", pf.Alias, item)
 		}
@@ -1146,8 +1484,7 @@ func (m *Menu) DeletePortForward() error {
 
 	// 确认删除
 	confirmPrompt := promptui.Select{
-		Label: fmt.Sprintf("确定要删除端口转发 %s:%d -> %s:%d 吗？",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort),
+		Label: fmt.Sprintf("确定要删除端口转发 %s 吗？", pf.Summary()),
 		Items: []string{"是", "否"},
 	}
 
@@ -1167,8 +1504,7 @@ func (m *Menu) DeletePortForward() error {
 		if err := m.configManager.DeletePortForward(pf.ID); err != nil {
 			return fmt.Errorf("删除端口转发失败: %w", err)
 		}
-		fmt.Printf("端口转发 %s:%d -> %s:%d 已删除\n",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort)
+		fmt.Printf("端口转发 %s 已删除\n", pf.Summary())
 	}
 
 	return nil
@@ -1185,8 +1521,7 @@ func (m *Menu) TestPortForward() error {
 	// 选择要测试的端口转发
 	var items []string
 	for _, pf := range pfs {
-		item := fmt.Sprintf("%s:%d -> %s:%d (%s)",
-			pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort, pf.Type)
+		item := fmt.Sprintf("%s (%s)", pf.Summary(), pf.Type)
 		if pf.Alias != "" {
 			item = fmt.Sprintf("[%s] %s", pf.Alias, item)
 		}
@@ -1205,8 +1540,7 @@ func (m *Menu) TestPortForward() error {
 
 	pf := pfs[index]
 
-	fmt.Printf("正在测试端口转发配置: %s:%d -> %s:%d ...\n",
-		pf.LocalHost, pf.LocalPort, pf.RemoteHost, pf.RemotePort)
+	fmt.Printf("正在测试端口转发配置: %s ...\n", pf.Summary())
 
 	// 测试端口转发配置
 	if err := m.forwardManager.TestPortForward(pf); err != nil {
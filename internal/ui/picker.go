@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gotssh/internal/config"
+
+	"github.com/manifoldco/promptui"
+)
+
+// pickerSize 服务器选择列表一次显示的行数
+const pickerSize = 10
+
+// ServerLabel 服务器在选择列表中的显示形式，继承自分组的用户名和端口不显示
+func ServerLabel(server *config.ServerConfig) string {
+	label := server.Host
+	if server.User != "" {
+		label = server.User + "@" + label
+	}
+	if server.Port != 0 {
+		label += fmt.Sprintf(":%d", server.Port)
+	}
+	if server.Alias != "" {
+		label = fmt.Sprintf("[%s] %s", server.Alias, label)
+	}
+	if server.Group != "" {
+		label += " (" + server.Group + ")"
+	}
+	for _, tag := range server.Tags {
+		label += " #" + tag
+	}
+	if server.Description != "" {
+		label += " - " + server.Description
+	}
+	return label
+}
+
+// PickServer 显示可搜索的服务器选择列表
+// 输入关键字在别名、主机、用户名、标签和描述中模糊搜索，tag:xxx 按标签过滤；
+// annotate 不为 nil 时其返回值附加在每一项后面
+func PickServer(label string, servers []*config.ServerConfig, annotate func(*config.ServerConfig) string) (*config.ServerConfig, error) {
+	return PickServerTo(nil, label, servers, annotate)
+}
+
+// PickServerTo 与 PickServer 相同，选择列表输出到 out 而不是标准输出，out 为 nil 时使用标准输出。
+// 用于标准输出需要保留给命令结果的场景，如 run 命令
+func PickServerTo(out io.Writer, label string, servers []*config.ServerConfig, annotate func(*config.ServerConfig) string) (*config.ServerConfig, error) {
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("没有可选择的服务器")
+	}
+
+	items := make([]string, len(servers))
+	for i, server := range servers {
+		items[i] = ServerLabel(server)
+		if annotate != nil {
+			items[i] += annotate(server)
+		}
+	}
+
+	prompt := promptui.Select{
+		Label:             label + "（输入关键字搜索，tag:标签 按标签过滤）",
+		Items:             items,
+		Size:              pickerSize,
+		Searcher:          serverSearcher(servers),
+		StartInSearchMode: len(servers) > pickerSize,
+	}
+
+	if out != nil {
+		prompt.Stdout = nopWriteCloser{out}
+	}
+
+	index, _, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+	return servers[index], nil
+}
+
+// nopWriteCloser 选择列表关闭时不关闭输出
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// serverSearcher 增量搜索使用的匹配函数
+func serverSearcher(servers []*config.ServerConfig) func(input string, index int) bool {
+	return func(input string, index int) bool {
+		if strings.TrimSpace(input) == "" {
+			return true
+		}
+		_, ok := config.MatchServer(servers[index], input)
+		return ok
+	}
+}
+
+// LastConnectedNote 在选择列表中显示最近连接时间的附加信息
+func LastConnectedNote(lastConnected map[string]time.Time) func(*config.ServerConfig) string {
+	return func(server *config.ServerConfig) string {
+		if last, ok := lastConnected[server.ID]; ok {
+			return fmt.Sprintf(" (最近连接: %s)", last.Format("2006-01-02 15:04"))
+		}
+		return ""
+	}
+}
+
+// DynamicNote 在选择列表中标出来自动态清单、不能编辑的服务器
+func DynamicNote(configManager *config.Manager) func(*config.ServerConfig) string {
+	return func(server *config.ServerConfig) string {
+		if configManager.IsDynamic(server.ID) {
+			return " [动态清单，不可编辑]"
+		}
+		return ""
+	}
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gotssh/internal/config"
+)
+
+// TestServerLabel 测试服务器选择项的显示
+func TestServerLabel(t *testing.T) {
+	server := &config.ServerConfig{Alias: "db", User: "root", Host: "10.0.0.1", Port: 22, Tags: []string{"prod", "db"}, Description: "主库"}
+	assert.Equal(t, "[db] root@10.0.0.1:22 #prod #db - 主库", ServerLabel(server))
+	assert.Equal(t, "root@10.0.0.1:2222", ServerLabel(&config.ServerConfig{User: "root", Host: "10.0.0.1", Port: 2222}))
+}
+
+// TestServerSearcher 测试增量搜索匹配
+func TestServerSearcher(t *testing.T) {
+	servers := []*config.ServerConfig{
+		{Alias: "web", Host: "10.0.0.1", User: "root", Tags: []string{"prod"}},
+		{Alias: "db", Host: "10.0.0.2", User: "postgres", Tags: []string{"staging"}},
+	}
+	search := serverSearcher(servers)
+
+	assert.True(t, search("", 0))
+	assert.True(t, search("  ", 1))
+	assert.True(t, search("tag:prod", 0))
+	assert.False(t, search("tag:prod", 1))
+	assert.True(t, search("pg", 1))
+	assert.False(t, search("pg", 0))
+}
+
+// TestLastConnectedNote 测试最近连接时间的附加信息
+func TestLastConnectedNote(t *testing.T) {
+	last := time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local)
+	note := LastConnectedNote(map[string]time.Time{"a": last})
+
+	assert.Equal(t, " (最近连接: 2024-05-01 09:30)", note(&config.ServerConfig{ID: "a"}))
+	assert.Empty(t, note(&config.ServerConfig{ID: "b"}))
+}
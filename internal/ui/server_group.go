@@ -0,0 +1,387 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gotssh/internal/config"
+
+	"github.com/manifoldco/promptui"
+)
+
+// ShowServerGroupMenu 显示服务器分组管理菜单
+func (m *Menu) ShowServerGroupMenu() error {
+	for {
+		reloadConfig(m.configManager)
+
+		prompt := promptui.Select{
+			Label: "分组管理",
+			Items: []string{
+				"添加分组",
+				"编辑分组",
+				"删除分组",
+				"返回上级菜单",
+			},
+		}
+
+		_, result, err := prompt.Run()
+		if err != nil {
+			return fmt.Errorf("选择分组管理菜单失败: %w", err)
+		}
+
+		switch result {
+		case "添加分组":
+			if err := m.AddServerGroup(); err != nil {
+				fmt.Printf("添加分组失败: %v\n", err)
+			}
+		case "编辑分组":
+			if err := m.EditServerGroup(); err != nil {
+				fmt.Printf("编辑分组失败: %v\n", err)
+			}
+		case "删除分组":
+			if err := m.DeleteServerGroup(); err != nil {
+				fmt.Printf("删除分组失败: %v\n", err)
+			}
+		case "返回上级菜单":
+			return nil
+		}
+	}
+}
+
+// AddServerGroup 添加服务器分组
+func (m *Menu) AddServerGroup() error {
+	pathPrompt := promptui.Prompt{
+		Label: "分组路径 (如 prod/db)",
+		Validate: func(input string) error {
+			if config.NormalizeGroupPath(input) == "" {
+				return fmt.Errorf("分组路径不能为空")
+			}
+			return nil
+		},
+	}
+	path, err := pathPrompt.Run()
+	if err != nil {
+		return err
+	}
+
+	group := config.NewServerGroup(path)
+	if err := m.configureServerGroup(group); err != nil {
+		return err
+	}
+
+	if err := m.configManager.AddServerGroup(group); err != nil {
+		return fmt.Errorf("保存分组配置失败: %w", err)
+	}
+
+	fmt.Printf("分组 %s 添加成功！\n", group.Path)
+	return nil
+}
+
+// EditServerGroup 编辑服务器分组的默认设置
+func (m *Menu) EditServerGroup() error {
+	group, err := m.selectServerGroup("选择要编辑的分组")
+	if err != nil || group == nil {
+		return err
+	}
+
+	edited := *group
+	pathPrompt := promptui.Prompt{
+		Label:   "分组路径 (修改后组内服务器随之移动)",
+		Default: group.Path,
+		Validate: func(input string) error {
+			if config.NormalizeGroupPath(input) == "" {
+				return fmt.Errorf("分组路径不能为空")
+			}
+			return nil
+		},
+	}
+	path, err := pathPrompt.Run()
+	if err != nil {
+		return err
+	}
+	edited.Path = path
+
+	if err := m.configureServerGroup(&edited); err != nil {
+		return err
+	}
+
+	if err := m.configManager.UpdateServerGroup(group.Path, &edited); err != nil {
+		return fmt.Errorf("保存分组配置失败: %w", err)
+	}
+
+	fmt.Printf("分组 %s 更新成功！\n", edited.Path)
+	return nil
+}
+
+// DeleteServerGroup 删除服务器分组的默认设置
+func (m *Menu) DeleteServerGroup() error {
+	group, err := m.selectServerGroup("选择要删除的分组")
+	if err != nil || group == nil {
+		return err
+	}
+
+	confirmPrompt := promptui.Select{
+		Label: fmt.Sprintf("确定要删除分组 %s 的默认设置吗？(组内服务器保留分组路径)", group.Path),
+		Items: []string{"是", "否"},
+	}
+	_, confirmResult, err := confirmPrompt.Run()
+	if err != nil {
+		return err
+	}
+
+	if confirmResult == "是" {
+		if err := m.configManager.DeleteServerGroup(group.Path); err != nil {
+			return fmt.Errorf("删除分组失败: %w", err)
+		}
+		fmt.Printf("分组 %s 已删除\n", group.Path)
+	}
+	return nil
+}
+
+// selectServerGroup 选择一个服务器分组，没有分组时返回 nil
+func (m *Menu) selectServerGroup(label string) (*config.ServerGroup, error) {
+	groups := m.configManager.ListServerGroups()
+	if len(groups) == 0 {
+		fmt.Println("暂无分组配置")
+		return nil, nil
+	}
+
+	var items []string
+	for _, group := range groups {
+		items = append(items, group.Path+groupDefaults(group))
+	}
+
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+		Size:  pickerSize,
+	}
+	index, _, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+	return groups[index], nil
+}
+
+// configureServerGroup 交互式配置分组的默认设置，留空表示不设置
+func (m *Menu) configureServerGroup(group *config.ServerGroup) error {
+	// 默认用户名
+	userPrompt := promptui.Prompt{
+		Label:   "默认用户名 (可选)",
+		Default: group.User,
+	}
+	user, err := userPrompt.Run()
+	if err != nil {
+		return err
+	}
+	group.User = strings.TrimSpace(user)
+
+	// 默认端口
+	portDefault := ""
+	if group.Port != 0 {
+		portDefault = strconv.Itoa(group.Port)
+	}
+	portPrompt := promptui.Prompt{
+		Label:   "默认端口 (可选)",
+		Default: portDefault,
+		Validate: func(input string) error {
+			if input == "" {
+				return nil
+			}
+			port, err := strconv.Atoi(input)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("端口必须是1-65535之间的数字")
+			}
+			return nil
+		},
+	}
+	portStr, err := portPrompt.Run()
+	if err != nil {
+		return err
+	}
+	group.Port, _ = strconv.Atoi(portStr)
+
+	// 默认凭证
+	credentials := m.configManager.ListCredentials()
+	if len(credentials) > 0 {
+		credItems := []string{"不设置"}
+		cursor := 0
+		for i, cred := range credentials {
+			credItems = append(credItems, fmt.Sprintf("[%s] %s (%s)", cred.Alias, cred.Username, cred.Type))
+			if cred.ID == group.CredentialID {
+				cursor = i + 1
+			}
+		}
+		credPrompt := promptui.Select{
+			Label:     "默认凭证",
+			Items:     credItems,
+			CursorPos: cursor,
+		}
+		credIndex, _, err := credPrompt.Run()
+		if err != nil {
+			return err
+		}
+		group.CredentialID = ""
+		if credIndex > 0 {
+			group.CredentialID = credentials[credIndex-1].ID
+		}
+	}
+
+	// 默认代理
+	proxyItems := []string{"不设置", "配置代理"}
+	cursor := 0
+	if group.Proxy != nil {
+		proxyItems = append(proxyItems, fmt.Sprintf("保留当前代理 (%s://%s:%d)", group.Proxy.Type, group.Proxy.Host, group.Proxy.Port))
+		cursor = 2
+	}
+	proxyPrompt := promptui.Select{
+		Label:     "默认代理",
+		Items:     proxyItems,
+		CursorPos: cursor,
+	}
+	proxyIndex, _, err := proxyPrompt.Run()
+	if err != nil {
+		return err
+	}
+	switch proxyIndex {
+	case 0:
+		group.Proxy = nil
+	case 1:
+		proxyConfig, err := m.configureProxy()
+		if err != nil {
+			return err
+		}
+		group.Proxy = proxyConfig
+	}
+
+	// 默认跳板机
+	jumpPrompt := promptui.Prompt{
+		Label:   "默认跳板机 (可选，已保存服务器的别名)",
+		Default: group.JumpHost,
+	}
+	jumpHost, err := jumpPrompt.Run()
+	if err != nil {
+		return err
+	}
+	group.JumpHost = strings.TrimSpace(jumpHost)
+
+	// 默认启动脚本
+	scriptPrompt := promptui.Prompt{
+		Label:   "默认启动脚本 (可选)",
+		Default: group.StartupScript,
+	}
+	script, err := scriptPrompt.Run()
+	if err != nil {
+		return err
+	}
+	group.StartupScript = script
+
+	// 描述
+	descPrompt := promptui.Prompt{
+		Label:   "描述 (可选)",
+		Default: group.Description,
+	}
+	desc, err := descPrompt.Run()
+	if err != nil {
+		return err
+	}
+	group.Description = desc
+
+	return nil
+}
+
+// groupDefaults 分组默认设置的简要说明
+func groupDefaults(group *config.ServerGroup) string {
+	var parts []string
+	if group.User != "" {
+		parts = append(parts, "用户: "+group.User)
+	}
+	if group.Port != 0 {
+		parts = append(parts, fmt.Sprintf("端口: %d", group.Port))
+	}
+	if group.CredentialID != "" {
+		parts = append(parts, "凭证")
+	}
+	if group.Proxy != nil {
+		parts = append(parts, "代理: "+group.Proxy.Type)
+	}
+	if group.JumpHost != "" {
+		parts = append(parts, "跳板机: "+group.JumpHost)
+	}
+	if group.StartupScript != "" {
+		parts = append(parts, "启动脚本")
+	}
+
+	var s string
+	if len(parts) > 0 {
+		s = " (" + strings.Join(parts, ", ") + ")"
+	}
+	if group.Description != "" {
+		s += " - " + group.Description
+	}
+	return s
+}
+
+// serverTree 按分组生成服务器树形列表，未分组的服务器排在最前
+// 服务器显示继承分组默认值后的用户名和端口
+func (m *Menu) serverTree() string {
+	servers := m.configManager.ListServers()
+	var b strings.Builder
+	index := 0
+
+	writeServers := func(path, indent string) {
+		for _, server := range servers {
+			if config.NormalizeGroupPath(server.Group) != path {
+				continue
+			}
+			index++
+			b.WriteString(indent)
+			b.WriteString(m.serverLine(index, server))
+			b.WriteString("\n")
+		}
+	}
+
+	writeServers("", "")
+	for _, path := range m.configManager.GroupPaths() {
+		depth := strings.Count(path, "/")
+		indent := strings.Repeat("  ", depth)
+		fmt.Fprintf(&b, "%s📁 %s/", indent, path[strings.LastIndex(path, "/")+1:])
+		if group, err := m.configManager.GetServerGroup(path); err == nil {
+			b.WriteString(groupDefaults(group))
+		}
+		b.WriteString("\n")
+		writeServers(path, indent+"  ")
+	}
+	return b.String()
+}
+
+// serverLine 服务器列表中的一行
+func (m *Menu) serverLine(index int, server *config.ServerConfig) string {
+	resolved := m.configManager.ResolveServer(server)
+
+	line := fmt.Sprintf("%d. ", index)
+	if server.Alias != "" {
+		line += fmt.Sprintf("[%s] ", server.Alias)
+	}
+	line += fmt.Sprintf("%s@%s:%d", resolved.User, resolved.Host, resolved.Port)
+	if server.Description != "" {
+		line += " - " + server.Description
+	}
+	if len(server.Tags) > 0 {
+		line += fmt.Sprintf(" [标签: %s]", strings.Join(server.Tags, ", "))
+	}
+	line += fmt.Sprintf(" (认证: %s)", resolved.AuthType)
+	if resolved.Proxy != nil {
+		line += fmt.Sprintf(" [代理: %s]", resolved.Proxy.Type)
+	}
+	if resolved.JumpHost != "" {
+		line += fmt.Sprintf(" [跳板机: %s]", resolved.JumpHost)
+	}
+	if m.configManager.IsDynamic(server.ID) {
+		line += fmt.Sprintf(" [%s，不可编辑]", m.configManager.Origin("servers", server.ID))
+		return line
+	}
+	line += fmt.Sprintf(" [创建时间: %s]", server.CreatedAt.Format("2006-01-02 15:04:05"))
+	return line
+}
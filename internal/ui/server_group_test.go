@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gotssh/internal/config"
+)
+
+// TestServerTree 测试按分组显示的服务器树形列表
+func TestServerTree(t *testing.T) {
+	menu, configManager, _ := createTestUIManager(t)
+
+	group := config.NewServerGroup("prod/db")
+	group.User = "postgres"
+	group.Port = 5022
+	group.Description = "数据库"
+	require.NoError(t, configManager.AddServerGroup(group))
+
+	db := config.NewServerConfig("10.0.0.2")
+	db.Alias = "db-1"
+	db.User = ""
+	db.Port = 0
+	db.Group = "prod/db"
+	require.NoError(t, configManager.AddServer(db))
+
+	web := config.NewServerConfig("10.0.0.1")
+	web.Alias = "web-1"
+	web.Group = "prod"
+	require.NoError(t, configManager.AddServer(web))
+
+	plain := config.NewServerConfig("10.0.0.3")
+	require.NoError(t, configManager.AddServer(plain))
+
+	lines := strings.Split(strings.TrimRight(menu.serverTree(), "\n"), "\n")
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "1. root@10.0.0.3:22")
+	assert.Equal(t, "📁 prod/", lines[1])
+	assert.Contains(t, lines[2], "  2. [web-1] root@10.0.0.1:22")
+	assert.Equal(t, "  📁 db/ (用户: postgres, 端口: 5022) - 数据库", lines[3])
+	assert.Contains(t, lines[4], "    3. [db-1] postgres@10.0.0.2:5022")
+
+	assert.NotPanics(t, func() {
+		menu.ShowServerList()
+	})
+}
+
+// TestServerTreeDynamic 测试动态清单中的服务器标为不可编辑
+func TestServerTreeDynamic(t *testing.T) {
+	menu, configManager, _ := createTestUIManager(t)
+	createTestServer(t, configManager)
+
+	hosts := filepath.Join(filepath.Dir(configManager.ConfigPath()), "hosts.json")
+	require.NoError(t, os.WriteFile(hosts, []byte(`{"web": ["10.0.0.8"]}`), 0644))
+	configManager.GetConfig().InventoryProviders["cloud"] = &config.InventoryProvider{File: hosts}
+	require.NoError(t, configManager.Save())
+	_, err := configManager.RefreshInventoryProviders()
+	require.NoError(t, err)
+
+	tree := menu.serverTree()
+	assert.Contains(t, tree, "[10.0.0.8] root@10.0.0.8:22 [标签: web] (认证: ask) [动态清单 (cloud)，不可编辑]")
+	assert.Contains(t, tree, "[test-server] testuser@test.example.com:22")
+
+	note := DynamicNote(configManager)
+	for _, server := range configManager.ListServers() {
+		if server.Host == "10.0.0.8" {
+			assert.Equal(t, " [动态清单，不可编辑]", note(server))
+		} else {
+			assert.Empty(t, note(server))
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"gotssh/internal/config"
+
+	"github.com/manifoldco/promptui"
+)
+
+// AddServersFromTemplate 选择模板和范围，预览后批量添加服务器
+func (m *Menu) AddServersFromTemplate() error {
+	t, err := m.selectServerTemplate("选择服务器模板")
+	if err != nil || t == nil {
+		return err
+	}
+
+	// 主机模式中带范围时可以直接使用，使用 {n} 时必须输入范围
+	rangeLabel := "范围 (如 01-40 或 1-3,7)"
+	if !strings.Contains(t.Host, "{n}") {
+		rangeLabel += "，留空使用模板中的范围"
+	}
+	rangePrompt := promptui.Prompt{
+		Label: rangeLabel,
+	}
+	rangeSpec, err := rangePrompt.Run()
+	if err != nil {
+		return err
+	}
+	rangeSpec = strings.TrimSpace(rangeSpec)
+
+	previews, err := m.configManager.PreviewServerTemplate(t.Name, rangeSpec)
+	if err != nil {
+		return err
+	}
+	fmt.Print(FormatTemplatePreview(previews))
+
+	count := addableCount(previews)
+	if count == 0 {
+		fmt.Println("没有可以添加的服务器")
+		return nil
+	}
+
+	confirmPrompt := promptui.Select{
+		Label: fmt.Sprintf("确定添加 %d 台服务器吗？", count),
+		Items: []string{"是", "否"},
+	}
+	_, confirmResult, err := confirmPrompt.Run()
+	if err != nil {
+		return err
+	}
+	if confirmResult != "是" {
+		return nil
+	}
+
+	added, skipped, err := m.configManager.AddServersFromTemplate(t.Name, rangeSpec)
+	if err != nil {
+		return fmt.Errorf("批量添加服务器失败: %w", err)
+	}
+	fmt.Printf("已添加 %d 台服务器", added)
+	if skipped > 0 {
+		fmt.Printf("，跳过 %d 台", skipped)
+	}
+	fmt.Println()
+	return nil
+}
+
+// DeleteServersByTemplate 删除由所选模板创建的所有服务器
+func (m *Menu) DeleteServersByTemplate() error {
+	t, err := m.selectServerTemplate("选择模板，删除由其创建的服务器")
+	if err != nil || t == nil {
+		return err
+	}
+
+	servers := m.configManager.ServersByTemplate(t.Name)
+	if len(servers) == 0 {
+		fmt.Printf("没有由模板 %s 创建的服务器\n", t.Name)
+		return nil
+	}
+
+	for _, server := range servers {
+		fmt.Printf("  - %s\n", ServerLabel(server))
+	}
+	confirmPrompt := promptui.Select{
+		Label: fmt.Sprintf("确定删除以上 %d 台服务器及其端口转发吗？", len(servers)),
+		Items: []string{"是", "否"},
+	}
+	_, confirmResult, err := confirmPrompt.Run()
+	if err != nil {
+		return err
+	}
+	if confirmResult != "是" {
+		return nil
+	}
+
+	deleted, err := m.configManager.DeleteServersByTemplate(t.Name)
+	if err != nil {
+		return fmt.Errorf("批量删除服务器失败: %w", err)
+	}
+	fmt.Printf("已删除 %d 台服务器\n", deleted)
+	return nil
+}
+
+// selectServerTemplate 选择服务器模板，没有模板时返回 nil
+func (m *Menu) selectServerTemplate(label string) (*config.ServerTemplate, error) {
+	templates := m.configManager.ListServerTemplates()
+	if len(templates) == 0 {
+		fmt.Println("暂无服务器模板，请在配置文件的 server_templates 中添加")
+		return nil, nil
+	}
+
+	var items []string
+	for _, t := range templates {
+		items = append(items, TemplateLabel(t))
+	}
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+		Size:  pickerSize,
+	}
+	index, _, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+	return templates[index], nil
+}
+
+// TemplateLabel 服务器模板的显示形式
+func TemplateLabel(t *config.ServerTemplate) string {
+	label := fmt.Sprintf("[%s] %s", t.Name, t.Host)
+	if t.Alias != "" {
+		label += " (别名: " + t.Alias + ")"
+	}
+	if t.Group != "" {
+		label += " (分组: " + t.Group + ")"
+	}
+	if t.Description != "" {
+		label += " - " + t.Description
+	}
+	return label
+}
+
+// FormatTemplatePreview 模板展开结果的预览，标出将被跳过的服务器
+func FormatTemplatePreview(previews []config.TemplatePreview) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== 将添加 %d 台服务器 ===\n", addableCount(previews))
+	for _, preview := range previews {
+		if preview.Conflict != "" {
+			fmt.Fprintf(&b, "  ✗ %s (跳过: %s)\n", ServerLabel(preview.Server), preview.Conflict)
+		} else {
+			fmt.Fprintf(&b, "  + %s\n", ServerLabel(preview.Server))
+		}
+	}
+	return b.String()
+}
+
+// addableCount 预览中可以添加的服务器数
+func addableCount(previews []config.TemplatePreview) int {
+	count := 0
+	for _, preview := range previews {
+		if preview.Conflict == "" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gotssh/internal/config"
+)
+
+// TestTemplateLabel 测试服务器模板的显示
+func TestTemplateLabel(t *testing.T) {
+	tmpl := &config.ServerTemplate{Name: "k8s", Host: "node-[01-40]", Alias: "k8s-{n}", Group: "prod", Description: "集群"}
+	assert.Equal(t, "[k8s] node-[01-40] (别名: k8s-{n}) (分组: prod) - 集群", TemplateLabel(tmpl))
+	assert.Equal(t, "[web] web{n}", TemplateLabel(&config.ServerTemplate{Name: "web", Host: "web{n}"}))
+}
+
+// TestFormatTemplatePreview 测试模板预览标出跳过的服务器
+func TestFormatTemplatePreview(t *testing.T) {
+	previews := []config.TemplatePreview{
+		{Server: &config.ServerConfig{Alias: "k8s-01", User: "root", Host: "node-01", Port: 22}},
+		{Server: &config.ServerConfig{Alias: "k8s-02", User: "root", Host: "node-02", Port: 22}, Conflict: "服务器已存在"},
+	}
+
+	preview := FormatTemplatePreview(previews)
+	assert.Contains(t, preview, "=== 将添加 1 台服务器 ===")
+	assert.Contains(t, preview, "  + [k8s-01] root@node-01:22\n")
+	assert.Contains(t, preview, "  ✗ [k8s-02] root@node-02:22 (跳过: 服务器已存在)\n")
+}